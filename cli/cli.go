@@ -0,0 +1,267 @@
+// Package cli implements the okinoko command: one subcommand per DAO export.
+// Every command validates its arguments with the client package, prints the
+// vsc.call transaction it would send (action, payload and transfer.allow
+// intent), and optionally submits it.
+//
+// The command holds no keys. With -submit the unsigned transaction is POSTed
+// as JSON to an endpoint that signs and broadcasts it (a wallet bridge or a
+// node-side relay). Built with the native tag, the contract binary runs the
+// same commands against a local emulator instead; see RunLocal.
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"okinoko_dao/client"
+)
+
+// Intent is an intent attached to a transaction, e.g. transfer.allow.
+type Intent struct {
+	Type string            `json:"type"`
+	Args map[string]string `json:"args"`
+}
+
+// Tx is the body of a vsc.call operation.
+type Tx struct {
+	NetID      string   `json:"net_id"`
+	Caller     string   `json:"caller,omitempty"`
+	ContractID string   `json:"contract_id"`
+	Action     string   `json:"action"`
+	Payload    string   `json:"payload"`
+	RcLimit    uint     `json:"rc_limit"`
+	Intents    []Intent `json:"intents"`
+}
+
+// Result is what a submitter reports back.
+type Result struct {
+	OK   bool     `json:"ok"`
+	Ret  string   `json:"ret,omitempty"`
+	Err  string   `json:"err,omitempty"`
+	Code string   `json:"code,omitempty"` // revert symbol, see package errcode
+	Logs []string `json:"logs,omitempty"`
+}
+
+// Submitter sends a transaction somewhere.
+type Submitter interface {
+	Submit(ctx context.Context, tx Tx) (Result, error)
+}
+
+// Chain is implemented by submitters backed by a local emulator, which also
+// serve the dev subcommands.
+type Chain interface {
+	Submitter
+	Deposit(address string, amount client.Amount, asset string)
+	Advance(d time.Duration)
+}
+
+// Env is where Run reads and writes.
+type Env struct {
+	Out io.Writer
+	// Local, when set, receives every transaction instead of -submit.
+	Local Submitter
+	// ContractID is the default for -contract.
+	ContractID string
+}
+
+// ErrUsage marks a command line that could not be parsed.
+var ErrUsage = errors.New("invalid usage")
+
+// errFlags is a flag error the flag package has already printed.
+var errFlags = fmt.Errorf("%w: bad flags", ErrUsage)
+
+const usage = `usage: okinoko [flags] <command> [args]
+
+commands:
+  init [-owner-only] [-events legacy|json|both]
+  contract mode <public|owner-only>
+  contract transfer <address>
+  contract accept|renounce
+  contract fee <amount> [asset]              (0 removes the fee)
+  project create -name N -deposit AMOUNT [-asset A] [options]
+  project join <project> -deposit AMOUNT [-asset A]
+  project leave <project>
+  project unstake <project> <amount>
+  project funds <project> <amount> [-stake] [-asset A]
+  project transfer <project> <address>
+  project pause <project> <true|false>
+  proposal create -project ID -name N [-simulate] [options]
+  proposal vote <proposal> <choice>...
+  proposal tally|execute|cancel|simulate <proposal>
+  whitelist add|remove <project> <address>...
+  dev deposit <address> <amount> <asset>     (local emulator only)
+  dev advance <duration>                     (local emulator only)
+
+Run "okinoko <command> -h" for a command's flags.
+
+flags:
+`
+
+// Run executes one command line and returns the process exit code.
+func Run(args []string, env Env) int {
+	if env.Out == nil {
+		env.Out = os.Stdout
+	}
+	err := run(args, env)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errFlags):
+		return 2
+	case errors.Is(err, ErrUsage):
+		fmt.Fprintln(env.Out, "error:", err)
+		return 2
+	default:
+		fmt.Fprintln(env.Out, "error:", err)
+		return 1
+	}
+}
+
+func run(args []string, env Env) error {
+	fs := flag.NewFlagSet("okinoko", flag.ContinueOnError)
+	fs.SetOutput(env.Out)
+	tx := Tx{}
+	fs.StringVar(&tx.ContractID, "contract", firstNonEmpty(os.Getenv("OKINOKO_CONTRACT"), env.ContractID), "contract id (default $OKINOKO_CONTRACT)")
+	fs.StringVar(&tx.Caller, "as", os.Getenv("OKINOKO_CALLER"), "calling account (default $OKINOKO_CALLER)")
+	fs.StringVar(&tx.NetID, "net", "vsc-mainnet", "network id")
+	fs.UintVar(&tx.RcLimit, "rc", 1000, "RC limit")
+	submit := fs.String("submit", "", "POST the transaction to this URL")
+	quiet := fs.Bool("q", false, "do not print the transaction")
+	fs.Usage = func() {
+		fmt.Fprint(env.Out, usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return flagError(err)
+	}
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		return fmt.Errorf("%w: command required", ErrUsage)
+	}
+
+	var sub Submitter
+	switch {
+	case env.Local != nil && *submit != "":
+		return fmt.Errorf("%w: -submit cannot be used with the local emulator", ErrUsage)
+	case env.Local != nil:
+		sub = env.Local
+	case *submit != "":
+		sub = HTTPSubmitter{URL: *submit}
+	}
+
+	if rest[0] == "dev" {
+		chain, ok := sub.(Chain)
+		if !ok {
+			return fmt.Errorf("dev commands need the local emulator")
+		}
+		return runDev(rest[1:], chain, env.Out)
+	}
+	call, allow, err := build(rest, env.Out)
+	if err != nil {
+		return err
+	}
+	if tx.ContractID == "" {
+		return fmt.Errorf("%w: -contract required", ErrUsage)
+	}
+	tx.Action, tx.Payload = call.Action, call.Payload
+	tx.Intents = []Intent{}
+	if allow != nil {
+		tx.Intents = append(tx.Intents, Intent{
+			Type: "transfer.allow",
+			Args: map[string]string{"limit": allow.amount.String(), "token": allow.asset},
+		})
+	}
+	if !*quiet {
+		b, _ := json.MarshalIndent(tx, "", "  ")
+		fmt.Fprintf(env.Out, "%s\n", b)
+	}
+	if sub == nil {
+		return nil
+	}
+	if tx.Caller == "" {
+		return fmt.Errorf("%w: -as required to submit", ErrUsage)
+	}
+	res, err := sub.Submit(context.Background(), tx)
+	if err != nil {
+		return err
+	}
+	for _, line := range res.Logs {
+		fmt.Fprintln(env.Out, "log:", line)
+	}
+	if !res.OK {
+		return fmt.Errorf("%s failed: %w", tx.Action, client.ParseError(res.Code, res.Err))
+	}
+	fmt.Fprintln(env.Out, "ok:", res.Ret)
+	return nil
+}
+
+// HTTPSubmitter POSTs the transaction as JSON to URL. A 2xx response is
+// success; a JSON Result body is decoded, anything else is returned as Ret.
+type HTTPSubmitter struct {
+	URL    string
+	Client *http.Client
+}
+
+// Submit implements Submitter.
+func (h HTTPSubmitter) Submit(ctx context.Context, tx Tx) (Result, error) {
+	body, err := json.Marshal(tx)
+	if err != nil {
+		return Result{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	hc := h.Client
+	if hc == nil {
+		hc = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Result{}, err
+	}
+	text := strings.TrimSpace(string(data))
+	if resp.StatusCode/100 != 2 {
+		return Result{Err: fmt.Sprintf("%s: %s", resp.Status, text)}, nil
+	}
+	var res Result
+	if json.Unmarshal(data, &res) == nil && (res.OK || res.Err != "") {
+		return res, nil
+	}
+	return Result{OK: true, Ret: text}, nil
+}
+
+// flagError keeps -h a clean exit and marks other parse errors as reported.
+func flagError(err error) error {
+	if errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return errFlags
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
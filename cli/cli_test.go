@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// fakeSubmitter records what it is sent and reports success, or fail if set.
+type fakeSubmitter struct {
+	sent []Tx
+	fail *Result
+}
+
+func (f *fakeSubmitter) Submit(_ context.Context, tx Tx) (Result, error) {
+	f.sent = append(f.sent, tx)
+	if f.fail != nil {
+		return *f.fail, nil
+	}
+	return Result{OK: true, Ret: "done", Logs: []string{"event"}}, nil
+}
+
+func TestRunRendersTransaction(t *testing.T) {
+	var out bytes.Buffer
+	code := Run([]string{"-contract", "c1", "project", "join", "3", "-deposit", "1.5", "-asset", "hbd"}, Env{Out: &out})
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, out.String())
+	}
+	var tx Tx
+	if err := json.Unmarshal(out.Bytes(), &tx); err != nil {
+		t.Fatalf("output is not a transaction: %v\n%s", err, out.String())
+	}
+	if tx.Action != "project_join" || tx.ContractID != "c1" || tx.Payload != "3" {
+		t.Fatalf("tx %+v", tx)
+	}
+	if len(tx.Intents) != 1 || tx.Intents[0].Args["limit"] != "1.500" || tx.Intents[0].Args["token"] != "hbd" {
+		t.Fatalf("intents %+v", tx.Intents)
+	}
+}
+
+func TestRunSubmits(t *testing.T) {
+	var out bytes.Buffer
+	sub := &fakeSubmitter{}
+	code := Run([]string{"-as", "hive:alice", "-q", "proposal", "vote", "7", "1", "2"}, Env{Out: &out, Local: sub, ContractID: "local"})
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, out.String())
+	}
+	if len(sub.sent) != 1 || sub.sent[0].Caller != "hive:alice" || sub.sent[0].Action != "proposals_vote" {
+		t.Fatalf("sent %+v", sub.sent)
+	}
+	if out.String() != "log: event\nok: done\n" {
+		t.Fatalf("output %q", out.String())
+	}
+}
+
+func TestRunReportsErrorCode(t *testing.T) {
+	var out bytes.Buffer
+	sub := &fakeSubmitter{fail: &Result{Err: "cooldown not passed", Code: "E_COOLDOWN"}}
+	code := Run([]string{"-as", "hive:alice", "-q", "project", "leave", "3"}, Env{Out: &out, Local: sub, ContractID: "local"})
+	if code != 1 || out.String() != "error: project_leave failed: E_COOLDOWN: cooldown not passed\n" {
+		t.Fatalf("exit %d, output %q", code, out.String())
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	cases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{}, 2},
+		{[]string{"-contract", "c", "project", "frobnicate"}, 2},
+		{[]string{"-contract", "c", "proposal", "vote", "1"}, 2},
+		{[]string{"-contract", "c", "project", "join", "1", "-nope"}, 2},
+		{[]string{"-contract", "c", "project", "join", "1", "-deposit", "abc"}, 2},
+		{[]string{"-contract", "c", "project", "join", "x", "-deposit", "1"}, 1},
+		{[]string{"dev", "advance", "1h"}, 1},
+		{[]string{"-contract", "c", "proposal", "tally", "-h"}, 0},
+	}
+	for _, c := range cases {
+		var out bytes.Buffer
+		if code := Run(c.args, Env{Out: &out}); code != c.code {
+			t.Errorf("%s: exit %d, want %d\n%s", strings.Join(c.args, " "), code, c.code, out.String())
+		}
+	}
+}
@@ -0,0 +1,588 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"okinoko_dao/client"
+)
+
+// allowance is the transfer.allow intent a command attaches.
+type allowance struct {
+	amount client.Amount
+	asset  string
+}
+
+// build turns a command line into the call it stands for.
+func build(args []string, out io.Writer) (client.Call, *allowance, error) {
+	if args[0] == "init" {
+		return buildInit(args[1:], out)
+	}
+	if len(args) < 2 {
+		return client.Call{}, nil, fmt.Errorf("%w: unknown command %q", ErrUsage, strings.Join(args, " "))
+	}
+	group, verb, rest := args[0], args[1], args[2:]
+	switch group + " " + verb {
+	case "contract mode":
+		return buildContractMode(rest, out)
+	case "contract transfer":
+		return buildContractTransfer(rest, out)
+	case "contract accept", "contract renounce":
+		fs := newFlags("contract "+verb, "", out)
+		if err := parse(fs, rest, 0); err != nil {
+			return client.Call{}, nil, err
+		}
+		if verb == "accept" {
+			return client.AcceptContract(), nil, nil
+		}
+		return client.RenounceContract(), nil, nil
+	case "contract fee":
+		return buildContractFee(rest, out)
+	case "project create":
+		return buildCreateProject(rest, out)
+	case "project join":
+		return buildJoin(rest, out)
+	case "project leave":
+		return idCommand(verb, rest, out, client.Leave)
+	case "project unstake":
+		return buildUnstake(rest, out)
+	case "project funds":
+		return buildFunds(rest, out)
+	case "project transfer":
+		return buildTransfer(rest, out)
+	case "project pause":
+		return buildPause(rest, out)
+	case "proposal create":
+		return buildCreateProposal(rest, out)
+	case "proposal vote":
+		return buildVote(rest, out)
+	case "proposal tally":
+		return idCommand(verb, rest, out, client.Tally)
+	case "proposal execute":
+		return idCommand(verb, rest, out, client.Execute)
+	case "proposal cancel":
+		return idCommand(verb, rest, out, client.Cancel)
+	case "proposal simulate":
+		return idCommand(verb, rest, out, client.Simulate)
+	case "whitelist add":
+		return buildWhitelist(verb, rest, out, client.WhitelistAdd)
+	case "whitelist remove":
+		return buildWhitelist(verb, rest, out, client.WhitelistRemove)
+	}
+	return client.Call{}, nil, fmt.Errorf("%w: unknown command %q", ErrUsage, group+" "+verb)
+}
+
+func buildInit(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("init", "", out)
+	ownerOnly := fs.Bool("owner-only", false, "only the contract owner may create projects")
+	events := fs.String("events", "", "event format: legacy, json or both")
+	if err := parse(fs, args, 0); err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := client.Init{OwnerOnly: *ownerOnly, Events: *events}.Call()
+	return call, nil, err
+}
+
+func buildContractMode(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("contract mode", "<public|owner-only>", out)
+	if err := parse(fs, args, 1); err != nil {
+		return client.Call{}, nil, err
+	}
+	switch fs.Arg(0) {
+	case "public":
+		return client.SetCreationMode(false), nil, nil
+	case "owner-only":
+		return client.SetCreationMode(true), nil, nil
+	}
+	return client.Call{}, nil, fmt.Errorf("mode must be public or owner-only")
+}
+
+func buildContractTransfer(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("contract transfer", "<address>", out)
+	if err := parse(fs, args, 1); err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := client.TransferContract(fs.Arg(0))
+	return call, nil, err
+}
+
+func buildContractFee(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("contract fee", "<amount> [asset]", out)
+	if err := parseArgs(fs, args); err != nil {
+		return client.Call{}, nil, err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return client.Call{}, nil, fmt.Errorf("%w: contract fee takes an amount and an asset", ErrUsage)
+	}
+	fee, err := client.ParseAmount(fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := client.SetCreationFee(fee, fs.Arg(1))
+	return call, nil, err
+}
+
+func buildCreateProject(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("project create", "", out)
+	var a client.CreateProjectArgs
+	fs.StringVar(&a.Name, "name", "", "project name")
+	fs.StringVar(&a.Description, "desc", "", "project description")
+	voting := fs.String("voting", "democratic", "voting system: democratic, stake or stake-reputation")
+	fs.Float64Var(&a.ThresholdPercent, "threshold", 0, "pass threshold in percent (0 = contract default)")
+	fs.Float64Var(&a.QuorumPercent, "quorum", 0, "quorum in percent (0 = contract default)")
+	fs.Uint64Var(&a.ProposalDuration, "duration", 0, "default voting period in hours")
+	fs.Uint64Var(&a.ExecutionDelay, "delay", 0, "execution delay in hours")
+	fs.Uint64Var(&a.LeaveCooldown, "cooldown", 0, "leave cooldown in hours")
+	fs.Var((*amountFlag)(&a.ProposalCost), "cost", "proposal cost")
+	fs.Var((*amountFlag)(&a.StakeMin), "stake-min", "minimum stake to join")
+	fs.StringVar(&a.MembershipContract, "nft-contract", "", "membership NFT contract")
+	fs.StringVar(&a.MembershipFunction, "nft-function", "", "membership NFT query function")
+	fs.StringVar(&a.MembershipNFT, "nft", "", "membership NFT id")
+	fs.StringVar(&a.MembershipPayloadFormat, "nft-payload", "", "membership query payload format")
+	fs.StringVar(&a.Metadata, "metadata", "", "project metadata")
+	fs.StringVar(&a.URL, "url", "", "project URL")
+	fs.BoolVar(&a.MembersOnly, "members-only", false, "only members may create proposals")
+	fs.BoolVar(&a.WhitelistOnly, "whitelist-only", false, "only whitelisted addresses may join")
+	var stakeAssets listFlag
+	fs.Var(&stakeAssets, "stake-asset", "additional stake asset as asset=weight (repeatable)")
+	allow := allowFlags(fs, "deposit", "founding stake to transfer")
+	if err := parse(fs, args, 0); err != nil {
+		return client.Call{}, nil, err
+	}
+	vs, err := parseVotingSystem(*voting)
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	a.VotingSystem = vs
+	for _, entry := range stakeAssets {
+		asset, w, ok := strings.Cut(entry, "=")
+		weight, err := strconv.ParseFloat(w, 64)
+		if !ok || err != nil {
+			return client.Call{}, nil, fmt.Errorf("stake asset must be asset=weight, got %q", entry)
+		}
+		if a.StakeAssets == nil {
+			a.StakeAssets = map[string]float64{}
+		}
+		a.StakeAssets[asset] = weight
+	}
+	if strings.TrimSpace(a.Name) == "" {
+		return client.Call{}, nil, fmt.Errorf("%w: -name required", ErrUsage)
+	}
+	if err := allow.require("-deposit"); err != nil {
+		return client.Call{}, nil, err
+	}
+	if allow.amount < a.StakeMin {
+		return client.Call{}, nil, fmt.Errorf("deposit must cover the minimum stake of %s", a.StakeMin)
+	}
+	call, err := a.Call()
+	return call, allow.intent(), err
+}
+
+func buildJoin(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("project join", "<project>", out)
+	allow := allowFlags(fs, "deposit", "stake or membership fee to transfer")
+	if err := parse(fs, args, 1); err != nil {
+		return client.Call{}, nil, err
+	}
+	id, err := parseID("project", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	return client.Join(id), allow.intent(), nil
+}
+
+func buildUnstake(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("project unstake", "<project> <amount>", out)
+	if err := parse(fs, args, 2); err != nil {
+		return client.Call{}, nil, err
+	}
+	id, err := parseID("project", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	amount, err := client.ParseAmount(fs.Arg(1))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := client.Unstake(id, amount)
+	return call, nil, err
+}
+
+func buildFunds(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("project funds", "<project> <amount>", out)
+	toStake := fs.Bool("stake", false, "add to your stake instead of the treasury")
+	asset := fs.String("asset", "hive", "asset to transfer")
+	if err := parse(fs, args, 2); err != nil {
+		return client.Call{}, nil, err
+	}
+	id, err := parseID("project", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	allow := &allowance{asset: *asset}
+	if allow.amount, err = client.ParseAmount(fs.Arg(1)); err != nil {
+		return client.Call{}, nil, err
+	}
+	if err := allow.require("amount"); err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := client.AddFunds(id, *toStake)
+	return call, allow, err
+}
+
+func buildTransfer(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("project transfer", "<project> <address>", out)
+	if err := parse(fs, args, 2); err != nil {
+		return client.Call{}, nil, err
+	}
+	id, err := parseID("project", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := client.TransferOwnership(id, fs.Arg(1))
+	return call, nil, err
+}
+
+func buildPause(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("project pause", "<project> <true|false>", out)
+	if err := parse(fs, args, 2); err != nil {
+		return client.Call{}, nil, err
+	}
+	id, err := parseID("project", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	paused, err := strconv.ParseBool(fs.Arg(1))
+	if err != nil {
+		return client.Call{}, nil, fmt.Errorf("paused must be true or false")
+	}
+	call, err := client.Pause(id, paused)
+	return call, nil, err
+}
+
+func buildCreateProposal(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("proposal create", "", out)
+	var a client.CreateProposalArgs
+	project := fs.String("project", "", "project id")
+	fs.StringVar(&a.Name, "name", "", "proposal name")
+	fs.StringVar(&a.Description, "desc", "", "proposal description")
+	fs.Uint64Var(&a.Duration, "duration", 0, "voting period in hours (0 = project default)")
+	fs.BoolVar(&a.ForcePoll, "poll", false, "make it a poll even without options")
+	fs.StringVar(&a.Metadata, "metadata", "", "proposal metadata")
+	fs.StringVar(&a.URL, "url", "", "proposal URL")
+	var options, payouts, meta, iccs listFlag
+	fs.Var(&options, "option", "poll option as text or text|https://url (repeatable)")
+	fs.Var(&payouts, "payout", "payout as address:amount:asset[:l1] (repeatable)")
+	fs.Var(&meta, "meta", "meta action as key=value (repeatable)")
+	fs.Var(&iccs, "icc", "contract call as contract|function|payload[|asset=amount,...] (repeatable)")
+	allow := allowFlags(fs, "cost", "proposal cost to transfer")
+	simulate := fs.Bool("simulate", false, "dry-run the proposal instead of creating it")
+	if err := parse(fs, args, 0); err != nil {
+		return client.Call{}, nil, err
+	}
+	var err error
+	if a.ProjectID, err = parseID("project", *project); err != nil {
+		return client.Call{}, nil, err
+	}
+	if strings.TrimSpace(a.Name) == "" {
+		return client.Call{}, nil, fmt.Errorf("%w: -name required", ErrUsage)
+	}
+	for _, o := range options {
+		opt := client.Option{Text: o}
+		if i := strings.LastIndex(o, "|"); i >= 0 && strings.HasPrefix(o[i+1:], "https://") {
+			opt = client.Option{Text: o[:i], URL: o[i+1:]}
+		}
+		a.Options = append(a.Options, opt)
+	}
+	for _, p := range payouts {
+		payout, err := parsePayout(p)
+		if err != nil {
+			return client.Call{}, nil, err
+		}
+		a.Payouts = append(a.Payouts, payout)
+	}
+	for _, m := range meta {
+		key, value, _ := strings.Cut(m, "=")
+		action, err := client.ParseMeta(key, value)
+		if err != nil {
+			return client.Call{}, nil, err
+		}
+		a.Meta = append(a.Meta, action)
+	}
+	for _, c := range iccs {
+		icc, err := parseICC(c)
+		if err != nil {
+			return client.Call{}, nil, err
+		}
+		a.ICC = append(a.ICC, icc)
+	}
+	if *simulate {
+		call, err := a.Simulate()
+		return call, nil, err
+	}
+	call, err := a.Call()
+	return call, allow.intent(), err
+}
+
+func buildVote(args []string, out io.Writer) (client.Call, *allowance, error) {
+	fs := newFlags("proposal vote", "<proposal> <choice>...", out)
+	if err := parseArgs(fs, args); err != nil {
+		return client.Call{}, nil, err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return client.Call{}, nil, fmt.Errorf("%w: proposal and at least one choice required", ErrUsage)
+	}
+	id, err := parseID("proposal", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	var choices []uint
+	for _, c := range fs.Args()[1:] {
+		v, err := strconv.ParseUint(c, 10, 32)
+		if err != nil {
+			return client.Call{}, nil, fmt.Errorf("invalid choice %q", c)
+		}
+		choices = append(choices, uint(v))
+	}
+	call, err := client.Vote(id, choices...)
+	return call, nil, err
+}
+
+func buildWhitelist(verb string, args []string, out io.Writer, build func(uint64, ...string) (client.Call, error)) (client.Call, *allowance, error) {
+	fs := newFlags("whitelist "+verb, "<project> <address>...", out)
+	if err := parseArgs(fs, args); err != nil {
+		return client.Call{}, nil, err
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return client.Call{}, nil, fmt.Errorf("%w: project and at least one address required", ErrUsage)
+	}
+	id, err := parseID("project", fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	call, err := build(id, fs.Args()[1:]...)
+	return call, nil, err
+}
+
+// idCommand handles exports whose only argument is an id.
+func idCommand(verb string, args []string, out io.Writer, build func(uint64) client.Call) (client.Call, *allowance, error) {
+	what := "proposal"
+	if verb == "leave" {
+		what = "project"
+	}
+	fs := newFlags(what+" "+verb, "<"+what+">", out)
+	if err := parse(fs, args, 1); err != nil {
+		return client.Call{}, nil, err
+	}
+	id, err := parseID(what, fs.Arg(0))
+	if err != nil {
+		return client.Call{}, nil, err
+	}
+	return build(id), nil, nil
+}
+
+func runDev(args []string, chain Chain, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: dev deposit|advance", ErrUsage)
+	}
+	switch args[0] {
+	case "deposit":
+		fs := newFlags("dev deposit", "<address> <amount> <asset>", out)
+		if err := parse(fs, args[1:], 3); err != nil {
+			return err
+		}
+		amount, err := client.ParseAmount(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		if amount <= 0 {
+			return fmt.Errorf("deposit amount must be positive")
+		}
+		chain.Deposit(fs.Arg(0), amount, fs.Arg(2))
+		fmt.Fprintf(out, "deposited %s %s to %s\n", amount, fs.Arg(2), fs.Arg(0))
+		return nil
+	case "advance":
+		fs := newFlags("dev advance", "<duration>", out)
+		if err := parse(fs, args[1:], 1); err != nil {
+			return err
+		}
+		d, err := time.ParseDuration(fs.Arg(0))
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid duration %q", fs.Arg(0))
+		}
+		chain.Advance(d)
+		fmt.Fprintf(out, "advanced %s\n", d)
+		return nil
+	}
+	return fmt.Errorf("%w: unknown command %q", ErrUsage, "dev "+args[0])
+}
+
+// -----------------------------------------------------------------------------
+// Flag helpers
+// -----------------------------------------------------------------------------
+
+// parseArgs parses flags wherever they appear, so "join 3 -deposit 1" works
+// like "join -deposit 1 3"; fs.Args() then holds the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return flagError(err)
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	return fs.Parse(append([]string{"--"}, positional...))
+}
+
+func newFlags(name, argsUsage string, out io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.Usage = func() {
+		fmt.Fprintf(out, "usage: okinoko %s [flags] %s\n", name, argsUsage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses flags and requires exactly n positional arguments.
+func parse(fs *flag.FlagSet, args []string, n int) error {
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != n {
+		fs.Usage()
+		return fmt.Errorf("%w: %s takes %d argument(s)", ErrUsage, fs.Name(), n)
+	}
+	return nil
+}
+
+// allowFlags registers -<name> and -asset for a command that transfers funds.
+func allowFlags(fs *flag.FlagSet, name, help string) *allowance {
+	a := &allowance{}
+	fs.Var((*amountFlag)(&a.amount), name, help)
+	fs.StringVar(&a.asset, "asset", "hive", "asset to transfer")
+	return a
+}
+
+func (a *allowance) require(what string) error {
+	if a.amount <= 0 {
+		return fmt.Errorf("%w: %s must be positive", ErrUsage, what)
+	}
+	return nil
+}
+
+// intent returns nil when nothing is transferred.
+func (a *allowance) intent() *allowance {
+	if a.amount <= 0 {
+		return nil
+	}
+	return a
+}
+
+type amountFlag client.Amount
+
+func (a *amountFlag) String() string { return client.Amount(*a).String() }
+
+func (a *amountFlag) Set(s string) error {
+	v, err := client.ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	if v < 0 {
+		return fmt.Errorf("amount cannot be negative")
+	}
+	*a = amountFlag(v)
+	return nil
+}
+
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ", ") }
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func parseID(what, s string) (uint64, error) {
+	id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s id %q", what, s)
+	}
+	return id, nil
+}
+
+func parseVotingSystem(s string) (client.VotingSystem, error) {
+	switch s {
+	case "democratic", "0":
+		return client.Democratic, nil
+	case "stake", "1":
+		return client.Stake, nil
+	case "stake-reputation", "2":
+		return client.StakeReputation, nil
+	}
+	return 0, fmt.Errorf("voting system must be democratic, stake or stake-reputation")
+}
+
+// parsePayout reads address:amount:asset[:l1]. Addresses contain colons, so
+// the fields are taken from the right.
+func parsePayout(s string) (client.Payout, error) {
+	parts := strings.Split(s, ":")
+	l1 := len(parts) > 0 && parts[len(parts)-1] == "l1"
+	if l1 {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) < 3 {
+		return client.Payout{}, fmt.Errorf("payout must be address:amount:asset[:l1], got %q", s)
+	}
+	n := len(parts)
+	amount, err := client.ParseAmount(parts[n-2])
+	if err != nil {
+		return client.Payout{}, err
+	}
+	return client.Payout{Address: strings.Join(parts[:n-2], ":"), Amount: amount, Asset: parts[n-1], L1: l1}, nil
+}
+
+// parseICC reads contract|function|payload[|asset=amount,...]. The payload
+// may itself contain "|"; a last segment is only taken as assets when every
+// entry in it is asset=amount.
+func parseICC(s string) (client.ICC, error) {
+	parts := strings.SplitN(s, "|", 3)
+	if len(parts) < 3 {
+		return client.ICC{}, fmt.Errorf("icc must be contract|function|payload[|asset=amount,...], got %q", s)
+	}
+	icc := client.ICC{Contract: parts[0], Function: parts[1], Payload: parts[2]}
+	if i := strings.LastIndex(icc.Payload, "|"); i >= 0 {
+		if assets, ok := parseICCAssets(icc.Payload[i+1:]); ok {
+			icc.Payload, icc.Assets = icc.Payload[:i], assets
+		}
+	}
+	return icc, nil
+}
+
+func parseICCAssets(s string) (map[string]client.Amount, bool) {
+	assets := map[string]client.Amount{}
+	for _, entry := range strings.Split(s, ",") {
+		asset, amt, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, false
+		}
+		v, err := client.ParseAmount(amt)
+		if err != nil {
+			return nil, false
+		}
+		assets[strings.TrimSpace(asset)] = v
+	}
+	return assets, true
+}
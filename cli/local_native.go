@@ -0,0 +1,117 @@
+//go:build native
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+
+	"okinoko_dao/client"
+	"okinoko_dao/emulator"
+	"okinoko_dao/sdk"
+)
+
+// LocalContractID is the id the DAO is registered under in the local emulator.
+const LocalContractID = "okinoko-dao"
+
+// RunLocal runs the command against a local emulator whose chain persists in
+// $OKINOKO_EMULATOR (default okinoko-emulator.gob) between invocations. The
+// contract, built with the native tag, passes its method table:
+//
+//	go build -tags native -o okinoko-local ./contract
+//	./okinoko-local dev deposit hive:alice 100 hive
+//	./okinoko-local -as hive:alice init
+func RunLocal(args []string, methods map[string]emulator.Method) int {
+	path := os.Getenv("OKINOKO_EMULATOR")
+	if path == "" {
+		path = "okinoko-emulator.gob"
+	}
+	chain, err := LoadLocalChain(path, methods)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	code := Run(args, Env{Out: os.Stdout, Local: chain, ContractID: LocalContractID})
+	if err := chain.Save(path); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return code
+}
+
+// LocalChain adapts the emulator to Chain.
+type LocalChain struct {
+	emu *emulator.Emulator
+}
+
+var _ Chain = LocalChain{}
+
+// NewLocalChain starts an empty chain with the DAO registered as
+// LocalContractID.
+func NewLocalChain(start time.Time, methods map[string]emulator.Method) LocalChain {
+	emu := emulator.New(start)
+	emu.Register(LocalContractID, "hive:deployer", methods)
+	return LocalChain{emu}
+}
+
+// LoadLocalChain restores the chain saved at path, or starts a new one when
+// there is none.
+func LoadLocalChain(path string, methods map[string]emulator.Method) (LocalChain, error) {
+	chain := NewLocalChain(time.Now().UTC().Truncate(time.Second), methods)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return chain, nil
+	}
+	if err != nil {
+		return chain, err
+	}
+	defer f.Close()
+	if err := chain.emu.Load(f); err != nil {
+		return chain, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return chain, nil
+}
+
+// Save writes the chain to path.
+func (c LocalChain) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.emu.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Submit implements Submitter.
+func (c LocalChain) Submit(_ context.Context, tx Tx) (Result, error) {
+	intents := make([]sdk.Intent, len(tx.Intents))
+	for i, in := range tx.Intents {
+		intents[i] = sdk.Intent{Type: in.Type, Args: in.Args}
+	}
+	res := c.emu.Call(emulator.Call{
+		Caller:     tx.Caller,
+		ContractID: tx.ContractID,
+		Action:     tx.Action,
+		Payload:    strconv.Quote(tx.Payload),
+		Intents:    intents,
+	})
+	return Result{OK: res.Success, Ret: res.Ret, Err: res.Err, Code: res.Symbol, Logs: res.Logs}, nil
+}
+
+// Deposit implements Chain.
+func (c LocalChain) Deposit(address string, amount client.Amount, asset string) {
+	c.emu.Deposit(address, asset, int64(amount))
+}
+
+// Advance implements Chain.
+func (c LocalChain) Advance(d time.Duration) {
+	c.emu.Advance(d)
+}
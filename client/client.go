@@ -0,0 +1,191 @@
+// Package client builds payloads for the DAO contract's exports and parses the
+// events it logs, so integrators do not hand-craft pipe strings such as
+// "1|Title|Desc|24||0|hive:alice:5.000:hbd|||".
+//
+// Builders produce the JSON object form every export accepts, which carries
+// any text verbatim. Each builder validates against the contract's own limits
+// (okinoko_dao/limits) and returns the error the contract would abort with, so
+// a bad call fails before it is submitted.
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/limits"
+)
+
+// Call is one contract invocation: the export to call and its payload.
+type Call struct {
+	Action  string
+	Payload string
+}
+
+// Amount is an asset amount in base units (AmountScale per whole unit).
+type Amount int64
+
+// ParseAmount reads a decimal amount such as "1.5" or "1.500".
+func ParseAmount(s string) (Amount, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	scaled := math.Round(v * limits.AmountScale)
+	if scaled >= math.MaxInt64 || scaled < math.MinInt64 {
+		return 0, fmt.Errorf("amount out of range")
+	}
+	return Amount(scaled), nil
+}
+
+// String formats the amount with three decimals, as payloads expect.
+func (a Amount) String() string {
+	return strconv.FormatFloat(float64(a)/limits.AmountScale, 'f', 3, 64)
+}
+
+// MarshalJSON writes the amount as a decimal number, as events carry it.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatFloat(float64(a)/limits.AmountScale, 'f', -1, 64)), nil
+}
+
+// UnmarshalJSON reads a decimal number (or numeric string) into base units.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// VotingSystem selects how ballots are weighted.
+type VotingSystem uint8
+
+const (
+	// Democratic gives every member one vote.
+	Democratic VotingSystem = 0
+	// Stake weighs ballots by stake.
+	Stake VotingSystem = 1
+	// StakeReputation weighs ballots by stake scaled with reputation.
+	StakeReputation VotingSystem = 2
+)
+
+// IsStakeWeighted reports whether ballots are weighted by stake.
+func (vs VotingSystem) IsStakeWeighted() bool {
+	return vs == Stake || vs == StakeReputation
+}
+
+// object is an ordered JSON object, so built payloads are byte-stable.
+type object struct {
+	keys []string
+	vals []interface{}
+}
+
+func (o *object) set(key string, val interface{}) {
+	o.keys = append(o.keys, key)
+	o.vals = append(o.vals, val)
+}
+
+// MarshalJSON writes the fields in insertion order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		kb, _ := json.Marshal(k)
+		b.Write(kb)
+		b.WriteByte(':')
+		vb, err := json.Marshal(o.vals[i])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(vb)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
+
+func (o *object) call(action string) Call {
+	b, err := json.Marshal(o)
+	if err != nil {
+		// Only strings, numbers, booleans and nested objects are ever set.
+		panic(err)
+	}
+	return Call{Action: action, Payload: string(b)}
+}
+
+// idCall is the payload of exports that only take a project or proposal id.
+func idCall(action string, id uint64) Call {
+	return Call{Action: action, Payload: strconv.FormatUint(id, 10)}
+}
+
+// -----------------------------------------------------------------------------
+// Validation
+// -----------------------------------------------------------------------------
+
+func isValidAsset(asset string) bool {
+	for _, a := range limits.Assets {
+		if a == asset {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAddress mirrors the contract's address check.
+func validateAddress(addr string) error {
+	if addr == "" {
+		return fmt.Errorf("address required")
+	}
+	if len(addr) > limits.MaxAddressLength {
+		return fmt.Errorf("address exceeds maximum length")
+	}
+	for i := 0; i < len(addr); i++ {
+		if c := addr[i]; c == '|' || c == ';' || c == ',' || c <= ' ' {
+			return fmt.Errorf("invalid character in address")
+		}
+	}
+	for _, prefix := range limits.AddressPrefixes {
+		if len(addr) > len(prefix) && strings.HasPrefix(addr, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid address %q", addr)
+}
+
+func validateAddresses(addrs []string, max int, what string) error {
+	if len(addrs) == 0 {
+		return fmt.Errorf("%s requires addresses", what)
+	}
+	if len(addrs) > max {
+		return fmt.Errorf("%s cannot exceed %d addresses", what, max)
+	}
+	for _, a := range addrs {
+		if err := validateAddress(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkLength(val string, max int, what string) error {
+	if len(val) > max {
+		return fmt.Errorf("%s exceeds maximum length of %d characters", what, max)
+	}
+	return nil
+}
+
+func checkHTTPS(url string, what string) error {
+	if url != "" && !strings.HasPrefix(strings.ToLower(url), "https://") {
+		return fmt.Errorf("%s must use https", what)
+	}
+	return checkLength(url, limits.MaxURLLength, what)
+}
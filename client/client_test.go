@@ -0,0 +1,157 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"okinoko_dao/errcode"
+)
+
+func TestParseAmount(t *testing.T) {
+	for in, want := range map[string]Amount{"1": 1000, "1.5": 1500, "0.0004": 0, "2.0005": 2001} {
+		got, err := ParseAmount(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseAmount(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := ParseAmount("NaN"); err == nil {
+		t.Fatal("NaN accepted")
+	}
+	if got := Amount(1500).String(); got != "1.500" {
+		t.Fatalf("String() = %q", got)
+	}
+}
+
+func TestCreateProposalPayload(t *testing.T) {
+	keep, _ := UpdateQuorum(40)
+	call, err := CreateProposalArgs{
+		ProjectID:   3,
+		Name:        "grant | round 1",
+		Description: "pay: alice; bob",
+		Duration:    24,
+		Payouts: []Payout{
+			{Address: "hive:alice", Amount: 2500, Asset: "hbd"},
+			{Address: "hive:bob", Amount: 1000, Asset: "hive", L1: true},
+		},
+		Meta: []MetaAction{keep, TogglePause()},
+		ICC: []ICC{{Contract: "sink", Function: "take", Payload: "a|b",
+			Assets: map[string]Amount{"hive": 1500}, Expect: ExpectMin("out.amount", 1.5), Receive: []string{"hbd"}}},
+	}.Call()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if call.Action != "proposal_create" {
+		t.Fatalf("action %q", call.Action)
+	}
+	want := `{"projectId":"3","name":"grant | round 1","description":"pay: alice; bob","duration":"24",` +
+		`"payouts":[{"address":"hive:alice","amount":"2.500","asset":"hbd"},{"address":"hive:bob","amount":"1.000","asset":"hive","mode":"l1"}],` +
+		`"meta":{"update_quorum":"40","toggle_pause":"1"},` +
+		`"icc":[{"contract":"sink","function":"take","payload":"a|b","assets":{"hive":"1.500"},"expect":"min:out.amount:1.5","receive":["hbd"]}]}`
+	if call.Payload != want {
+		t.Fatalf("payload\n got %s\nwant %s", call.Payload, want)
+	}
+}
+
+func TestBuildersRejectWhatTheContractRejects(t *testing.T) {
+	long := strings.Repeat("x", 1000)
+	cases := map[string]error{}
+	_, cases["long name"] = CreateProjectArgs{Name: long}.Call()
+	_, cases["threshold"] = CreateProjectArgs{ThresholdPercent: 101}.Call()
+	_, cases["stake asset"] = CreateProjectArgs{StakeAssets: map[string]float64{"btc": 1}}.Call()
+	_, cases["http option"] = CreateProposalArgs{Options: []Option{{Text: "a", URL: "http://x"}}}.Call()
+	_, cases["l1 to did"] = CreateProposalArgs{Payouts: []Payout{{Address: "did:key:z", Amount: 1, Asset: "hive", L1: true}}}.Call()
+	_, cases["l1 savings"] = CreateProposalArgs{Payouts: []Payout{{Address: "hive:a", Amount: 1, Asset: "hbd_savings", L1: true}}}.Call()
+	_, cases["zero payout"] = CreateProposalArgs{Payouts: []Payout{{Address: "hive:a", Asset: "hive"}}}.Call()
+	_, cases["dup meta"] = CreateProposalArgs{Meta: []MetaAction{TogglePause(), TogglePause()}}.Call()
+	_, cases["bad address"] = WhitelistAdd(1, "alice")
+	_, cases["init events"] = Init{Events: "xml"}.Call()
+	_, cases["quorum meta"] = UpdateQuorum(0)
+	_, cases["kick list"] = KickMember()
+	_, cases["export target"] = ExportProjectMeta("dao:2")
+	_, cases["export dup"] = ExportProjectMeta("dao2", 3, 3)
+	_, cases["icc threshold"] = UpdateICCThreshold(0.5)
+	_, cases["icc allowlist"] = ICCAllowlistAddMeta("dao2")
+	_, cases["icc expect"] = CreateProposalArgs{ICC: []ICC{{Contract: "c", Function: "f", Expect: "gt:1"}}}.Call()
+	late, _ := NotBefore(time.Unix(1767571200, 0))
+	early, _ := NotAfter(time.Unix(1767484800, 0))
+	_, cases["window"] = CreateProposalArgs{Meta: []MetaAction{late, early}}.Call()
+	_, cases["icc receive"] = CreateProposalArgs{ICC: []ICC{{Contract: "c", Function: "f", Receive: []string{"hbd_savings"}}}}.Call()
+	_, cases["contract owner"] = TransferContract("alice")
+	_, cases["fee asset"] = SetCreationFee(1000, "btc")
+	for name, err := range cases {
+		if err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+}
+
+func TestIDCalls(t *testing.T) {
+	if c := Tally(0); c.Action != "proposal_tally" || c.Payload != "0" {
+		t.Fatalf("got %+v", c)
+	}
+	if c, err := (CreateProposalArgs{ProjectID: 1, Name: "n"}).Simulate(); err != nil || c.Action != "proposal_simulate" {
+		t.Fatalf("got %+v, %v", c, err)
+	}
+	c, err := Vote(7, 0, 2)
+	if err != nil || c.Payload != `{"proposalId":"7","choices":["0","2"]}` {
+		t.Fatalf("got %+v, %v", c, err)
+	}
+}
+
+func TestParseMeta(t *testing.T) {
+	m, err := ParseMeta("distribute", "2.5:hbd")
+	if err != nil || m != (MetaAction{"distribute", "2.500:hbd"}) {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	m, err = ParseMeta("kick_member", "hive:a, hive:b")
+	if err != nil || m.Value != "hive:a,hive:b" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	m, err = ParseMeta("export_project", "dao2:4, 9")
+	if err != nil || m.Value != "dao2:4,9" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	m, err = ParseMeta("requires", "4, 7")
+	if err != nil || m.Value != "4,7" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	m, err = ParseMeta("condition", "oracle, 0x10, ge, 1.5")
+	if err != nil || m.Value != "oracle,0x10,ge,1.5" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	m, err = ParseMeta("update_stakeMin", "2.5")
+	if err != nil || m != (MetaAction{"update_stakeMin", "2.500"}) {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	m, err = ParseMeta("icc_allowlist_add", "dao2:proposals_vote, nft:mint")
+	if err != nil || m.Value != "dao2:proposals_vote,nft:mint" {
+		t.Fatalf("got %+v, %v", m, err)
+	}
+	for key, value := range map[string]string{
+		"update_threshold": "abc", "update_quorum": "0", "update_stakeWeight": "hive",
+		"update_proposalCreatorRestriction": "all", "launch_rocket": "1",
+		"icc_allowlist_remove": "dao2:", "update_iccThreshold": "101", "not_after": "tomorrow",
+		"condition": "oracle,price,gte,1", "requires": "4,4",
+		"update_url": "http://example.com", "update_membershipNFTPayload": "{nft}",
+		"update_votingSystem": "3", "update_stakeMin": "-1", "update_name": " ",
+	} {
+		if _, err := ParseMeta(key, value); err == nil {
+			t.Errorf("%s=%s accepted", key, value)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	err := error(ParseError("E_NOT_MEMBER", "hive:bob is not a member"))
+	if !errors.Is(err, &Error{Code: errcode.NotMember}) || errors.Is(err, &Error{Code: errcode.Paused}) {
+		t.Fatalf("code matching broken for %v", err)
+	}
+	if err.Error() != "E_NOT_MEMBER: hive:bob is not a member" {
+		t.Fatalf("got %q", err.Error())
+	}
+	if e := ParseError("sdk_error", "boom"); e.Code != "" || e.Error() != "boom" {
+		t.Fatalf("unknown symbol kept: %+v", e)
+	}
+}
@@ -0,0 +1,36 @@
+package client
+
+import "okinoko_dao/errcode"
+
+// Error is a failed contract call: the errcode catalogue code the contract
+// reverted with and the human readable detail. Branch on Code; Message may
+// change between releases.
+type Error struct {
+	Code    errcode.Code
+	Message string
+}
+
+// ParseError builds an Error from a failed call's revert symbol and message.
+// A symbol outside the catalogue (a host error, or an older contract that
+// aborted without one) leaves Code empty.
+func ParseError(symbol, message string) *Error {
+	e := &Error{Message: message}
+	if errcode.Known(symbol) {
+		e.Code = errcode.Code(symbol)
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return string(e.Code) + ": " + e.Message
+}
+
+// Is reports whether target is an *Error with the same code, so
+// errors.Is(err, &client.Error{Code: errcode.NotMember}) matches any message.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code != "" && t.Code == e.Code
+}
@@ -0,0 +1,635 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EventSchemaVersion is the newest JSON event schema ParseEvent understands.
+// It tracks the contract's EventSchemaVersion.
+const EventSchemaVersion = 1
+
+// Event is a decoded contract log line. Type returns the JSON event type id
+// ("member.joined", ...); switch on the concrete type to read its fields.
+type Event interface {
+	Type() string
+}
+
+// ContractInitEvent is logged once by contract_init. Events is empty for
+// legacy lines, which do not carry it.
+type ContractInitEvent struct {
+	Owner  string `json:"owner"`
+	Mode   string `json:"mode"`
+	Events string `json:"events"`
+}
+
+// ContractConfigEvent records a contract setting changed by the contract
+// owner. Field is "mode" ("public"/"owner-only"), "pendingOwner", "owner"
+// (empty once renounced) or "fee" ("<amount> <asset>", empty when none).
+type ContractConfigEvent struct {
+	By    string `json:"by"`
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// CreationFeeEvent records the project creation fee paid to the contract owner.
+type CreationFeeEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	By        string `json:"by"`
+	To        string `json:"to"`
+	Amount    Amount `json:"amount"`
+	Asset     string `json:"asset"`
+}
+
+// ProjectCreatedEvent carries the full configuration of a new project.
+// Membership fields are empty when the project has no NFT gating.
+type ProjectCreatedEvent struct {
+	ProjectID          uint64             `json:"projectId"`
+	By                 string             `json:"by"`
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	Metadata           string             `json:"metadata"`
+	URL                string             `json:"url"`
+	Asset              string             `json:"asset"`
+	VotingSystem       VotingSystem       `json:"votingSystem"`
+	Threshold          float64            `json:"threshold"`
+	Quorum             float64            `json:"quorum"`
+	ProposalDuration   uint64             `json:"proposalDuration"`
+	ExecutionDelay     uint64             `json:"executionDelay"`
+	LeaveCooldown      uint64             `json:"leaveCooldown"`
+	ProposalCost       Amount             `json:"proposalCost"`
+	StakeMin           Amount             `json:"stakeMin"`
+	MembershipContract string             `json:"membershipContract"`
+	MembershipFunction string             `json:"membershipFunction"`
+	MembershipNFT      string             `json:"membershipNft"`
+	MembershipPayload  string             `json:"membershipPayload"`
+	MembersOnly        bool               `json:"membersOnly"`
+	WhitelistOnly      bool               `json:"whitelistOnly"`
+	StakeAssets        map[string]float64 `json:"stakeAssets"`
+}
+
+// MemberJoinedEvent is logged when an address joins a project.
+type MemberJoinedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	By        string `json:"by"`
+}
+
+// MemberLeftEvent is logged when a member leaves or is removed.
+type MemberLeftEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	By        string `json:"by"`
+}
+
+// EventOption is a ballot option as logged.
+type EventOption struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// EventPayout is a payout entry as logged; Mode is "ledger" or "l1".
+type EventPayout struct {
+	To     string `json:"to"`
+	Amount Amount `json:"amount"`
+	Asset  string `json:"asset"`
+	Mode   string `json:"mode"`
+}
+
+// ProposalCreatedEvent describes a new proposal and its outcome.
+type ProposalCreatedEvent struct {
+	ProposalID  uint64            `json:"proposalId"`
+	ProjectID   uint64            `json:"projectId"`
+	By          string            `json:"by"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Metadata    string            `json:"metadata"`
+	URL         string            `json:"url"`
+	Duration    uint64            `json:"duration"`
+	IsPoll      bool              `json:"isPoll"`
+	Options     []EventOption     `json:"options"`
+	Payouts     []EventPayout     `json:"payouts"`
+	OutcomeMeta map[string]string `json:"outcomeMeta"`
+}
+
+// ProposalStateEvent is logged on every proposal state change.
+type ProposalStateEvent struct {
+	ProposalID uint64 `json:"proposalId"`
+	State      string `json:"state"`
+}
+
+// ProposalReadyEvent gives the unix time a passed proposal becomes executable
+// and, when it has one, the deadline after which it fails instead.
+type ProposalReadyEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	ReadyAt    int64  `json:"readyAt"`
+	Deadline   int64  `json:"deadline,omitempty"`
+}
+
+// ProposalResultEvent summarizes an executed proposal.
+type ProposalResultEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	Result     string `json:"result"`
+}
+
+// ProposalConfigEvent records one project setting changed by a proposal.
+type ProposalConfigEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	Field      string `json:"field"`
+	Old        string `json:"old"`
+	New        string `json:"new"`
+}
+
+// VoteCastEvent records a ballot and its weight.
+type VoteCastEvent struct {
+	ProposalID uint64  `json:"proposalId"`
+	By         string  `json:"by"`
+	Choices    []uint  `json:"choices"`
+	Weight     float64 `json:"weight"`
+}
+
+// FundsAddedEvent records a deposit into a treasury or a member's stake.
+type FundsAddedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	By        string `json:"by"`
+	Amount    Amount `json:"amount"`
+	Asset     string `json:"asset"`
+	ToStake   bool   `json:"toStake"`
+}
+
+// FundsRemovedEvent records funds leaving a treasury or a member's stake.
+type FundsRemovedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	To        string `json:"to"`
+	Amount    Amount `json:"amount"`
+	Asset     string `json:"asset"`
+	FromStake bool   `json:"fromStake"`
+	// Mode is "l1" for a payout withdrawn to the Hive base layer and empty
+	// for every other removal.
+	Mode string `json:"mode,omitempty"`
+}
+
+// ReputationChangedEvent records a reputation delta and the new total.
+type ReputationChangedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	By        string `json:"by"`
+	Delta     int64  `json:"delta"`
+	Total     int64  `json:"total"`
+	Reason    string `json:"reason"`
+}
+
+// WhitelistChangedEvent records addresses added to or removed from a whitelist.
+type WhitelistChangedEvent struct {
+	ProjectID uint64   `json:"projectId"`
+	Action    string   `json:"action"`
+	Addresses []string `json:"addresses"`
+}
+
+// ICCAllowlistEvent records contract:function pairs added to or removed from
+// a project's ICC allowlist.
+type ICCAllowlistEvent struct {
+	ProjectID uint64   `json:"projectId"`
+	Action    string   `json:"action"`
+	Targets   []string `json:"targets"`
+}
+
+// ProjectDissolvedEvent marks a project dissolved; members leave afterwards
+// to claim their share.
+type ProjectDissolvedEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	Members    int64  `json:"members"`
+}
+
+// ProjectExportedEvent closes a project's event stream on the source
+// deployment; the project continues as NewID on Target. ProposalID is the
+// export_project proposal that moved it.
+type ProjectExportedEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	By         string `json:"by"`
+	Target     string `json:"target"`
+	NewID      string `json:"newId"`
+	Members    int64  `json:"members"`
+	Proposals  int64  `json:"proposals"`
+}
+
+// ProjectImportedEvent opens the event stream of a project moved in from
+// Source. Proposals maps the carried proposal ids as "old:new,...".
+type ProjectImportedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	Source    string `json:"source"`
+	SourceID  uint64 `json:"sourceId"`
+	Members   int64  `json:"members"`
+	Proposals string `json:"proposals"`
+}
+
+// DividendDistributedEvent records treasury funds handed to stakers.
+type DividendDistributedEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	Amount     Amount `json:"amount"`
+	Asset      string `json:"asset"`
+}
+
+// DividendReturnedEvent records dividend rounding remainders moved back into
+// the treasury.
+type DividendReturnedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	Amount    Amount `json:"amount"`
+	Asset     string `json:"asset"`
+}
+
+// DividendClaimedEvent records dividends paid to a member.
+type DividendClaimedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	By        string `json:"by"`
+	Amount    Amount `json:"amount"`
+	Asset     string `json:"asset"`
+}
+
+// HbdStakedEvent records treasury HBD moved into savings.
+type HbdStakedEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	Amount     Amount `json:"amount"`
+}
+
+// HbdUnstakedEvent records a savings withdrawal and when it matures.
+type HbdUnstakedEvent struct {
+	ProjectID  uint64 `json:"projectId"`
+	ProposalID uint64 `json:"proposalId"`
+	Amount     Amount `json:"amount"`
+	MaturesAt  int64  `json:"maturesAt"`
+}
+
+// HbdReleasedEvent records matured withdrawals credited back to the treasury.
+type HbdReleasedEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	Amount    Amount `json:"amount"`
+}
+
+// HbdInterestEvent records savings interest credited to the treasury.
+type HbdInterestEvent struct {
+	ProjectID uint64 `json:"projectId"`
+	Amount    Amount `json:"amount"`
+}
+
+// Type returns the JSON event type id of each event.
+func (ContractInitEvent) Type() string        { return "contract.init" }
+func (ContractConfigEvent) Type() string      { return "contract.config" }
+func (CreationFeeEvent) Type() string         { return "contract.fee" }
+func (ProjectCreatedEvent) Type() string      { return "project.created" }
+func (MemberJoinedEvent) Type() string        { return "member.joined" }
+func (MemberLeftEvent) Type() string          { return "member.left" }
+func (ProposalCreatedEvent) Type() string     { return "proposal.created" }
+func (ProposalStateEvent) Type() string       { return "proposal.state" }
+func (ProposalReadyEvent) Type() string       { return "proposal.ready" }
+func (ProposalResultEvent) Type() string      { return "proposal.result" }
+func (ProposalConfigEvent) Type() string      { return "proposal.config" }
+func (VoteCastEvent) Type() string            { return "vote.cast" }
+func (FundsAddedEvent) Type() string          { return "funds.added" }
+func (FundsRemovedEvent) Type() string        { return "funds.removed" }
+func (ReputationChangedEvent) Type() string   { return "reputation.changed" }
+func (WhitelistChangedEvent) Type() string    { return "whitelist.changed" }
+func (ICCAllowlistEvent) Type() string        { return "icc.allowlist" }
+func (ProjectDissolvedEvent) Type() string    { return "project.dissolved" }
+func (ProjectExportedEvent) Type() string     { return "project.exported" }
+func (ProjectImportedEvent) Type() string     { return "project.imported" }
+func (DividendDistributedEvent) Type() string { return "dividend.distributed" }
+func (DividendClaimedEvent) Type() string     { return "dividend.claimed" }
+func (DividendReturnedEvent) Type() string    { return "dividend.returned" }
+func (HbdStakedEvent) Type() string           { return "hbd.staked" }
+func (HbdUnstakedEvent) Type() string         { return "hbd.unstaked" }
+func (HbdReleasedEvent) Type() string         { return "hbd.released" }
+func (HbdInterestEvent) Type() string         { return "hbd.interest" }
+
+// -----------------------------------------------------------------------------
+// Parsing
+// -----------------------------------------------------------------------------
+
+// legacy field encodings
+const (
+	fStr = iota
+	fUint
+	fInt
+	fNum
+	fBool
+	fStrs    // "a;b"
+	fUints   // "1,2"
+	fMeta    // "k=v;k=v"
+	fWeights // "asset=w;asset=w"
+	fOptions // "text:url;text"
+	fPayouts // "addr:amount:asset[:l1];..."
+)
+
+// legacyField maps one key of a legacy line onto a JSON field.
+type legacyField struct {
+	key  string
+	json string
+	enc  int
+}
+
+type eventSpec struct {
+	code   string // legacy line prefix
+	new    func() Event
+	fields []legacyField
+}
+
+var (
+	idProject  = legacyField{"id", "projectId", fUint}
+	idProposal = legacyField{"id", "proposalId", fUint}
+	pID        = legacyField{"pId", "projectId", fUint}
+	prID       = legacyField{"prId", "proposalId", fUint}
+	byAddr     = legacyField{"by", "by", fStr}
+	amount     = legacyField{"am", "amount", fNum}
+	asset      = legacyField{"as", "asset", fStr}
+)
+
+// eventSpecs lists every event the contract logs, keyed by JSON type id.
+var eventSpecs = map[string]eventSpec{
+	"contract.init": {"shindao_init", func() Event { return &ContractInitEvent{} }, []legacyField{
+		{"owner", "owner", fStr}, {"mode", "mode", fStr},
+	}},
+	"contract.config": {"cc", func() Event { return &ContractConfigEvent{} }, []legacyField{
+		byAddr, {"f", "field", fStr}, {"old", "old", fStr}, {"new", "new", fStr},
+	}},
+	"contract.fee": {"cf", func() Event { return &CreationFeeEvent{} }, []legacyField{
+		idProject, byAddr, {"to", "to", fStr}, amount, asset,
+	}},
+	"project.created": {"dc", func() Event { return &ProjectCreatedEvent{} }, []legacyField{
+		idProject, byAddr, {"name", "name", fStr}, {"description", "description", fStr},
+		{"metadata", "metadata", fStr}, {"url", "url", fStr}, {"asset", "asset", fStr},
+		{"voting", "votingSystem", fUint}, {"threshold", "threshold", fNum}, {"quorum", "quorum", fNum},
+		{"proposalDuration", "proposalDuration", fUint}, {"executionDelay", "executionDelay", fUint},
+		{"leaveCooldown", "leaveCooldown", fUint}, {"proposalCost", "proposalCost", fNum},
+		{"stakeMin", "stakeMin", fNum}, {"membershipContract", "membershipContract", fStr},
+		{"membershipFunction", "membershipFunction", fStr}, {"membershipNft", "membershipNft", fStr},
+		{"membershipPayload", "membershipPayload", fStr}, {"membersOnly", "membersOnly", fBool},
+		{"whitelistOnly", "whitelistOnly", fBool}, {"stakeAssets", "stakeAssets", fWeights},
+	}},
+	"member.joined": {"mj", func() Event { return &MemberJoinedEvent{} }, []legacyField{idProject, byAddr}},
+	"member.left":   {"ml", func() Event { return &MemberLeftEvent{} }, []legacyField{idProject, byAddr}},
+	"proposal.created": {"pc", func() Event { return &ProposalCreatedEvent{} }, []legacyField{
+		idProposal, {"project", "projectId", fUint}, byAddr, {"name", "name", fStr},
+		{"description", "description", fStr}, {"metadata", "metadata", fStr}, {"url", "url", fStr},
+		{"duration", "duration", fUint}, {"isPoll", "isPoll", fBool}, {"options", "options", fOptions},
+		{"payouts", "payouts", fPayouts}, {"outcomeMeta", "outcomeMeta", fMeta},
+	}},
+	"proposal.state": {"ps", func() Event { return &ProposalStateEvent{} }, []legacyField{
+		idProposal, {"s", "state", fStr},
+	}},
+	"proposal.ready": {"px", func() Event { return &ProposalReadyEvent{} }, []legacyField{
+		pID, prID, {"ready", "readyAt", fInt}, {"until", "deadline", fInt},
+	}},
+	"proposal.result": {"pr", func() Event { return &ProposalResultEvent{} }, []legacyField{
+		pID, prID, {"r", "result", fStr},
+	}},
+	"proposal.config": {"pm", func() Event { return &ProposalConfigEvent{} }, []legacyField{
+		pID, prID, {"f", "field", fStr}, {"old", "old", fStr}, {"new", "new", fStr},
+	}},
+	"vote.cast": {"v", func() Event { return &VoteCastEvent{} }, []legacyField{
+		idProposal, byAddr, {"cs", "choices", fUints}, {"w", "weight", fNum},
+	}},
+	"funds.added": {"af", func() Event { return &FundsAddedEvent{} }, []legacyField{
+		idProject, byAddr, amount, asset, {"s", "toStake", fBool},
+	}},
+	"funds.removed": {"rf", func() Event { return &FundsRemovedEvent{} }, []legacyField{
+		idProject, {"to", "to", fStr}, amount, asset, {"fs", "fromStake", fBool}, {"md", "mode", fStr},
+	}},
+	"reputation.changed": {"rp", func() Event { return &ReputationChangedEvent{} }, []legacyField{
+		idProject, byAddr, {"d", "delta", fInt}, {"r", "total", fInt}, {"why", "reason", fStr},
+	}},
+	"whitelist.changed": {"wl", func() Event { return &WhitelistChangedEvent{} }, []legacyField{
+		idProject, {"act", "action", fStr}, {"addrs", "addresses", fStrs},
+	}},
+	"icc.allowlist": {"ia", func() Event { return &ICCAllowlistEvent{} }, []legacyField{
+		idProject, {"act", "action", fStr}, {"targets", "targets", fStrs},
+	}},
+	"project.dissolved": {"dd", func() Event { return &ProjectDissolvedEvent{} }, []legacyField{
+		idProject, prID, {"members", "members", fInt},
+	}},
+	"project.exported": {"ex", func() Event { return &ProjectExportedEvent{} }, []legacyField{
+		idProject, prID, byAddr, {"to", "target", fStr}, {"newId", "newId", fStr},
+		{"members", "members", fInt}, {"proposals", "proposals", fInt},
+	}},
+	"project.imported": {"im", func() Event { return &ProjectImportedEvent{} }, []legacyField{
+		idProject, {"from", "source", fStr}, {"srcId", "sourceId", fUint},
+		{"members", "members", fInt}, {"proposals", "proposals", fStr},
+	}},
+	"dividend.distributed": {"dv", func() Event { return &DividendDistributedEvent{} }, []legacyField{
+		idProject, prID, amount, asset,
+	}},
+	"dividend.claimed": {"dvc", func() Event { return &DividendClaimedEvent{} }, []legacyField{
+		idProject, byAddr, amount, asset,
+	}},
+	"dividend.returned": {"dvr", func() Event { return &DividendReturnedEvent{} }, []legacyField{
+		idProject, amount, asset,
+	}},
+	"hbd.staked": {"hs", func() Event { return &HbdStakedEvent{} }, []legacyField{
+		idProject, prID, amount,
+	}},
+	"hbd.unstaked": {"hu", func() Event { return &HbdUnstakedEvent{} }, []legacyField{
+		idProject, prID, amount, {"at", "maturesAt", fInt},
+	}},
+	"hbd.released": {"hr", func() Event { return &HbdReleasedEvent{} }, []legacyField{
+		idProject, amount,
+	}},
+	"hbd.interest": {"hi", func() Event { return &HbdInterestEvent{} }, []legacyField{
+		idProject, amount,
+	}},
+}
+
+// legacyTypes maps legacy line codes onto JSON type ids.
+var legacyTypes = func() map[string]string {
+	m := make(map[string]string, len(eventSpecs))
+	for kind, spec := range eventSpecs {
+		m[spec.code] = kind
+	}
+	return m
+}()
+
+// ErrUnknownEvent is returned for log lines that are not DAO events, such as
+// logs written by other contracts in the same transaction.
+var ErrUnknownEvent = fmt.Errorf("not a DAO event")
+
+// ParseEvent decodes one log line in either the JSON form
+// ({"v":1,"type":"member.joined",...}) or the legacy form ("mj|id:1|by:...").
+// The returned Event is a pointer to one of the *Event structs above.
+//
+// Legacy lines carry user text sanitized ('|', ';' and ':' replaced), so
+// names, option texts and URLs read from them may differ from what was
+// submitted; the JSON form carries them verbatim.
+func ParseEvent(line string) (Event, error) {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		return parseJSONEvent(line)
+	}
+	return parseLegacyEvent(line)
+}
+
+func parseJSONEvent(line string) (Event, error) {
+	var head struct {
+		V    int    `json:"v"`
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(line), &head); err != nil {
+		return nil, fmt.Errorf("invalid JSON event: %w", err)
+	}
+	spec, ok := eventSpecs[head.Type]
+	if !ok || head.V < 1 {
+		return nil, ErrUnknownEvent
+	}
+	if head.V > EventSchemaVersion {
+		return nil, fmt.Errorf("event schema version %d is newer than %d", head.V, EventSchemaVersion)
+	}
+	ev := spec.new()
+	if err := json.Unmarshal([]byte(line), ev); err != nil {
+		return nil, fmt.Errorf("invalid %s event: %w", head.Type, err)
+	}
+	return ev, nil
+}
+
+func parseLegacyEvent(line string) (Event, error) {
+	parts := strings.Split(line, "|")
+	kind, ok := legacyTypes[parts[0]]
+	if !ok {
+		return nil, ErrUnknownEvent
+	}
+	spec := eventSpecs[kind]
+	values := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		if i := strings.IndexByte(part, ':'); i > 0 {
+			values[part[:i]] = part[i+1:]
+		}
+	}
+	// Re-encode the known fields as the JSON form so both forms share one decoder.
+	obj := make(map[string]interface{}, len(spec.fields))
+	for _, f := range spec.fields {
+		raw, present := values[f.key]
+		if !present {
+			continue
+		}
+		v, err := decodeLegacyValue(raw, f.enc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s field %s: %w", parts[0], f.key, err)
+		}
+		obj[f.json] = v
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	ev := spec.new()
+	if err := json.Unmarshal(b, ev); err != nil {
+		return nil, fmt.Errorf("invalid %s event: %w", parts[0], err)
+	}
+	return ev, nil
+}
+
+// decodeLegacyValue converts one legacy field into a JSON-encodable value.
+func decodeLegacyValue(raw string, enc int) (interface{}, error) {
+	switch enc {
+	case fStr:
+		return raw, nil
+	case fUint:
+		if raw == "" {
+			return nil, nil
+		}
+		_, err := strconv.ParseUint(raw, 10, 64)
+		return json.Number(raw), err
+	case fInt:
+		_, err := strconv.ParseInt(raw, 10, 64)
+		return json.Number(raw), err
+	case fNum:
+		_, err := strconv.ParseFloat(raw, 64)
+		return json.Number(raw), err
+	case fBool:
+		return strconv.ParseBool(raw)
+	case fStrs:
+		return splitList(raw, ";"), nil
+	case fUints:
+		out := []json.Number{}
+		for _, s := range splitList(raw, ",") {
+			if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+				return nil, err
+			}
+			out = append(out, json.Number(s))
+		}
+		return out, nil
+	case fMeta:
+		out := map[string]string{}
+		for _, entry := range splitList(raw, ";") {
+			k, v, _ := strings.Cut(entry, "=")
+			out[k] = v
+		}
+		return out, nil
+	case fWeights:
+		out := map[string]json.Number{}
+		for _, entry := range splitList(raw, ";") {
+			k, v, _ := strings.Cut(entry, "=")
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return nil, err
+			}
+			out[k] = json.Number(v)
+		}
+		return out, nil
+	case fOptions:
+		out := []EventOption{}
+		for _, entry := range splitList(raw, ";") {
+			text, url, _ := strings.Cut(entry, ":")
+			out = append(out, EventOption{Text: text, URL: url})
+		}
+		return out, nil
+	case fPayouts:
+		out := []map[string]interface{}{}
+		for _, entry := range splitList(raw, ";") {
+			p, err := parseLegacyPayout(entry)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, p)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("unknown encoding %d", enc)
+}
+
+// parseLegacyPayout reads "addr:amount:asset[:l1]" from the right, since the
+// address itself contains ':'.
+func parseLegacyPayout(entry string) (map[string]interface{}, error) {
+	parts := strings.Split(entry, ":")
+	mode := "ledger"
+	if len(parts) > 0 && parts[len(parts)-1] == "l1" {
+		mode = "l1"
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("invalid payout %q", entry)
+	}
+	n := len(parts)
+	amt := parts[n-2]
+	if _, err := strconv.ParseFloat(amt, 64); err != nil {
+		return nil, fmt.Errorf("invalid payout amount %q", amt)
+	}
+	return map[string]interface{}{
+		"to":     strings.Join(parts[:n-2], ":"),
+		"amount": json.Number(amt),
+		"asset":  parts[n-1],
+		"mode":   mode,
+	}, nil
+}
+
+func splitList(raw, sep string) []string {
+	out := []string{}
+	for _, s := range strings.Split(raw, sep) {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
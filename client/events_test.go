@@ -0,0 +1,109 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLegacyEvents(t *testing.T) {
+	cases := map[string]Event{
+		"mj|id:4|by:hive:bob": &MemberJoinedEvent{ProjectID: 4, By: "hive:bob"},
+		"v|id:9|by:hive:bob|cs:0,2|w:1.500000": &VoteCastEvent{
+			ProposalID: 9, By: "hive:bob", Choices: []uint{0, 2}, Weight: 1.5,
+		},
+		"rf|id:1|to:hive:alice|am:2.500000|as:hbd|fs:false|md:l1": &FundsRemovedEvent{
+			ProjectID: 1, To: "hive:alice", Amount: 2500, Asset: "hbd", Mode: "l1",
+		},
+		"rf|id:1|to:hive:bob|am:1.000000|as:hive|fs:true": &FundsRemovedEvent{
+			ProjectID: 1, To: "hive:bob", Amount: 1000, Asset: "hive", FromStake: true,
+		},
+		"wl|id:2|act:add|addrs:hive:a;did:key:z": &WhitelistChangedEvent{
+			ProjectID: 2, Action: "add", Addresses: []string{"hive:a", "did:key:z"},
+		},
+		"ia|id:2|act:remove|targets:dao2:proposals_vote;nft:mint": &ICCAllowlistEvent{
+			ProjectID: 2, Action: "remove", Targets: []string{"dao2:proposals_vote", "nft:mint"},
+		},
+		"im|id:0|from:contract:dao1|srcId:4|members:2|proposals:7:0,9:1": &ProjectImportedEvent{
+			Source: "contract:dao1", SourceID: 4, Members: 2, Proposals: "7:0,9:1",
+		},
+		"px|pId:1|prId:3|ready:1767484800|until:1767571200": &ProposalReadyEvent{
+			ProjectID: 1, ProposalID: 3, ReadyAt: 1767484800, Deadline: 1767571200,
+		},
+		"pm|pId:1|prId:3|f:owner|old:hive:a|new:": &ProposalConfigEvent{
+			ProjectID: 1, ProposalID: 3, Field: "owner", Old: "hive:a",
+		},
+		"cc|by:hive:a|f:fee|old:|new:1.500000 hbd": &ContractConfigEvent{
+			By: "hive:a", Field: "fee", New: "1.500000 hbd",
+		},
+		"cf|id:2|by:hive:b|to:hive:a|am:1.500000|as:hbd": &CreationFeeEvent{
+			ProjectID: 2, By: "hive:b", To: "hive:a", Amount: 1500, Asset: "hbd",
+		},
+	}
+	for line, want := range cases {
+		got, err := ParseEvent(line)
+		if err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s:\n got %+v\nwant %+v", line, got, want)
+		}
+	}
+}
+
+func TestParseLegacyProposalCreated(t *testing.T) {
+	line := "pc|id:5|project:1|by:hive:a|name:n|description:d|metadata:|url:|duration:24|isPoll:true" +
+		"|options:yes:https-//x;no|payouts:hive:alice:1.000000:hive;hive:bob:2.000000:hbd:l1|outcomeMeta:toggle_pause=1"
+	ev, err := ParseEvent(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := ev.(*ProposalCreatedEvent)
+	if pc.ProposalID != 5 || pc.ProjectID != 1 || !pc.IsPoll || pc.Duration != 24 {
+		t.Fatalf("header %+v", pc)
+	}
+	if want := []EventOption{{"yes", "https-//x"}, {"no", ""}}; !reflect.DeepEqual(pc.Options, want) {
+		t.Fatalf("options %+v", pc.Options)
+	}
+	want := []EventPayout{
+		{To: "hive:alice", Amount: 1000, Asset: "hive", Mode: "ledger"},
+		{To: "hive:bob", Amount: 2000, Asset: "hbd", Mode: "l1"},
+	}
+	if !reflect.DeepEqual(pc.Payouts, want) {
+		t.Fatalf("payouts %+v", pc.Payouts)
+	}
+	if pc.OutcomeMeta["toggle_pause"] != "1" {
+		t.Fatalf("meta %+v", pc.OutcomeMeta)
+	}
+}
+
+func TestParseJSONEvents(t *testing.T) {
+	ev, err := ParseEvent(`{"v":1,"type":"project.created","projectId":2,"by":"hive:a","name":"a: b; c",` +
+		`"votingSystem":1,"threshold":50.5,"proposalCost":1.5,"membershipContract":null,"stakeAssets":{"hbd":2}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := ev.(*ProjectCreatedEvent)
+	if dc.Name != "a: b; c" || dc.VotingSystem != Stake || dc.ProposalCost != 1500 || dc.StakeAssets["hbd"] != 2 {
+		t.Fatalf("got %+v", dc)
+	}
+	if _, err := ParseEvent(`{"v":2,"type":"member.joined"}`); err == nil {
+		t.Fatal("future schema version accepted")
+	}
+	for _, line := range []string{"hello", `{"v":1,"type":"other"}`, "x|id:1"} {
+		if _, err := ParseEvent(line); err != ErrUnknownEvent {
+			t.Fatalf("%q: got %v", line, err)
+		}
+	}
+}
+
+// Every event type has a legacy code and round-trips through its own struct.
+func TestEventSpecsComplete(t *testing.T) {
+	if len(eventSpecs) != 27 || len(legacyTypes) != len(eventSpecs) {
+		t.Fatalf("%d specs, %d legacy codes", len(eventSpecs), len(legacyTypes))
+	}
+	for kind, spec := range eventSpecs {
+		if got := spec.new().Type(); got != kind {
+			t.Fatalf("%s decodes into %s", kind, got)
+		}
+	}
+}
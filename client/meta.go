@@ -0,0 +1,583 @@
+package client
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"okinoko_dao/limits"
+)
+
+// MetaAction is one governance change a proposal enacts when it passes. Build
+// them with the constructors below rather than by hand; each returns the same
+// error the contract would abort with for an invalid value.
+type MetaAction struct {
+	Key   string
+	Value string
+}
+
+// UpdateThreshold changes the pass threshold in percent.
+func UpdateThreshold(percent float64) (MetaAction, error) {
+	if !(percent >= limits.MinThresholdPercent && percent <= limits.MaxThresholdPercent) {
+		return MetaAction{}, fmt.Errorf("threshold must be between %.0f%% and %.0f%%", limits.MinThresholdPercent, limits.MaxThresholdPercent)
+	}
+	return MetaAction{"update_threshold", formatFloat(percent)}, nil
+}
+
+// UpdateQuorum changes the quorum in percent.
+func UpdateQuorum(percent float64) (MetaAction, error) {
+	if !(percent >= limits.MinQuorumPercent && percent <= limits.MaxQuorumPercent) {
+		return MetaAction{}, fmt.Errorf("quorum must be between %.0f%% and %.0f%%", limits.MinQuorumPercent, limits.MaxQuorumPercent)
+	}
+	return MetaAction{"update_quorum", formatFloat(percent)}, nil
+}
+
+// UpdateProposalDuration changes the default voting period in hours.
+func UpdateProposalDuration(hours uint64) (MetaAction, error) {
+	if hours < limits.MinProposalDurationHours {
+		return MetaAction{}, fmt.Errorf("proposal duration must be at least %d hour(s)", limits.MinProposalDurationHours)
+	}
+	if hours > limits.MaxProposalDurationHours {
+		return MetaAction{}, fmt.Errorf("proposal duration must not exceed %d hours", limits.MaxProposalDurationHours)
+	}
+	return MetaAction{"update_proposalDuration", strconv.FormatUint(hours, 10)}, nil
+}
+
+// UpdateExecutionDelay changes the delay between passing and execution in hours.
+func UpdateExecutionDelay(hours uint64) (MetaAction, error) {
+	if hours > limits.MaxDurationHours {
+		return MetaAction{}, fmt.Errorf("execution delay must not exceed %d hours", limits.MaxDurationHours)
+	}
+	return MetaAction{"update_executionDelay", strconv.FormatUint(hours, 10)}, nil
+}
+
+// UpdateLeaveCooldown changes the leave cooldown in hours.
+func UpdateLeaveCooldown(hours uint64) (MetaAction, error) {
+	if hours > limits.MaxDurationHours {
+		return MetaAction{}, fmt.Errorf("leave cooldown must not exceed %d hours", limits.MaxDurationHours)
+	}
+	return MetaAction{"update_leaveCooldown", strconv.FormatUint(hours, 10)}, nil
+}
+
+// UpdateProposalCost changes the fee for creating a proposal.
+func UpdateProposalCost(cost Amount) (MetaAction, error) {
+	if cost < 0 {
+		return MetaAction{}, fmt.Errorf("proposal cost cannot be negative")
+	}
+	return MetaAction{"update_proposalCost", cost.String()}, nil
+}
+
+// UpdateURL changes the project URL; empty clears it.
+func UpdateURL(url string) (MetaAction, error) {
+	if err := checkHTTPS(url, "url"); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{"update_url", url}, nil
+}
+
+// UpdateName renames the project.
+func UpdateName(name string) (MetaAction, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return MetaAction{}, fmt.Errorf("project name cannot be empty")
+	}
+	if err := checkLength(name, limits.MaxNameLength, "project name"); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{"update_name", name}, nil
+}
+
+// UpdateDescription replaces the project description.
+func UpdateDescription(description string) (MetaAction, error) {
+	description = strings.TrimSpace(description)
+	if err := checkLength(description, limits.MaxDescriptionLength, "project description"); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{"update_description", description}, nil
+}
+
+// UpdateMetadata replaces the project metadata (empty clears it).
+func UpdateMetadata(metadata string) (MetaAction, error) {
+	metadata = strings.TrimSpace(metadata)
+	if err := checkLength(metadata, limits.MaxDescriptionLength, "project metadata"); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{"update_metadata", metadata}, nil
+}
+
+// UpdateVotingSystem switches how ballots of proposals created afterwards are
+// weighted. The contract additionally requires a minimum stake for the
+// stake-weighted systems and retired stake assets for Democratic.
+func UpdateVotingSystem(vs VotingSystem) (MetaAction, error) {
+	if vs > StakeReputation {
+		return MetaAction{}, fmt.Errorf("invalid voting system %d", vs)
+	}
+	return MetaAction{"update_votingSystem", strconv.Itoa(int(vs))}, nil
+}
+
+// UpdateStakeMin changes the stake required to join. The contract rejects
+// switching between zero (free membership) and a positive minimum.
+func UpdateStakeMin(min Amount) (MetaAction, error) {
+	if min < 0 {
+		return MetaAction{}, fmt.Errorf("min stake cannot be negative")
+	}
+	return MetaAction{"update_stakeMin", min.String()}, nil
+}
+
+// UpdateWhitelistOnly switches whitelist-only joining on or off.
+func UpdateWhitelistOnly(on bool) MetaAction {
+	return MetaAction{"update_whitelistOnly", strconv.FormatBool(on)}
+}
+
+// UpdateMembershipNFT changes the membership NFT id.
+func UpdateMembershipNFT(tokenID string) (MetaAction, error) {
+	if err := checkLength(tokenID, limits.MaxTokenIdLength, "membership NFT id"); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{"update_membershipNFT", tokenID}, nil
+}
+
+// UpdateMembershipNFTContract changes the contract queried for membership.
+func UpdateMembershipNFTContract(contract string) MetaAction {
+	return MetaAction{"update_membershipNFTContract", contract}
+}
+
+// UpdateMembershipNFTFunction changes the function queried for membership.
+func UpdateMembershipNFTFunction(function string) MetaAction {
+	return MetaAction{"update_membershipNFTContractFunction", function}
+}
+
+// UpdateMembershipNFTPayload changes the membership query payload format. A
+// non-empty format must contain the {nft} and {caller} placeholders; an empty
+// one restores the default.
+func UpdateMembershipNFTPayload(format string) (MetaAction, error) {
+	if f := strings.TrimSpace(format); f != "" && (!strings.Contains(f, "{nft}") || !strings.Contains(f, "{caller}")) {
+		return MetaAction{}, fmt.Errorf("membership NFT payload must contain {nft} and {caller}")
+	}
+	return MetaAction{"update_membershipNFTPayload", format}, nil
+}
+
+// UpdateProposalCreatorRestriction limits proposal creation to members (true)
+// or opens it to anyone (false).
+func UpdateProposalCreatorRestriction(membersOnly bool) MetaAction {
+	if membersOnly {
+		return MetaAction{"update_proposalCreatorRestriction", "members"}
+	}
+	return MetaAction{"update_proposalCreatorRestriction", "public"}
+}
+
+// UpdateOwner hands the project to a member.
+func UpdateOwner(addr string) (MetaAction, error) {
+	if err := validateAddress(addr); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{"update_owner", addr}, nil
+}
+
+// RemoveOwner makes the project ownerless.
+func RemoveOwner() MetaAction { return MetaAction{"remove_owner", "1"} }
+
+// TogglePause flips the project's paused flag.
+func TogglePause() MetaAction { return MetaAction{"toggle_pause", "1"} }
+
+// WhitelistAddMeta adds addresses to the whitelist.
+func WhitelistAddMeta(addrs ...string) (MetaAction, error) {
+	return addressMeta("whitelist_add", addrs, limits.MaxWhitelistAddresses)
+}
+
+// WhitelistRemoveMeta removes addresses from the whitelist.
+func WhitelistRemoveMeta(addrs ...string) (MetaAction, error) {
+	return addressMeta("whitelist_remove", addrs, limits.MaxWhitelistAddresses)
+}
+
+// UpdateICCThreshold sets the threshold in percent that proposals calling a
+// target outside the ICC allowlist must reach; 0 applies the normal threshold.
+func UpdateICCThreshold(percent float64) (MetaAction, error) {
+	if percent != 0 && !(percent >= limits.MinThresholdPercent && percent <= limits.MaxThresholdPercent) {
+		return MetaAction{}, fmt.Errorf("ICC threshold must be 0 or between %.0f%% and %.0f%%", limits.MinThresholdPercent, limits.MaxThresholdPercent)
+	}
+	return MetaAction{"update_iccThreshold", formatFloat(percent)}, nil
+}
+
+// ICCAllowlistAddMeta allowlists contract:function pairs as ICC targets.
+func ICCAllowlistAddMeta(targets ...string) (MetaAction, error) {
+	return iccAllowlistMeta("icc_allowlist_add", targets)
+}
+
+// ICCAllowlistRemoveMeta removes contract:function pairs from the ICC allowlist.
+func ICCAllowlistRemoveMeta(targets ...string) (MetaAction, error) {
+	return iccAllowlistMeta("icc_allowlist_remove", targets)
+}
+
+func iccAllowlistMeta(key string, targets []string) (MetaAction, error) {
+	if len(targets) == 0 {
+		return MetaAction{}, fmt.Errorf("%s requires contract:function entries", key)
+	}
+	if len(targets) > limits.MaxICCAllowlistEntries {
+		return MetaAction{}, fmt.Errorf("%s cannot exceed %d entries per proposal", key, limits.MaxICCAllowlistEntries)
+	}
+	for _, t := range targets {
+		i := strings.LastIndex(t, ":")
+		if i <= 0 || i == len(t)-1 || strings.ContainsAny(t, ",;|= \t\n") {
+			return MetaAction{}, fmt.Errorf("invalid %s entry %q (need contract:function)", key, t)
+		}
+	}
+	return MetaAction{key, strings.Join(targets, ",")}, nil
+}
+
+// KickMember removes members and refunds their stake.
+func KickMember(addrs ...string) (MetaAction, error) {
+	return addressMeta("kick_member", addrs, limits.MaxKickAddresses)
+}
+
+func addressMeta(key string, addrs []string, max int) (MetaAction, error) {
+	if err := validateAddresses(addrs, max, key); err != nil {
+		return MetaAction{}, err
+	}
+	return MetaAction{key, strings.Join(addrs, ",")}, nil
+}
+
+// Distribute pays amount of asset from the treasury to stakers as dividends.
+func Distribute(amount Amount, asset string) (MetaAction, error) {
+	if amount <= 0 {
+		return MetaAction{}, fmt.Errorf("distribute amount must be positive")
+	}
+	if !isValidAsset(asset) {
+		return MetaAction{}, fmt.Errorf("asset %s is not supported", asset)
+	}
+	return MetaAction{"distribute", amount.String() + ":" + asset}, nil
+}
+
+// UpdateStakeWeight sets an additional stake asset's weight; 0 stops new stake
+// in that asset from counting.
+func UpdateStakeWeight(asset string, weight float64) (MetaAction, error) {
+	if !isValidAsset(asset) {
+		return MetaAction{}, fmt.Errorf("stake asset %s is not supported", asset)
+	}
+	if !(weight >= 0 && weight <= limits.MaxStakeWeight) {
+		return MetaAction{}, fmt.Errorf("stake weight must be between 0 and %.0f", limits.MaxStakeWeight)
+	}
+	return MetaAction{"update_stakeWeight", asset + ":" + formatFloat(weight)}, nil
+}
+
+// TreasuryStakeHbd moves treasury HBD into savings.
+func TreasuryStakeHbd(amount Amount) (MetaAction, error) {
+	if amount <= 0 {
+		return MetaAction{}, fmt.Errorf("stake amount must be positive")
+	}
+	return MetaAction{"treasury_stake_hbd", amount.String()}, nil
+}
+
+// TreasuryUnstakeHbd starts withdrawing treasury HBD from savings.
+func TreasuryUnstakeHbd(amount Amount) (MetaAction, error) {
+	if amount <= 0 {
+		return MetaAction{}, fmt.Errorf("unstake amount must be positive")
+	}
+	return MetaAction{"treasury_unstake_hbd", amount.String()}, nil
+}
+
+// NotBefore holds a passed proposal back until t. It is not an action but
+// travels with them in the meta field.
+func NotBefore(t time.Time) (MetaAction, error) { return windowMeta("not_before", t) }
+
+// NotAfter sets the deadline for executing a passed proposal; executing it
+// later fails it instead.
+func NotAfter(t time.Time) (MetaAction, error) { return windowMeta("not_after", t) }
+
+func windowMeta(key string, t time.Time) (MetaAction, error) {
+	if t.Unix() <= 0 {
+		return MetaAction{}, fmt.Errorf("%s must be a positive unix timestamp", key)
+	}
+	return MetaAction{key, strconv.FormatInt(t.Unix(), 10)}, nil
+}
+
+// ConditionMeta holds a passed proposal back until key in contract's state
+// compares to value: op is eq or ne for text, lt, le, gt or ge for numbers.
+// Keys that are not printable text are sent in hex.
+func ConditionMeta(contract, key, op, value string) (MetaAction, error) {
+	if strings.TrimSpace(contract) == "" || key == "" {
+		return MetaAction{}, fmt.Errorf("condition contract and key cannot be empty")
+	}
+	if strings.Contains(contract, ",") {
+		return MetaAction{}, fmt.Errorf("invalid condition contract %q", contract)
+	}
+	switch op {
+	case "eq", "ne":
+	case "lt", "le", "gt", "ge":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return MetaAction{}, fmt.Errorf("condition comparator %s needs a number, got %q", op, value)
+		}
+	default:
+		return MetaAction{}, fmt.Errorf("unknown condition comparator %q (use eq, ne, lt, le, gt or ge)", op)
+	}
+	return MetaAction{"condition", contract + "," + conditionKey(key) + "," + op + "," + value}, nil
+}
+
+// conditionKey spells a state key for the condition meta, in hex unless it
+// is printable text the contract would read back unchanged.
+func conditionKey(key string) string {
+	if strings.HasPrefix(key, "0x") || strings.TrimSpace(key) != key {
+		return "0x" + hex.EncodeToString([]byte(key))
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e || key[i] == ',' || key[i] == ';' {
+			return "0x" + hex.EncodeToString([]byte(key))
+		}
+	}
+	return key
+}
+
+// RequiresMeta makes a proposal wait until the listed earlier proposals of
+// the same project have executed; it fails if one of them fails.
+func RequiresMeta(proposalIDs ...uint64) (MetaAction, error) {
+	if len(proposalIDs) == 0 {
+		return MetaAction{}, fmt.Errorf("requires needs at least one proposal id")
+	}
+	if len(proposalIDs) > limits.MaxPrerequisites {
+		return MetaAction{}, fmt.Errorf("a proposal cannot require more than %d proposals", limits.MaxPrerequisites)
+	}
+	seen := map[uint64]bool{}
+	ids := make([]string, len(proposalIDs))
+	for i, id := range proposalIDs {
+		if seen[id] {
+			return MetaAction{}, fmt.Errorf("prerequisite %d listed twice", id)
+		}
+		seen[id] = true
+		ids[i] = strconv.FormatUint(id, 10)
+	}
+	return MetaAction{"requires", strings.Join(ids, ",")}, nil
+}
+
+// DissolveProject liquidates the project and refunds members.
+func DissolveProject() MetaAction { return MetaAction{"dissolve_project", "1"} }
+
+// ExportProjectMeta moves the project, its funds and the listed open proposals
+// to another deployment of the contract.
+func ExportProjectMeta(contract string, proposalIDs ...uint64) (MetaAction, error) {
+	if err := validateExport(contract, proposalIDs); err != nil {
+		return MetaAction{}, err
+	}
+	value := contract
+	if len(proposalIDs) > 0 {
+		ids := make([]string, len(proposalIDs))
+		for i, id := range proposalIDs {
+			ids[i] = strconv.FormatUint(id, 10)
+		}
+		value += ":" + strings.Join(ids, ",")
+	}
+	return MetaAction{"export_project", value}, nil
+}
+
+// validateExport checks an export target and proposal list.
+func validateExport(contract string, proposalIDs []uint64) error {
+	if contract == "" {
+		return fmt.Errorf("target contract required")
+	}
+	if strings.ContainsAny(contract, ":,;| \t\n") {
+		return fmt.Errorf("invalid target contract %q", contract)
+	}
+	if len(proposalIDs) > limits.MaxExportProposals {
+		return fmt.Errorf("export cannot carry more than %d proposals", limits.MaxExportProposals)
+	}
+	seen := map[uint64]bool{}
+	for _, id := range proposalIDs {
+		if seen[id] {
+			return fmt.Errorf("proposal %d listed twice", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// validateMeta checks a proposal's meta list as a whole.
+func validateMeta(meta []MetaAction) error {
+	seen := map[string]bool{}
+	size := 0
+	var notBefore, notAfter int64
+	for _, m := range meta {
+		switch m.Key {
+		case "not_before":
+			notBefore, _ = strconv.ParseInt(m.Value, 10, 64)
+		case "not_after":
+			notAfter, _ = strconv.ParseInt(m.Value, 10, 64)
+		}
+		if m.Key == "" {
+			return fmt.Errorf("meta action key cannot be empty")
+		}
+		if seen[m.Key] {
+			return fmt.Errorf("duplicate meta action: %s", m.Key)
+		}
+		seen[m.Key] = true
+		size += len(m.Key) + len(m.Value) + 2
+	}
+	if size > limits.MaxMetaLength {
+		return fmt.Errorf("proposal meta exceeds maximum length of %d characters", limits.MaxMetaLength)
+	}
+	if notBefore > 0 && notAfter > 0 && notAfter <= notBefore {
+		return fmt.Errorf("not_after must be later than not_before")
+	}
+	return nil
+}
+
+// ParseMeta builds a meta action from its raw key and value, as a proposal
+// payload or command line carries it, validating the value with the matching
+// constructor. Unknown keys are rejected.
+func ParseMeta(key, value string) (MetaAction, error) {
+	value = strings.TrimSpace(value)
+	switch key {
+	case "update_threshold":
+		return parseFloatMeta(value, UpdateThreshold)
+	case "update_quorum":
+		return parseFloatMeta(value, UpdateQuorum)
+	case "update_proposalDuration":
+		return parseHoursMeta(value, UpdateProposalDuration)
+	case "update_executionDelay":
+		return parseHoursMeta(value, UpdateExecutionDelay)
+	case "update_leaveCooldown":
+		return parseHoursMeta(value, UpdateLeaveCooldown)
+	case "update_proposalCost":
+		return parseAmountMeta(value, UpdateProposalCost)
+	case "update_url":
+		return UpdateURL(value)
+	case "update_name":
+		return UpdateName(value)
+	case "update_description":
+		return UpdateDescription(value)
+	case "update_metadata":
+		return UpdateMetadata(value)
+	case "update_votingSystem":
+		vs, err := strconv.ParseUint(value, 10, 8)
+		if err != nil {
+			return MetaAction{}, fmt.Errorf("invalid %s value %q", key, value)
+		}
+		return UpdateVotingSystem(VotingSystem(vs))
+	case "update_stakeMin":
+		return parseAmountMeta(value, UpdateStakeMin)
+	case "update_whitelistOnly":
+		on, err := strconv.ParseBool(value)
+		if err != nil {
+			return MetaAction{}, fmt.Errorf("invalid %s value %q", key, value)
+		}
+		return UpdateWhitelistOnly(on), nil
+	case "update_membershipNFT":
+		return UpdateMembershipNFT(value)
+	case "update_membershipNFTContract":
+		return UpdateMembershipNFTContract(value), nil
+	case "update_membershipNFTContractFunction":
+		return UpdateMembershipNFTFunction(value), nil
+	case "update_membershipNFTPayload":
+		return UpdateMembershipNFTPayload(value)
+	case "update_proposalCreatorRestriction":
+		switch value {
+		case "members", "public":
+			return UpdateProposalCreatorRestriction(value == "members"), nil
+		}
+		return MetaAction{}, fmt.Errorf("proposal creator restriction must be members or public")
+	case "update_owner":
+		return UpdateOwner(value)
+	case "remove_owner":
+		return RemoveOwner(), nil
+	case "toggle_pause":
+		return TogglePause(), nil
+	case "dissolve_project":
+		return DissolveProject(), nil
+	case "export_project":
+		contract, list, _ := strings.Cut(value, ":")
+		var ids []uint64
+		for _, part := range splitList(list, ",") {
+			id, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				return MetaAction{}, fmt.Errorf("invalid proposal id %q", part)
+			}
+			ids = append(ids, id)
+		}
+		return ExportProjectMeta(strings.TrimSpace(contract), ids...)
+	case "whitelist_add":
+		return WhitelistAddMeta(splitList(value, ",")...)
+	case "whitelist_remove":
+		return WhitelistRemoveMeta(splitList(value, ",")...)
+	case "update_iccThreshold":
+		return parseFloatMeta(value, UpdateICCThreshold)
+	case "icc_allowlist_add":
+		return ICCAllowlistAddMeta(splitList(value, ",")...)
+	case "icc_allowlist_remove":
+		return ICCAllowlistRemoveMeta(splitList(value, ",")...)
+	case "kick_member":
+		return KickMember(splitList(value, ",")...)
+	case "distribute":
+		amt, asset, ok := strings.Cut(value, ":")
+		if !ok {
+			return MetaAction{}, fmt.Errorf("distribute must be amount:asset")
+		}
+		return parseAmountMeta(amt, func(a Amount) (MetaAction, error) { return Distribute(a, asset) })
+	case "update_stakeWeight":
+		asset, w, ok := strings.Cut(value, ":")
+		if !ok {
+			return MetaAction{}, fmt.Errorf("stake weight must be asset:weight")
+		}
+		return parseFloatMeta(w, func(v float64) (MetaAction, error) { return UpdateStakeWeight(asset, v) })
+	case "treasury_stake_hbd":
+		return parseAmountMeta(value, TreasuryStakeHbd)
+	case "treasury_unstake_hbd":
+		return parseAmountMeta(value, TreasuryUnstakeHbd)
+	case "requires":
+		var ids []uint64
+		for _, part := range splitList(value, ",") {
+			id, err := strconv.ParseUint(part, 10, 64)
+			if err != nil {
+				return MetaAction{}, fmt.Errorf("invalid proposal id %q", part)
+			}
+			ids = append(ids, id)
+		}
+		return RequiresMeta(ids...)
+	case "condition":
+		parts := strings.SplitN(value, ",", 4)
+		if len(parts) != 4 {
+			return MetaAction{}, fmt.Errorf("condition must be contract,key,op,value")
+		}
+		key := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(key, "0x") {
+			raw, err := hex.DecodeString(key[2:])
+			if err != nil || len(raw) == 0 {
+				return MetaAction{}, fmt.Errorf("invalid hex condition key %s", key)
+			}
+			key = string(raw)
+		}
+		return ConditionMeta(strings.TrimSpace(parts[0]), key, strings.TrimSpace(parts[2]), strings.TrimSpace(parts[3]))
+	case "not_before", "not_after":
+		sec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return MetaAction{}, fmt.Errorf("invalid %s timestamp %q", key, value)
+		}
+		return windowMeta(key, time.Unix(sec, 0))
+	}
+	return MetaAction{}, fmt.Errorf("unknown meta action %q", key)
+}
+
+func parseFloatMeta(value string, build func(float64) (MetaAction, error)) (MetaAction, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return MetaAction{}, fmt.Errorf("invalid number %q", value)
+	}
+	return build(v)
+}
+
+func parseHoursMeta(value string, build func(uint64) (MetaAction, error)) (MetaAction, error) {
+	v, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return MetaAction{}, fmt.Errorf("invalid hours %q", value)
+	}
+	return build(v)
+}
+
+func parseAmountMeta(value string, build func(Amount) (MetaAction, error)) (MetaAction, error) {
+	v, err := ParseAmount(value)
+	if err != nil {
+		return MetaAction{}, err
+	}
+	return build(v)
+}
@@ -0,0 +1,638 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/limits"
+)
+
+// -----------------------------------------------------------------------------
+// contract_init
+// -----------------------------------------------------------------------------
+
+// Init configures the contract once, right after deployment.
+type Init struct {
+	// OwnerOnly restricts project creation to the contract owner.
+	OwnerOnly bool
+	// Events selects the event encoding: "legacy" (default), "json" or "both".
+	Events string
+}
+
+// Validate checks the arguments the way contract_init does.
+func (a Init) Validate() error {
+	switch a.Events {
+	case "", "legacy", "json", "both":
+		return nil
+	}
+	return fmt.Errorf(`event format must be "legacy", "json" or "both"`)
+}
+
+// Call builds the contract_init payload.
+func (a Init) Call() (Call, error) {
+	if err := a.Validate(); err != nil {
+		return Call{}, err
+	}
+	o := &object{}
+	if a.OwnerOnly {
+		o.set("mode", "owner-only")
+	} else {
+		o.set("mode", "public")
+	}
+	if a.Events != "" {
+		o.set("events", a.Events)
+	}
+	return o.call("contract_init"), nil
+}
+
+// -----------------------------------------------------------------------------
+// Contract administration (contract owner only)
+// -----------------------------------------------------------------------------
+
+// SetCreationMode opens project creation to everyone or restricts it to the
+// contract owner.
+func SetCreationMode(ownerOnly bool) Call {
+	o := &object{}
+	if ownerOnly {
+		o.set("mode", "owner-only")
+	} else {
+		o.set("mode", "public")
+	}
+	return o.call("contract_mode")
+}
+
+// TransferContract offers the contract to newOwner, who must call
+// AcceptContract. Passing the current owner withdraws the offer.
+func TransferContract(newOwner string) (Call, error) {
+	if err := validateAddress(newOwner); err != nil {
+		return Call{}, err
+	}
+	o := &object{}
+	o.set("newOwner", newOwner)
+	return o.call("contract_transfer"), nil
+}
+
+// AcceptContract takes over the contract after TransferContract named the caller.
+func AcceptContract() Call { return Call{Action: "contract_accept", Payload: "accept"} }
+
+// RenounceContract leaves the contract without an owner. It cannot be undone.
+func RenounceContract() Call {
+	o := &object{}
+	o.set("confirm", "renounce")
+	return o.call("contract_renounce")
+}
+
+// SetCreationFee sets the fee charged for each new project, paid to the
+// contract owner. A zero fee removes it and ignores asset.
+func SetCreationFee(fee Amount, asset string) (Call, error) {
+	if fee < 0 {
+		return Call{}, fmt.Errorf("creation fee cannot be negative")
+	}
+	o := &object{}
+	o.set("amount", fee.String())
+	if fee > 0 {
+		if !isValidAsset(asset) {
+			return Call{}, fmt.Errorf("asset %s is not supported", asset)
+		}
+		o.set("asset", asset)
+	}
+	return o.call("contract_fee"), nil
+}
+
+// -----------------------------------------------------------------------------
+// project_create
+// -----------------------------------------------------------------------------
+
+// CreateProjectArgs describes a new project. Zero values take the contract's
+// fallbacks, except ProposalCost and StakeMin which are sent as given.
+type CreateProjectArgs struct {
+	Name             string
+	Description      string
+	VotingSystem     VotingSystem
+	ThresholdPercent float64
+	QuorumPercent    float64
+	ProposalDuration uint64 // hours
+	ExecutionDelay   uint64 // hours
+	LeaveCooldown    uint64 // hours
+	ProposalCost     Amount
+	StakeMin         Amount
+
+	// Membership NFT gating; leave MembershipContract empty for open projects.
+	MembershipContract      string
+	MembershipFunction      string
+	MembershipNFT           string
+	MembershipPayloadFormat string
+
+	Metadata string
+	URL      string
+	// MembersOnly lets only members create proposals.
+	MembersOnly   bool
+	WhitelistOnly bool
+	// StakeAssets adds assets that count as stake, keyed by asset with their
+	// weight relative to the funds asset.
+	StakeAssets map[string]float64
+}
+
+// Validate checks the arguments against the contract's limits.
+func (a CreateProjectArgs) Validate() error {
+	if err := checkLength(strings.TrimSpace(a.Name), limits.MaxNameLength, "project name"); err != nil {
+		return err
+	}
+	if err := checkLength(strings.TrimSpace(a.Description), limits.MaxDescriptionLength, "project description"); err != nil {
+		return err
+	}
+	if err := checkLength(a.Metadata, limits.MaxDescriptionLength, "project metadata"); err != nil {
+		return err
+	}
+	if err := checkLength(a.URL, limits.MaxURLLength, "project URL"); err != nil {
+		return err
+	}
+	if a.VotingSystem > StakeReputation {
+		return fmt.Errorf("invalid voting system")
+	}
+	if t := a.ThresholdPercent; t != 0 && (t < limits.MinThresholdPercent || t > limits.MaxThresholdPercent) {
+		return fmt.Errorf("threshold must be between %.0f%% and %.0f%%", limits.MinThresholdPercent, limits.MaxThresholdPercent)
+	}
+	if q := a.QuorumPercent; q != 0 && (q < limits.MinQuorumPercent || q > limits.MaxQuorumPercent) {
+		return fmt.Errorf("quorum must be between %.0f%% and %.0f%%", limits.MinQuorumPercent, limits.MaxQuorumPercent)
+	}
+	if a.ProposalDuration > limits.MaxProposalDurationHours {
+		return fmt.Errorf("proposal duration must not exceed %d hours", limits.MaxProposalDurationHours)
+	}
+	if a.ExecutionDelay > limits.MaxDurationHours {
+		return fmt.Errorf("execution delay must not exceed %d hours", limits.MaxDurationHours)
+	}
+	if a.LeaveCooldown > limits.MaxDurationHours {
+		return fmt.Errorf("leave cooldown must not exceed %d hours", limits.MaxDurationHours)
+	}
+	if a.ProposalCost < 0 || a.StakeMin < 0 {
+		return fmt.Errorf("amounts cannot be negative")
+	}
+	if err := checkLength(a.MembershipNFT, limits.MaxTokenIdLength, "membership NFT id"); err != nil {
+		return err
+	}
+	for asset, w := range a.StakeAssets {
+		if !isValidAsset(asset) {
+			return fmt.Errorf("stake asset %s is not supported", asset)
+		}
+		if !(w > 0 && w <= limits.MaxStakeWeight) {
+			return fmt.Errorf("stake weight must be greater than 0 and at most %.0f", limits.MaxStakeWeight)
+		}
+	}
+	return nil
+}
+
+// Call builds the project_create payload.
+func (a CreateProjectArgs) Call() (Call, error) {
+	if err := a.Validate(); err != nil {
+		return Call{}, err
+	}
+	o := &object{}
+	o.set("name", a.Name)
+	o.set("description", a.Description)
+	o.set("votingSystem", strconv.Itoa(int(a.VotingSystem)))
+	o.set("threshold", formatFloat(a.ThresholdPercent))
+	o.set("quorum", formatFloat(a.QuorumPercent))
+	o.set("proposalDuration", strconv.FormatUint(a.ProposalDuration, 10))
+	if a.ExecutionDelay > 0 {
+		o.set("executionDelay", strconv.FormatUint(a.ExecutionDelay, 10))
+	}
+	o.set("leaveCooldown", strconv.FormatUint(a.LeaveCooldown, 10))
+	o.set("proposalCost", a.ProposalCost.String())
+	o.set("stakeMin", a.StakeMin.String())
+	setOpt(o, "membershipContract", a.MembershipContract)
+	setOpt(o, "membershipFn", a.MembershipFunction)
+	setOpt(o, "membershipNftId", a.MembershipNFT)
+	setOpt(o, "metadata", a.Metadata)
+	if a.MembersOnly {
+		o.set("proposalCreatorRestriction", "members")
+	}
+	setOpt(o, "membershipPayloadFormat", a.MembershipPayloadFormat)
+	setOpt(o, "url", a.URL)
+	if a.WhitelistOnly {
+		o.set("whitelistOnly", "true")
+	}
+	if len(a.StakeAssets) > 0 {
+		weights := &object{}
+		for _, asset := range sortedKeys(a.StakeAssets) {
+			weights.set(asset, formatFloat(a.StakeAssets[asset]))
+		}
+		o.set("stakeAssets", weights)
+	}
+	return o.call("project_create"), nil
+}
+
+// -----------------------------------------------------------------------------
+// proposal_create
+// -----------------------------------------------------------------------------
+
+// Option is one ballot option with an optional https link.
+type Option struct {
+	Text string
+	URL  string
+}
+
+// Payout sends treasury funds when a proposal passes. L1 withdraws to the Hive
+// base layer instead of crediting the ledger.
+type Payout struct {
+	Address string
+	Amount  Amount
+	Asset   string
+	L1      bool
+}
+
+// ICC is one inter-contract call executed when a proposal passes. Assets are
+// the allowances handed to the called contract. Expect, when set, is the
+// result the callee must return for the execution to go through; build it
+// with ExpectEqual, ExpectContains or ExpectMin. Receive lists the assets
+// ("hive", "hbd") the callee sends back; they are credited to the treasury
+// together with any allowance it left undrawn.
+type ICC struct {
+	Contract string
+	Function string
+	Payload  string
+	Assets   map[string]Amount
+	Expect   string
+	Receive  []string
+}
+
+// ExpectEqual requires the callee to return exactly text.
+func ExpectEqual(text string) string { return "eq:" + text }
+
+// ExpectContains requires the callee's response to contain text.
+func ExpectContains(text string) string { return "has:" + text }
+
+// ExpectMin requires the number at field, a dotted path into the callee's
+// JSON response ("out.amount", "fills.0.qty"), to be at least min. An empty
+// field reads the whole response as the number.
+func ExpectMin(field string, min float64) string { return "min:" + field + ":" + formatFloat(min) }
+
+// validateExpect checks an ICC.Expect predicate like the contract does.
+func validateExpect(expect string) error {
+	if expect == "" {
+		return nil
+	}
+	if len(expect) > limits.MaxICCExpectLength {
+		return fmt.Errorf("ICC expectation exceeds maximum length of %d characters", limits.MaxICCExpectLength)
+	}
+	kind, rest, _ := strings.Cut(expect, ":")
+	switch kind {
+	case "eq":
+		return nil
+	case "has":
+		if rest == "" {
+			return fmt.Errorf("ICC expectation has: needs a text")
+		}
+		return nil
+	case "min":
+		i := strings.LastIndex(rest, ":")
+		if i < 0 {
+			return fmt.Errorf("ICC expectation min needs <field>:<number>")
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(rest[i+1:]), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("invalid ICC expectation minimum %q", rest[i+1:])
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid ICC expectation %q (use eq:<text>, has:<text> or min:<field>:<number>)", expect)
+}
+
+// CreateProposalArgs describes a new proposal. Without options the proposal is
+// a yes/no vote; with options, or ForcePoll, it is a poll.
+type CreateProposalArgs struct {
+	ProjectID   uint64
+	Name        string
+	Description string
+	Duration    uint64 // hours; 0 uses the project default
+	Options     []Option
+	ForcePoll   bool
+	Payouts     []Payout
+	Meta        []MetaAction
+	Metadata    string
+	URL         string
+	ICC         []ICC
+}
+
+// Validate checks the arguments against the contract's limits.
+func (a CreateProposalArgs) Validate() error {
+	if err := checkLength(strings.TrimSpace(a.Name), limits.MaxNameLength, "proposal name"); err != nil {
+		return err
+	}
+	if err := checkLength(strings.TrimSpace(a.Description), limits.MaxDescriptionLength, "proposal description"); err != nil {
+		return err
+	}
+	if err := checkLength(a.Metadata, limits.MaxDescriptionLength, "proposal metadata"); err != nil {
+		return err
+	}
+	if err := checkLength(a.URL, limits.MaxURLLength, "proposal URL"); err != nil {
+		return err
+	}
+	if len(a.Options) > limits.MaxProposalOptions {
+		return fmt.Errorf("proposal cannot have more than %d options", limits.MaxProposalOptions)
+	}
+	for _, opt := range a.Options {
+		if strings.TrimSpace(opt.Text) == "" {
+			return fmt.Errorf("option text cannot be empty")
+		}
+		if err := checkLength(opt.Text, limits.MaxOptionTextLength, "option text"); err != nil {
+			return err
+		}
+		if err := checkHTTPS(opt.URL, "option URL"); err != nil {
+			return err
+		}
+	}
+	if len(a.Payouts) > limits.MaxPayoutReceivers {
+		return fmt.Errorf("proposal cannot have more than %d payout entries", limits.MaxPayoutReceivers)
+	}
+	for _, p := range a.Payouts {
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateMeta(a.Meta); err != nil {
+		return err
+	}
+	if len(a.ICC) > limits.MaxICCCalls {
+		return fmt.Errorf("ICC cannot exceed %d calls per proposal", limits.MaxICCCalls)
+	}
+	for _, c := range a.ICC {
+		if strings.TrimSpace(c.Contract) == "" {
+			return fmt.Errorf("ICC contract address cannot be empty")
+		}
+		if strings.TrimSpace(c.Function) == "" {
+			return fmt.Errorf("ICC function cannot be empty")
+		}
+		for asset, amt := range c.Assets {
+			if !isValidAsset(asset) {
+				return fmt.Errorf("ICC asset %s is not supported", asset)
+			}
+			if amt <= 0 {
+				return fmt.Errorf("ICC asset amount must be positive")
+			}
+		}
+		if err := validateExpect(c.Expect); err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, asset := range c.Receive {
+			if asset != "hive" && asset != "hbd" {
+				return fmt.Errorf("ICC can only receive hive or hbd, not %s", asset)
+			}
+			if seen[asset] {
+				return fmt.Errorf("ICC receive asset %s specified multiple times", asset)
+			}
+			seen[asset] = true
+		}
+	}
+	return nil
+}
+
+func (p Payout) validate() error {
+	if !isValidAsset(p.Asset) {
+		return fmt.Errorf("payout asset %s is not supported", p.Asset)
+	}
+	if p.Amount <= 0 {
+		return fmt.Errorf("payout amount must be positive")
+	}
+	if err := validateAddress(p.Address); err != nil {
+		return err
+	}
+	if p.L1 {
+		if !strings.HasPrefix(p.Address, "hive:") {
+			return fmt.Errorf("l1 payouts require a hive: address")
+		}
+		if p.Asset != "hive" && p.Asset != "hbd" {
+			return fmt.Errorf("l1 payouts support hive and hbd only")
+		}
+	}
+	return nil
+}
+
+// Call builds the proposal_create payload.
+func (a CreateProposalArgs) Call() (Call, error) {
+	if err := a.Validate(); err != nil {
+		return Call{}, err
+	}
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(a.ProjectID, 10))
+	o.set("name", a.Name)
+	o.set("description", a.Description)
+	o.set("duration", strconv.FormatUint(a.Duration, 10))
+	if len(a.Options) > 0 {
+		opts := make([]interface{}, len(a.Options))
+		for i, opt := range a.Options {
+			if opt.URL == "" {
+				opts[i] = opt.Text
+				continue
+			}
+			e := &object{}
+			e.set("text", opt.Text)
+			e.set("url", opt.URL)
+			opts[i] = e
+		}
+		o.set("options", opts)
+	}
+	if a.ForcePoll {
+		o.set("forcePoll", "true")
+	}
+	if len(a.Payouts) > 0 {
+		payouts := make([]interface{}, len(a.Payouts))
+		for i, p := range a.Payouts {
+			e := &object{}
+			e.set("address", p.Address)
+			e.set("amount", p.Amount.String())
+			e.set("asset", p.Asset)
+			if p.L1 {
+				e.set("mode", "l1")
+			}
+			payouts[i] = e
+		}
+		o.set("payouts", payouts)
+	}
+	if len(a.Meta) > 0 {
+		meta := &object{}
+		for _, m := range a.Meta {
+			meta.set(m.Key, m.Value)
+		}
+		o.set("meta", meta)
+	}
+	setOpt(o, "metadata", a.Metadata)
+	setOpt(o, "url", a.URL)
+	if len(a.ICC) > 0 {
+		calls := make([]interface{}, len(a.ICC))
+		for i, c := range a.ICC {
+			e := &object{}
+			e.set("contract", c.Contract)
+			e.set("function", c.Function)
+			e.set("payload", c.Payload)
+			if len(c.Assets) > 0 {
+				assets := &object{}
+				for _, asset := range sortedKeys(c.Assets) {
+					assets.set(asset, c.Assets[asset].String())
+				}
+				e.set("assets", assets)
+			}
+			setOpt(e, "expect", c.Expect)
+			if len(c.Receive) > 0 {
+				e.set("receive", c.Receive)
+			}
+			calls[i] = e
+		}
+		o.set("icc", calls)
+	}
+	return o.call("proposal_create"), nil
+}
+
+// Simulate builds a proposal_simulate call that dry-runs the proposal before
+// it is created. No proposal cost is charged.
+func (a CreateProposalArgs) Simulate() (Call, error) {
+	call, err := a.Call()
+	if err != nil {
+		return Call{}, err
+	}
+	call.Action = "proposal_simulate"
+	return call, nil
+}
+
+// -----------------------------------------------------------------------------
+// Other exports
+// -----------------------------------------------------------------------------
+
+// Vote casts a ballot. Yes/no proposals take choice 1 for yes and 0 for no;
+// polls take one or more option indexes.
+func Vote(proposalID uint64, choices ...uint) (Call, error) {
+	if len(choices) == 0 {
+		return Call{}, fmt.Errorf("at least one choice is required")
+	}
+	if len(choices) > limits.MaxProposalOptions {
+		return Call{}, fmt.Errorf("too many choices")
+	}
+	list := make([]string, len(choices))
+	for i, c := range choices {
+		list[i] = strconv.FormatUint(uint64(c), 10)
+	}
+	o := &object{}
+	o.set("proposalId", strconv.FormatUint(proposalID, 10))
+	o.set("choices", list)
+	return o.call("proposals_vote"), nil
+}
+
+// AddFunds deposits the attached transfer into the treasury, or into the
+// caller's stake when toStake is set.
+func AddFunds(projectID uint64, toStake bool) (Call, error) {
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(projectID, 10))
+	o.set("toStake", strconv.FormatBool(toStake))
+	return o.call("project_funds"), nil
+}
+
+// Unstake requests (first call) or completes (second call) a partial unstake.
+func Unstake(projectID uint64, amount Amount) (Call, error) {
+	if amount <= 0 {
+		return Call{}, fmt.Errorf("unstake amount must be positive")
+	}
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(projectID, 10))
+	o.set("amount", amount.String())
+	return o.call("project_unstake"), nil
+}
+
+// TransferOwnership hands a project to a new owner.
+func TransferOwnership(projectID uint64, newOwner string) (Call, error) {
+	if err := validateAddress(newOwner); err != nil {
+		return Call{}, err
+	}
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(projectID, 10))
+	o.set("newOwner", newOwner)
+	return o.call("project_transfer"), nil
+}
+
+// Pause pauses or resumes a project immediately (owner only).
+func Pause(projectID uint64, paused bool) (Call, error) {
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(projectID, 10))
+	o.set("paused", strconv.FormatBool(paused))
+	return o.call("project_pause"), nil
+}
+
+// WhitelistAdd adds addresses to a whitelist-only project.
+func WhitelistAdd(projectID uint64, addresses ...string) (Call, error) {
+	return whitelistCall("project_whitelist_add", projectID, addresses)
+}
+
+// WhitelistRemove removes addresses from a project's whitelist.
+func WhitelistRemove(projectID uint64, addresses ...string) (Call, error) {
+	return whitelistCall("project_whitelist_remove", projectID, addresses)
+}
+
+func whitelistCall(action string, projectID uint64, addresses []string) (Call, error) {
+	if err := validateAddresses(addresses, limits.MaxWhitelistAddresses, "whitelist"); err != nil {
+		return Call{}, err
+	}
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(projectID, 10))
+	o.set("addresses", addresses)
+	return o.call(action), nil
+}
+
+// DividendClaim collects the caller's dividends; an empty asset claims all.
+func DividendClaim(projectID uint64, asset string) (Call, error) {
+	if asset != "" && !isValidAsset(asset) {
+		return Call{}, fmt.Errorf("asset %s is not supported", asset)
+	}
+	o := &object{}
+	o.set("projectId", strconv.FormatUint(projectID, 10))
+	setOpt(o, "asset", asset)
+	return o.call("dividend_claim"), nil
+}
+
+// Join joins a project; attach the stake (or membership fee) as a transfer.
+func Join(projectID uint64) Call { return idCall("project_join", projectID) }
+
+// Leave starts, or after the cooldown completes, leaving a project.
+func Leave(projectID uint64) Call { return idCall("project_leave", projectID) }
+
+// Tally closes voting on a proposal once its duration has passed.
+func Tally(proposalID uint64) Call { return idCall("proposal_tally", proposalID) }
+
+// Execute runs the outcome of a passed proposal.
+func Execute(proposalID uint64) Call { return idCall("proposal_execute", proposalID) }
+
+// Simulate dry-runs a proposal against the current state; the call returns a
+// JSON report of every check, payout, meta action and inter-contract call.
+func Simulate(proposalID uint64) Call { return idCall("proposal_simulate", proposalID) }
+
+// Cancel cancels an active proposal (creator or project owner).
+func Cancel(proposalID uint64) Call { return idCall("proposal_cancel", proposalID) }
+
+// ReputationClaim claims the reputation earned by voting on a proposal.
+func ReputationClaim(proposalID uint64) Call { return idCall("reputation_claim", proposalID) }
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+// setOpt sets a text field only when it is non-empty.
+func setOpt(o *object, key, val string) {
+	if val != "" {
+		o.set(key, val)
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,16 @@
+// Command okinoko builds, validates and optionally submits calls to the
+// Okinoko DAO contract. See package okinoko_dao/cli for the commands.
+//
+//	okinoko -contract vsc1... project join 3 -deposit 1.000
+//	okinoko -contract vsc1... -as hive:alice -submit https://relay.example/call proposal vote 7 1
+package main
+
+import (
+	"os"
+
+	"okinoko_dao/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], cli.Env{Out: os.Stdout}))
+}
@@ -109,6 +109,104 @@ func (w *binWriter) writeStringMap(m map[string]string) {
 	}
 }
 
+// writeAssetAmountMap stores per-asset amounts in sorted asset order.
+func (w *binWriter) writeAssetAmountMap(m map[sdk.Asset]Amount) {
+	keys := sortedAssetKeys(m)
+	w.writeVarUint(uint64(len(keys)))
+	for _, k := range keys {
+		w.writeAsset(k)
+		w.writeAmount(m[k])
+	}
+}
+
+// writeAssetWeightMap stores per-asset stake weights in sorted asset order.
+func (w *binWriter) writeAssetWeightMap(m map[sdk.Asset]float64) {
+	keys := sortedAssetKeys(m)
+	w.writeVarUint(uint64(len(keys)))
+	for _, k := range keys {
+		w.writeAsset(k)
+		w.writeFloat64(m[k])
+	}
+}
+
+// writePayoutModes stores the payout modes of an outcome, one byte per entry.
+// All-ledger outcomes (and no outcome) write an empty list, so their bytes match
+// records written before payout modes existed.
+func (w *binWriter) writePayoutModes(out *ProposalOutcome) {
+	if out == nil || !hasL1Payout(out.Payout) {
+		w.writeVarUint(0)
+		return
+	}
+	w.writeVarUint(uint64(len(out.Payout)))
+	for _, entry := range out.Payout {
+		w.buf.WriteByte(byte(entry.Mode))
+	}
+}
+
+// writeICCExpectations appends each call's expected-result predicate after
+// the payout modes. Like those it is a single 0 when no call declares one, so
+// records without expectations keep their size.
+func (w *binWriter) writeICCExpectations(out *ProposalOutcome) {
+	if out == nil || !hasICCExpectation(out.ICC) {
+		w.writeVarUint(0)
+		return
+	}
+	w.writeVarUint(uint64(len(out.ICC)))
+	for _, icc := range out.ICC {
+		w.buf.WriteByte(byte(icc.Expect.Kind))
+		w.writeString(icc.Expect.Field)
+		w.writeString(icc.Expect.Value)
+	}
+}
+
+// writeICCReceipts appends the assets each call declares it sends back, after
+// the expectations and in the same shape: 0 when no call receives anything.
+func (w *binWriter) writeICCReceipts(out *ProposalOutcome) {
+	if out == nil || !hasICCReceipts(out.ICC) {
+		w.writeVarUint(0)
+		return
+	}
+	w.writeVarUint(uint64(len(out.ICC)))
+	for _, icc := range out.ICC {
+		w.writeVarUint(uint64(len(icc.Receive)))
+		for _, asset := range icc.Receive {
+			w.writeString(asset.String())
+		}
+	}
+}
+
+// writeStateCondition appends a proposal's state condition: 0 without one,
+// else 1 and its fields.
+func (w *binWriter) writeStateCondition(c *StateCondition) {
+	if c == nil {
+		w.writeVarUint(0)
+		return
+	}
+	w.writeVarUint(1)
+	w.writeString(c.Contract)
+	w.writeString(c.Key)
+	w.buf.WriteByte(byte(c.Op))
+	w.writeString(c.Value)
+}
+
+func hasICCReceipts(calls []InterContractCall) bool {
+	for _, icc := range calls {
+		if len(icc.Receive) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func hasICCExpectation(calls []InterContractCall) bool {
+	for _, icc := range calls {
+		if icc.Expect.Kind != ICCExpectNone {
+			return true
+		}
+	}
+	return false
+}
+
 // writeAddress canonicalizes the address before writing, so later parsing is easyer.
 func (w *binWriter) writeAddress(a sdk.Address) {
 	w.writeString(AddressToString(a))
@@ -135,6 +233,7 @@ func encodeProjectConfig(w *binWriter, cfg *ProjectConfig) {
 	w.writeString(cfg.MembershipNftPayloadFormat)
 	w.writeBool(cfg.ProposalsMembersOnly)
 	w.writeBool(cfg.WhitelistOnly)
+	w.writeFloat64(cfg.ICCThresholdPercent)
 }
 
 // encodeMember serializes member lifecycle data for caching and rehydrating later.
@@ -150,6 +249,8 @@ func encodeMember(w *binWriter, m *Member) {
 	w.writeInt64(m.VoteLockUntil)
 	w.writeInt64(m.UnstakeRequested)
 	w.writeAmount(m.UnstakePending)
+	w.writeInt64(m.ReputationAt)
+	w.writeAssetAmountMap(m.AssetStakes)
 }
 
 // EncodeMember packs a Member into bytes so storage stays lean and no json noise leaks.
@@ -224,6 +325,7 @@ func EncodeProject(prj *Project) []byte {
 	w.writeAmount(prj.StakeTotal)
 	w.writeUint64(prj.MemberCount)
 	w.writeString(prj.URL)
+	w.writeBool(prj.Dissolved)
 	return w.bytes()
 }
 
@@ -252,6 +354,26 @@ func EncodeProposal(prpsl *Proposal) []byte {
 	w.writeVarUint(prpsl.VoterCount)
 	w.writeAmount(prpsl.CostPaid)
 	w.writeVarUint(prpsl.JoinSeqSnapshot)
+	w.writeBool(prpsl.QuorumReached)
+	w.writeAmount(prpsl.VotedStake)
+	w.writeAmount(prpsl.VotedWeight)
+	w.writeAssetWeightMap(prpsl.StakeWeights)
+	w.writePayoutModes(prpsl.Outcome)
+	w.writeICCExpectations(prpsl.Outcome)
+	w.writeICCReceipts(prpsl.Outcome)
+	w.writeVarUint(uint64(prpsl.NotBefore))
+	w.writeVarUint(uint64(prpsl.NotAfter))
+	w.writeStateCondition(prpsl.Condition)
+	w.writeVarUint(uint64(len(prpsl.Prerequisites)))
+	for _, id := range prpsl.Prerequisites {
+		w.writeVarUint(id)
+	}
+	if prpsl.VotingSystem == nil {
+		w.writeVarUint(0)
+	} else {
+		w.writeVarUint(1)
+		w.buf.WriteByte(byte(*prpsl.VotingSystem))
+	}
 	return w.bytes()
 }
 
@@ -283,6 +405,7 @@ func EncodeCreateProposalArgs(args *CreateProposalArgs) []byte {
 	w.writeUint64(args.ProposalDuration)
 	w.writeString(args.Metadata)
 	w.writeString(args.URL)
+	w.writePayoutModes(args.ProposalOutcome)
 	return w.bytes()
 }
 
@@ -478,8 +601,156 @@ func (r *binReader) readStringMap() (map[string]string, error) {
 	return result, nil
 }
 
+// readAssetAmountMap is the inverse of writeAssetAmountMap. Empty maps decode as nil.
+func (r *binReader) readAssetAmountMap() (map[sdk.Asset]Amount, error) {
+	count, err := r.readVarUint()
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	result := make(map[sdk.Asset]Amount, count)
+	for i := uint64(0); i < count; i++ {
+		asset, err := r.readAsset()
+		if err != nil {
+			return nil, err
+		}
+		if result[asset], err = r.readAmount(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// readAssetWeightMap is the inverse of writeAssetWeightMap. Empty maps decode as nil.
+func (r *binReader) readAssetWeightMap() (map[sdk.Asset]float64, error) {
+	count, err := r.readVarUint()
+	if err != nil || count == 0 {
+		return nil, err
+	}
+	result := make(map[sdk.Asset]float64, count)
+	for i := uint64(0); i < count; i++ {
+		asset, err := r.readAsset()
+		if err != nil {
+			return nil, err
+		}
+		if result[asset], err = r.readFloat64(); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// readPayoutModes applies the list written by writePayoutModes to the already
+// decoded payout entries.
+func (r *binReader) readPayoutModes(out *ProposalOutcome) error {
+	count, err := r.readVarUint()
+	if err != nil || count == 0 {
+		return err
+	}
+	if out == nil || count != uint64(len(out.Payout)) {
+		return errors.New("payout mode count mismatch")
+	}
+	for i := range out.Payout {
+		b, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		out.Payout[i].Mode = PayoutMode(b)
+	}
+	return nil
+}
+
+// readICCExpectations applies the list written by writeICCExpectations to the
+// already decoded calls.
+func (r *binReader) readICCExpectations(out *ProposalOutcome) error {
+	count, err := r.readVarUint()
+	if err != nil || count == 0 {
+		return err
+	}
+	if out == nil || count != uint64(len(out.ICC)) {
+		return errors.New("ICC expectation count mismatch")
+	}
+	for i := range out.ICC {
+		b, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		exp := ICCExpectation{Kind: ICCExpectKind(b)}
+		if exp.Field, err = r.readString(); err != nil {
+			return err
+		}
+		if exp.Value, err = r.readString(); err != nil {
+			return err
+		}
+		out.ICC[i].Expect = exp
+	}
+	return nil
+}
+
+// readICCReceipts applies the list written by writeICCReceipts to the already
+// decoded calls.
+func (r *binReader) readICCReceipts(out *ProposalOutcome) error {
+	count, err := r.readVarUint()
+	if err != nil || count == 0 {
+		return err
+	}
+	if out == nil || count != uint64(len(out.ICC)) {
+		return errors.New("ICC receipt count mismatch")
+	}
+	for i := range out.ICC {
+		n, err := r.readVarUint()
+		if err != nil {
+			return err
+		}
+		if n > uint64(len(r.data)-r.pos) {
+			return errors.New("ICC receipt list exceeds record")
+		}
+		for j := uint64(0); j < n; j++ {
+			asset, err := r.readString()
+			if err != nil {
+				return err
+			}
+			out.ICC[i].Receive = append(out.ICC[i].Receive, AssetFromString(asset))
+		}
+	}
+	return nil
+}
+
+// readStateCondition is the inverse of writeStateCondition.
+func (r *binReader) readStateCondition() (*StateCondition, error) {
+	n, err := r.readVarUint()
+	if err != nil || n == 0 {
+		return nil, err
+	}
+	c := &StateCondition{}
+	if c.Contract, err = r.readString(); err != nil {
+		return nil, err
+	}
+	if c.Key, err = r.readString(); err != nil {
+		return nil, err
+	}
+	op, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+	c.Op = ConditionOp(op)
+	if c.Value, err = r.readString(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
 // decodeProjectConfig is the inverse of encodeProjectConfig and keeps same field order.
 func decodeProjectConfig(r *binReader) (ProjectConfig, error) {
+	cfg, err := decodeProjectConfigV0(r)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.ICCThresholdPercent, err = r.readFloat64()
+	return cfg, err
+}
+
+// decodeProjectConfigV0 reads the config layout from before the ICC threshold.
+func decodeProjectConfigV0(r *binReader) (ProjectConfig, error) {
 	var cfg ProjectConfig
 	b, err := r.readByte()
 	if err != nil {
@@ -532,6 +803,20 @@ func decodeProjectConfig(r *binReader) (ProjectConfig, error) {
 
 // decodeMember reads back the fields emitted by encodeMember in exact order.
 func decodeMember(r *binReader) (Member, error) {
+	m, err := decodeMemberV0(r)
+	if err != nil {
+		return m, err
+	}
+	if m.ReputationAt, err = r.readInt64(); err != nil {
+		return m, err
+	}
+	m.AssetStakes, err = r.readAssetAmountMap()
+	return m, err
+}
+
+// decodeMemberV0 reads the member layout from before reputation decay and
+// multi-asset staking.
+func decodeMemberV0(r *binReader) (Member, error) {
 	var m Member
 	addr, err := r.readString()
 	if err != nil {
@@ -785,6 +1070,9 @@ func DecodeProject(data []byte) (*Project, error) {
 			return nil, err
 		}
 	}
+	if prj.Dissolved, err = r.readBool(); err != nil {
+		return nil, err
+	}
 	return prj, nil
 }
 
@@ -818,6 +1106,7 @@ func EncodeProjectMeta(meta *ProjectMeta) []byte {
 	w.writeString(meta.Tx)
 	w.writeString(meta.Metadata)
 	w.writeString(meta.URL)
+	w.writeBool(meta.Dissolved)
 	return w.bytes()
 }
 
@@ -825,6 +1114,18 @@ func EncodeProjectMeta(meta *ProjectMeta) []byte {
 // Example payload: DecodeProjectMeta(EncodeProjectMeta(&ProjectMeta{Name:"test"}))
 func DecodeProjectMeta(data []byte) (*ProjectMeta, error) {
 	r := newReader(data)
+	meta, err := decodeProjectMetaV0(r)
+	if err != nil {
+		return nil, err
+	}
+	if meta.Dissolved, err = r.readBool(); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// decodeProjectMetaV0 reads the meta layout from before dissolution.
+func decodeProjectMetaV0(r *binReader) (*ProjectMeta, error) {
 	var meta ProjectMeta
 	var err error
 	if owner, err := r.readString(); err == nil {
@@ -862,6 +1163,8 @@ func EncodeProjectFinance(fin *ProjectFinance) []byte {
 	w.writeAsset(fin.FundsAsset)
 	w.writeAmount(fin.StakeTotal)
 	w.writeUint64(fin.MemberCount)
+	w.writeAssetWeightMap(fin.StakeWeights)
+	w.writeAssetAmountMap(fin.AssetStakeTotals)
 	return w.bytes()
 }
 
@@ -869,6 +1172,22 @@ func EncodeProjectFinance(fin *ProjectFinance) []byte {
 // Example payload: DecodeProjectFinance(EncodeProjectFinance(&ProjectFinance{FundsAsset:AssetFromString("hive")}))
 func DecodeProjectFinance(data []byte) (*ProjectFinance, error) {
 	r := newReader(data)
+	fin, err := decodeProjectFinanceV0(r)
+	if err != nil {
+		return nil, err
+	}
+	if fin.StakeWeights, err = r.readAssetWeightMap(); err != nil {
+		return nil, err
+	}
+	if fin.AssetStakeTotals, err = r.readAssetAmountMap(); err != nil {
+		return nil, err
+	}
+	return fin, nil
+}
+
+// decodeProjectFinanceV0 reads the finance layout from before multi-asset
+// staking.
+func decodeProjectFinanceV0(r *binReader) (*ProjectFinance, error) {
 	var fin ProjectFinance
 	var err error
 	if asset, err := r.readString(); err == nil {
@@ -889,6 +1208,85 @@ func DecodeProjectFinance(data []byte) (*ProjectFinance, error) {
 // Example payload: DecodeProposal(EncodeProposal(&Proposal{ID:11, ProjectID:2}))
 func DecodeProposal(data []byte) (*Proposal, error) {
 	r := newReader(data)
+	prpsl, err := decodeProposalV1(r)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.readICCExpectations(prpsl.Outcome); err != nil {
+		return nil, err
+	}
+	if err = r.readICCReceipts(prpsl.Outcome); err != nil {
+		return nil, err
+	}
+	notBefore, err := r.readVarUint()
+	if err != nil {
+		return nil, err
+	}
+	notAfter, err := r.readVarUint()
+	if err != nil {
+		return nil, err
+	}
+	prpsl.NotBefore, prpsl.NotAfter = int64(notBefore), int64(notAfter)
+	if prpsl.Condition, err = r.readStateCondition(); err != nil {
+		return nil, err
+	}
+	n, err := r.readVarUint()
+	if err != nil {
+		return nil, err
+	}
+	if n > MaxPrerequisites {
+		return nil, errors.New("too many prerequisites")
+	}
+	for i := uint64(0); i < n; i++ {
+		id, err := r.readVarUint()
+		if err != nil {
+			return nil, err
+		}
+		prpsl.Prerequisites = append(prpsl.Prerequisites, id)
+	}
+	if n, err = r.readVarUint(); err != nil {
+		return nil, err
+	}
+	if n == 1 {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		vs := VotingSystem(b)
+		prpsl.VotingSystem = &vs
+	}
+	return prpsl, nil
+}
+
+// decodeProposalV1 reads the proposal layout from before ICC expectations and
+// receipts, execution windows, state conditions, prerequisites and per-proposal
+// voting systems.
+func decodeProposalV1(r *binReader) (*Proposal, error) {
+	prpsl, err := decodeProposalV0(r)
+	if err != nil {
+		return nil, err
+	}
+	if prpsl.QuorumReached, err = r.readBool(); err != nil {
+		return nil, err
+	}
+	if prpsl.VotedStake, err = r.readAmount(); err != nil {
+		return nil, err
+	}
+	if prpsl.VotedWeight, err = r.readAmount(); err != nil {
+		return nil, err
+	}
+	if prpsl.StakeWeights, err = r.readAssetWeightMap(); err != nil {
+		return nil, err
+	}
+	if err = r.readPayoutModes(prpsl.Outcome); err != nil {
+		return nil, err
+	}
+	return prpsl, nil
+}
+
+// decodeProposalV0 reads the proposal layout from before reputation and
+// multi-asset staking.
+func decodeProposalV0(r *binReader) (*Proposal, error) {
 	prpsl := &Proposal{}
 	var err error
 	if prpsl.ID, err = r.readUint64(); err != nil {
@@ -1052,6 +1450,11 @@ func DecodeCreateProposalArgs(data []byte) (*CreateProposalArgs, error) {
 			return nil, err
 		}
 	}
+	if r.pos < len(r.data) {
+		if err = r.readPayoutModes(args.ProposalOutcome); err != nil {
+			return nil, err
+		}
+	}
 	return args, nil
 }
 
@@ -1,104 +1,106 @@
 package main
 
-import "okinoko_dao/sdk"
+import "okinoko_dao/limits"
 
 // -----------------------------------------------------------------------------
 // Supported Assets
 // -----------------------------------------------------------------------------
 
 // validAssets lists the supported asset types for treasury and transfers.
-var validAssets = []string{
-	sdk.AssetHbd.String(),
-	sdk.AssetHive.String(),
-	sdk.AssetHbdSavings.String(),
-}
-
-// validAddressPrefixes lists the address namespaces this contract accepts. VSC
-// account addresses are "hive:<username>"; contracts and keys use a "did:" URI.
-// validateAddress requires one of these plus a non-empty body, so a malformed
+var validAssets = limits.Assets
+
+// validAddressPrefixes lists the address namespaces this contract accepts (see
+// limits.AddressPrefixes). validateAddress requires one of these plus a non-empty body, so a malformed
 // beneficiary cannot silently receive an irreversible treasury payout.
-var validAddressPrefixes = []string{"hive:", "did:"}
+var validAddressPrefixes = limits.AddressPrefixes
+
+// -----------------------------------------------------------------------------
+// Amount Scaling and Validation Limits
+// -----------------------------------------------------------------------------
+
+// The limits live in okinoko_dao/limits so clients validate against the same
+// numbers before submitting.
+const (
+	AmountScale = limits.AmountScale
+
+	MaxNameLength            = limits.MaxNameLength
+	MaxDescriptionLength     = limits.MaxDescriptionLength
+	MaxOptionTextLength      = limits.MaxOptionTextLength
+	MaxURLLength             = limits.MaxURLLength
+	MaxProposalOptions       = limits.MaxProposalOptions
+	MaxPayoutReceivers       = limits.MaxPayoutReceivers
+	MaxWhitelistAddresses    = limits.MaxWhitelistAddresses
+	MaxAddressLength         = limits.MaxAddressLength
+	MaxTokenIdLength         = limits.MaxTokenIdLength
+	MaxKickAddresses         = limits.MaxKickAddresses
+	MaxMetaLength            = limits.MaxMetaLength
+	MaxICCCalls              = limits.MaxICCCalls
+	MaxExportProposals       = limits.MaxExportProposals
+	MaxICCAllowlistEntries   = limits.MaxICCAllowlistEntries
+	MaxICCExpectLength       = limits.MaxICCExpectLength
+	MaxICCResponseLog        = limits.MaxICCResponseLog
+	MaxPrerequisites         = limits.MaxPrerequisites
+	MinProposalDurationHours = limits.MinProposalDurationHours
+	MaxDurationHours         = limits.MaxDurationHours
+	MaxProposalDurationHours = limits.MaxProposalDurationHours
+	MinThresholdPercent      = limits.MinThresholdPercent
+	MaxThresholdPercent      = limits.MaxThresholdPercent
+	MinQuorumPercent         = limits.MinQuorumPercent
+	MaxQuorumPercent         = limits.MaxQuorumPercent
+	MaxStakeWeight           = limits.MaxStakeWeight
+)
+
+// -----------------------------------------------------------------------------
+// Reputation
+// -----------------------------------------------------------------------------
+
+const (
+	// ReputationVoteReward is credited once per ballot cast on a proposal that
+	// reached quorum, claimed through reputation_claim after the tally.
+	ReputationVoteReward = 10
+	// ReputationExecuteReward is credited to the member who executes a passed
+	// proposal. Execution is a public good (anyone may trigger it) that otherwise
+	// only costs the caller gas.
+	ReputationExecuteReward = 5
+	// ReputationHalfLifeHours halves a member's reputation for every full period
+	// without new participation, so standing reflects recent activity.
+	ReputationHalfLifeHours = 720
+	// ReputationBlendCap is the reputation at which a VotingSystemStakeReputation
+	// ballot carries its full stake. A member with no reputation votes with half
+	// their stake; reputation above the cap adds nothing further.
+	ReputationBlendCap = 1000
+)
 
 // -----------------------------------------------------------------------------
-// Amount Scaling
+// Dividends
 // -----------------------------------------------------------------------------
 
-// AmountScale defines the precision multiplier for converting floats to int64.
-const AmountScale = 1000
+// DividendPrecision scales the dividend-per-stake accumulator so that small
+// distributions over a large StakeTotal do not floor to zero.
+const DividendPrecision = 1_000_000_000_000
 
 // -----------------------------------------------------------------------------
-// Validation Limits
+// HBD Savings
 // -----------------------------------------------------------------------------
 
 const (
-	// MaxNameLength limits the size of project and proposal names.
-	MaxNameLength = 128
-	// MaxDescriptionLength limits the size of project and proposal descriptions.
-	MaxDescriptionLength = 512
-	// MaxOptionTextLength limits the size of proposal option text.
-	MaxOptionTextLength = 500
-	// MaxURLLength limits the size of URLs (for projects, proposals, and options).
-	MaxURLLength = 500
-	// MaxProposalOptions limits the number of options per proposal. Each option is
-	// a separate state write plus an entry in the creation event; the wasm heap
-	// exhausts (nil-deref trap) around ~46 options, so this is capped well below
-	// that so the advertised maximum is always reachable, not just parseable.
-	MaxProposalOptions = 40
-	// MaxPayoutReceivers limits the number of payout entries per proposal.
-	MaxPayoutReceivers = 50
-	// MaxWhitelistAddresses limits the number of addresses per whitelist operation.
-	MaxWhitelistAddresses = 50
-	// MaxAddressLength bounds any user-supplied address string. Hive addresses
-	// ("hive:<username>") are short; this cap keeps forged keys/records from
-	// bloating state.
-	MaxAddressLength = 128
-	// MaxTokenIdLength bounds a membership NFT token id. magi_nft token ids are
-	// arbitrary strings (e.g. "alicante-991"), so the id must stay short and safe
-	// to embed in the pipe-delimited create payload and the JSON membership call.
-	MaxTokenIdLength = 128
-	// MaxKickAddresses limits the number of addresses per kick_member operation.
-	MaxKickAddresses = 50
-	// MaxMetaLength bounds the outcome-meta blob. It must accommodate the largest
-	// LEGITIMATE directive, which is whitelist_add/kick_member carrying
-	// MaxWhitelistAddresses (50) x MaxAddressLength (128) plus separators, so it is
-	// necessarily much larger than MaxDescriptionLength.
-	MaxMetaLength = 8192
-	// MaxICCCalls limits inter-contract calls per proposal. Each one is an external
-	// call plus a treasury debit executed inside a single ExecuteProposal.
-	MaxICCCalls = 20
-	// MinProposalDurationHours enforces a minimum voting period.
-	MinProposalDurationHours = 1
-	// MaxDurationHours caps execution delay and leave cooldown.
-	// Any value * 3600 must stay well within int64, so this also prevents the
-	// deadline/execution-time integer overflow. 10 years is far beyond any real use.
-	MaxDurationHours = 87600
-	// MaxProposalDurationHours caps a single proposal's VOTING period at 90 days.
-	//
-	// This is tighter than MaxDurationHours for a security reason, not a UX one.
-	// Creating a proposal takes a payout lock on every named beneficiary
-	// (CreateProposal -> incrementPayoutLocks), which blocks their project_leave and
-	// their removal via kick_member until the proposal is tallied — and tallying is
-	// impossible before the deadline the CREATOR chose. Since anyone may name anyone
-	// as a beneficiary without consent, the voting period is the exact length of time
-	// one member can freeze another member's stake. At MaxDurationHours that was ten
-	// years; this bounds it to 90 days.
-	//
-	// NOTE: this bounds the griefing window, it does not close it. A hostile member
-	// can still freeze a victim for up to 90 days, and stacking proposals defeats a
-	// per-proposal cancel. Full mitigation requires either taking the lock only once
-	// a proposal PASSES, or letting a named beneficiary decline their own payout —
-	// both change deliberate, test-encoded behaviour and need a product decision.
-	MaxProposalDurationHours = 2160
-	// MinThresholdPercent is the minimum allowed threshold percentage.
-	MinThresholdPercent = 1.0
-	// MaxThresholdPercent is the maximum allowed threshold percentage.
-	MaxThresholdPercent = 100.0
-	// MinQuorumPercent is the minimum allowed quorum percentage.
-	MinQuorumPercent = 1.0
-	// MaxQuorumPercent is the maximum allowed quorum percentage.
-	MaxQuorumPercent = 100.0
+	// HbdUnstakePeriodHours is how long the ledger holds HBD leaving savings
+	// before it is spendable again (the Hive savings withdrawal period).
+	HbdUnstakePeriodHours = 72
+	// MaxPendingHbdUnstakes bounds the open withdrawals per treasury so the
+	// pending list stays one small state value.
+	MaxPendingHbdUnstakes = 10
 )
 
+// -----------------------------------------------------------------------------
+// Export
+// -----------------------------------------------------------------------------
+
+// ExportCoolingOffHours is the least time between an export passing and its
+// execution, so members who voted against it can leave first. A longer leave
+// cooldown extends it.
+const ExportCoolingOffHours = 72
+
 // -----------------------------------------------------------------------------
 // Default/Fallback Values
 // -----------------------------------------------------------------------------
@@ -125,6 +127,19 @@ const (
 	ContractConfigKey = "contract:cfg"
 )
 
+const (
+	// ICCReceiptLockKey is set while an inter-contract call whose receipts are
+	// being measured runs. The contract refuses re-entry meanwhile, so nothing
+	// but the callee moves its balance. See measureICCReceipts.
+	ICCReceiptLockKey = "lock:icc"
+)
+
+const (
+	// HbdSavingsPoolKey books the contract's HBD savings across all project
+	// treasuries and the interest they earned. See recognizeHbdInterest.
+	HbdSavingsPoolKey = "pool:hbds"
+)
+
 // -----------------------------------------------------------------------------
 // Counter Keys
 // -----------------------------------------------------------------------------
@@ -159,6 +174,27 @@ const (
 	kProjectWhitelist byte = 0x06
 	// kProjectTreasury stores per-asset balances in multi-asset treasury.
 	kProjectTreasury byte = 0x07
+	// kProjectRoster lists a project's members densely so the membership can be
+	// walked (export) in MemberCount steps.
+	kProjectRoster byte = 0x08
+	// kProjectDividend stores the cumulative dividend-per-stake accumulator per asset.
+	kProjectDividend byte = 0x09
+	// kMemberDividend stores a member's dividend books per asset: {debt}_{pending}
+	kMemberDividend byte = 0x0a
+	// kProjectHbdUnstake stores the treasury's pending HBD savings withdrawals.
+	kProjectHbdUnstake byte = 0x0b
+	// kProjectICCAllowlist flags contract:function pairs a project's proposals
+	// may call without the elevated ICC threshold.
+	kProjectICCAllowlist byte = 0x0c
+	// kProjectDividendDust stores the dividend remainder per asset that no
+	// member is owed yet, below one unit.
+	kProjectDividendDust byte = 0x0d
+	// kProjectHbdInterest stores a treasury's interest debt against the HBD
+	// savings pool, like a member's dividend debt.
+	kProjectHbdInterest byte = 0x0e
+	// kProjectListSlot indexes the entries of the whitelist and the ICC
+	// allowlist by insertion order so they can be walked (export).
+	kProjectListSlot byte = 0x0f
 	// kProposalMeta contains encoded Proposal records.
 	kProposalMeta byte = 0x10
 	// kProposalOption stores ProposalOption entries indexed by proposal+option index.
@@ -167,6 +203,14 @@ const (
 	kVoteReceipt byte = 0x20
 	// kMemberStakeHistory stores historical stake snapshots: {stake}_{timestamp}
 	kMemberStakeHistory byte = 0x22
+	// kMemberRosterPos stores a member's slot in the project roster.
+	kMemberRosterPos byte = 0x23
+)
+
+// Lists indexed under kProjectListSlot.
+const (
+	listWhitelist    byte = 'w'
+	listICCAllowlist byte = 'i'
 )
 
 // -----------------------------------------------------------------------------
@@ -176,6 +220,9 @@ const (
 const (
 	VotingSystemDemocratic VotingSystem = 0
 	VotingSystemStake      VotingSystem = 1
+	// VotingSystemStakeReputation weighs each ballot by stake scaled with the
+	// voter's reputation. See reputationWeight.
+	VotingSystemStakeReputation VotingSystem = 2
 )
 
 // -----------------------------------------------------------------------------
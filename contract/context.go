@@ -3,6 +3,7 @@ package main
 import (
 	"strconv"
 
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 )
 
@@ -27,10 +28,22 @@ func currentEnv() *sdk.Env {
 		cachedEnvLoaded = true
 		cachedTransfer = nil
 		cachedMembers = map[string]*Member{}
+		eventFormat = nil
 	}
 	return &cachedEnv
 }
 
+// callContract wraps sdk.ContractCall. The callee may run this code again (a
+// re-entrant call, or another deployment sharing these globals in the native
+// emulator), so the per-transaction caches are dropped on both sides of the
+// call and each frame reloads its own env and records.
+func callContract(contractID, method, payload string, opts *sdk.ContractCallOptions) *string {
+	cachedEnvLoaded = false
+	ret := sdk.ContractCall(contractID, method, payload, opts)
+	cachedEnvLoaded = false
+	return ret
+}
+
 // currentIntents is just a tiny helper to access intents already pulled above.
 func currentIntents() []sdk.Intent {
 	return currentEnv().Intents
@@ -64,14 +77,14 @@ func getFirstTransferAllow() *TransferAllow {
 		if intent.Type == "transfer.allow" {
 			token := intent.Args["token"]
 			if !isValidAsset(token) {
-				sdk.Abort("invalid intent asset")
+				abort(errcode.InvalidIntent, "invalid intent asset")
 			}
 			limitStr := intent.Args["limit"]
 			limit, err := strconv.ParseFloat(limitStr, 64)
 			// ParseFloat accepts "-5"/"NaN" with a nil error; reject any
 			// non-positive or non-finite limit before it reaches HiveDraw.
 			if err != nil || !(limit > 0) {
-				sdk.Abort("invalid intent limit")
+				abort(errcode.InvalidIntent, "invalid intent limit")
 			}
 			ta := &TransferAllow{
 				Limit: limit,
@@ -92,12 +105,12 @@ func getAllTransferAllows() []TransferAllow {
 		if intent.Type == "transfer.allow" {
 			token := intent.Args["token"]
 			if !isValidAsset(token) {
-				sdk.Abort("invalid intent asset")
+				abort(errcode.InvalidIntent, "invalid intent asset")
 			}
 			limitStr := intent.Args["limit"]
 			limit, err := strconv.ParseFloat(limitStr, 64)
 			if err != nil || !(limit > 0) {
-				sdk.Abort("invalid intent limit")
+				abort(errcode.InvalidIntent, "invalid intent limit")
 			}
 			transfers = append(transfers, TransferAllow{
 				Limit: limit,
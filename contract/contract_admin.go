@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// -----------------------------------------------------------------------------
+// Contract administration
+// -----------------------------------------------------------------------------
+//
+// The contract owner (the account that called contract_init) can change the
+// contract-level settings below. None of them reach into a project: the owner
+// gets no access to treasuries, stakes or proposals, and the creation fee is
+// paid by the project creator on top of their deposit, straight to the owner.
+//
+// Ownership moves in two steps (contract_transfer, then contract_accept by the
+// new owner) so a mistyped address cannot lose it. Renouncing is final; it
+// clears the creation fee, which would have no recipient, and requires public
+// project creation, since nobody could create projects otherwise.
+
+// requireContractOwner aborts unless the caller owns the contract and returns
+// the contract config.
+func requireContractOwner() *ContractConfig {
+	requireInitialized()
+	cfg := loadContractConfig()
+	if cfg.Owner == "" {
+		abort(errcode.Unauthorized, "contract ownership was renounced")
+	}
+	if getActorAddress() != cfg.Owner {
+		abort(errcode.Unauthorized, "only contract owner can change contract settings")
+	}
+	return cfg
+}
+
+// creationModeName returns the contract_init mode name of a creation setting.
+func creationModeName(public bool) string {
+	if public {
+		return "public"
+	}
+	return "owner-only"
+}
+
+// formatCreationFee renders the creation fee for events, empty when none.
+func formatCreationFee(fee Amount, asset sdk.Asset) string {
+	if fee <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%f %s", AmountToFloat(fee), asset.String())
+}
+
+// SetCreationMode switches project creation between public and owner-only.
+// Example payload: SetCreationMode(strptr("owner-only"))
+//
+//go:wasmexport contract_mode
+func SetCreationMode(payload *string) *string {
+	cfg := requireContractOwner()
+	mode := decodePayloadFields(payload, "permission mode required (public or owner-only)", "mode").get(0)
+	if mode != "public" && mode != "owner-only" {
+		abort(errcode.InvalidValue, "permission mode must be exactly \"public\" or \"owner-only\"")
+	}
+	public := mode == "public"
+	if public != cfg.ProjectCreationPublic {
+		cfg.ProjectCreationPublic = public
+		saveContractConfig(cfg)
+		emitContractConfigEvent(cfg.Owner.String(), "mode", creationModeName(!public), mode)
+	}
+	return strptr("project creation is " + mode)
+}
+
+// TransferContractOwnership offers the contract to a new owner, who takes it
+// with contract_accept. Naming the current owner withdraws a pending offer.
+// Example payload: TransferContractOwnership(strptr("hive:alice"))
+//
+//go:wasmexport contract_transfer
+func TransferContractOwnership(payload *string) *string {
+	cfg := requireContractOwner()
+	f := decodePayloadFields(payload, "new owner address required", "newOwner")
+	newOwner := AddressFromString(strings.TrimSpace(f.get(0)))
+	validateAddress(newOwner)
+	if newOwner == cfg.Owner {
+		newOwner = ""
+	}
+	old := cfg.PendingOwner
+	if newOwner != old {
+		cfg.PendingOwner = newOwner
+		saveContractConfig(cfg)
+		emitContractConfigEvent(cfg.Owner.String(), "pendingOwner", old.String(), newOwner.String())
+	}
+	if newOwner == "" {
+		return strptr("transfer withdrawn")
+	}
+	return strptr("transfer pending")
+}
+
+// AcceptContractOwnership completes a transfer started by contract_transfer.
+// Only the offered account can call it; the payload is ignored.
+//
+//go:wasmexport contract_accept
+func AcceptContractOwnership(payload *string) *string {
+	requireInitialized()
+	cfg := loadContractConfig()
+	caller := getActorAddress()
+	if cfg.PendingOwner == "" || caller != cfg.PendingOwner {
+		abort(errcode.Unauthorized, "no contract ownership transfer pending for caller")
+	}
+	old := cfg.Owner
+	cfg.Owner = caller
+	cfg.PendingOwner = ""
+	saveContractConfig(cfg)
+	emitContractConfigEvent(caller.String(), "owner", old.String(), caller.String())
+	return strptr("ownership accepted")
+}
+
+// RenounceContractOwnership leaves the contract without an owner for good.
+// The payload must be exactly "renounce".
+//
+//go:wasmexport contract_renounce
+func RenounceContractOwnership(payload *string) *string {
+	cfg := requireContractOwner()
+	f := decodePayloadFields(payload, "payload \"renounce\" required", "confirm")
+	if f.get(0) != "renounce" {
+		abort(errcode.InvalidValue, "payload must be exactly \"renounce\"")
+	}
+	if !cfg.ProjectCreationPublic {
+		abort(errcode.InvalidValue, "make project creation public before renouncing, nobody could create projects otherwise")
+	}
+	by := cfg.Owner.String()
+	if cfg.CreationFee > 0 {
+		emitContractConfigEvent(by, "fee", formatCreationFee(cfg.CreationFee, cfg.CreationFeeAsset), "")
+		cfg.CreationFee, cfg.CreationFeeAsset = 0, ""
+	}
+	if cfg.PendingOwner != "" {
+		emitContractConfigEvent(by, "pendingOwner", cfg.PendingOwner.String(), "")
+		cfg.PendingOwner = ""
+	}
+	cfg.Owner = ""
+	saveContractConfig(cfg)
+	emitContractConfigEvent(by, "owner", by, "")
+	return strptr("ownership renounced")
+}
+
+// SetCreationFee sets the fee project_create charges, paid to the contract
+// owner. An amount of 0 removes it.
+// Example payload: SetCreationFee(strptr("10.000|hive"))
+//
+//go:wasmexport contract_fee
+func SetCreationFee(payload *string) *string {
+	cfg := requireContractOwner()
+	f := decodePayloadFields(payload, "fee payload required (amount|asset)", "amount", "asset")
+	v, err := strconv.ParseFloat(strings.TrimSpace(f.get(0)), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) || v < 0 {
+		abort(errcode.InvalidValue, "invalid creation fee")
+	}
+	fee := FloatToAmount(v)
+	if v > 0 && fee <= 0 {
+		abort(errcode.InvalidValue, "creation fee is below the minimum representable amount")
+	}
+	var asset sdk.Asset
+	if fee > 0 {
+		// The fee goes to the owner's ledger balance; hbd_savings only exists
+		// as a treasury position and cannot be sent with an intent.
+		token := strings.ToLower(strings.TrimSpace(f.get(1)))
+		if token != sdk.AssetHive.String() && token != sdk.AssetHbd.String() {
+			abort(errcode.InvalidValue, fmt.Sprintf("creation fee can only be paid in hive or hbd, not %q", token))
+		}
+		asset = sdk.Asset(token)
+	}
+	old := formatCreationFee(cfg.CreationFee, cfg.CreationFeeAsset)
+	cfg.CreationFee, cfg.CreationFeeAsset = fee, asset
+	if updated := formatCreationFee(fee, asset); updated != old {
+		saveContractConfig(cfg)
+		emitContractConfigEvent(cfg.Owner.String(), "fee", old, updated)
+	}
+	if fee == 0 {
+		return strptr("creation fee removed")
+	}
+	return strptr("creation fee set")
+}
+
+// chargeCreationFee draws the creation fee from the caller's transfer.allow
+// intent in the fee asset and pays it to the contract owner. When shared, the
+// intent also carries the founding stake and project_create already checked
+// that it covers both.
+func chargeCreationFee(cfg *ContractConfig, shared bool) {
+	if !shared {
+		var ta *TransferAllow
+		for _, t := range getAllTransferAllows() {
+			if t.Token == cfg.CreationFeeAsset {
+				ta = &t
+				break
+			}
+		}
+		if ta == nil {
+			abort(errcode.NoIntent, fmt.Sprintf("project creation fee requires a %s transfer intent", cfg.CreationFeeAsset.String()))
+		}
+		if FloatToAmount(ta.Limit) < cfg.CreationFee {
+			abort(errcode.InsufficientFunds, fmt.Sprintf("project creation fee requires at least %f %s", AmountToFloat(cfg.CreationFee), cfg.CreationFeeAsset.String()))
+		}
+	}
+	amount := AmountToInt64(cfg.CreationFee)
+	sdk.HiveDraw(amount, cfg.CreationFeeAsset)
+	sdk.HiveTransfer(cfg.Owner, amount, cfg.CreationFeeAsset)
+}
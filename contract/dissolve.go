@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// -----------------------------------------------------------------------------
+// Dissolution
+// -----------------------------------------------------------------------------
+
+// dissolveProject closes prj as the outcome of a passed dissolve_project
+// proposal. The project is marked paused and dissolved, which freezes its
+// stakes and treasury; members then take their stake back plus their share of
+// the treasury one by one with project_leave (see claimDissolvedShare).
+//
+// Paying out on claim keeps the execution independent of the member count and
+// needs no walk of the member roster, which members who joined before it
+// existed are missing from.
+//
+// The caller persists prj afterwards.
+func dissolveProject(prj *Project, proposalID uint64) {
+	// Matured withdrawals were already credited when execution began; anything
+	// still pending has not reached the contract and could not be shared out,
+	// and savings could not be transferred at all.
+	requireNoPendingHbdUnstakes(prj.ID)
+	must(checkNoHbdSavings(prj, "dissolving"))
+	prj.Paused = true
+	prj.Dissolved = true
+	emitProjectDissolvedEvent(prj.ID, proposalID, int(prj.MemberCount))
+}
+
+// checkNoHbdSavings refuses to let savings into a claim or an export: HBD in
+// savings cannot be transferred, so it must be unstaked first.
+func checkNoHbdSavings(prj *Project, doing string) *failure {
+	if getTreasuryBalance(prj.ID, sdk.AssetHbdSavings) > 0 {
+		return fail(errcode.Locked, fmt.Sprintf("treasury holds hbd savings, unstake them before %s", doing))
+	}
+	return nil
+}
+
+// claimDissolvedShare pays a member of a dissolved project their stake and a
+// share of every treasury asset, then removes them. The share is the member's
+// voting stake over the stake still in the project (an equal share of the
+// members left in a free-membership project), so earlier claims do not change
+// anyone's cut; the last member takes the remaining balances, rounding dust
+// included, and leaves the treasury at exactly zero.
+func claimDissolvedShare(prj *Project, addr sdk.Address, member *Member) *string {
+	// Paid first: exiting can return dividend remainders to the treasury.
+	stake := member.Stake
+	exitDividends(prj.ID, addr, stake)
+
+	weight := memberVotingStake(prj.StakeWeights, member)
+	total := projectVotingStake(prj)
+	if total == 0 {
+		weight, total = 1, Amount(prj.MemberCount)
+	}
+	last := prj.MemberCount <= 1
+
+	for _, assetStr := range validAssets {
+		asset := AssetFromString(assetStr)
+		balance := getTreasuryBalance(prj.ID, asset)
+		if balance <= 0 {
+			continue
+		}
+		share := balance
+		if !last {
+			share = mulDivAmount(balance, weight, total)
+		}
+		if share <= 0 {
+			continue
+		}
+		sdk.HiveTransfer(addr, AmountToInt64(share), asset)
+		setTreasuryBalance(prj.ID, asset, balance-share)
+		emitFundsRemoved(prj.ID, AddressToString(addr), AmountToFloat(share), AssetToString(asset), false)
+	}
+
+	if stake > 0 {
+		sdk.HiveTransfer(addr, AmountToInt64(stake), prj.FundsAsset)
+	}
+	refundAssetStakes(prj, addr, member)
+	deleteAllStakeHistory(prj.ID, addr, member.StakeIncrement)
+	deleteMember(prj.ID, addr)
+	if prj.MemberCount > 0 {
+		prj.MemberCount--
+	}
+	if prj.StakeTotal < stake {
+		abort(errcode.Internal, "accounting error: stake total mismatch")
+	}
+	prj.StakeTotal -= stake
+	saveProjectFinance(prj)
+	emitLeaveEvent(prj.ID, AddressToString(addr))
+	emitFundsRemoved(prj.ID, AddressToString(addr), AmountToFloat(stake), AssetToString(prj.FundsAsset), true)
+	return strptr("share claimed")
+}
+
+// requireDissolveFlag validates the dissolve_project meta value. Only an explicit
+// truthy value dissolves; anything else is rejected rather than ignored.
+func requireDissolveFlag(value string) {
+	must(checkDissolveFlag(value))
+}
+
+// checkDissolveFlag is requireDissolveFlag without the abort.
+func checkDissolveFlag(value string) *failure {
+	if !parseBoolField(value) {
+		return fail(errcode.InvalidValue, fmt.Sprintf("invalid dissolve_project value %q, expected 1", value))
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// -----------------------------------------------------------------------------
+// Dividends
+// -----------------------------------------------------------------------------
+//
+// A distribute outcome moves treasury funds into a per-asset accumulator that
+// grows by amount*DividendPrecision/StakeTotal. A member's share of everything
+// distributed while they held stake is stake*acc minus their recorded debt, so
+// neither distributing nor claiming ever walks the member list. The books must
+// be settled before every stake change, otherwise the new stake would earn on
+// past distributions.
+//
+// Dividends follow the stake in the project's funds asset (Member.Stake), not
+// the weighted voting stake. Additional stake assets carry votes only: their
+// weights change by governance, and re-basing every member's books on such a
+// change would mean walking the member list.
+//
+// Flooring leaves remainders nobody is owed: what the per-stake step cannot
+// express at distribution, and the sub-unit share a member leaves behind on
+// exit. They collect per asset and every whole unit goes back to the treasury.
+
+// ClaimDividends pays out the caller's accrued dividends in every asset, or in a
+// single asset when one is given.
+// Payload: "projectId" or "projectId|asset"
+// Example payload: ClaimDividends(strptr("5|hive"))
+//
+//go:wasmexport dividend_claim
+func ClaimDividends(payload *string) *string {
+	requireInitialized()
+	f := decodePayloadFields(payload, "project ID is required", "projectId", "asset")
+	projectID := parseEntityIDField(f.get(0), "project id")
+	assets := validAssets
+	if strings.TrimSpace(f.get(1)) != "" {
+		assetStr := strings.ToLower(strings.TrimSpace(f.get(1)))
+		if !isValidAsset(assetStr) {
+			abort(errcode.InvalidValue, fmt.Sprintf("asset %s is not supported", assetStr))
+		}
+		assets = []string{assetStr}
+	}
+	prj := loadProject(projectID)
+	caller := getActorAddress()
+	member := getMember(prj.ID, caller)
+
+	settleDividends(prj.ID, caller, member.Stake, member.Stake)
+	if !payDividends(prj.ID, caller, assets, false) {
+		abort(errcode.InsufficientFunds, "no dividends to claim")
+	}
+	return strptr("dividends claimed")
+}
+
+// distributeDividend applies a passed distribute=<amount>:<asset> outcome: the
+// amount leaves the treasury and is credited to stakers pro-rata by stake.
+func distributeDividend(prj *Project, proposalID uint64, value string) {
+	amount, asset, f := checkDistribute(prj, value)
+	must(f)
+	if !removeTreasuryFunds(prj.ID, asset, amount) {
+		abort(errcode.InsufficientFunds, fmt.Sprintf("insufficient %s funds in treasury", AssetToString(asset)))
+	}
+
+	product := new(big.Int).Mul(big.NewInt(int64(amount)), big.NewInt(DividendPrecision))
+	stake := big.NewInt(int64(prj.StakeTotal))
+	delta, rem := new(big.Int).QuoRem(product, stake, new(big.Int))
+	acc := loadDividendAcc(prj.ID, asset)
+	acc.Add(acc, delta)
+	saveDividendAcc(prj.ID, asset, acc)
+	emitDividendDistributedEvent(prj.ID, proposalID, AmountToFloat(amount), AssetToString(asset))
+	// delta*StakeTotal is what the stakers are owed; the rest was not handed out.
+	returnDividendDust(prj.ID, asset, rem)
+}
+
+// returnDividendDust adds a remainder no member is owed (scaled by
+// DividendPrecision) to the project's dust of asset and moves every whole unit
+// of it back into the treasury.
+func returnDividendDust(projectID uint64, asset sdk.Asset, units *big.Int) {
+	if units.Sign() <= 0 {
+		return
+	}
+	dust := loadDividendDust(projectID, asset)
+	dust.Add(dust, units)
+	whole, rem := new(big.Int).QuoRem(dust, big.NewInt(DividendPrecision), new(big.Int))
+	saveDividendDust(projectID, asset, rem)
+	if whole.Sign() <= 0 {
+		return
+	}
+	if !whole.IsInt64() {
+		abort(errcode.Internal, "amount overflow")
+	}
+	addTreasuryFunds(projectID, asset, Amount(whole.Int64()))
+	emitDividendReturnedEvent(projectID, AmountToFloat(Amount(whole.Int64())), AssetToString(asset))
+}
+
+// checkDistribute parses a distribute value and checks that the project has
+// stakers to credit. The treasury balance is left to the caller.
+func checkDistribute(prj *Project, value string) (Amount, sdk.Asset, *failure) {
+	amount, asset, f := checkDistributeValue(value)
+	if f != nil {
+		return 0, "", f
+	}
+	if prj.StakeTotal <= 0 {
+		return 0, "", fail(errcode.InvalidValue, "cannot distribute without staked members")
+	}
+	return amount, asset, nil
+}
+
+// checkDistributeValue parses a distribute=<amount>:<asset> value.
+func checkDistributeValue(value string) (Amount, sdk.Asset, *failure) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 2 {
+		return 0, "", fail(errcode.InvalidPayload, "distribute requires amount:asset")
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil {
+		return 0, "", fail(errcode.InvalidPayload, "invalid distribute amount")
+	}
+	amount, f := checkAmount(v)
+	if f != nil {
+		return 0, "", f
+	}
+	if amount <= 0 {
+		return 0, "", fail(errcode.InvalidValue, "distribute amount must be positive")
+	}
+	assetStr := strings.ToLower(strings.TrimSpace(parts[1]))
+	if !isValidAsset(assetStr) {
+		return 0, "", fail(errcode.InvalidValue, fmt.Sprintf("distribute asset %s is not supported", assetStr))
+	}
+	if assetStr == sdk.AssetHbdSavings.String() {
+		return 0, "", fail(errcode.WrongAsset, "hbd_savings cannot be distributed, unstake it first")
+	}
+	return amount, AssetFromString(assetStr), nil
+}
+
+// settleDividends moves everything a member accrued at oldStake into their
+// pending balance and re-bases their debt on newStake. Call it before any change
+// to Member.Stake (with both values equal for a plain claim).
+func settleDividends(projectID uint64, addr sdk.Address, oldStake, newStake Amount) {
+	precision := big.NewInt(DividendPrecision)
+	for _, assetStr := range validAssets {
+		asset := AssetFromString(assetStr)
+		acc := loadDividendAcc(projectID, asset)
+		if acc.Sign() == 0 {
+			continue
+		}
+		debt, pending := loadDividendBooks(projectID, asset, addr)
+		accrued := new(big.Int).Mul(big.NewInt(int64(oldStake)), acc)
+		accrued.Sub(accrued, debt)
+		// Carry the sub-unit remainder in the debt so repeated settlements do not
+		// each floor away a fraction.
+		whole, rem := new(big.Int).QuoRem(accrued, precision, new(big.Int))
+		if whole.Sign() > 0 {
+			if !whole.IsInt64() {
+				abort(errcode.Internal, "amount overflow")
+			}
+			pending = safeAddAmount(pending, Amount(whole.Int64()))
+		}
+		newDebt := new(big.Int).Mul(big.NewInt(int64(newStake)), acc)
+		newDebt.Sub(newDebt, rem)
+		saveDividendBooks(projectID, asset, addr, newDebt, pending)
+	}
+}
+
+// payDividends transfers the settled pending balance of each listed asset to
+// addr. With exiting set the member's books are removed afterwards; the caller
+// must have settled them down to zero stake first. Reports whether anything moved.
+func payDividends(projectID uint64, addr sdk.Address, assets []string, exiting bool) bool {
+	paid := false
+	for _, assetStr := range assets {
+		asset := AssetFromString(assetStr)
+		debt, pending := loadDividendBooks(projectID, asset, addr)
+		if pending > 0 {
+			sdk.HiveTransfer(addr, AmountToInt64(pending), asset)
+			emitDividendClaimedEvent(projectID, AddressToString(addr), AmountToFloat(pending), assetStr)
+			paid = true
+		}
+		if exiting {
+			// Settled down to zero stake, the debt holds only the sub-unit
+			// remainder of the member's share, negated.
+			if debt.Sign() < 0 {
+				returnDividendDust(projectID, asset, new(big.Int).Neg(debt))
+			}
+			deleteDividendBooks(projectID, asset, addr)
+		} else if pending > 0 {
+			saveDividendBooks(projectID, asset, addr, debt, 0)
+		}
+	}
+	return paid
+}
+
+// exitDividends settles and pays out a member who is about to lose their whole
+// stake (leave, kick, dissolution) so nothing is left behind on a deleted member.
+func exitDividends(projectID uint64, addr sdk.Address, stake Amount) {
+	settleDividends(projectID, addr, stake, 0)
+	payDividends(projectID, addr, validAssets, true)
+}
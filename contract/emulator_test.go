@@ -0,0 +1,1513 @@
+//go:build native
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"okinoko_dao/cli"
+	"okinoko_dao/client"
+	"okinoko_dao/emulator"
+	"okinoko_dao/errcode"
+	"okinoko_dao/indexer"
+	"okinoko_dao/sdk"
+)
+
+// Native tests drive the exported entry points through the in-memory host:
+//
+//	go test -tags native ./contract
+
+const (
+	daoID    = "dao"
+	daoOwner = "hive:tibfox"
+)
+
+// newEmulator registers an initialized DAO and funds the usual test accounts.
+func newEmulator(t *testing.T) *emulator.Emulator {
+	t.Helper()
+	emu := emulator.New(time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC))
+	emu.Register(daoID, daoOwner, daoMethods)
+	for _, acct := range []string{daoOwner, "hive:someone", "hive:someoneelse"} {
+		emu.Deposit(acct, "hive", 200_000)
+		emu.Deposit(acct, "hbd", 200_000)
+	}
+	call(t, emu, daoOwner, "contract_init", "public", nil)
+	return emu
+}
+
+// call runs action with a quoted payload and fails the test unless it succeeds.
+func call(t *testing.T, emu *emulator.Emulator, caller, action, payload string, intents []sdk.Intent) emulator.Result {
+	t.Helper()
+	res := tryCall(emu, caller, action, payload, intents)
+	if !res.Success {
+		t.Fatalf("%s by %s failed: %s", action, caller, res.Err)
+	}
+	return res
+}
+
+func tryCall(emu *emulator.Emulator, caller, action, payload string, intents []sdk.Intent) emulator.Result {
+	return emu.Call(emulator.Call{
+		Caller:     caller,
+		ContractID: daoID,
+		Action:     action,
+		Payload:    strconv.Quote(payload),
+		Intents:    intents,
+	})
+}
+
+func allow(limit string) []sdk.Intent {
+	return []sdk.Intent{emulator.TransferAllow(limit, "hive")}
+}
+
+func createdID(t *testing.T, res emulator.Result) uint64 {
+	t.Helper()
+	id, err := strconv.ParseUint(strings.TrimPrefix(res.Ret, "msg:"), 10, 64)
+	if err != nil {
+		t.Fatalf("no id in %q", res.Ret)
+	}
+	return id
+}
+
+// newProject creates a democratic project owned by hive:someone that
+// hive:someoneelse has joined, with 5.000 hive in its treasury.
+func newProject(t *testing.T, emu *emulator.Emulator) uint64 {
+	t.Helper()
+	fields := "dao|desc|0|50.001|50.001|1|0|10|1|1|||||1|||"
+	pid := createdID(t, call(t, emu, "hive:someone", "project_create", fields, allow("1.000")))
+	call(t, emu, "hive:someoneelse", "project_join", fmt.Sprint(pid), allow("1.000"))
+	call(t, emu, "hive:someone", "project_funds", fmt.Sprintf("%d|false", pid), allow("5.000"))
+	return pid
+}
+
+// passAndExecute votes yes with both members, waits out the vote and executes.
+func passAndExecute(t *testing.T, emu *emulator.Emulator, propID uint64) emulator.Result {
+	t.Helper()
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+	}
+	emu.Advance(2 * time.Hour)
+	call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(propID), nil)
+	return tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(propID), nil)
+}
+
+// passAndExport is passAndExecute for an export, which must also wait out its
+// cooling-off after the tally.
+func passAndExport(t *testing.T, emu *emulator.Emulator, propID uint64) emulator.Result {
+	t.Helper()
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+	}
+	emu.Advance(2 * time.Hour)
+	call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(propID), nil)
+	if res := tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(propID), nil); res.Symbol != string(errcode.TooEarly) {
+		t.Fatalf("export executed during its cooling-off: %+v", res)
+	}
+	emu.Advance(ExportCoolingOffHours * time.Hour)
+	return tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(propID), nil)
+}
+
+func TestNativePayoutLifecycle(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	if got := emu.Balance(emulator.ContractAddress(daoID), "hive"); got != 7_000 {
+		t.Fatalf("contract holds %d, want 7000", got)
+	}
+
+	prop := fmt.Sprintf("%d|grant|pay it|1||0|hive:someoneelse:2.000:hive||", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+	before := emu.Balance("hive:someoneelse", "hive")
+
+	res := passAndExecute(t, emu, propID)
+	if !res.Success {
+		t.Fatalf("execute failed: %s", res.Err)
+	}
+	if got := emu.Balance("hive:someoneelse", "hive") - before; got != 2_000 {
+		t.Fatalf("payout delivered %d, want 2000", got)
+	}
+	// Ledger payouts keep the original rf layout, without md.
+	want := fmt.Sprintf("rf|id:%d|to:hive:someoneelse|am:2.000000|as:hive|fs:false", pid)
+	found := false
+	for _, line := range res.Logs {
+		found = found || line == want
+	}
+	if !found {
+		t.Fatalf("no %q in %q", want, res.Logs)
+	}
+}
+
+// Meta values execution would reject are rejected when the proposal is created.
+func TestNativeMetaValidatedAtCreation(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	for meta, want := range map[string]errcode.Code{
+		"update_threshold=abc":              errcode.InvalidValue,
+		"update_owner=hive:nobody":          errcode.NotMember,
+		"update_url=http://example.com":     errcode.InvalidValue,
+		"update_membershipNFTPayload={nft}": errcode.InvalidValue,
+		"distribute=1":                      errcode.InvalidPayload,
+		"export_project=nowhere":            errcode.NotFound,
+	} {
+		prop := fmt.Sprintf("%d|change|x|1||0||%s||", pid, meta)
+		if res := tryCall(emu, "hive:someone", "proposal_create", prop, allow("1.000")); res.Symbol != string(want) {
+			t.Errorf("%s: got %s (%s), want %s", meta, res.Symbol, res.Err, want)
+		}
+	}
+	prop := fmt.Sprintf("%d|change|x|1||0||update_owner=hive:someoneelse;update_url=https://example.com||", pid)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))); !res.Success {
+		t.Fatalf("execute failed: %s", res.Err)
+	}
+}
+
+// Governance can switch the voting system and raise the minimum stake; open
+// proposals keep their voting system and members below the new minimum cannot
+// vote on later ones.
+func TestNativeProjectSettingsMeta(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	for _, meta := range []string{"update_stakeMin=0", "update_votingSystem=3", "update_name= "} {
+		prop := fmt.Sprintf("%d|change|x|1||0||%s||", pid, meta)
+		if res := tryCall(emu, "hive:someone", "proposal_create", prop, allow("1.000")); res.Symbol != string(errcode.InvalidValue) {
+			t.Errorf("%s: got %s (%s)", meta, res.Symbol, res.Err)
+		}
+	}
+
+	open := createdID(t, call(t, emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|open|x|48||0||||", pid), allow("1.000")))
+	prop := fmt.Sprintf("%d|change|x|1||0||update_votingSystem=1;update_stakeMin=2;update_name=renamed;update_metadata=m||", pid)
+	res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000"))))
+	if !res.Success {
+		t.Fatalf("execute failed: %s", res.Err)
+	}
+	logs := strings.Join(res.Logs, "\n")
+	for _, want := range []string{"f:votingSystem|old:0|new:1", "f:stakeMin|old:1.000000|new:2.000000", "f:name|old:dao|new:renamed", "f:metadata|old:|new:m"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("no %q in %s", want, logs)
+		}
+	}
+
+	// The open proposal still counts one vote per member...
+	vote := call(t, emu, "hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", open), nil)
+	if !strings.Contains(strings.Join(vote.Logs, "\n"), "|w:1.000000") {
+		t.Fatalf("vote logs %q", vote.Logs)
+	}
+	// ...while a new one is stake-weighted, and a 1.000 stake is below the minimum.
+	later := createdID(t, call(t, emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|later|x|1||0||||", pid), allow("1.000")))
+	if res := tryCall(emu, "hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", later), nil); res.Symbol != string(errcode.NotEligible) {
+		t.Fatalf("vote below the minimum stake: %+v", res)
+	}
+}
+
+// A unanimous vote of members without reputation passes a reputation-blended
+// project, while a lone voter, however blended, is still measured against the
+// full stake of those who stayed away.
+func TestNativeReputationUnanimousVote(t *testing.T) {
+	emu := newEmulator(t)
+	pid := createdID(t, call(t, emu, "hive:someone", "project_create", "dao|desc|2|60|1|1|0|10|1|1|||||1|||", allow("1.000")))
+	call(t, emu, "hive:someoneelse", "project_join", fmt.Sprint(pid), allow("1.000"))
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|unanimous|x|1||0||||", pid), allow("1.000")))
+	if res := passAndExecute(t, emu, propID); !res.Success {
+		t.Fatalf("unanimous vote did not pass: %s", res.Err)
+	}
+
+	loneID := createdID(t, call(t, emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|lone|x|1||0||||", pid), allow("1.000")))
+	call(t, emu, "hive:someone", "proposals_vote", fmt.Sprintf("%d|0", loneID), nil)
+	call(t, emu, "hive:someone", "proposals_vote", fmt.Sprintf("%d|1", loneID), nil)
+	emu.Advance(2 * time.Hour)
+	call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(loneID), nil)
+	state, _ := emu.State(daoID, proposalKey(loneID))
+	p, _, err := decodeProposalRecord(state)
+	if err != nil || p.State != ProposalFailed {
+		t.Fatalf("lone voter: %+v, %v", p, err)
+	}
+	if p.VotedStake != FloatToAmount(1) || p.VotedWeight <= 0 || p.VotedWeight >= p.VotedStake {
+		t.Fatalf("re-vote counted twice: stake %d, weight %d", p.VotedStake, p.VotedWeight)
+	}
+}
+
+// A passed proposal waits for its not_before, and one executed after its
+// not_after fails and releases its payout lock instead.
+func TestNativeExecutionWindow(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	at := func(d time.Duration) int64 { return emu.Now().Add(d).Unix() }
+
+	never := fmt.Sprintf("%d|grant|x|1||0|hive:someoneelse:2.000:hive|not_after=%d||", pid, at(30*time.Minute))
+	if res := tryCall(emu, "hive:someone", "proposal_create", never, allow("1.000")); res.Symbol != string(errcode.InvalidValue) {
+		t.Fatalf("unreachable deadline accepted: %+v", res)
+	}
+
+	due := fmt.Sprintf("%d|grant|x|1||0|hive:someoneelse:2.000:hive|not_before=%d;not_after=%d||", pid, at(5*time.Hour), at(8*time.Hour))
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", due, allow("1.000")))
+	late := fmt.Sprintf("%d|grant|x|1||0|hive:someoneelse:1.000:hive|not_after=%d||", pid, at(3*time.Hour))
+	lateID := createdID(t, call(t, emu, "hive:someone", "proposal_create", late, allow("1.000")))
+	call(t, emu, "hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", lateID), nil)
+	call(t, emu, "hive:someone", "proposals_vote", fmt.Sprintf("%d|1", lateID), nil)
+
+	res := passAndExecute(t, emu, propID)
+	if res.Symbol != string(errcode.TooEarly) {
+		t.Fatalf("executed before not_before: %+v", res)
+	}
+	tally := call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(lateID), nil)
+	if want := fmt.Sprintf("|until:%d", at(time.Hour)); !strings.Contains(strings.Join(tally.Logs, "\n"), want) {
+		t.Fatalf("no deadline in %q", tally.Logs)
+	}
+
+	emu.Advance(3 * time.Hour)
+	if res := call(t, emu, "hive:someone", "proposal_execute", fmt.Sprint(propID), nil); res.Ret != "executed" {
+		t.Fatalf("execute returned %q", res.Ret)
+	}
+	if res := call(t, emu, "hive:someone", "proposal_execute", fmt.Sprint(lateID), nil); res.Ret != "expired" {
+		t.Fatalf("late execute returned %q", res.Ret)
+	}
+	state, _ := emu.State(daoID, proposalKey(lateID))
+	if p, _, err := decodeProposalRecord(state); err != nil || p.State != ProposalFailed {
+		t.Fatalf("late proposal: %+v, %v", p, err)
+	}
+	if _, ok := emu.State(daoID, payoutLockKey(pid, "hive:someoneelse")); ok {
+		t.Fatal("payout lock kept after expiry")
+	}
+}
+
+// A conditional proposal stays passed until the other contract's state
+// satisfies its condition.
+func TestNativeStateCondition(t *testing.T) {
+	emu := newEmulator(t)
+	emu.Register("oracle", daoOwner, map[string]emulator.Method{
+		"set": func(payload *string) *string {
+			sdk.StateSetObject("price", *payload)
+			return nil
+		},
+	})
+	setPrice := func(v string) {
+		t.Helper()
+		if res := emu.Call(emulator.Call{Caller: daoOwner, ContractID: "oracle", Action: "set", Payload: v}); !res.Success {
+			t.Fatalf("oracle set failed: %s", res.Err)
+		}
+	}
+	pid := newProject(t, emu)
+
+	missing := fmt.Sprintf("%d|grant|x|1||0||condition=nowhere,price,ge,1.5||", pid)
+	if res := tryCall(emu, "hive:someone", "proposal_create", missing, allow("1.000")); res.Symbol != string(errcode.NotFound) {
+		t.Fatalf("condition on a missing contract accepted: %+v", res)
+	}
+
+	prop := fmt.Sprintf("%d|grant|x|1||0|hive:someoneelse:2.000:hive|condition=oracle,price,ge,1.5||", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+	if res := passAndExecute(t, emu, propID); res.Symbol != string(errcode.ConditionUnmet) {
+		t.Fatalf("executed with the price unset: %+v", res)
+	}
+	setPrice("1.2")
+	res := tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(propID), nil)
+	if res.Symbol != string(errcode.ConditionUnmet) || !strings.Contains(res.Err, `currently "1.2"`) {
+		t.Fatalf("executed below the condition: %+v", res)
+	}
+	setPrice("1.5")
+	call(t, emu, "hive:someone", "proposal_execute", fmt.Sprint(propID), nil)
+}
+
+// A proposal with prerequisites waits for them to execute and fails once one
+// of them fails.
+func TestNativePrerequisites(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	create := func(meta string) uint64 {
+		t.Helper()
+		prop := fmt.Sprintf("%d|step|x|1||0|hive:someoneelse:1.000:hive|%s||", pid, meta)
+		return createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+	}
+	vote := func(choice int, ids ...uint64) {
+		t.Helper()
+		for _, id := range ids {
+			for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+				call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|%d", id, choice), nil)
+			}
+		}
+	}
+	execute := func(id uint64) emulator.Result {
+		return tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(id), nil)
+	}
+
+	if res := tryCall(emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|p|x|1||0||requires=99||", pid), allow("1.000")); res.Symbol != string(errcode.NotFound) {
+		t.Fatalf("missing prerequisite accepted: %+v", res)
+	}
+
+	first := create("")
+	second := create(fmt.Sprintf("requires=%d", first))
+	doomed := create("")
+	dependent := create(fmt.Sprintf("requires=%d,%d", first, doomed))
+	vote(1, first, second, dependent)
+	vote(0, doomed)
+	emu.Advance(2 * time.Hour)
+	for _, id := range []uint64{first, second, doomed, dependent} {
+		call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(id), nil)
+	}
+
+	if res := execute(second); res.Symbol != string(errcode.TooEarly) {
+		t.Fatalf("executed before its prerequisite: %+v", res)
+	}
+	if res := execute(first); !res.Success {
+		t.Fatalf("prerequisite failed to execute: %s", res.Err)
+	}
+	if res := execute(second); !res.Success {
+		t.Fatalf("execute after prerequisite failed: %s", res.Err)
+	}
+	res := execute(dependent)
+	if res.Ret != "prerequisite failed" || !strings.Contains(strings.Join(res.Logs, "\n"), fmt.Sprintf("prerequisite %d failed", doomed)) {
+		t.Fatalf("dependent of a failed proposal: %+v", res)
+	}
+	state, _ := emu.State(daoID, proposalKey(dependent))
+	if p, _, err := decodeProposalRecord(state); err != nil || p.State != ProposalFailed {
+		t.Fatalf("dependent: %+v, %v", p, err)
+	}
+}
+
+// proposal_simulate reports every step that would fail, for a proposal still
+// to be created and for a stored one, and execution stops at the first of them.
+func TestNativeSimulate(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	type step struct {
+		Step, Action, Code string
+		OK                 bool
+	}
+	var report struct {
+		ProposalID *uint64 `json:"proposalId"`
+		Executable bool
+		Checks     []step
+		Payouts    []step
+		Meta       []step
+		ICC        []step
+	}
+	simulate := func(payload string) {
+		t.Helper()
+		report.Checks, report.Meta = nil, nil
+		res := call(t, emu, "hive:someoneelse", "proposal_simulate", payload, nil)
+		if err := json.Unmarshal([]byte(res.Ret), &report); err != nil {
+			t.Fatalf("report %q: %v", res.Ret, err)
+		}
+	}
+	codes := func(steps []step) string {
+		out := []string{}
+		for _, s := range steps {
+			out = append(out, s.Step+s.Action+":"+s.Code)
+		}
+		return strings.Join(out, ",")
+	}
+
+	// 5.000 in the treasury: the payout fits, the dividend after it does not,
+	// and the owner cannot be kicked.
+	prop := fmt.Sprintf("%d|grant|x|1||0|hive:someoneelse:3.000:hive|distribute=3:hive;kick_member=hive:someone;update_quorum=40||", pid)
+	simulate(prop)
+	if report.ProposalID != nil || report.Executable || len(report.Checks) != 0 || !report.Payouts[0].OK {
+		t.Fatalf("create payload: %+v", report)
+	}
+	if got := codes(report.Meta); got != "distribute:E_INSUFFICIENT_FUNDS,kick_member:E_INVALID_VALUE,update_quorum:" {
+		t.Fatalf("meta %s", got)
+	}
+
+	// The proposal cost reaches the treasury, so the dividend fits now.
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+	simulate(fmt.Sprintf(`{"proposalId":%d}`, propID))
+	if *report.ProposalID != propID || codes(report.Checks) != "state:E_PROPOSAL_STATE,delay:E_TOO_EARLY" {
+		t.Fatalf("active proposal: %+v", report)
+	}
+	if got := codes(report.Meta); got != "distribute:,kick_member:E_INVALID_VALUE,update_quorum:" {
+		t.Fatalf("meta %s", got)
+	}
+	if res := passAndExecute(t, emu, propID); res.Symbol != string(errcode.InvalidValue) {
+		t.Fatalf("execute: %+v", res)
+	}
+	simulate(fmt.Sprint(propID))
+	if len(report.Checks) != 0 || report.Executable {
+		t.Fatalf("passed proposal: %+v", report)
+	}
+
+	// A create payload meets the same schedule rules proposal_create applies.
+	cancelled := createdID(t, call(t, emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|doomed|x|1||0||||", pid), allow("1.000")))
+	call(t, emu, "hive:someone", "proposal_cancel", fmt.Sprint(cancelled), nil)
+	soon := emu.Now().Add(30 * time.Minute).Unix()
+	simulate(fmt.Sprintf("%d|late|x|1||0||not_after=%d;requires=%d||", pid, soon, cancelled))
+	if got := codes(report.Checks); got != "window:E_INVALID_VALUE,prerequisites:E_PROPOSAL_STATE" || report.Executable {
+		t.Fatalf("schedule checks %s", got)
+	}
+}
+
+func TestNativeICCDeliversAssets(t *testing.T) {
+	emu := newEmulator(t)
+	// A Go-registered companion that keeps whatever allowance it is given.
+	emu.Register("sink", daoOwner, map[string]emulator.Method{
+		"take": func(payload *string) *string {
+			amt, _ := strconv.ParseInt(strings.Trim(*payload, `"`), 10, 64)
+			sdk.HiveDraw(amt, sdk.AssetHive)
+			return nil
+		},
+	})
+	pid := newProject(t, emu)
+	prop := fmt.Sprintf("%d|call sink|x|1||0||||https://example.com|sink|take|1500|hive=1.500", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+
+	res := passAndExecute(t, emu, propID)
+	if !res.Success {
+		t.Fatalf("execute failed: %s", res.Err)
+	}
+	if got := emu.Balance(emulator.ContractAddress("sink"), "hive"); got != 1_500 {
+		t.Fatalf("sink received %d, want 1500", got)
+	}
+}
+
+// A call whose response misses its declared result reverts the execution;
+// a matching one logs the response.
+func TestNativeICCResultAssertion(t *testing.T) {
+	emu := newEmulator(t)
+	emu.Register("sink", daoOwner, map[string]emulator.Method{
+		"quote": func(payload *string) *string {
+			sdk.HiveDraw(1000, sdk.AssetHive)
+			return strptr(`{"out":{"amount":"1.5"}}`)
+		},
+		"repeat": func(payload *string) *string {
+			return strptr(`{"out":{"amount":"1.5","amount":"3"}}`)
+		},
+	})
+	pid := newProject(t, emu)
+	// A response the expectation cannot read fails it, whatever is wrong with it.
+	repeat := fmt.Sprintf("%d|swap|x|1||0||||https://example.com|sink|repeat|||min:out.amount:1", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", repeat, allow("1.000")))
+	if res := passAndExecute(t, emu, propID); res.Symbol != string(errcode.ICCResult) {
+		t.Fatalf("response with a repeated field: %+v", res)
+	}
+
+	short := fmt.Sprintf("%d|swap|x|1||0||||https://example.com|sink|quote||hive=1.000|min:out.amount:2", pid)
+	propID = createdID(t, call(t, emu, "hive:someone", "proposal_create", short, allow("1.000")))
+	if res := passAndExecute(t, emu, propID); res.Symbol != string(errcode.ICCResult) {
+		t.Fatalf("short result executed: %+v", res)
+	}
+	if got := emu.Balance(emulator.ContractAddress("sink"), "hive"); got != 0 {
+		t.Fatalf("sink kept %d after the revert", got)
+	}
+
+	ok := fmt.Sprintf("%d|swap|x|1||0||||https://example.com|sink|quote||hive=1.000|min:out.amount:1.5", pid)
+	propID = createdID(t, call(t, emu, "hive:someone", "proposal_create", ok, allow("1.000")))
+	res := passAndExecute(t, emu, propID)
+	if !res.Success {
+		t.Fatalf("execute failed: %s", res.Err)
+	}
+	want := `ICC executed: sink.quote returned {"out":{"amount":"1.5"}}`
+	if !strings.Contains(strings.Join(res.Logs, "\n"), want) {
+		t.Fatalf("no result event in %q", res.Logs)
+	}
+}
+
+// Declared receipts, plus any allowance the callee left, are credited to the
+// treasury; the callee cannot re-enter the contract to deposit them twice.
+func TestNativeICCReceipts(t *testing.T) {
+	emu := newEmulator(t)
+	emu.Deposit(emulator.ContractAddress("sink"), "hbd", 10_000)
+	emu.Register("sink", daoOwner, map[string]emulator.Method{
+		"swap": func(payload *string) *string {
+			sdk.HiveDraw(1000, sdk.AssetHive)
+			sdk.HiveTransfer(sdk.Address(emulator.ContractAddress(daoID)), 2000, sdk.AssetHbd)
+			return nil
+		},
+		"reenter": func(payload *string) *string {
+			return sdk.ContractCall(daoID, "project_funds", `"0|false"`, nil)
+		},
+	})
+	pid := newProject(t, emu)
+	swap := fmt.Sprintf("%d|swap|x|1||0||||https://example.com|sink|swap||hive=1.500||hbd,hive", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", swap, allow("1.000")))
+	res := passAndExecute(t, emu, propID)
+	if !res.Success {
+		t.Fatalf("execute failed: %s", res.Err)
+	}
+	// 5.000 + 1.000 proposal cost - 1.500 allowance + 0.500 left undrawn.
+	for asset, want := range map[string]Amount{"hive": 5_000, "hbd": 2_000} {
+		if got, _ := emu.State(daoID, projectTreasuryKey(pid, AssetFromString(asset))); got != encodeTreasuryRecord(want) {
+			t.Errorf("treasury %s %q, want %d", asset, got, want)
+		}
+	}
+	if !strings.Contains(strings.Join(res.Logs, "\n"), "by:contract:sink|am:2.000000|as:hbd") {
+		t.Fatalf("no funds event in %q", res.Logs)
+	}
+
+	reenter := fmt.Sprintf("%d|reenter|x|1||0||||https://example.com|sink|reenter||||hbd", pid)
+	propID = createdID(t, call(t, emu, "hive:someone", "proposal_create", reenter, allow("1.000")))
+	if res := passAndExecute(t, emu, propID); res.Symbol != string(errcode.Locked) {
+		t.Fatalf("re-entry executed: %+v", res)
+	}
+}
+
+// Calls to allowlisted targets pass on the normal threshold and run for
+// anyone; other targets need the ICC threshold and stay creator-only.
+func TestNativeICCAllowlist(t *testing.T) {
+	emu := newEmulator(t)
+	emu.Register("sink", daoOwner, map[string]emulator.Method{
+		"take": func(payload *string) *string { return nil },
+		"keep": func(payload *string) *string { return nil },
+	})
+	pid := newProject(t, emu)
+	call(t, emu, daoOwner, "project_join", fmt.Sprint(pid), allow("1.000"))
+	voters := []string{"hive:someone", "hive:someoneelse", daoOwner}
+	propose := func(meta, icc string, yes int) uint64 {
+		t.Helper()
+		prop := fmt.Sprintf("%d|p|x|1||0||%s|||%s", pid, meta, icc)
+		propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+		for _, voter := range voters[:yes] {
+			call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+		}
+		emu.Advance(2 * time.Hour)
+		call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(propID), nil)
+		return propID
+	}
+	execute := func(caller string, propID uint64) emulator.Result {
+		return tryCall(emu, caller, "proposal_execute", fmt.Sprint(propID), nil)
+	}
+
+	setup := propose("icc_allowlist_add=sink:take;update_iccThreshold=75", "", 3)
+	if res := execute("hive:someoneelse", setup); !res.Success {
+		t.Fatalf("allowlist setup failed: %s", res.Err)
+	}
+
+	// Two of three members clear 50.001% but not 75%.
+	allowed := propose("", "sink|take|1", 2)
+	if res := execute("hive:someoneelse", allowed); !res.Success {
+		t.Fatalf("allowlisted call by non-creator failed: %s", res.Err)
+	}
+	other := propose("", "sink|keep|1", 2)
+	state, _ := emu.State(daoID, proposalKey(other))
+	if p, _, err := decodeProposalRecord(state); err != nil || p.State != ProposalFailed {
+		t.Fatalf("non-allowlisted call with 2/3: %+v, %v", p, err)
+	}
+
+	other = propose("", "sink|keep|1", 3)
+	if res := execute("hive:someoneelse", other); res.Symbol != string(errcode.Unauthorized) {
+		t.Fatalf("non-creator executed a non-allowlisted call: %+v", res)
+	}
+	if res := execute("hive:someone", other); !res.Success {
+		t.Fatalf("creator execute failed: %s", res.Err)
+	}
+}
+
+// Payloads built by the client library are accepted, and every event the
+// contract logs in either format parses into its typed form.
+func TestNativeClientRoundTrip(t *testing.T) {
+	emu := emulator.New(time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC))
+	emu.Register(daoID, daoOwner, daoMethods)
+	emu.Deposit("hive:someone", "hive", 200_000)
+	submit := func(caller string, c client.Call, err error, intents []sdk.Intent) emulator.Result {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return call(t, emu, caller, c.Action, c.Payload, intents)
+	}
+	init, err := client.Init{Events: "both"}.Call()
+	submit(daoOwner, init, err, nil)
+
+	prj, err := client.CreateProjectArgs{
+		Name: "club: one; two", VotingSystem: client.Democratic, ThresholdPercent: 50.001,
+		QuorumPercent: 50.001, ProposalDuration: 1, LeaveCooldown: 1, ProposalCost: 1000, StakeMin: 1000,
+	}.Call()
+	pid := createdID(t, submit("hive:someone", prj, err, allow("1.000")))
+	pause := client.TogglePause()
+	prop, err := client.CreateProposalArgs{
+		ProjectID: pid, Name: "grant", Description: "x", Duration: 1,
+		Payouts: []client.Payout{{Address: "hive:someone", Amount: 500, Asset: "hive"}},
+		Meta:    []client.MetaAction{pause},
+	}.Call()
+	res := submit("hive:someone", prop, err, allow("1.000"))
+
+	var legacy, typed int
+	for _, line := range res.Logs {
+		ev, err := client.ParseEvent(line)
+		if err != nil {
+			t.Fatalf("%q: %v", line, err)
+		}
+		if pc, ok := ev.(*client.ProposalCreatedEvent); ok {
+			if pc.ProjectID != pid || len(pc.Payouts) != 1 || pc.Payouts[0].Amount != 500 || pc.OutcomeMeta["toggle_pause"] != "1" {
+				t.Fatalf("proposal event %+v", pc)
+			}
+		}
+		if strings.HasPrefix(line, "{") {
+			typed++
+		} else {
+			legacy++
+		}
+	}
+	if typed == 0 || typed != legacy {
+		t.Fatalf("%d legacy and %d JSON events", legacy, typed)
+	}
+}
+
+// The reference indexer, fed nothing but the logs, agrees with the contract's
+// state on every project, member, treasury and tally.
+func TestNativeIndexerMatchesState(t *testing.T) {
+	emu := newEmulator(t)
+	ix := indexer.New()
+	run := func(caller, action, payload string, intents []sdk.Intent) emulator.Result {
+		t.Helper()
+		res := call(t, emu, caller, action, payload, intents)
+		if err := ix.ApplyLines(res.Logs); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	fields := "dao|desc|0|50.001|50.001|1|0|1|1|1|||||1|||"
+	pid := createdID(t, run("hive:someone", "project_create", fields, allow("3.000")))
+	run("hive:someoneelse", "project_join", fmt.Sprint(pid), allow("1.000"))
+	run("hive:someone", "project_funds", fmt.Sprintf("%d|false", pid), allow("5.000"))
+	run("hive:someone", "project_whitelist_add", fmt.Sprintf("%d|hive:tibfox", pid), nil)
+	emu.Advance(time.Minute)
+
+	prop := fmt.Sprintf("%d|grant|pay it|1||0|hive:someoneelse:2.000:hive|update_threshold=60;update_iccThreshold=75;icc_allowlist_add=dao:proposal_tally||", pid)
+	propID := createdID(t, run("hive:someone", "proposal_create", prop, allow("1.000")))
+	run("hive:someone", "proposals_vote", fmt.Sprintf("%d|0", propID), nil)
+	run("hive:someone", "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+	run("hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+	emu.Advance(2 * time.Hour)
+	run("hive:someone", "proposal_tally", fmt.Sprint(propID), nil)
+	run("hive:someone", "proposal_execute", fmt.Sprint(propID), nil)
+
+	poll := fmt.Sprintf("%d|poll|which|1|a;b;c|0|||", pid)
+	run("hive:someoneelse", "proposal_create", poll, allow("1.000"))
+	run("hive:someoneelse", "project_leave", fmt.Sprint(pid), nil)
+	emu.Advance(2 * time.Hour)
+	run("hive:someoneelse", "project_leave", fmt.Sprint(pid), nil)
+
+	dump := map[string]string{}
+	for _, key := range emu.StateKeys(daoID) {
+		dump[key], _ = emu.State(daoID, key)
+	}
+	state, err := indexer.FromState(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range indexer.Check(ix.State, state) {
+		t.Error(m)
+	}
+	if prj := ix.State.Projects[pid]; prj == nil || len(prj.Members) != 1 || prj.Threshold != 60 {
+		t.Fatalf("indexed project %+v", prj)
+	}
+}
+
+// daoMethods registers exactly the //go:wasmexport functions, each under its
+// export name.
+func TestNativeMethodTable(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exports := map[string]string{}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc == nil {
+				continue
+			}
+			for _, c := range fn.Doc.List {
+				if export, ok := strings.CutPrefix(c.Text, "//go:wasmexport "); ok {
+					exports[strings.TrimSpace(export)] = fn.Name.Name
+				}
+			}
+		}
+	}
+	for export, fn := range exports {
+		m, ok := daoMethods[export]
+		if !ok {
+			t.Errorf("export %s (%s) missing from daoMethods", export, fn)
+			continue
+		}
+		got := runtime.FuncForPC(reflect.ValueOf(m).Pointer()).Name()
+		if got = got[strings.LastIndex(got, ".")+1:]; got != fn {
+			t.Errorf("export %s registered as %s, want %s", export, got, fn)
+		}
+	}
+	for method := range daoMethods {
+		if _, ok := exports[method]; !ok {
+			t.Errorf("daoMethods registers %s, which is not exported", method)
+		}
+	}
+}
+
+func TestNativeCLILocalChain(t *testing.T) {
+	chain := cli.NewLocalChain(time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC), daoMethods)
+	chain.Deposit("hive:alice", 10_000, "hive")
+	var out strings.Builder
+	run := func(args ...string) {
+		t.Helper()
+		out.Reset()
+		args = append([]string{"-as", "hive:alice", "-q"}, args...)
+		if code := cli.Run(args, cli.Env{Out: &out, Local: chain, ContractID: cli.LocalContractID}); code != 0 {
+			t.Fatalf("%s: exit %d\n%s", strings.Join(args, " "), code, out.String())
+		}
+	}
+	run("init")
+	run("project", "create", "-name", "club", "-deposit", "1", "-stake-min", "1")
+	run("proposal", "create", "-project", "0", "-name", "pay", "-payout", "hive:alice:0.5:hive")
+	run("proposal", "vote", "0", "1")
+	chain.Advance(100 * time.Hour)
+	run("proposal", "tally", "0")
+	if !strings.Contains(out.String(), "ok:") {
+		t.Fatalf("tally output %q", out.String())
+	}
+}
+
+// Records written before versioning carry no header; they still load and are
+// rewritten in the current version the first time a transaction reads them.
+// proposal_simulate reads them too but leaves them as they are.
+func TestNativeMigratesLegacyRecords(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	prop := fmt.Sprintf("%d|grant|pay it|1||0|hive:someoneelse:2.000:hive||", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+	methods := map[string]emulator.Method{
+		"test_put": func(payload *string) *string {
+			raw, _ := strconv.Unquote(*payload)
+			key, value, _ := strings.Cut(raw, ":")
+			k, _ := hex.DecodeString(key)
+			v, _ := hex.DecodeString(value)
+			sdk.StateSetObject(string(k), string(v))
+			return nil
+		},
+	}
+	for name, m := range daoMethods {
+		methods[name] = m
+	}
+	emu.Register(daoID, daoOwner, methods)
+
+	metaKey := projectKey(pid)
+	treasuryKey := projectTreasuryKey(pid, AssetFromString("hive"))
+	mbrKey := memberKey(pid, AddressFromString("hive:someoneelse"))
+	propKey := proposalKey(propID)
+	// A version 0 record is the current body cut where the v0 layout ends.
+	v0Len := map[string]func(*binReader) error{
+		metaKey: func(r *binReader) error { _, err := decodeProjectMetaV0(r); return err },
+		mbrKey:  func(r *binReader) error { _, err := decodeMemberV0(r); return err },
+		propKey: func(r *binReader) error { _, err := decodeProposalV0(r); return err },
+	}
+	current := map[string]string{}
+	legacy := map[string]string{treasuryKey: "5000"}
+	for key, decodeV0 := range v0Len {
+		current[key], _ = emu.State(daoID, key)
+		_, body := recordVersion(current[key])
+		r := newReader([]byte(body))
+		if err := decodeV0(r); err != nil || r.pos == len(r.data) {
+			t.Fatalf("record %x: v0 layout ends at %d of %d: %v", key, r.pos, len(r.data), err)
+		}
+		legacy[key] = body[:r.pos]
+	}
+	for key, value := range legacy {
+		call(t, emu, daoOwner, "test_put", hex.EncodeToString([]byte(key))+":"+hex.EncodeToString([]byte(value)), nil)
+	}
+
+	call(t, emu, "hive:someoneelse", "proposal_simulate", fmt.Sprint(propID), nil)
+	for key, value := range legacy {
+		if got, _ := emu.State(daoID, key); got != value {
+			t.Errorf("simulation rewrote record %x: %q", key, got)
+		}
+	}
+
+	// Adding funds reads the meta and treasury records, voting reads the
+	// member and the proposal.
+	call(t, emu, "hive:someoneelse", "project_funds", fmt.Sprintf("%d|false", pid), allow("1.000"))
+	call(t, emu, "hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+	if got, _ := emu.State(daoID, metaKey); got != current[metaKey] {
+		t.Errorf("project meta not migrated: %q", got)
+	}
+	raw, _ := emu.State(daoID, mbrKey)
+	if m, stale, err := decodeMemberRecord(raw); err != nil || stale || m.Stake != 1_000 {
+		t.Errorf("member %+v stale %v: %v", m, stale, err)
+	}
+	raw, _ = emu.State(daoID, propKey)
+	if p, stale, err := decodeProposalRecord(raw); err != nil || stale || p.Outcome == nil || len(p.Outcome.Payout) != 1 {
+		t.Errorf("proposal %+v stale %v: %v", p, stale, err)
+	}
+	if got, _ := emu.State(daoID, treasuryKey); got != encodeTreasuryRecord(6_000) {
+		t.Errorf("treasury %q, want 6.000 in the current version", got)
+	}
+}
+
+// Dissolution needs no member roster: members who joined before it existed
+// claim their stake and treasury share by leaving, like everyone else.
+func TestNativeDissolveWithoutRoster(t *testing.T) {
+	emu := newEmulator(t)
+	pid := newProject(t, emu)
+	methods := map[string]emulator.Method{
+		"test_del": func(payload *string) *string {
+			raw, _ := strconv.Unquote(*payload)
+			k, _ := hex.DecodeString(raw)
+			sdk.StateDeleteObject(string(k))
+			return nil
+		},
+	}
+	for name, m := range daoMethods {
+		methods[name] = m
+	}
+	emu.Register(daoID, daoOwner, methods)
+	keys := []string{projectRosterCountKey(pid)}
+	for i, addr := range []string{"hive:someone", "hive:someoneelse"} {
+		keys = append(keys, projectRosterKey(pid, uint64(i)), memberRosterPosKey(pid, AddressFromString(addr)))
+	}
+	for _, key := range keys {
+		if v, _ := emu.State(daoID, key); v == "" {
+			t.Fatalf("roster key %x not set", key)
+		}
+		call(t, emu, daoOwner, "test_del", hex.EncodeToString([]byte(key)), nil)
+	}
+
+	prop := fmt.Sprintf("%d|wind down|x|1||0||dissolve_project=1||", pid)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))); !res.Success {
+		t.Fatalf("dissolution failed: %s", res.Err)
+	}
+	if res := tryCall(emu, "hive:someone", "project_funds", fmt.Sprintf("%d|false", pid), allow("1.000")); res.Symbol != string(errcode.Dissolved) {
+		t.Fatalf("deposit after dissolution: %+v", res)
+	}
+
+	// Treasury 6.000 (5.000 funds and the proposal cost), split by equal stakes.
+	for _, acct := range []string{"hive:someoneelse", "hive:someone"} {
+		before := emu.Balance(acct, "hive")
+		if res := call(t, emu, acct, "project_leave", fmt.Sprint(pid), nil); res.Ret != "share claimed" {
+			t.Fatalf("leave returned %q", res.Ret)
+		}
+		if got := emu.Balance(acct, "hive") - before; got != 4_000 {
+			t.Errorf("%s received %d, want 4000", acct, got)
+		}
+	}
+	if got := emu.Balance(emulator.ContractAddress(daoID), "hive"); got != 0 {
+		t.Errorf("contract still holds %d", got)
+	}
+	if res := tryCall(emu, "hive:someone", "project_leave", fmt.Sprint(pid), nil); res.Symbol != string(errcode.NotMember) {
+		t.Fatalf("second claim: %+v", res)
+	}
+}
+
+// Dividends follow the funds-asset stake. Shares that do not divide evenly
+// leave sub-unit remainders, which go back to the treasury once members exit
+// instead of staying in the contract.
+func TestNativeDividendRemainders(t *testing.T) {
+	emu := newEmulator(t)
+	pid := createdID(t, call(t, emu, "hive:someone", "project_create", "dao|desc|1|50.001|50.001|1|0|10|1|1|||||1|||", allow("1.000")))
+	call(t, emu, "hive:someoneelse", "project_join", fmt.Sprint(pid), allow("2.000"))
+	call(t, emu, "hive:someone", "project_funds", fmt.Sprintf("%d|false", pid), allow("5.000"))
+
+	prop := fmt.Sprintf("%d|share|x|1||0||distribute=1.000:hive||", pid)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))); !res.Success {
+		t.Fatalf("distribute failed: %s", res.Err)
+	}
+	prop = fmt.Sprintf("%d|wind down|x|1||0||dissolve_project=1||", pid)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))); !res.Success {
+		t.Fatalf("dissolution failed: %s", res.Err)
+	}
+
+	// 1.000 splits 333.33/666.67; the two thirds of a unit left behind add up
+	// to one unit, returned when the second member exits and taken as the last
+	// share. Treasury 6.000 is split 1:2 with that unit on top.
+	for _, c := range []struct {
+		acct string
+		want int64
+	}{{"hive:someone", 1_000 + 333 + 2_000}, {"hive:someoneelse", 2_000 + 666 + 4_001}} {
+		before := emu.Balance(c.acct, "hive")
+		res := call(t, emu, c.acct, "project_leave", fmt.Sprint(pid), nil)
+		if got := emu.Balance(c.acct, "hive") - before; got != c.want {
+			t.Errorf("%s received %d, want %d (logs %q)", c.acct, got, c.want, res.Logs)
+		}
+	}
+	if got := emu.Balance(emulator.ContractAddress(daoID), "hive"); got != 0 {
+		t.Errorf("contract still holds %d", got)
+	}
+}
+
+// Savings interest the ledger pays on the contract is split over the projects'
+// hbd_savings balances and credited when each project next executes.
+func TestNativeHbdSavingsInterest(t *testing.T) {
+	emu := newEmulator(t)
+	contract := emulator.ContractAddress(daoID)
+	execute := func(pid uint64, meta string) emulator.Result {
+		t.Helper()
+		prop := fmt.Sprintf("%d|savings|x|1||0%s", pid, meta)
+		res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000"))))
+		if !res.Success {
+			t.Fatalf("%s failed: %s", meta, res.Err)
+		}
+		return res
+	}
+	var pids []uint64
+	for _, hbd := range []string{"10.000", "30.000"} {
+		pid := newProject(t, emu)
+		call(t, emu, "hive:someone", "project_funds", fmt.Sprintf("%d|false", pid), []sdk.Intent{emulator.TransferAllow(hbd, "hbd")})
+		execute(pid, "||treasury_stake_hbd="+hbd+"||")
+		pids = append(pids, pid)
+	}
+	if res := tryCall(emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|x|x|1||0||distribute=1.000:hbd_savings||", pids[0]), allow("1.000")); res.Symbol != string(errcode.WrongAsset) {
+		t.Fatalf("distributing savings: %+v", res)
+	}
+	// Savings cannot be transferred to claimants, so dissolving waits for them
+	// to be unstaked (see also the end of the test).
+	if res := tryCall(emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|x|x|1||0||dissolve_project=1||", pids[0]), allow("1.000")); res.Symbol != string(errcode.Locked) {
+		t.Fatalf("dissolving with savings: %+v", res)
+	}
+
+	emu.Deposit(contract, "hbd_savings", 4_000)
+	res := execute(pids[0], "||treasury_unstake_hbd=11.000||")
+	if want := fmt.Sprintf("hi|id:%d|am:1.000000", pids[0]); !strings.Contains(strings.Join(res.Logs, "\n"), want) {
+		t.Fatalf("no %q in %q", want, res.Logs)
+	}
+	// The second project's 3.000 waits in the pool until it executes.
+	if got := emu.Balance(contract, "hbd_savings"); got != 33_000 {
+		t.Fatalf("contract saves %d, want 33000", got)
+	}
+	execute(pids[1], "||treasury_unstake_hbd=33.000||")
+
+	emu.Advance(emulator.HbdUnstakePeriod)
+	before := emu.Balance("hive:someoneelse", "hbd")
+	execute(pids[0], "|hive:someoneelse:11.000:hbd||")
+	if got := emu.Balance("hive:someoneelse", "hbd") - before; got != 11_000 {
+		t.Fatalf("paid %d from released savings, want 11000", got)
+	}
+	if got := emu.Balance(contract, "hbd_savings"); got != 0 {
+		t.Errorf("contract still saves %d", got)
+	}
+	if got := emu.Balance(contract, "hbd"); got != 33_000 {
+		t.Errorf("contract holds %d hbd, want the second project's 33000", got)
+	}
+
+	// Savings staked by the dissolving proposal itself stop it at execution.
+	late := newProject(t, emu)
+	call(t, emu, "hive:someone", "project_funds", fmt.Sprintf("%d|false", late), []sdk.Intent{emulator.TransferAllow("1.000", "hbd")})
+	prop := fmt.Sprintf("%d|x|x|1||0||treasury_stake_hbd=1.000;dissolve_project=1||", late)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))); res.Symbol != string(errcode.Locked) {
+		t.Fatalf("dissolving after staking savings: %+v", res)
+	}
+}
+
+// A project exported by proposal continues on the second deployment with its
+// members, funds and open proposals, and an export back returns it.
+func TestNativeExportImportRoundTrip(t *testing.T) {
+	emu := newEmulator(t)
+	const dao2 = "dao2"
+	emu.Register(dao2, daoOwner, daoMethods)
+	at := func(contract, caller, action, payload string, intents []sdk.Intent) emulator.Result {
+		return emu.Call(emulator.Call{
+			Caller: caller, ContractID: contract, Action: action,
+			Payload: strconv.Quote(payload), Intents: intents,
+		})
+	}
+	if res := at(dao2, daoOwner, "contract_init", "public", nil); !res.Success {
+		t.Fatal(res.Err)
+	}
+
+	pid := newProject(t, emu)
+	if res := tryCall(emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|move|x|1||0||export_project=%s||", pid, dao2), allow("1.000")); res.Symbol != string(errcode.Unauthorized) {
+		t.Fatalf("export to a target that is not allowlisted: %+v", res)
+	}
+	call(t, emu, "hive:someone", "project_whitelist_add", fmt.Sprintf("%d|hive:pending", pid), nil)
+	allowlist := fmt.Sprintf("%d|allow|x|1||0||icc_allowlist_add=%s:proposal_tally,%s:project_import,%s:project_import||", pid, dao2, dao2, daoID)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", allowlist, allow("1.000")))); !res.Success {
+		t.Fatal(res.Err)
+	}
+	prop := fmt.Sprintf("%d|text|carried|80||0|||", pid)
+	propID := createdID(t, call(t, emu, "hive:someone", "proposal_create", prop, allow("1.000")))
+	call(t, emu, "hive:someone", "proposals_vote", fmt.Sprintf("%d|1", propID), nil)
+
+	if res := tryCall(emu, "hive:someone", "proposal_create", fmt.Sprintf("%d|move|x|1||0||export_project=%s||", pid, daoID), allow("1.000")); res.Success {
+		t.Fatal("project exported to its own contract")
+	}
+	move := fmt.Sprintf("%d|move|x|1||0||export_project=%s:%d||", pid, dao2, propID)
+	moveID := createdID(t, call(t, emu, "hive:someone", "proposal_create", move, allow("1.000")))
+	held := emu.Balance(emulator.ContractAddress(daoID), "hive")
+	res := passAndExport(t, emu, moveID)
+	if !res.Success {
+		t.Fatalf("export failed: %s", res.Err)
+	}
+	const newID = 0
+	if want := fmt.Sprintf("prId:%d|by:hive:someone|to:%s|newId:%d|", moveID, dao2, newID); !strings.Contains(strings.Join(res.Logs, "\n"), want) {
+		t.Fatalf("no %q in %q", want, res.Logs)
+	}
+
+	if got := emu.Balance(emulator.ContractAddress(dao2), "hive"); got != held {
+		t.Fatalf("target holds %d, want %d", got, held)
+	}
+	if got := emu.Balance(emulator.ContractAddress(daoID), "hive"); got != 0 {
+		t.Fatalf("source still holds %d", got)
+	}
+	if res := tryCall(emu, "hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", propID), nil); res.Symbol != string(errcode.Dissolved) {
+		t.Fatalf("vote on the exported project: %+v", res)
+	}
+
+	// Both lists move with the project.
+	target := iccTarget(dao2, "proposal_tally")
+	if _, ok := emu.State(dao2, whitelistKey(newID, "hive:pending")); !ok {
+		t.Fatal("whitelist approval not carried")
+	}
+	if _, ok := emu.State(dao2, iccAllowlistKey(newID, target)); !ok {
+		t.Fatal("ICC allowlist not carried")
+	}
+	if _, ok := emu.State(daoID, whitelistKey(pid, "hive:pending")); ok {
+		t.Fatal("whitelist approval left on the source")
+	}
+	if _, ok := emu.State(daoID, iccAllowlistKey(pid, target)); ok {
+		t.Fatal("ICC allowlist left on the source")
+	}
+
+	// The carried proposal arrives without its ballot, votes again and
+	// finishes on the target.
+	state, _ := emu.State(dao2, proposalKey(0))
+	if p, _, err := decodeProposalRecord(state); err != nil || p.VoterCount != 0 || p.State != ProposalActive {
+		t.Fatalf("imported proposal %+v, %v", p, err)
+	}
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		if res := at(dao2, voter, "proposals_vote", "0|1", nil); !res.Success {
+			t.Fatal(res.Err)
+		}
+	}
+	emu.Advance(8 * time.Hour)
+	if res := at(dao2, "hive:someone", "proposal_tally", "0", nil); !res.Success {
+		t.Fatal(res.Err)
+	}
+	state, _ = emu.State(dao2, proposalKey(0))
+	p, _, err := decodeProposalRecord(state)
+	if err != nil || p.ProjectID != newID || p.State != ProposalPassed {
+		t.Fatalf("carried proposal %+v, %v", p, err)
+	}
+
+	back := fmt.Sprintf("%d|home|go back|1||0||export_project=%s||", newID, daoID)
+	res = at(dao2, "hive:someone", "proposal_create", back, allow("1.000"))
+	if !res.Success {
+		t.Fatal(res.Err)
+	}
+	backID := createdID(t, res)
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		if res := at(dao2, voter, "proposals_vote", fmt.Sprintf("%d|1", backID), nil); !res.Success {
+			t.Fatal(res.Err)
+		}
+	}
+	emu.Advance(2 * time.Hour)
+	if res := at(dao2, "hive:someone", "proposal_tally", fmt.Sprint(backID), nil); !res.Success {
+		t.Fatal(res.Err)
+	}
+	emu.Advance(ExportCoolingOffHours * time.Hour)
+	if res := at(dao2, "hive:someone", "proposal_execute", fmt.Sprint(backID), nil); !res.Success {
+		t.Fatal(res.Err)
+	}
+	if got := emu.Balance(emulator.ContractAddress(daoID), "hive"); got != held+1_000 {
+		t.Fatalf("source holds %d after the return, want %d", got, held+1_000)
+	}
+	dump := map[string]string{}
+	for _, key := range emu.StateKeys(daoID) {
+		dump[key], _ = emu.State(daoID, key)
+	}
+	restored, err := indexer.FromState(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prj := restored.Projects[pid+1]; prj == nil || len(prj.Members) != 2 || prj.Owner != "hive:someone" {
+		t.Fatalf("returned project %+v", prj)
+	}
+}
+
+// An export needs the ICC threshold, or every vote when none is set above the
+// project threshold, and members who voted against it can leave before it
+// executes.
+func TestNativeExportSafeguards(t *testing.T) {
+	emu := newEmulator(t)
+	const dao2 = "dao2"
+	emu.Register(dao2, daoOwner, daoMethods)
+	if res := emu.Call(emulator.Call{Caller: daoOwner, ContractID: dao2, Action: "contract_init", Payload: strconv.Quote("public")}); !res.Success {
+		t.Fatal(res.Err)
+	}
+	pid := newProject(t, emu)
+	emu.Deposit("hive:third", "hive", 10_000)
+	call(t, emu, "hive:third", "project_join", fmt.Sprint(pid), allow("1.000"))
+	setup := fmt.Sprintf("%d|setup|x|1||0||icc_allowlist_add=%s:project_import||", pid, dao2)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", setup, allow("1.000")))); !res.Success {
+		t.Fatal(res.Err)
+	}
+	export := func() uint64 {
+		move := fmt.Sprintf("%d|move|x|1||0||export_project=%s||", pid, dao2)
+		id := createdID(t, call(t, emu, "hive:someone", "proposal_create", move, allow("1.000")))
+		call(t, emu, "hive:someone", "proposals_vote", fmt.Sprintf("%d|1", id), nil)
+		call(t, emu, "hive:someoneelse", "proposals_vote", fmt.Sprintf("%d|1", id), nil)
+		call(t, emu, "hive:third", "proposals_vote", fmt.Sprintf("%d|0", id), nil)
+		emu.Advance(2 * time.Hour)
+		call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(id), nil)
+		return id
+	}
+	state := func(id uint64) ProposalState {
+		raw, _ := emu.State(daoID, proposalKey(id))
+		p, _, err := decodeProposalRecord(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.State
+	}
+
+	// Two of three carry an ordinary proposal, not an export.
+	if got := state(export()); got != ProposalFailed {
+		t.Fatalf("export without unanimity is %s", got)
+	}
+	raise := fmt.Sprintf("%d|raise|x|1||0||update_iccThreshold=60||", pid)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", raise, allow("1.000")))); !res.Success {
+		t.Fatal(res.Err)
+	}
+	moveID := export()
+	if got := state(moveID); got != ProposalPassed {
+		t.Fatalf("export at the ICC threshold is %s", got)
+	}
+
+	// The dissenter leaves with their stake during the cooling-off.
+	call(t, emu, "hive:third", "project_leave", fmt.Sprint(pid), nil)
+	emu.Advance(10 * time.Hour)
+	before := emu.Balance("hive:third", "hive")
+	call(t, emu, "hive:third", "project_leave", fmt.Sprint(pid), nil)
+	if got := emu.Balance("hive:third", "hive"); got != before+1_000 {
+		t.Fatalf("dissenter got %d back, want 1000", got-before)
+	}
+	if res := tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(moveID), nil); res.Symbol != string(errcode.TooEarly) {
+		t.Fatalf("export during the cooling-off: %+v", res)
+	}
+	emu.Advance(ExportCoolingOffHours * time.Hour)
+	if res := tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(moveID), nil); !res.Success {
+		t.Fatal(res.Err)
+	}
+}
+
+// A member missing from the roster, as one who joined before it existed and
+// has not acted since, stays behind and claims their stake and share of the
+// treasury on the source.
+func TestNativeExportLeavesUnrosteredMembers(t *testing.T) {
+	emu := newEmulator(t)
+	const dao2 = "dao2"
+	emu.Register(dao2, daoOwner, daoMethods)
+	if res := emu.Call(emulator.Call{Caller: daoOwner, ContractID: dao2, Action: "contract_init", Payload: strconv.Quote("public")}); !res.Success {
+		t.Fatal(res.Err)
+	}
+	methods := map[string]emulator.Method{"forget_roster": func(payload *string) *string {
+		raw, _ := strconv.Unquote(*payload)
+		id, addr, _ := strings.Cut(raw, "|")
+		pid, _ := strconv.ParseUint(id, 10, 64)
+		deleteRosterEntry(pid, AddressFromString(addr))
+		return nil
+	}}
+	for name, m := range daoMethods {
+		methods[name] = m
+	}
+	emu.Register(daoID, daoOwner, methods)
+
+	pid := newProject(t, emu)
+	setup := fmt.Sprintf("%d|setup|x|1||0||icc_allowlist_add=%s:project_import||", pid, dao2)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", setup, allow("1.000")))); !res.Success {
+		t.Fatal(res.Err)
+	}
+	move := fmt.Sprintf("%d|move|x|1||0||export_project=%s||", pid, dao2)
+	moveID := createdID(t, call(t, emu, "hive:someone", "proposal_create", move, allow("1.000")))
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|1", moveID), nil)
+	}
+	emu.Advance(2 * time.Hour)
+	call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(moveID), nil)
+	call(t, emu, "hive:someone", "forget_roster", fmt.Sprintf("%d|hive:someoneelse", pid), nil)
+	emu.Advance(ExportCoolingOffHours * time.Hour)
+	held := emu.Balance(emulator.ContractAddress(daoID), "hive")
+	call(t, emu, "hive:someone", "proposal_execute", fmt.Sprint(moveID), nil)
+
+	// Equal stakes: the member left behind keeps their stake and half the
+	// treasury.
+	kept := emu.Balance(emulator.ContractAddress(daoID), "hive")
+	if moved := emu.Balance(emulator.ContractAddress(dao2), "hive"); moved+kept != held || moved != kept {
+		t.Fatalf("moved %d and kept %d of %d", moved, kept, held)
+	}
+	before := emu.Balance("hive:someoneelse", "hive")
+	call(t, emu, "hive:someoneelse", "project_leave", fmt.Sprint(pid), nil)
+	if got := emu.Balance("hive:someoneelse", "hive") - before; got != kept {
+		t.Fatalf("member left behind claimed %d, want %d", got, kept)
+	}
+}
+
+// A project created before the whitelist index may hold approvals no walk
+// finds, so it cannot be exported: not when the export is proposed, and not
+// when it executes.
+func TestNativeExportNeedsIndexedWhitelist(t *testing.T) {
+	emu := newEmulator(t)
+	const dao2 = "dao2"
+	emu.Register(dao2, daoOwner, daoMethods)
+	if res := emu.Call(emulator.Call{Caller: daoOwner, ContractID: dao2, Action: "contract_init", Payload: strconv.Quote("public")}); !res.Success {
+		t.Fatal(res.Err)
+	}
+	methods := map[string]emulator.Method{"forget_index": func(payload *string) *string {
+		raw, _ := strconv.Unquote(*payload)
+		pid, _ := strconv.ParseUint(raw, 10, 64)
+		sdk.StateDeleteObject(projectListsIndexedKey(pid))
+		return nil
+	}}
+	for name, m := range daoMethods {
+		methods[name] = m
+	}
+	emu.Register(daoID, daoOwner, methods)
+
+	pid := newProject(t, emu)
+	setup := fmt.Sprintf("%d|setup|x|1||0||icc_allowlist_add=%s:project_import||", pid, dao2)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", setup, allow("1.000")))); !res.Success {
+		t.Fatal(res.Err)
+	}
+	move := fmt.Sprintf("%d|move|x|1||0||export_project=%s||", pid, dao2)
+	moveID := createdID(t, call(t, emu, "hive:someone", "proposal_create", move, allow("1.000")))
+	call(t, emu, "hive:someone", "forget_index", fmt.Sprint(pid), nil)
+	if res := tryCall(emu, "hive:someone", "proposal_create", move, allow("1.000")); res.Symbol != string(errcode.NotEligible) {
+		t.Fatalf("export proposed from a pre-index project: %+v", res)
+	}
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|1", moveID), nil)
+	}
+	emu.Advance(2 * time.Hour)
+	call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(moveID), nil)
+	emu.Advance(ExportCoolingOffHours * time.Hour)
+	if res := tryCall(emu, "hive:someone", "proposal_execute", fmt.Sprint(moveID), nil); res.Symbol != string(errcode.NotEligible) {
+		t.Fatalf("export executed from a pre-index project: %+v", res)
+	}
+}
+
+// A passed proposal cannot travel, and an import applies project_create's
+// rules to the bundle.
+func TestNativeExportImportValidation(t *testing.T) {
+	emu := newEmulator(t)
+	const dao2 = "dao2"
+	emu.Register(dao2, daoOwner, daoMethods)
+	importCall := func(b *projectBundle) emulator.Result {
+		return emu.Call(emulator.Call{
+			Caller: emulator.ContractAddress(daoID), ContractID: dao2, Action: "project_import",
+			Payload: strconv.Quote(hex.EncodeToString(encodeProjectBundle(b))),
+		})
+	}
+	if res := emu.Call(emulator.Call{Caller: daoOwner, ContractID: dao2, Action: "contract_init", Payload: strconv.Quote("public")}); !res.Success {
+		t.Fatal(res.Err)
+	}
+
+	pid := newProject(t, emu)
+	grant := fmt.Sprintf("%d|grant|x|1||0|hive:someoneelse:1.000:hive||", pid)
+	grantID := createdID(t, call(t, emu, "hive:someone", "proposal_create", grant, allow("1.000")))
+	for _, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		call(t, emu, voter, "proposals_vote", fmt.Sprintf("%d|1", grantID), nil)
+	}
+	emu.Advance(2 * time.Hour)
+	call(t, emu, "hive:someone", "proposal_tally", fmt.Sprint(grantID), nil)
+	allowlist := fmt.Sprintf("%d|allow|x|1||0||icc_allowlist_add=%s:project_import||", pid, dao2)
+	if res := passAndExecute(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", allowlist, allow("1.000")))); !res.Success {
+		t.Fatal(res.Err)
+	}
+	move := fmt.Sprintf("%d|move|x|1||0||export_project=%s:%d||", pid, dao2, grantID)
+	res := passAndExport(t, emu, createdID(t, call(t, emu, "hive:someone", "proposal_create", move, allow("1.000"))))
+	if res.Symbol != string(errcode.ProposalState) {
+		t.Fatalf("exporting a passed proposal: %+v", res)
+	}
+
+	valid := func() *projectBundle {
+		return &projectBundle{
+			Meta:     ProjectMeta{Name: "dao"},
+			Config:   ProjectConfig{ThresholdPercent: 50.001, QuorumPercent: 50.001, ProposalDurationHours: 1, LeaveCooldownHours: 1},
+			Finance:  ProjectFinance{FundsAsset: sdk.AssetHive},
+			Treasury: map[sdk.Asset]Amount{},
+		}
+	}
+	if res := importCall(valid()); !res.Success {
+		t.Fatalf("valid bundle: %s", res.Err)
+	}
+	high := valid()
+	high.Config.ThresholdPercent = 101
+	long := valid()
+	long.Meta.Name = strings.Repeat("x", MaxNameLength+1)
+	passed := valid()
+	passed.Proposals = []bundleProposal{{
+		Proposal: Proposal{State: ProposalPassed, DurationHours: 1, OptionCount: 2},
+		Options:  []ProposalOption{{Text: "no"}, {Text: "yes"}},
+	}}
+	listed := valid()
+	listed.ICCAllowlist = []string{"dao2"}
+	for name, b := range map[string]*projectBundle{"threshold": high, "name": long, "passed proposal": passed, "allowlist": listed} {
+		if res := importCall(b); res.Success {
+			t.Errorf("bundle with invalid %s imported", name)
+		}
+	}
+
+	// A member's stake history must end at their stake; earlier entries are
+	// capped at it and reputation at the blend cap.
+	now := emu.Now().Unix()
+	withMember := func(history ...string) *projectBundle {
+		b := valid()
+		b.JoinSeq = 1
+		b.Finance.MemberCount = 1
+		b.Finance.StakeTotal = 2_000
+		b.Members = []bundleMember{{
+			Member:  Member{Address: "hive:imported", Stake: 2_000, JoinedAt: now - 100, Reputation: 5_000, StakeIncrement: uint64(len(history) - 1)},
+			History: history,
+		}}
+		return b
+	}
+	fundedImport := func(b *projectBundle) emulator.Result {
+		return emu.Call(emulator.Call{
+			Caller: emulator.ContractAddress(daoID), ContractID: dao2, Action: "project_import",
+			Payload: strconv.Quote(hex.EncodeToString(encodeProjectBundle(b))), Intents: allow("2.000"),
+		})
+	}
+	for name, b := range map[string]*projectBundle{
+		"history end":    withMember(fmt.Sprintf("5000_%d", now-100), fmt.Sprintf("3000_%d", now-50)),
+		"history order":  withMember(fmt.Sprintf("5000_%d", now-50), fmt.Sprintf("2000_%d", now-100)),
+		"future history": withMember(fmt.Sprintf("2000_%d", now+100)),
+		"history entry":  withMember("2000"),
+	} {
+		if res := fundedImport(b); res.Success {
+			t.Errorf("bundle with invalid %s imported", name)
+		}
+	}
+	res = fundedImport(withMember(fmt.Sprintf("5000_%d", now-100), fmt.Sprintf("2000_%d", now-50)))
+	if !res.Success {
+		t.Fatal(res.Err)
+	}
+	id := createdID(t, res)
+	raw, _ := emu.State(dao2, memberKey(id, "hive:imported"))
+	m, _, err := decodeMemberRecord(raw)
+	if err != nil || m.Reputation != ReputationBlendCap {
+		t.Fatalf("imported member %+v, %v", m, err)
+	}
+	if got, _ := emu.State(dao2, memberStakeHistoryKey(id, "hive:imported", 0)); got != fmt.Sprintf("2000_%d", now-100) {
+		t.Fatalf("first history entry %q, want it capped at the stake", got)
+	}
+}
+
+// An import pays the target's creation fee out of the imported treasury.
+func TestNativeImportCreationFee(t *testing.T) {
+	emu := newEmulator(t)
+	const dao2 = "dao2"
+	emu.Register(dao2, daoOwner, daoMethods)
+	source := emulator.ContractAddress(daoID)
+	emu.Deposit(source, "hive", 5_000)
+	importCall := func(treasury Amount) emulator.Result {
+		b := &projectBundle{
+			Meta:     ProjectMeta{Name: "dao"},
+			Config:   ProjectConfig{ThresholdPercent: 50.001, QuorumPercent: 50.001, ProposalDurationHours: 1, LeaveCooldownHours: 1},
+			Finance:  ProjectFinance{FundsAsset: sdk.AssetHive},
+			Treasury: map[sdk.Asset]Amount{sdk.AssetHive: treasury},
+		}
+		return emu.Call(emulator.Call{
+			Caller: source, ContractID: dao2, Action: "project_import",
+			Payload: strconv.Quote(hex.EncodeToString(encodeProjectBundle(b))),
+			Intents: allow(fmt.Sprintf("%.3f", AmountToFloat(treasury))),
+		})
+	}
+	for _, step := range [][2]string{{"contract_init", "public"}, {"contract_fee", "2.000|hive"}} {
+		if res := emu.Call(emulator.Call{Caller: daoOwner, ContractID: dao2, Action: step[0], Payload: strconv.Quote(step[1])}); !res.Success {
+			t.Fatal(res.Err)
+		}
+	}
+
+	if res := importCall(1_000); res.Symbol != string(errcode.InsufficientFunds) {
+		t.Fatalf("import with a treasury below the fee: %+v", res)
+	}
+	before := emu.Balance(daoOwner, "hive")
+	res := importCall(3_000)
+	if !res.Success {
+		t.Fatal(res.Err)
+	}
+	if want := "cf|id:0|by:" + source + "|to:" + daoOwner + "|am:2.000000|as:hive"; !strings.Contains(strings.Join(res.Logs, "\n"), want) {
+		t.Fatalf("no %q in %q", want, res.Logs)
+	}
+	if got := emu.Balance(daoOwner, "hive"); got != before+2_000 {
+		t.Fatalf("owner holds %d, want %d", got, before+2_000)
+	}
+	dump := map[string]string{}
+	for _, key := range emu.StateKeys(dao2) {
+		dump[key], _ = emu.State(dao2, key)
+	}
+	imported, err := indexer.FromState(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := imported.Projects[0].Treasury["hive"]; got != 1_000 {
+		t.Fatalf("treasury %d after the fee, want 1000", got)
+	}
+}
+
+// The contract owner changes contract settings without touching projects: the
+// creation fee goes straight to them, and ownership moves only when accepted.
+func TestNativeContractAdministration(t *testing.T) {
+	emu := newEmulator(t)
+	ix := indexer.New()
+	run := func(caller, action, payload string, intents []sdk.Intent) emulator.Result {
+		t.Helper()
+		res := call(t, emu, caller, action, payload, intents)
+		if err := ix.ApplyLines(res.Logs); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	expect := func(code errcode.Code, caller, action, payload string, intents []sdk.Intent) {
+		t.Helper()
+		if res := tryCall(emu, caller, action, payload, intents); res.Symbol != string(code) {
+			t.Fatalf("%s %q by %s: got %s (%s)", action, payload, caller, res.Symbol, res.Err)
+		}
+	}
+	fields := "dao|desc|0|50.001|50.001|1|0|10|1|1|||||1|||"
+
+	expect(errcode.Unauthorized, "hive:someone", "contract_mode", "owner-only", nil)
+	run(daoOwner, "contract_mode", "owner-only", nil)
+	expect(errcode.Unauthorized, "hive:someone", "project_create", fields, allow("1.000"))
+	run(daoOwner, "contract_mode", "public", nil)
+
+	expect(errcode.InvalidValue, daoOwner, "contract_fee", "1.000|hbd_savings", nil)
+	expect(errcode.InvalidValue, daoOwner, "contract_fee", "1.000|btc", nil)
+
+	// A fee in the stake asset comes out of the same intent.
+	run(daoOwner, "contract_fee", "2.000|hive", nil)
+	expect(errcode.StakeAmount, "hive:someone", "project_create", fields, allow("2.500"))
+	before := emu.Balance(daoOwner, "hive")
+	res := run("hive:someone", "project_create", fields, allow("3.000"))
+	if got := emu.Balance(daoOwner, "hive") - before; got != 2_000 {
+		t.Fatalf("owner received %d", got)
+	}
+	if !strings.Contains(strings.Join(res.Logs, "\n"), "cf|id:0|by:hive:someone|to:hive:tibfox|am:2.000000|as:hive") {
+		t.Fatalf("logs %q", res.Logs)
+	}
+	// One in another asset needs its own intent.
+	run(daoOwner, "contract_fee", "1|hbd", nil)
+	expect(errcode.NoIntent, "hive:someone", "project_create", fields, allow("1.000"))
+	both := append(allow("1.000"), emulator.TransferAllow("1.000", "hbd"))
+	run("hive:someone", "project_create", fields, both)
+	if ix.CreationFee != 1_000 || ix.CreationFeeAsset != "hbd" {
+		t.Fatalf("indexed fee %d %s", ix.CreationFee, ix.CreationFeeAsset)
+	}
+
+	run(daoOwner, "contract_transfer", "hive:someone", nil)
+	expect(errcode.Unauthorized, "hive:someoneelse", "contract_accept", "", nil)
+	run("hive:someone", "contract_accept", "", nil)
+	expect(errcode.Unauthorized, daoOwner, "contract_fee", "0", nil)
+
+	run("hive:someone", "contract_mode", "owner-only", nil)
+	expect(errcode.InvalidValue, "hive:someone", "contract_renounce", "renounce", nil)
+	run("hive:someone", "contract_mode", "public", nil)
+	expect(errcode.InvalidValue, "hive:someone", "contract_renounce", "yes", nil)
+	run("hive:someone", "contract_renounce", "renounce", nil)
+	expect(errcode.Unauthorized, "hive:someone", "contract_mode", "owner-only", nil)
+	// The renounced fee is no longer charged.
+	run("hive:someoneelse", "project_create", fields, allow("1.000"))
+	if ix.Owner != "" || ix.Mode != "public" || ix.CreationFee != 0 || ix.PendingOwner != "" {
+		t.Fatalf("indexed contract %+v", ix)
+	}
+}
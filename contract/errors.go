@@ -0,0 +1,34 @@
+package main
+
+import (
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// abort reverts the transaction with a code from the errcode catalogue as the
+// revert symbol and msg as the human readable detail. Callers branch on the
+// code; the message may change between releases.
+func abort(code errcode.Code, msg string) {
+	sdk.Revert(msg, string(code))
+	// Like sdk.Abort, never return to the caller should the host not unwind.
+	panic(msg)
+}
+
+// failure is a check that did not pass, carrying the code and message abort
+// would revert with. Checks that proposal_simulate also runs return one
+// instead of aborting, so a dry run can report every problem it finds.
+type failure struct {
+	Code errcode.Code
+	Msg  string
+}
+
+func fail(code errcode.Code, msg string) *failure {
+	return &failure{Code: code, Msg: msg}
+}
+
+// must aborts with f unless it is nil.
+func must(f *failure) {
+	if f != nil {
+		abort(f.Code, f.Msg)
+	}
+}
@@ -17,6 +17,9 @@ func sanitizeEventValue(val string) string {
 	return strings.TrimSpace(val)
 }
 
+// legacyDelimiters blanks the characters that end a legacy field or line.
+var legacyDelimiters = strings.NewReplacer("|", " ", "\n", " ")
+
 func formatOptionalString(ptr *string) string {
 	if ptr == nil {
 		return ""
@@ -53,11 +56,26 @@ func formatPayoutMap(payout []PayoutEntry) string {
 	}
 	out := make([]string, 0, len(payout))
 	for _, entry := range payout {
-		out = append(out, fmt.Sprintf("%s:%f:%s", AddressToString(entry.Address), AmountToFloat(entry.Amount), AssetToString(entry.Asset)))
+		part := fmt.Sprintf("%s:%f:%s", AddressToString(entry.Address), AmountToFloat(entry.Amount), AssetToString(entry.Asset))
+		if entry.Mode == PayoutModeL1 {
+			part += ":" + entry.Mode.String()
+		}
+		out = append(out, part)
 	}
 	return strings.Join(out, ";")
 }
 
+func formatStakeWeights(weights map[sdk.Asset]float64) string {
+	if len(weights) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(weights))
+	for _, asset := range sortedAssetKeys(weights) {
+		parts = append(parts, fmt.Sprintf("%s=%f", AssetToString(asset), weights[asset]))
+	}
+	return strings.Join(parts, ";")
+}
+
 func formatOptionsList(opts []ProposalOptionInput) string {
 	if len(opts) == 0 {
 		return ""
@@ -76,36 +94,81 @@ func formatOptionsList(opts []ProposalOptionInput) string {
 }
 
 // emitInitEvent logs contract initialization with owner and mode.
-func emitInitEvent(owner string, mode string) {
-	sdk.Log(fmt.Sprintf(
+func emitInitEvent(owner string, mode string, format EventFormat) {
+	logEvent(fmt.Sprintf(
 		"shindao_init|owner:%s|mode:%s",
 		owner,
 		mode,
-	))
+	), "contract.init", func(e *jsonEvent) {
+		e.Str("owner", owner)
+		e.Str("mode", mode)
+		e.Str("events", format.String())
+	})
+}
+
+// emitContractConfigEvent logs a contract setting changed by the contract
+// owner (or, for "owner", by the account accepting ownership).
+func emitContractConfigEvent(by string, field string, oldValue string, newValue string) {
+	logEvent(fmt.Sprintf(
+		"cc|by:%s|f:%s|old:%s|new:%s",
+		by,
+		field,
+		oldValue,
+		newValue,
+	), "contract.config", func(e *jsonEvent) {
+		e.Str("by", by)
+		e.Str("field", field)
+		e.Str("old", oldValue)
+		e.Str("new", newValue)
+	})
+}
+
+// emitCreationFeeEvent logs the project creation fee paid to the contract owner.
+func emitCreationFeeEvent(projectId uint64, by string, to string, amount float64, asset string) {
+	logEvent(fmt.Sprintf(
+		"cf|id:%d|by:%s|to:%s|am:%f|as:%s",
+		projectId,
+		by,
+		to,
+		amount,
+		asset,
+	), "contract.fee", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("by", by)
+		e.Str("to", to)
+		e.Num("amount", amount)
+		e.Str("asset", asset)
+	})
 }
 
 // emitJoinedEvent writes a tiny "mj" log so watchers know someone fresh just joined the project adress.
 func emitJoinedEvent(projectId uint64, memberAddress string) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"mj|id:%d|by:%s",
 		projectId,
 		memberAddress,
-	))
+	), "member.joined", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("by", memberAddress)
+	})
 }
 
 // emitLeaveEvent mirrors the join ping but signals a seat freed up inside the
 func emitLeaveEvent(projectId uint64, memberAddress string) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"ml|id:%d|by:%s",
 		projectId,
 		memberAddress,
-	))
+	), "member.left", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("by", memberAddress)
+	})
 }
 
 // emitProjectCreatedEvent gives explorers a neat ping without scanning full storage diffs.
 func emitProjectCreatedEvent(project *Project, createdByAddress string) {
 	payload := fmt.Sprintf(
-		"dc|id:%d|by:%s|name:%s|description:%s|metadata:%s|url:%s|asset:%s|voting:%s|threshold:%f|quorum:%f|proposalDuration:%d|executionDelay:%d|leaveCooldown:%d|proposalCost:%f|stakeMin:%f|membershipContract:%s|membershipFunction:%s|membershipNft:%s|membershipPayload:%s|membersOnly:%s|whitelistOnly:%s",
+		"dc|id:%d|by:%s|name:%s|description:%s|metadata:%s|url:%s|asset:%s|voting:%s|threshold:%f|quorum:%f|proposalDuration:%d|executionDelay:%d|leaveCooldown:%d|proposalCost:%f|stakeMin:%f|membershipContract:%s|membershipFunction:%s|membershipNft:%s|membershipPayload:%s|membersOnly:%s|whitelistOnly:%s|stakeAssets:%s",
 		project.ID,
 		createdByAddress,
 		sanitizeEventValue(project.Name),
@@ -127,17 +190,46 @@ func emitProjectCreatedEvent(project *Project, createdByAddress string) {
 		sanitizeEventValue(project.Config.MembershipNftPayloadFormat),
 		strconv.FormatBool(project.Config.ProposalsMembersOnly),
 		strconv.FormatBool(project.Config.WhitelistOnly),
+		formatStakeWeights(project.StakeWeights),
 	)
-	sdk.Log(payload)
+	logEvent(payload, "project.created", func(e *jsonEvent) {
+		cfg := project.Config
+		e.Uint("projectId", project.ID)
+		e.Str("by", createdByAddress)
+		e.Str("name", project.Name)
+		e.Str("description", project.Description)
+		e.Str("metadata", project.Metadata)
+		e.Str("url", project.URL)
+		e.Str("asset", AssetToString(project.FundsAsset))
+		e.Uint("votingSystem", uint64(cfg.VotingSystem))
+		e.Num("threshold", cfg.ThresholdPercent)
+		e.Num("quorum", cfg.QuorumPercent)
+		e.Uint("proposalDuration", cfg.ProposalDurationHours)
+		e.Uint("executionDelay", cfg.ExecutionDelayHours)
+		e.Uint("leaveCooldown", cfg.LeaveCooldownHours)
+		e.Num("proposalCost", cfg.ProposalCost)
+		e.Num("stakeMin", cfg.StakeMinAmt)
+		e.OptStr("membershipContract", cfg.MembershipNFTContract)
+		e.OptStr("membershipFunction", cfg.MembershipNFTContractFunction)
+		e.OptStr("membershipNft", cfg.MembershipNFT)
+		e.Str("membershipPayload", cfg.MembershipNftPayloadFormat)
+		e.Bool("membersOnly", cfg.ProposalsMembersOnly)
+		e.Bool("whitelistOnly", cfg.WhitelistOnly)
+		e.Weights("stakeAssets", project.StakeWeights)
+	})
 }
 
 // emitProposalCreatedEvent keeps observers updated with a short pc line for every new idea.
 func emitProposalCreatedEvent(prpsl *Proposal, projectID uint64, creator string, options []ProposalOptionInput) {
 	var payoutStr string
 	var outcomeMeta string
+	var payouts []PayoutEntry
+	var meta map[string]string
 	if prpsl.Outcome != nil {
-		payoutStr = formatPayoutMap(prpsl.Outcome.Payout)
-		outcomeMeta = formatMetadataMap(prpsl.Outcome.Meta)
+		payouts = prpsl.Outcome.Payout
+		meta = prpsl.Outcome.Meta
+		payoutStr = formatPayoutMap(payouts)
+		outcomeMeta = formatMetadataMap(meta)
 	}
 	payload := fmt.Sprintf(
 		"pc|id:%d|project:%d|by:%s|name:%s|description:%s|metadata:%s|url:%s|duration:%d|isPoll:%s|options:%s|payouts:%s|outcomeMeta:%s",
@@ -154,83 +246,195 @@ func emitProposalCreatedEvent(prpsl *Proposal, projectID uint64, creator string,
 		payoutStr,
 		outcomeMeta,
 	)
-	sdk.Log(payload)
+	logEvent(payload, "proposal.created", func(e *jsonEvent) {
+		e.Uint("proposalId", prpsl.ID)
+		e.Uint("projectId", projectID)
+		e.Str("by", creator)
+		e.Str("name", prpsl.Name)
+		e.Str("description", prpsl.Description)
+		e.Str("metadata", prpsl.Metadata)
+		e.Str("url", prpsl.URL)
+		e.Uint("duration", prpsl.DurationHours)
+		e.Bool("isPoll", prpsl.IsPoll)
+		e.Options("options", options)
+		e.Payouts("payouts", payouts)
+		e.StrMap("outcomeMeta", meta)
+	})
 }
 
 // emitProposalStateChangedEvent is the swiss army knife log entry for any state flip.
 func emitProposalStateChangedEvent(proposalId uint64, proposalState ProposalState) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"ps|id:%d|s:%s",
 		proposalId,
 		proposalState.String(),
-	))
+	), "proposal.state", func(e *jsonEvent) {
+		e.Uint("proposalId", proposalId)
+		e.Str("state", proposalState.String())
+	})
 }
 
 // emitProposalExecutionDelayEvent logs when a passed poll becomes executable so runners can queue it.
-func emitProposalExecutionDelayEvent(projectId uint64, proposalId uint64, readyAt int64) {
-	sdk.Log(fmt.Sprintf(
+// A non-zero deadline is the proposal's not_after; the legacy line omits it when unset.
+func emitProposalExecutionDelayEvent(projectId uint64, proposalId uint64, readyAt int64, deadline int64) {
+	line := fmt.Sprintf(
 		"px|pId:%d|prId:%d|ready:%s",
 		projectId,
 		proposalId,
 		strconv.FormatInt(readyAt, 10),
-	))
+	)
+	if deadline > 0 {
+		line += "|until:" + strconv.FormatInt(deadline, 10)
+	}
+	logEvent(line, "proposal.ready", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Int("readyAt", readyAt)
+		if deadline > 0 {
+			e.Int("deadline", deadline)
+		}
+	})
 }
 
 // emitProposalResultEvent leaves a short hint whether funds moved or config toggled after execution.
 func emitProposalResultEvent(projectId uint64, proposalId uint64, result string) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"pr|pId:%d|prId:%d|r:%s",
 		projectId,
 		proposalId,
 		result,
-	))
+	), "proposal.result", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Str("result", result)
+	})
+}
+
+// emitICCResultEvent logs an executed inter-contract call with the callee's
+// response as a proposal result. The legacy line flattens separators in the
+// response; the JSON event carries it as returned.
+func emitICCResultEvent(projectId uint64, proposalId uint64, contractAddr, function, response string) {
+	result := fmt.Sprintf("ICC executed: %s.%s", contractAddr, function)
+	legacy := result
+	if response != "" {
+		result += " returned " + response
+		legacy += " returned " + strings.NewReplacer("|", " ", "\n", " ").Replace(response)
+	}
+	logEvent(fmt.Sprintf(
+		"pr|pId:%d|prId:%d|r:%s",
+		projectId,
+		proposalId,
+		legacy,
+	), "proposal.result", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Str("result", result)
+	})
 }
 
 // emitProposalConfigUpdatedEvent spells out field diffs so auditors can track sensitive flips.
+// Free text (name, description, metadata, url) loses only its record delimiters
+// in the legacy line; the JSON event carries it unchanged.
 func emitProposalConfigUpdatedEvent(projectId uint64, proposalId uint64, field string, old string, new string) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"pm|pId:%d|prId:%d|f:%s|old:%s|new:%s",
 		projectId,
 		proposalId,
 		field,
-		old,
-		new,
-	))
+		legacyDelimiters.Replace(old),
+		legacyDelimiters.Replace(new),
+	), "proposal.config", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Str("field", field)
+		e.Str("old", old)
+		e.Str("new", new)
+	})
 }
 
 // emitVoteCasted includes raw choice indexes plus weight so quorum math can be replayed from logs only.
 func emitVoteCasted(proposalId uint64, voter string, choices []uint, weight float64) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"v|id:%d|by:%s|cs:%s|w:%f",
 		proposalId,
 		voter,
 		UIntSliceToString(choices),
 		weight,
-	))
+	), "vote.cast", func(e *jsonEvent) {
+		e.Uint("proposalId", proposalId)
+		e.Str("by", voter)
+		e.Uints("choices", choices)
+		e.Num("weight", weight)
+	})
 }
 
 // emitFundsAdded tells indexing bots whether the transfer beefed up treasury or user stake via one bool char.
 func emitFundsAdded(projectId uint64, addedByAddress string, amount float64, asset string, toStake bool) {
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"af|id:%d|by:%s|am:%f|as:%s|s:%s",
 		projectId,
 		addedByAddress,
 		amount,
 		asset,
 		strconv.FormatBool(toStake),
-	))
+	), "funds.added", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("by", addedByAddress)
+		e.Num("amount", amount)
+		e.Str("asset", asset)
+		e.Bool("toStake", toStake)
+	})
 }
 
 // emitFundsRemoved mirrors the add log but lets us trace payouts and unstaking in a single terse line.
 func emitFundsRemoved(projectId uint64, removedToAddress string, amount float64, asset string, fromStake bool) {
-	sdk.Log(fmt.Sprintf(
+	emitFundsRemovedVia(projectId, removedToAddress, amount, asset, fromStake, PayoutModeLedger)
+}
+
+// emitFundsRemovedVia is emitFundsRemoved with the destination mode spelled out,
+// for payouts that may leave the ledger. Only a base-layer withdrawal adds the
+// md field; every other removal keeps the original rf layout.
+func emitFundsRemovedVia(projectId uint64, removedToAddress string, amount float64, asset string, fromStake bool, mode PayoutMode) {
+	line := fmt.Sprintf(
 		"rf|id:%d|to:%s|am:%f|as:%s|fs:%s",
 		projectId,
 		removedToAddress,
 		amount,
 		asset,
 		strconv.FormatBool(fromStake),
-	))
+	)
+	if mode == PayoutModeL1 {
+		line += "|md:" + mode.String()
+	}
+	logEvent(line, "funds.removed", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("to", removedToAddress)
+		e.Num("amount", amount)
+		e.Str("asset", asset)
+		e.Bool("fromStake", fromStake)
+		if mode == PayoutModeL1 {
+			e.Str("mode", mode.String())
+		}
+	})
+}
+
+// emitReputationEvent logs a reputation delta and the resulting total so indexers
+// can follow standing without replaying decay themselves.
+func emitReputationEvent(projectId uint64, memberAddress string, delta int64, total int64, reason string) {
+	logEvent(fmt.Sprintf(
+		"rp|id:%d|by:%s|d:%d|r:%d|why:%s",
+		projectId,
+		memberAddress,
+		delta,
+		total,
+		reason,
+	), "reputation.changed", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("by", memberAddress)
+		e.Int("delta", delta)
+		e.Int("total", total)
+		e.Str("reason", reason)
+	})
 }
 
 // emitWhitelistEvent records whitelist additions/removals for downstream indexers.
@@ -242,10 +446,187 @@ func emitWhitelistEvent(projectId uint64, action string, addresses []sdk.Address
 	for _, addr := range addresses {
 		addrs = append(addrs, AddressToString(addr))
 	}
-	sdk.Log(fmt.Sprintf(
+	logEvent(fmt.Sprintf(
 		"wl|id:%d|act:%s|addrs:%s",
 		projectId,
 		sanitizeEventValue(action),
 		strings.Join(addrs, ";"),
-	))
+	), "whitelist.changed", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("action", action)
+		e.Strs("addresses", addrs)
+	})
+}
+
+// emitICCAllowlistEvent records contract:function pairs added to or removed from a project's ICC allowlist.
+func emitICCAllowlistEvent(projectId uint64, action string, targets []string) {
+	if len(targets) == 0 {
+		return
+	}
+	logEvent(fmt.Sprintf(
+		"ia|id:%d|act:%s|targets:%s",
+		projectId,
+		sanitizeEventValue(action),
+		strings.Join(targets, ";"),
+	), "icc.allowlist", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("action", action)
+		e.Strs("targets", targets)
+	})
+}
+
+// emitProjectDissolvedEvent marks a project dissolved; its members leave afterwards.
+func emitProjectDissolvedEvent(projectId uint64, proposalId uint64, members int) {
+	logEvent(fmt.Sprintf(
+		"dd|id:%d|prId:%d|members:%d",
+		projectId,
+		proposalId,
+		members,
+	), "project.dissolved", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Int("members", int64(members))
+	})
+}
+
+// emitProjectExportedEvent logs a project moved to another deployment.
+func emitProjectExportedEvent(projectId uint64, proposalId uint64, by string, target string, newId string, members int, proposals int) {
+	logEvent(fmt.Sprintf(
+		"ex|id:%d|prId:%d|by:%s|to:%s|newId:%s|members:%d|proposals:%d",
+		projectId,
+		proposalId,
+		by,
+		target,
+		newId,
+		members,
+		proposals,
+	), "project.exported", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Str("by", by)
+		e.Str("target", target)
+		e.Str("newId", newId)
+		e.Int("members", int64(members))
+		e.Int("proposals", int64(proposals))
+	})
+}
+
+// emitProjectImportedEvent logs a project restored from another deployment.
+// proposals maps the carried proposal ids as "old:new,...".
+func emitProjectImportedEvent(projectId uint64, source string, sourceId uint64, members int, proposals string) {
+	logEvent(fmt.Sprintf(
+		"im|id:%d|from:%s|srcId:%d|members:%d|proposals:%s",
+		projectId,
+		source,
+		sourceId,
+		members,
+		proposals,
+	), "project.imported", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("source", source)
+		e.Uint("sourceId", sourceId)
+		e.Int("members", int64(members))
+		e.Str("proposals", proposals)
+	})
+}
+
+// emitDividendDistributedEvent logs treasury funds handed to stakers by a distribute outcome.
+func emitDividendDistributedEvent(projectId uint64, proposalId uint64, amount float64, asset string) {
+	logEvent(fmt.Sprintf(
+		"dv|id:%d|prId:%d|am:%f|as:%s",
+		projectId,
+		proposalId,
+		amount,
+		asset,
+	), "dividend.distributed", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Num("amount", amount)
+		e.Str("asset", asset)
+	})
+}
+
+// emitDividendReturnedEvent logs dividend rounding remainders moved back into
+// the treasury.
+func emitDividendReturnedEvent(projectId uint64, amount float64, asset string) {
+	logEvent(fmt.Sprintf(
+		"dvr|id:%d|am:%f|as:%s",
+		projectId,
+		amount,
+		asset,
+	), "dividend.returned", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Num("amount", amount)
+		e.Str("asset", asset)
+	})
+}
+
+// emitDividendClaimedEvent logs dividends paid out to a member.
+func emitDividendClaimedEvent(projectId uint64, memberAddress string, amount float64, asset string) {
+	logEvent(fmt.Sprintf(
+		"dvc|id:%d|by:%s|am:%f|as:%s",
+		projectId,
+		memberAddress,
+		amount,
+		asset,
+	), "dividend.claimed", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Str("by", memberAddress)
+		e.Num("amount", amount)
+		e.Str("asset", asset)
+	})
+}
+
+// emitHbdStakedEvent logs treasury HBD moved into savings.
+func emitHbdStakedEvent(projectId uint64, proposalId uint64, amount float64) {
+	logEvent(fmt.Sprintf(
+		"hs|id:%d|prId:%d|am:%f",
+		projectId,
+		proposalId,
+		amount,
+	), "hbd.staked", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Num("amount", amount)
+	})
+}
+
+// emitHbdUnstakedEvent logs a treasury savings withdrawal and when it becomes spendable.
+func emitHbdUnstakedEvent(projectId uint64, proposalId uint64, amount float64, maturesAt int64) {
+	logEvent(fmt.Sprintf(
+		"hu|id:%d|prId:%d|am:%f|at:%d",
+		projectId,
+		proposalId,
+		amount,
+		maturesAt,
+	), "hbd.unstaked", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Uint("proposalId", proposalId)
+		e.Num("amount", amount)
+		e.Int("maturesAt", maturesAt)
+	})
+}
+
+// emitHbdUnstakeReleasedEvent logs matured withdrawals credited back to the hbd treasury.
+func emitHbdUnstakeReleasedEvent(projectId uint64, amount float64) {
+	logEvent(fmt.Sprintf(
+		"hr|id:%d|am:%f",
+		projectId,
+		amount,
+	), "hbd.released", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Num("amount", amount)
+	})
+}
+
+// emitHbdInterestEvent logs savings interest credited to the hbd_savings treasury.
+func emitHbdInterestEvent(projectId uint64, amount float64) {
+	logEvent(fmt.Sprintf(
+		"hi|id:%d|am:%f",
+		projectId,
+		amount,
+	), "hbd.interest", func(e *jsonEvent) {
+		e.Uint("projectId", projectId)
+		e.Num("amount", amount)
+	})
 }
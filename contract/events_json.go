@@ -0,0 +1,250 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+
+	"okinoko_dao/sdk"
+
+	"github.com/CosmWasm/tinyjson/jwriter"
+)
+
+// -----------------------------------------------------------------------------
+// JSON events
+// -----------------------------------------------------------------------------
+//
+// Besides the terse legacy lines every event has a JSON form:
+//
+//	{"v":1,"type":"member.joined","projectId":1,"by":"hive:bob"}
+//
+// "v" is EventSchemaVersion and "type" names the event schema. Fields are
+// typed (numbers, booleans, arrays, objects) and text is carried unsanitized.
+// Which form is logged is chosen once at contract_init (see EventFormat).
+
+// EventSchemaVersion is bumped whenever a JSON event type changes incompatibly.
+const EventSchemaVersion = 1
+
+// EventFormat selects which event encodings the contract logs.
+type EventFormat uint8
+
+const (
+	// EventFormatLegacy logs only the pipe-delimited lines (default).
+	EventFormatLegacy EventFormat = 0
+	// EventFormatJSON logs only the JSON form.
+	EventFormatJSON EventFormat = 1
+	// EventFormatBoth logs the legacy line followed by its JSON form.
+	EventFormatBoth EventFormat = 2
+)
+
+// String returns the payload spelling of the format.
+func (f EventFormat) String() string {
+	switch f {
+	case EventFormatJSON:
+		return "json"
+	case EventFormatBoth:
+		return "both"
+	default:
+		return "legacy"
+	}
+}
+
+// parseEventFormat maps the contract_init spelling onto an EventFormat.
+func parseEventFormat(s string) (EventFormat, bool) {
+	switch s {
+	case "", "legacy":
+		return EventFormatLegacy, true
+	case "json":
+		return EventFormatJSON, true
+	case "both":
+		return EventFormatBoth, true
+	}
+	return EventFormatLegacy, false
+}
+
+// eventFormat caches the configured format for the current transaction (it is
+// dropped with the other per-tx caches in currentEnv).
+var eventFormat *EventFormat
+
+// currentEventFormat returns the configured event format.
+func currentEventFormat() EventFormat {
+	currentEnv()
+	if eventFormat != nil {
+		return *eventFormat
+	}
+	cfg := loadContractConfig()
+	if cfg == nil {
+		return EventFormatLegacy
+	}
+	eventFormat = &cfg.EventFormat
+	return cfg.EventFormat
+}
+
+// logEvent logs an event in the configured format(s). fill writes the typed
+// fields of the JSON form and only runs when that form is logged.
+func logEvent(legacy string, kind string, fill func(e *jsonEvent)) {
+	format := currentEventFormat()
+	if format != EventFormatJSON {
+		sdk.Log(legacy)
+	}
+	if format == EventFormatLegacy {
+		return
+	}
+	e := &jsonEvent{}
+	e.w.RawString(`{"v":`)
+	e.w.Int(EventSchemaVersion)
+	e.Str("type", kind)
+	fill(e)
+	e.w.RawByte('}')
+	sdk.Log(string(e.w.Buffer.BuildBytes()))
+}
+
+// jsonEvent writes the fields of one JSON event after its "v"/"type" header.
+type jsonEvent struct {
+	w jwriter.Writer
+}
+
+func (e *jsonEvent) key(k string) {
+	e.w.RawByte(',')
+	e.w.String(k)
+	e.w.RawByte(':')
+}
+
+// Str writes a string field.
+func (e *jsonEvent) Str(k, v string) {
+	e.key(k)
+	e.w.String(v)
+}
+
+// OptStr writes a string field, or null when v is nil.
+func (e *jsonEvent) OptStr(k string, v *string) {
+	e.key(k)
+	if v == nil {
+		e.w.RawString("null")
+		return
+	}
+	e.w.String(*v)
+}
+
+// Uint writes an unsigned integer field.
+func (e *jsonEvent) Uint(k string, v uint64) {
+	e.key(k)
+	e.w.Uint64(v)
+}
+
+// Int writes a signed integer field.
+func (e *jsonEvent) Int(k string, v int64) {
+	e.key(k)
+	e.w.Int64(v)
+}
+
+// Num writes a decimal field (amounts, weights, percentages) in its shortest
+// exact spelling.
+func (e *jsonEvent) Num(k string, v float64) {
+	e.key(k)
+	e.w.RawString(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// Bool writes a boolean field.
+func (e *jsonEvent) Bool(k string, v bool) {
+	e.key(k)
+	e.w.Bool(v)
+}
+
+// Strs writes an array of strings.
+func (e *jsonEvent) Strs(k string, v []string) {
+	e.key(k)
+	e.w.RawByte('[')
+	for i, s := range v {
+		if i > 0 {
+			e.w.RawByte(',')
+		}
+		e.w.String(s)
+	}
+	e.w.RawByte(']')
+}
+
+// Uints writes an array of unsigned integers.
+func (e *jsonEvent) Uints(k string, v []uint) {
+	e.key(k)
+	e.w.RawByte('[')
+	for i, n := range v {
+		if i > 0 {
+			e.w.RawByte(',')
+		}
+		e.w.Uint(n)
+	}
+	e.w.RawByte(']')
+}
+
+// StrMap writes a string map as an object with sorted keys.
+func (e *jsonEvent) StrMap(k string, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	e.key(k)
+	e.w.RawByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			e.w.RawByte(',')
+		}
+		e.w.String(key)
+		e.w.RawByte(':')
+		e.w.String(m[key])
+	}
+	e.w.RawByte('}')
+}
+
+// Weights writes stake weights as an asset -> weight object.
+func (e *jsonEvent) Weights(k string, weights map[sdk.Asset]float64) {
+	e.key(k)
+	e.w.RawByte('{')
+	for i, asset := range sortedAssetKeys(weights) {
+		if i > 0 {
+			e.w.RawByte(',')
+		}
+		e.w.String(AssetToString(asset))
+		e.w.RawByte(':')
+		e.w.RawString(strconv.FormatFloat(weights[asset], 'f', -1, 64))
+	}
+	e.w.RawByte('}')
+}
+
+// Options writes proposal options as [{"text":..,"url":..}].
+func (e *jsonEvent) Options(k string, opts []ProposalOptionInput) {
+	e.key(k)
+	e.w.RawByte('[')
+	for i, opt := range opts {
+		if i > 0 {
+			e.w.RawByte(',')
+		}
+		e.w.RawString(`{"text":`)
+		e.w.String(opt.Text)
+		e.w.RawString(`,"url":`)
+		e.w.String(opt.URL)
+		e.w.RawByte('}')
+	}
+	e.w.RawByte(']')
+}
+
+// Payouts writes payout entries as [{"to":..,"amount":..,"asset":..,"mode":..}].
+func (e *jsonEvent) Payouts(k string, payout []PayoutEntry) {
+	e.key(k)
+	e.w.RawByte('[')
+	for i, entry := range payout {
+		if i > 0 {
+			e.w.RawByte(',')
+		}
+		e.w.RawString(`{"to":`)
+		e.w.String(AddressToString(entry.Address))
+		e.w.RawString(`,"amount":`)
+		e.w.RawString(strconv.FormatFloat(AmountToFloat(entry.Amount), 'f', -1, 64))
+		e.w.RawString(`,"asset":`)
+		e.w.String(AssetToString(entry.Asset))
+		e.w.RawString(`,"mode":`)
+		e.w.String(entry.Mode.String())
+		e.w.RawByte('}')
+	}
+	e.w.RawByte(']')
+}
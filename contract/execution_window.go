@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"okinoko_dao/errcode"
+	"strconv"
+	"time"
+)
+
+// Execution window keys. They ride in the proposal's meta field next to the
+// meta actions, but are taken out at creation and stored on the proposal:
+// they say when the outcome may run, not what it does.
+const (
+	MetaNotBefore = "not_before"
+	MetaNotAfter  = "not_after"
+)
+
+// isExecutionWindowKey reports whether key is one of the window keys.
+func isExecutionWindowKey(key string) bool {
+	return key == MetaNotBefore || key == MetaNotAfter
+}
+
+// takeExecutionWindow removes the window keys from meta and returns their
+// values as unix seconds, 0 where a bound is not set.
+func takeExecutionWindow(meta map[string]string) (notBefore, notAfter int64) {
+	notBefore = parseWindowBound(meta, MetaNotBefore)
+	notAfter = parseWindowBound(meta, MetaNotAfter)
+	if notBefore > 0 && notAfter > 0 && notAfter <= notBefore {
+		abort(errcode.InvalidValue, "not_after must be later than not_before")
+	}
+	return notBefore, notAfter
+}
+
+func parseWindowBound(meta map[string]string, key string) int64 {
+	raw, ok := meta[key]
+	if !ok {
+		return 0
+	}
+	delete(meta, key)
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v <= 0 {
+		abort(errcode.InvalidValue, fmt.Sprintf("%s must be a positive unix timestamp", key))
+	}
+	return v
+}
+
+// checkExecutionWindow rejects windows a new proposal could never meet:
+// a poll never executes, and a deadline must leave time after the vote and
+// the execution delay.
+func checkExecutionWindow(prpsl *Proposal, prj *Project) *failure {
+	if prpsl.NotBefore == 0 && prpsl.NotAfter == 0 {
+		return nil
+	}
+	if prpsl.IsPoll {
+		return fail(errcode.InvalidValue, "polls do not execute and cannot have an execution window")
+	}
+	if prpsl.NotAfter > 0 && prpsl.NotAfter < earliestExecution(prpsl, prj) {
+		return fail(errcode.InvalidValue, fmt.Sprintf("not_after must not be before %s, when the proposal can first execute",
+			time.Unix(earliestExecution(prpsl, prj), 0).UTC().Format(time.RFC3339)))
+	}
+	return nil
+}
+
+// earliestExecution is the end of the vote plus the project's execution delay,
+// moved to the proposal's not_before when that is later. An export waits its
+// cooling-off from the end of the vote at least.
+func earliestExecution(prpsl *Proposal, prj *Project) int64 {
+	ready := prpsl.CreatedAt + int64(prpsl.DurationHours+prj.Config.ExecutionDelayHours)*3600
+	if _, ok := exportMetaTarget(prpsl.Outcome); ok {
+		if r := prpsl.CreatedAt + int64(prpsl.DurationHours)*3600 + exportCoolingOff(prj); r > ready {
+			ready = r
+		}
+	}
+	if prpsl.NotBefore > ready {
+		ready = prpsl.NotBefore
+	}
+	return ready
+}
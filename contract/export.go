@@ -0,0 +1,954 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// -----------------------------------------------------------------------------
+// Project export / import
+// -----------------------------------------------------------------------------
+//
+// A passed export_project proposal moves a whole project to another deployment
+// of this contract: meta, config, finance, treasury, members with their stake
+// history, the whitelist and ICC allowlist, and the proposals it names (active
+// ones, or closed polls) with their options. There is no owner shortcut; the members decide.
+// The bundle travels as the payload of a project_import call on the target and
+// the funds travel with it as transfer.allow intents, one per asset, covering
+// the treasury plus every member's stake.
+//
+// An export is the one outcome that can take every member's stake, so it asks
+// more than other proposals: the target must be allowlisted as
+// "<target>:project_import" in the project's ICC allowlist, the vote must reach
+// the ICC threshold (unanimity when that is not above the normal threshold),
+// and it executes no sooner than ExportCoolingOffHours after it passed, giving
+// those who voted against it time to leave with their stake.
+//
+// The source project is emptied and marked dissolved before the call, so a
+// failed import reverts the whole export and nothing can act on the old copy
+// afterwards. Accrued dividends are paid out first. Members are found through
+// the roster; one who joined before it existed and has not acted since stays
+// behind and claims their stake and treasury share with project_leave.
+//
+// project_import trusts nothing in the bundle it cannot check: every balance
+// and stake it records must arrive with the call, and the books must add up.
+// The config, texts and addresses pass the checks project_create applies.
+// Ballots do not travel: active proposals restart their count on the target
+// against the imported membership, so no vote is taken on the exporter's word.
+
+// bundleFormat versions the export bundle layout.
+const bundleFormat = 3
+
+// projectBundle is everything an export carries to another deployment.
+type projectBundle struct {
+	SourceID  uint64
+	Meta      ProjectMeta
+	Config    ProjectConfig
+	Finance   ProjectFinance
+	JoinSeq   uint64
+	Treasury  map[sdk.Asset]Amount
+	Members   []bundleMember
+	Proposals []bundleProposal
+
+	Whitelist    []sdk.Address
+	ICCAllowlist []string
+}
+
+// bundleMember is a member record with its stake history, indexed by increment
+// ("" where an increment has no entry).
+type bundleMember struct {
+	Member  Member
+	History []string
+}
+
+// bundleProposal is a proposal with its options.
+type bundleProposal struct {
+	Proposal Proposal
+	Options  []ProposalOption
+}
+
+// exportMetaTarget returns the target of an outcome's export_project action.
+func exportMetaTarget(outcome *ProposalOutcome) (string, bool) {
+	if outcome == nil {
+		return "", false
+	}
+	value, ok := outcome.Meta["export_project"]
+	if !ok {
+		return "", false
+	}
+	target, _, _ := checkExportMeta(value)
+	return target, true
+}
+
+// checkExportTarget checks that target is another existing deployment the
+// project has allowlisted for imports.
+func checkExportTarget(prj *Project, target string) *failure {
+	if target == currentEnv().ContractId {
+		return fail(errcode.InvalidValue, "cannot export a project to the same contract")
+	}
+	if !contractExists(target) {
+		return fail(errcode.NotFound, fmt.Sprintf("target contract not found: %s", target))
+	}
+	if !isICCAllowlisted(prj.ID, iccTarget(target, "project_import")) {
+		return fail(errcode.Unauthorized, fmt.Sprintf("export target %s is not allowlisted, add %s first", target, iccTarget(target, "project_import")))
+	}
+	return nil
+}
+
+// checkListsExportable refuses projects created before the whitelist index:
+// approvals granted back then cannot be listed, so an export would drop them.
+func checkListsExportable(prj *Project) *failure {
+	if !listsIndexed(prj.ID) {
+		return fail(errcode.NotEligible, fmt.Sprintf("project %d was created before its whitelist was indexed, so its approvals cannot be exported", prj.ID))
+	}
+	return nil
+}
+
+// exportThreshold is the approval an export needs: the ICC threshold, or every
+// vote of the snapshot when that is not above the project threshold.
+func exportThreshold(prj *Project) float64 {
+	if prj.Config.ICCThresholdPercent > prj.Config.ThresholdPercent {
+		return prj.Config.ICCThresholdPercent
+	}
+	return MaxThresholdPercent
+}
+
+// checkExportApproval checks a passed export against exportThreshold again.
+func checkExportApproval(prj *Project, prpsl *Proposal) *failure {
+	if _, ok := exportMetaTarget(prpsl.Outcome); !ok {
+		return nil
+	}
+	winning := loadProposalOption(prpsl.ID, uint32(prpsl.ResultOptionID))
+	if !thresholdReached(prj, prpsl, AmountToFloat(winning.WeightTotal), exportThreshold(prj)) {
+		return fail(errcode.NotEligible, fmt.Sprintf("export_project needs %.3f%% approval", exportThreshold(prj)))
+	}
+	return nil
+}
+
+// exportCoolingOff is the time in seconds an export waits after passing: at
+// least ExportCoolingOffHours and never less than the leave cooldown.
+func exportCoolingOff(prj *Project) int64 {
+	hours := uint64(ExportCoolingOffHours)
+	if prj.Config.LeaveCooldownHours > hours {
+		hours = prj.Config.LeaveCooldownHours
+	}
+	return int64(hours) * 3600
+}
+
+// parseExportMeta reads an export_project meta value: "contract" or
+// "contract:proposalId,proposalId".
+func parseExportMeta(value string) (string, []uint64) {
+	target, ids, f := checkExportMeta(value)
+	must(f)
+	return target, ids
+}
+
+// checkExportMeta is parseExportMeta without the abort.
+func checkExportMeta(value string) (string, []uint64, *failure) {
+	target, list, _ := strings.Cut(strings.TrimSpace(value), ":")
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", nil, fail(errcode.InvalidPayload, "export_project requires a target contract")
+	}
+	ids, f := checkExportProposalIDs(list, ",")
+	return target, ids, f
+}
+
+// parseExportProposalIDs parses a separated list of proposal ids.
+func parseExportProposalIDs(raw string, sep string) []uint64 {
+	ids, f := checkExportProposalIDs(raw, sep)
+	must(f)
+	return ids
+}
+
+// checkExportProposalIDs is parseExportProposalIDs without the abort.
+func checkExportProposalIDs(raw string, sep string) ([]uint64, *failure) {
+	var ids []uint64
+	for _, part := range strings.Split(raw, sep) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, fail(errcode.InvalidPayload, "invalid proposal id")
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) > MaxExportProposals {
+		return nil, fail(errcode.InvalidValue, fmt.Sprintf("export cannot carry more than %d proposals", MaxExportProposals))
+	}
+	return ids, nil
+}
+
+// exportProject empties prj into a bundle, persists the emptied project and
+// calls project_import on target with the bundle and the funds. proposalID is
+// the proposal that ordered the export. It returns the project id on the
+// target.
+func exportProject(prj *Project, target string, proposalIDs []uint64, by string, proposalID uint64) string {
+	if target == "" {
+		abort(errcode.InvalidPayload, "target contract required")
+	}
+	must(checkExportTarget(prj, target))
+	must(checkListsExportable(prj))
+	// Funds that have not reached the contract, or sit in L1 savings, cannot
+	// travel with an intent.
+	requireNoPendingHbdUnstakes(prj.ID)
+	must(checkNoHbdSavings(prj, "exporting"))
+
+	b := projectBundle{
+		SourceID: prj.ID,
+		Meta: ProjectMeta{
+			Owner:       prj.Owner,
+			Name:        prj.Name,
+			Description: prj.Description,
+			Paused:      prj.Paused,
+			Tx:          prj.Tx,
+			Metadata:    prj.Metadata,
+			URL:         prj.URL,
+		},
+		Config: prj.Config,
+		Finance: ProjectFinance{
+			FundsAsset:       prj.FundsAsset,
+			StakeTotal:       prj.StakeTotal,
+			MemberCount:      prj.MemberCount,
+			StakeWeights:     prj.StakeWeights,
+			AssetStakeTotals: prj.AssetStakeTotals,
+		},
+		JoinSeq:  currentJoinSeq(prj),
+		Treasury: map[sdk.Asset]Amount{},
+
+		Whitelist:    whitelistEntries(prj.ID),
+		ICCAllowlist: iccAllowlistEntries(prj.ID),
+	}
+
+	// Members on the roster. Members who joined before the roster existed
+	// and have not acted since are not on it: they stay behind with their
+	// stake and their share of the treasury and claim both with project_leave,
+	// as after a dissolution. The owner is added here so the project never
+	// arrives without them.
+	if hasOwner(prj) {
+		if _, ok := loadMember(prj.ID, prj.Owner); ok {
+			addRosterEntry(prj.ID, prj.Owner)
+		}
+	}
+	var stakeSum Amount
+	assetSums := map[sdk.Asset]Amount{}
+	for _, addr := range rosterMembers(prj.ID) {
+		m, ok := loadMember(prj.ID, addr)
+		if !ok {
+			abort(errcode.Internal, "member roster corrupted")
+		}
+		bm := bundleMember{Member: *m}
+		for inc := uint64(0); inc <= m.StakeIncrement; inc++ {
+			entry := ""
+			if ptr := sdk.StateGetObject(memberStakeHistoryKey(prj.ID, addr, inc)); ptr != nil {
+				entry = *ptr
+			}
+			bm.History = append(bm.History, entry)
+		}
+		b.Members = append(b.Members, bm)
+		stakeSum = safeAddAmount(stakeSum, m.Stake)
+		for asset, amount := range m.AssetStakes {
+			assetSums[asset] = safeAddAmount(assetSums[asset], amount)
+		}
+	}
+	if uint64(len(b.Members)) > prj.MemberCount || stakeSum > prj.StakeTotal {
+		abort(errcode.Internal, "member roster corrupted")
+	}
+	b.Finance.MemberCount = uint64(len(b.Members))
+	b.Finance.StakeTotal = stakeSum
+	b.Finance.AssetStakeTotals = nil
+	for _, asset := range sortedAssetKeys(assetSums) {
+		if assetSums[asset] > prj.AssetStakeTotals[asset] {
+			abort(errcode.Internal, "member roster corrupted")
+		}
+		if assetSums[asset] > 0 {
+			if b.Finance.AssetStakeTotals == nil {
+				b.Finance.AssetStakeTotals = map[sdk.Asset]Amount{}
+			}
+			b.Finance.AssetStakeTotals[asset] = assetSums[asset]
+		}
+	}
+
+	seen := map[uint64]bool{}
+	for _, id := range proposalIDs {
+		if seen[id] {
+			abort(errcode.InvalidValue, fmt.Sprintf("proposal %d listed twice", id))
+		}
+		seen[id] = true
+		p := loadProposal(id)
+		if p.ProjectID != prj.ID {
+			abort(errcode.InvalidValue, fmt.Sprintf("proposal %d belongs to another project", id))
+		}
+		// A passed proposal would arrive approved on the target's word alone;
+		// execute it first, or let it lapse and propose it again there.
+		if p.State != ProposalActive && p.State != ProposalClosed {
+			abort(errcode.ProposalState, fmt.Sprintf("proposal %d is %s", id, p.State))
+		}
+		b.Proposals = append(b.Proposals, bundleProposal{Proposal: *p, Options: loadProposalOptions(id, p.OptionCount)})
+	}
+	// Prerequisites that already executed are met and dropped; any other must
+	// travel too, so the target can keep the chain.
+	for i := range b.Proposals {
+		p := &b.Proposals[i].Proposal
+		var kept []uint64
+		for _, pre := range p.Prerequisites {
+			if seen[pre] {
+				kept = append(kept, pre)
+				continue
+			}
+			if loadProposal(pre).State != ProposalExecuted {
+				abort(errcode.InvalidValue, fmt.Sprintf("proposal %d requires proposal %d, which is not exported", p.ID, pre))
+			}
+		}
+		p.Prerequisites = kept
+	}
+
+	// Empty the source of everyone who travels. Dividends accrued so far are
+	// paid here; the target starts its own books.
+	members, votingTotal := prj.MemberCount, projectVotingStake(prj)
+	for _, m := range b.Members {
+		addr := m.Member.Address
+		exitDividends(prj.ID, addr, m.Member.Stake)
+		deleteAllStakeHistory(prj.ID, addr, m.Member.StakeIncrement)
+		deleteMember(prj.ID, addr)
+	}
+	for _, addr := range b.Whitelist {
+		deleteWhitelistEntry(prj.ID, addr)
+	}
+	for _, target := range b.ICCAllowlist {
+		deleteICCAllowlistEntry(prj.ID, target)
+	}
+	prj.MemberCount -= b.Finance.MemberCount
+	prj.StakeTotal -= stakeSum
+	for asset, amount := range assetSums {
+		if prj.AssetStakeTotals[asset] -= amount; prj.AssetStakeTotals[asset] == 0 {
+			delete(prj.AssetStakeTotals, asset)
+		}
+	}
+	if len(prj.AssetStakeTotals) == 0 {
+		prj.AssetStakeTotals = nil
+	}
+	// Those left behind keep the share claimDissolvedShare will pay them.
+	keep := func(balance Amount) Amount {
+		if prj.MemberCount == 0 {
+			return 0
+		}
+		if votingTotal > 0 {
+			return mulDivAmount(balance, projectVotingStake(prj), votingTotal)
+		}
+		return mulDivAmount(balance, Amount(prj.MemberCount), Amount(members))
+	}
+	for _, assetStr := range validAssets {
+		asset := AssetFromString(assetStr)
+		if balance := getTreasuryBalance(prj.ID, asset); balance > 0 {
+			kept := keep(balance)
+			if balance > kept {
+				b.Treasury[asset] = balance - kept
+				setTreasuryBalance(prj.ID, asset, kept)
+			}
+		}
+	}
+	prj.Paused = true
+	prj.Dissolved = true
+	saveProject(prj)
+
+	intents := make([]sdk.Intent, 0, len(validAssets))
+	for _, asset := range bundleAssets(&b) {
+		amount := bundleFunds(&b)[asset]
+		intents = append(intents, sdk.Intent{
+			Type: "transfer.allow",
+			Args: map[string]string{
+				"token": AssetToString(asset),
+				"limit": fmt.Sprintf("%.3f", AmountToFloat(amount)),
+			},
+		})
+	}
+	ret := callContract(target, "project_import", hex.EncodeToString(encodeProjectBundle(&b)), &sdk.ContractCallOptions{Intents: intents})
+	newID := ""
+	if ret != nil {
+		newID = strings.TrimSpace(*ret)
+	}
+	emitProjectExportedEvent(prj.ID, proposalID, by, target, newID, len(b.Members), len(b.Proposals))
+	return newID
+}
+
+// ImportProject restores a project exported by another deployment. It is
+// called by an export_project proposal, with the bundle as payload and the project's funds
+// as transfer.allow intents, and returns the new project id.
+//
+//go:wasmexport project_import
+func ImportProject(payload *string) *string {
+	requireInitialized()
+	cfg := loadContractConfig()
+	if !cfg.ProjectCreationPublic && !isContractOwner(getActorAddress()) {
+		abort(errcode.Unauthorized, "only contract owner can create projects")
+	}
+	raw, err := hex.DecodeString(unwrapPayload(payload, "import payload required"))
+	if err != nil {
+		abort(errcode.InvalidPayload, "invalid import bundle")
+	}
+	b, err := decodeProjectBundle(raw)
+	if err != nil {
+		abort(errcode.InvalidPayload, fmt.Sprintf("invalid import bundle: %v", err))
+	}
+	validateProjectBundle(b)
+	drawBundleFunds(b)
+	// The exporter cannot know this deployment's fee, so the project pays it
+	// from its treasury.
+	if cfg.CreationFee > 0 {
+		if b.Treasury[cfg.CreationFeeAsset] < cfg.CreationFee {
+			abort(errcode.InsufficientFunds, fmt.Sprintf("project creation fee requires a treasury of at least %f %s", AmountToFloat(cfg.CreationFee), cfg.CreationFeeAsset.String()))
+		}
+		b.Treasury[cfg.CreationFeeAsset] -= cfg.CreationFee
+		sdk.HiveTransfer(cfg.Owner, AmountToInt64(cfg.CreationFee), cfg.CreationFeeAsset)
+	}
+
+	id := getCount(ProjectsCount)
+	setCount(ProjectsCount, id+1)
+	txID := ""
+	if txPtr := sdk.GetEnvKey("tx.id"); txPtr != nil {
+		txID = *txPtr
+	}
+	prj := Project{
+		ID:          id,
+		Owner:       b.Meta.Owner,
+		Name:        b.Meta.Name,
+		Description: b.Meta.Description,
+		URL:         b.Meta.URL,
+		Config:      b.Config,
+		Metadata:    b.Meta.Metadata,
+		FundsAsset:  b.Finance.FundsAsset,
+		Paused:      b.Meta.Paused,
+		Tx:          txID,
+		StakeTotal:  b.Finance.StakeTotal,
+		MemberCount: b.Finance.MemberCount,
+
+		StakeWeights:     b.Finance.StakeWeights,
+		AssetStakeTotals: b.Finance.AssetStakeTotals,
+	}
+	saveProject(&prj)
+	markListsIndexed(id)
+	setCount(projectJoinSeqKey(id), b.JoinSeq)
+	stakeSnap := projectVotingStake(&prj)
+	now := nowUnix()
+	for _, asset := range sortedAssetKeys(b.Treasury) {
+		setTreasuryBalance(id, asset, b.Treasury[asset])
+	}
+	for i := range b.Members {
+		m := &b.Members[i].Member
+		saveMember(id, m)
+		for inc, entry := range b.Members[i].History {
+			if entry != "" {
+				sdk.StateSetObject(memberStakeHistoryKey(id, m.Address, uint64(inc)), entry)
+			}
+		}
+	}
+	for _, addr := range b.Whitelist {
+		setWhitelistEntry(id, addr)
+	}
+	for _, target := range b.ICCAllowlist {
+		setICCAllowlistEntry(id, target)
+	}
+
+	// Ids are assigned up front so prerequisites can point at proposals
+	// listed later in the bundle.
+	first := getCount(ProposalsCount)
+	newIDs := make(map[uint64]uint64, len(b.Proposals))
+	for i := range b.Proposals {
+		newIDs[b.Proposals[i].Proposal.ID] = first + uint64(i)
+	}
+	setCount(ProposalsCount, first+uint64(len(b.Proposals)))
+	mapped := make([]string, 0, len(b.Proposals))
+	for i := range b.Proposals {
+		bp := &b.Proposals[i]
+		oldID := bp.Proposal.ID
+		p := bp.Proposal
+		p.ID = newIDs[oldID]
+		p.ProjectID = id
+		for j, pre := range p.Prerequisites {
+			p.Prerequisites[j] = newIDs[pre]
+		}
+		if p.State == ProposalActive {
+			must(checkOutcomeMeta(&prj, p.Outcome))
+			// The count restarts against the imported membership, as if the
+			// proposal had been created here.
+			if p.CreatedAt > now {
+				p.CreatedAt = now
+			}
+			p.MemberCountSnapshot = uint(prj.MemberCount)
+			p.StakeSnapshot = stakeSnap
+			p.JoinSeqSnapshot = b.JoinSeq
+			p.StakeWeights = copyStakeWeights(prj.StakeWeights)
+			votingSystem := prj.Config.VotingSystem
+			p.VotingSystem = &votingSystem
+			p.VoterCount = 0
+			p.QuorumReached = false
+			p.VotedStake = 0
+			p.VotedWeight = 0
+			p.ResultOptionID = 0
+			p.ExecutableAt = 0
+			for idx := range bp.Options {
+				bp.Options[idx].WeightTotal = 0
+				bp.Options[idx].VoterCount = 0
+			}
+		}
+		saveProposal(&p)
+		for idx := range bp.Options {
+			saveProposalOption(p.ID, uint32(idx), &bp.Options[idx])
+		}
+		mapped = append(mapped, fmt.Sprintf("%d:%d", oldID, p.ID))
+	}
+
+	callerStr := currentEnv().Caller.String()
+	emitProjectImportedEvent(id, callerStr, b.SourceID, len(b.Members), strings.Join(mapped, ","))
+	if cfg.CreationFee > 0 {
+		emitCreationFeeEvent(id, callerStr, cfg.Owner.String(), AmountToFloat(cfg.CreationFee), cfg.CreationFeeAsset.String())
+	}
+	return strptr(strconv.FormatUint(id, 10))
+}
+
+// validateProjectBundle checks that a bundle describes a consistent project.
+func validateProjectBundle(b *projectBundle) {
+	if b.Meta.Dissolved {
+		abort(errcode.InvalidValue, "cannot import a dissolved project")
+	}
+	// The rules project_create applies to its payload.
+	validateProjectText(b.Meta.Name, b.Meta.Description, b.Meta.Metadata, b.Meta.URL)
+	normalizeProjectConfig(&b.Config)
+	if c := b.Config.MembershipNFTContract; c != nil && !contractExists(*c) {
+		abort(errcode.NotFound, fmt.Sprintf("membership NFT contract not found: %s", *c))
+	}
+	if b.Config.MembershipNFT != nil {
+		validateTokenId(*b.Config.MembershipNFT)
+	}
+	funds := b.Finance.FundsAsset
+	if !isValidAsset(AssetToString(funds)) || funds == sdk.AssetHbdSavings {
+		abort(errcode.WrongAsset, "import bundle has an invalid funds asset")
+	}
+	for asset, w := range b.Finance.StakeWeights {
+		_, f := checkStakeAssetName(AssetToString(asset))
+		must(f)
+		if asset == funds || !(w >= 0 && w <= MaxStakeWeight) {
+			abort(errcode.InvalidValue, "import bundle has an invalid stake weight")
+		}
+	}
+	if uint64(len(b.Members)) != b.Finance.MemberCount {
+		abort(errcode.InvalidValue, "import bundle member count mismatch")
+	}
+	if b.Meta.Owner != "" {
+		validateAddress(b.Meta.Owner)
+	}
+	var stakeSum Amount
+	assetSums := map[sdk.Asset]Amount{}
+	addrs := map[sdk.Address]bool{}
+	seqs := map[uint64]bool{}
+	for i := range b.Members {
+		m := &b.Members[i].Member
+		validateAddress(m.Address)
+		if addrs[m.Address] || seqs[m.JoinSeq] || m.JoinSeq >= b.JoinSeq {
+			abort(errcode.InvalidValue, "import bundle has conflicting members")
+		}
+		addrs[m.Address], seqs[m.JoinSeq] = true, true
+		normalizeBundleMember(&b.Members[i])
+		stakeSum = safeAddAmount(stakeSum, m.Stake)
+		for asset, amount := range m.AssetStakes {
+			assetSums[asset] = safeAddAmount(assetSums[asset], amount)
+		}
+	}
+	if b.Meta.Owner != "" && !addrs[b.Meta.Owner] {
+		abort(errcode.InvalidValue, "import bundle owner is not a member")
+	}
+	if stakeSum != b.Finance.StakeTotal {
+		abort(errcode.InvalidValue, "import bundle stake total mismatch")
+	}
+	for _, asset := range sortedAssetKeys(assetSums) {
+		if assetSums[asset] != b.Finance.AssetStakeTotals[asset] {
+			abort(errcode.InvalidValue, "import bundle stake total mismatch")
+		}
+	}
+	for asset, amount := range b.Finance.AssetStakeTotals {
+		if amount != assetSums[asset] {
+			abort(errcode.InvalidValue, "import bundle stake total mismatch")
+		}
+	}
+	for asset, amount := range b.Treasury {
+		if !isValidAsset(AssetToString(asset)) || amount < 0 {
+			abort(errcode.InvalidValue, "import bundle has an invalid treasury")
+		}
+	}
+	if b.Treasury[AssetFromString("hbd_savings")] > 0 {
+		abort(errcode.InvalidValue, "import bundle cannot carry hbd savings")
+	}
+	listed := map[sdk.Address]bool{}
+	for _, addr := range b.Whitelist {
+		validateAddress(addr)
+		if listed[addr] || addrs[addr] {
+			abort(errcode.InvalidValue, "import bundle has an invalid whitelist")
+		}
+		listed[addr] = true
+	}
+	targets := map[string]bool{}
+	for _, target := range b.ICCAllowlist {
+		parsed, f := checkICCAllowlist("icc_allowlist", target, false)
+		if f != nil || len(parsed) != 1 || parsed[0] != target || targets[target] {
+			abort(errcode.InvalidValue, "import bundle has an invalid ICC allowlist")
+		}
+		targets[target] = true
+	}
+	bundled := map[uint64]bool{}
+	for _, bp := range b.Proposals {
+		if bundled[bp.Proposal.ID] {
+			abort(errcode.InvalidValue, "import bundle lists a proposal twice")
+		}
+		bundled[bp.Proposal.ID] = true
+	}
+	for _, bp := range b.Proposals {
+		p := bp.Proposal
+		if p.ProjectID != b.SourceID || (p.State != ProposalActive && p.State != ProposalClosed) {
+			abort(errcode.InvalidValue, "import bundle has an invalid proposal")
+		}
+		if uint32(len(bp.Options)) != p.OptionCount || p.OptionCount < 2 {
+			abort(errcode.InvalidValue, "import bundle has an invalid proposal")
+		}
+		if len(p.Name) > MaxNameLength || len(p.Description) > MaxDescriptionLength || len(p.Metadata) > MaxDescriptionLength || len(p.URL) > MaxURLLength {
+			abort(errcode.InvalidValue, "import bundle has an invalid proposal")
+		}
+		if p.DurationHours == 0 || p.DurationHours > MaxProposalDurationHours {
+			abort(errcode.InvalidValue, "import bundle has an invalid proposal")
+		}
+		if p.Creator != "" {
+			validateAddress(p.Creator)
+		}
+		if p.Outcome != nil {
+			for _, po := range p.Outcome.Payout {
+				validateAddress(po.Address)
+			}
+		}
+		for _, pre := range p.Prerequisites {
+			if !bundled[pre] {
+				abort(errcode.InvalidValue, "import bundle has a proposal requiring one it does not carry")
+			}
+		}
+	}
+}
+
+// normalizeBundleMember checks an imported member against what the bundle
+// brings and clamps what only the source could vouch for. Timestamps must not
+// lie ahead, a pending unstake must fit the stake, and the latest stake
+// history entry must equal the stake. Earlier entries are capped at the
+// current stake, since only that travels with the call and they would
+// otherwise lend voting weight on carried proposals. Reputation is capped at
+// ReputationBlendCap, above which it adds no weight here either, and vote
+// locks are dropped with the ballots.
+func normalizeBundleMember(bm *bundleMember) {
+	m := &bm.Member
+	now := nowUnix()
+	invalid := func() {
+		abort(errcode.InvalidValue, fmt.Sprintf("import bundle has an invalid member: %s", m.Address))
+	}
+	for _, ts := range []int64{m.JoinedAt, m.LastActionAt, m.ExitRequested, m.UnstakeRequested, m.ReputationAt} {
+		if ts < 0 || ts > now {
+			invalid()
+		}
+	}
+	if m.Stake < 0 || m.UnstakePending < 0 || m.UnstakePending > m.Stake || (m.UnstakeRequested == 0 && m.UnstakePending != 0) {
+		invalid()
+	}
+	for _, amount := range m.AssetStakes {
+		if amount < 0 {
+			invalid()
+		}
+	}
+	if uint64(len(bm.History)) != m.StakeIncrement+1 {
+		invalid()
+	}
+	var last int64
+	for inc, raw := range bm.History {
+		if raw == "" {
+			if uint64(inc) == m.StakeIncrement {
+				invalid()
+			}
+			continue
+		}
+		entry := parseStakeHistoryEntry(raw)
+		if entry == nil || entry.Stake < 0 || entry.Timestamp < last || entry.Timestamp > now {
+			invalid()
+		}
+		for _, amount := range entry.Assets {
+			if amount < 0 {
+				invalid()
+			}
+		}
+		last = entry.Timestamp
+		if uint64(inc) == m.StakeIncrement {
+			if entry.Stake != m.Stake || formatAssetStakes(entry.Assets) != formatAssetStakes(m.AssetStakes) {
+				invalid()
+			}
+			continue
+		}
+		if entry.Stake > m.Stake {
+			entry.Stake = m.Stake
+		}
+		for asset, amount := range entry.Assets {
+			if amount > m.AssetStakes[asset] {
+				entry.Assets[asset] = m.AssetStakes[asset]
+			}
+		}
+		bm.History[inc] = formatStakeHistoryEntry(entry)
+	}
+	if m.Reputation < 0 {
+		m.Reputation = 0
+	}
+	if m.Reputation > ReputationBlendCap {
+		m.Reputation = ReputationBlendCap
+	}
+	m.VoteLockUntil = 0
+}
+
+// drawBundleFunds pulls the funds a bundle accounts for. Each asset needs one
+// transfer.allow intent for exactly its total, and no other intent is accepted.
+func drawBundleFunds(b *projectBundle) {
+	need := bundleFunds(b)
+	allows := getAllTransferAllows()
+	got := map[sdk.Asset]bool{}
+	for _, ta := range allows {
+		if got[ta.Token] {
+			abort(errcode.InvalidIntent, fmt.Sprintf("duplicate %s transfer intent", ta.Token.String()))
+		}
+		got[ta.Token] = true
+		if FloatToAmount(ta.Limit) != need[ta.Token] {
+			abort(errcode.InvalidIntent, fmt.Sprintf("import requires exactly %.3f %s", AmountToFloat(need[ta.Token]), ta.Token.String()))
+		}
+	}
+	for _, asset := range bundleAssets(b) {
+		if !got[asset] {
+			abort(errcode.InvalidIntent, fmt.Sprintf("import requires exactly %.3f %s", AmountToFloat(need[asset]), asset.String()))
+		}
+		sdk.HiveDraw(AmountToInt64(need[asset]), asset)
+	}
+}
+
+// bundleFunds totals what the contract holds for a bundle per asset: the
+// treasury and every member's stake.
+func bundleFunds(b *projectBundle) map[sdk.Asset]Amount {
+	funds := map[sdk.Asset]Amount{}
+	for asset, amount := range b.Treasury {
+		funds[asset] = safeAddAmount(funds[asset], amount)
+	}
+	for _, bm := range b.Members {
+		funds[b.Finance.FundsAsset] = safeAddAmount(funds[b.Finance.FundsAsset], bm.Member.Stake)
+		for asset, amount := range bm.Member.AssetStakes {
+			funds[asset] = safeAddAmount(funds[asset], amount)
+		}
+	}
+	return funds
+}
+
+// bundleAssets lists the assets with a positive total, sorted.
+func bundleAssets(b *projectBundle) []sdk.Asset {
+	funds := bundleFunds(b)
+	assets := make([]sdk.Asset, 0, len(funds))
+	for _, asset := range sortedAssetKeys(funds) {
+		if funds[asset] > 0 {
+			assets = append(assets, asset)
+		}
+	}
+	return assets
+}
+
+// -----------------------------------------------------------------------------
+// Bundle encoding
+// -----------------------------------------------------------------------------
+
+// encodeProjectBundle writes the bundle with the record encoders of codec.go,
+// each record length-prefixed.
+func encodeProjectBundle(b *projectBundle) []byte {
+	w := newWriter()
+	w.buf.WriteByte(bundleFormat)
+	w.writeUint64(b.SourceID)
+	w.writeString(string(EncodeProjectMeta(&b.Meta)))
+	w.writeString(string(EncodeProjectConfig(&b.Config)))
+	w.writeString(string(EncodeProjectFinance(&b.Finance)))
+	w.writeUint64(b.JoinSeq)
+	w.writeAssetAmountMap(b.Treasury)
+	w.writeVarUint(uint64(len(b.Members)))
+	for i := range b.Members {
+		w.writeString(string(EncodeMember(&b.Members[i].Member)))
+		w.writeVarUint(uint64(len(b.Members[i].History)))
+		for _, entry := range b.Members[i].History {
+			w.writeString(entry)
+		}
+	}
+	w.writeVarUint(uint64(len(b.Proposals)))
+	for i := range b.Proposals {
+		bp := &b.Proposals[i]
+		w.writeString(string(EncodeProposal(&bp.Proposal)))
+		w.writeVarUint(uint64(len(bp.Options)))
+		for j := range bp.Options {
+			w.writeString(string(EncodeProposalOption(&bp.Options[j])))
+		}
+	}
+	w.writeVarUint(uint64(len(b.Whitelist)))
+	for _, addr := range b.Whitelist {
+		w.writeString(AddressToString(addr))
+	}
+	w.writeVarUint(uint64(len(b.ICCAllowlist)))
+	for _, target := range b.ICCAllowlist {
+		w.writeString(target)
+	}
+	return w.bytes()
+}
+
+// decodeProjectBundle is the inverse of encodeProjectBundle. Every count is
+// bounded by the bytes left, so a crafted prefix cannot force a large
+// allocation.
+func decodeProjectBundle(data []byte) (*projectBundle, error) {
+	r := newReader(data)
+	format, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+	if format != bundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format %d", format)
+	}
+	b := &projectBundle{}
+	if b.SourceID, err = r.readUint64(); err != nil {
+		return nil, err
+	}
+	raw, err := r.readString()
+	if err != nil {
+		return nil, err
+	}
+	meta, err := DecodeProjectMeta([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	b.Meta = *meta
+	if raw, err = r.readString(); err != nil {
+		return nil, err
+	}
+	cfg, err := DecodeProjectConfig([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	b.Config = *cfg
+	if raw, err = r.readString(); err != nil {
+		return nil, err
+	}
+	fin, err := DecodeProjectFinance([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+	b.Finance = *fin
+	if b.JoinSeq, err = r.readUint64(); err != nil {
+		return nil, err
+	}
+	if b.Treasury, err = r.readAssetAmountMap(); err != nil {
+		return nil, err
+	}
+	members, err := readBundleCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < members; i++ {
+		if raw, err = r.readString(); err != nil {
+			return nil, err
+		}
+		m, err := DecodeMember([]byte(raw))
+		if err != nil {
+			return nil, err
+		}
+		bm := bundleMember{Member: *m}
+		entries, err := readBundleCount(r)
+		if err != nil {
+			return nil, err
+		}
+		for j := uint64(0); j < entries; j++ {
+			entry, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			bm.History = append(bm.History, entry)
+		}
+		b.Members = append(b.Members, bm)
+	}
+	proposals, err := readBundleCount(r)
+	if err != nil {
+		return nil, err
+	}
+	if proposals > MaxExportProposals {
+		return nil, errors.New("too many proposals")
+	}
+	for i := uint64(0); i < proposals; i++ {
+		if raw, err = r.readString(); err != nil {
+			return nil, err
+		}
+		p, err := DecodeProposal([]byte(raw))
+		if err != nil {
+			return nil, err
+		}
+		bp := bundleProposal{Proposal: *p}
+		options, err := readBundleCount(r)
+		if err != nil {
+			return nil, err
+		}
+		if options > MaxProposalOptions {
+			return nil, errors.New("too many options")
+		}
+		for j := uint64(0); j < options; j++ {
+			if raw, err = r.readString(); err != nil {
+				return nil, err
+			}
+			opt, err := DecodeProposalOption([]byte(raw))
+			if err != nil {
+				return nil, err
+			}
+			bp.Options = append(bp.Options, *opt)
+		}
+		b.Proposals = append(b.Proposals, bp)
+	}
+	whitelist, err := readBundleCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < whitelist; i++ {
+		if raw, err = r.readString(); err != nil {
+			return nil, err
+		}
+		b.Whitelist = append(b.Whitelist, AddressFromString(raw))
+	}
+	allowlist, err := readBundleCount(r)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < allowlist; i++ {
+		if raw, err = r.readString(); err != nil {
+			return nil, err
+		}
+		b.ICCAllowlist = append(b.ICCAllowlist, raw)
+	}
+	if r.pos != len(r.data) {
+		return nil, errors.New("trailing bytes")
+	}
+	return b, nil
+}
+
+// readBundleCount reads a list length; every entry takes at least one byte.
+func readBundleCount(r *binReader) (uint64, error) {
+	n, err := r.readVarUint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(r.data)-r.pos) {
+		return 0, errors.New("length prefix exceeds data")
+	}
+	return n, nil
+}
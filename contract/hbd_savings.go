@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// -----------------------------------------------------------------------------
+// HBD savings
+// -----------------------------------------------------------------------------
+//
+// The hbd and hbd_savings treasury entries mirror the contract's ledger balances,
+// so moving between them always goes through the host. Staking is immediate.
+// Unstaking is not: the ledger pays the HBD out only after the savings
+// withdrawal period, so the amount leaves hbd_savings at once but is parked in
+// a pending list and credited to hbd only when it matures. Until then it cannot
+// be paid out, distributed or sent along with an inter-contract call.
+//
+// The ledger pays interest on the contract's savings as a whole. hbd_savings is
+// only ever held in treasuries (it cannot be a funds or stake asset, nor be
+// distributed), so whatever the ledger holds beyond the booked treasury
+// balances is interest. recognizeHbdInterest spreads it over the booked
+// balances through a per-unit accumulator, like dividends over stake, and each
+// treasury is credited its share whenever its balance is booked.
+//
+// The host functions are only linked into builds made with -tags hbdsavings
+// (see sdk.HbdSavingsSupported); other builds reject both outcomes.
+
+// parseHbdSavingsAmount validates the amount of a treasury_stake_hbd or
+// treasury_unstake_hbd outcome.
+func parseHbdSavingsAmount(action, value string) Amount {
+	amount, f := checkHbdSavingsAmount(action, value)
+	must(f)
+	return amount
+}
+
+// checkHbdSavingsAmount is parseHbdSavingsAmount without the abort.
+func checkHbdSavingsAmount(action, value string) (Amount, *failure) {
+	if !sdk.HbdSavingsSupported() {
+		return 0, fail(errcode.InvalidValue, fmt.Sprintf("%s is not supported by this build of the contract", action))
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return 0, fail(errcode.InvalidPayload, fmt.Sprintf("invalid %s amount", action))
+	}
+	amount, f := checkAmount(v)
+	if f != nil {
+		return 0, f
+	}
+	if amount <= 0 {
+		return 0, fail(errcode.InvalidValue, fmt.Sprintf("%s amount must be positive", action))
+	}
+	return amount, nil
+}
+
+// stakeTreasuryHbd applies a passed treasury_stake_hbd=<amount> outcome.
+func stakeTreasuryHbd(prj *Project, proposalID uint64, value string) {
+	amount := parseHbdSavingsAmount("treasury_stake_hbd", value)
+	if !removeTreasuryFunds(prj.ID, sdk.AssetHbd, amount) {
+		abort(errcode.InsufficientFunds, "insufficient hbd funds in treasury")
+	}
+	sdk.HiveStakeHbd(AmountToInt64(amount))
+	addTreasuryFunds(prj.ID, sdk.AssetHbdSavings, amount)
+	emitHbdStakedEvent(prj.ID, proposalID, AmountToFloat(amount))
+}
+
+// unstakeTreasuryHbd applies a passed treasury_unstake_hbd=<amount> outcome.
+func unstakeTreasuryHbd(prj *Project, proposalID uint64, value string, now int64) {
+	amount := parseHbdSavingsAmount("treasury_unstake_hbd", value)
+	pending := loadHbdUnstakes(prj.ID)
+	if len(pending) >= MaxPendingHbdUnstakes {
+		abort(errcode.InvalidValue, fmt.Sprintf("cannot have more than %d pending hbd unstakes", MaxPendingHbdUnstakes))
+	}
+	if !removeTreasuryFunds(prj.ID, sdk.AssetHbdSavings, amount) {
+		abort(errcode.InsufficientFunds, "insufficient hbd_savings funds in treasury")
+	}
+	sdk.HiveUnstakeHbd(AmountToInt64(amount))
+	maturesAt := now + int64(HbdUnstakePeriodHours)*3600
+	pending = append(pending, HbdUnstake{Amount: amount, MaturesAt: maturesAt})
+	saveHbdUnstakes(prj.ID, pending)
+	emitHbdUnstakedEvent(prj.ID, proposalID, AmountToFloat(amount), maturesAt)
+}
+
+// releaseHbdUnstakes credits every pending withdrawal that has matured by now
+// to the hbd treasury balance. Entries are appended with a fixed period, so
+// they mature in order and the first unmatured one ends the scan.
+func releaseHbdUnstakes(projectID uint64, now int64) {
+	pending := loadHbdUnstakes(projectID)
+	released := 0
+	var total Amount
+	for _, u := range pending {
+		if u.MaturesAt > now {
+			break
+		}
+		total = safeAddAmount(total, u.Amount)
+		released++
+	}
+	if released == 0 {
+		return
+	}
+	addTreasuryFunds(projectID, sdk.AssetHbd, total)
+	saveHbdUnstakes(projectID, pending[released:])
+	emitHbdUnstakeReleasedEvent(projectID, AmountToFloat(total))
+}
+
+// requireNoPendingHbdUnstakes aborts while HBD is still on its way out of
+// savings; a liquidation would otherwise skip it.
+func requireNoPendingHbdUnstakes(projectID uint64) {
+	pending := loadHbdUnstakes(projectID)
+	if len(pending) == 0 {
+		return
+	}
+	last := pending[len(pending)-1].MaturesAt
+	abort(errcode.Locked, fmt.Sprintf("hbd unstake pending until %s", time.Unix(last, 0).UTC().Format(time.RFC3339)))
+}
+
+// recognizeHbdInterest spreads the interest the ledger paid on the contract's
+// savings since the last call over the booked treasury balances. Called at the
+// start of a proposal execution, before any transfer, when the ledger holds
+// exactly the booked balances, the interest not yet credited and any new
+// interest.
+func recognizeHbdInterest() {
+	pool := loadHbdSavingsPool()
+	if pool.Booked <= 0 {
+		return
+	}
+	self := sdk.Address("contract:" + currentEnv().ContractId)
+	interest := Amount(sdk.GetBalance(self, sdk.AssetHbdSavings)) - pool.Booked - pool.Unpaid
+	if interest <= 0 {
+		return
+	}
+	delta := new(big.Int).Mul(big.NewInt(int64(interest)), big.NewInt(DividendPrecision))
+	delta.Quo(delta, big.NewInt(int64(pool.Booked)))
+	pool.Acc.Add(pool.Acc, delta)
+	pool.Unpaid = safeAddAmount(pool.Unpaid, interest)
+	saveHbdSavingsPool(pool)
+}
+
+// creditHbdInterest recognizes new interest and credits the project's share
+// to its hbd_savings balance.
+func creditHbdInterest(projectID uint64) {
+	recognizeHbdInterest()
+	if balance := getTreasuryBalance(projectID, sdk.AssetHbdSavings); balance > 0 {
+		setTreasuryBalance(projectID, sdk.AssetHbdSavings, balance)
+	}
+}
+
+// bookHbdSavings books a treasury's new hbd_savings balance against the pool:
+// it credits the interest the old balance earned since it was last booked,
+// moves the pool's booked total by the change and re-bases the treasury's
+// debt. Returns the new balance including that interest.
+func bookHbdSavings(projectID uint64, amount Amount) Amount {
+	pool := loadHbdSavingsPool()
+	old := getTreasuryBalance(projectID, sdk.AssetHbdSavings)
+	accrued := new(big.Int).Mul(big.NewInt(int64(old)), pool.Acc)
+	accrued.Sub(accrued, loadHbdInterestDebt(projectID))
+	// Carry the sub-unit remainder in the debt, as settleDividends does.
+	whole, rem := new(big.Int).QuoRem(accrued, big.NewInt(DividendPrecision), new(big.Int))
+	var interest Amount
+	if whole.Sign() > 0 {
+		if !whole.IsInt64() {
+			abort(errcode.Internal, "amount overflow")
+		}
+		interest = Amount(whole.Int64())
+		if interest > pool.Unpaid {
+			interest = pool.Unpaid
+		}
+	}
+	balance := safeAddAmount(amount, interest)
+	if pool.Booked < old {
+		abort(errcode.Internal, "accounting error: hbd savings pool mismatch")
+	}
+	pool.Booked = safeAddAmount(pool.Booked-old, balance)
+	pool.Unpaid -= interest
+	debt := new(big.Int).Mul(big.NewInt(int64(balance)), pool.Acc)
+	debt.Sub(debt, rem)
+	saveHbdInterestDebt(projectID, debt)
+	saveHbdSavingsPool(pool)
+	if interest > 0 {
+		emitHbdInterestEvent(projectID, AmountToFloat(interest))
+	}
+	return balance
+}
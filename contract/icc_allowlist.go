@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"okinoko_dao/errcode"
+	"strings"
+)
+
+// iccTarget names an inter-contract call target as stored in the allowlist.
+func iccTarget(contractAddr, function string) string {
+	return contractAddr + ":" + function
+}
+
+// parseICCAllowlist reads an icc_allowlist_add/_remove meta value: a comma
+// separated list of contract:function pairs. Function names never contain a
+// colon, so the last one splits the pair. Adding requires the contract to exist.
+func parseICCAllowlist(action, value string, mustExist bool) []string {
+	targets, f := checkICCAllowlist(action, value, mustExist)
+	must(f)
+	return targets
+}
+
+// checkICCAllowlist is parseICCAllowlist without the abort.
+func checkICCAllowlist(action, value string, mustExist bool) ([]string, *failure) {
+	targets := []string{}
+	seen := map[string]bool{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		sep := strings.LastIndex(entry, ":")
+		if sep <= 0 || sep == len(entry)-1 {
+			return nil, fail(errcode.InvalidPayload, fmt.Sprintf("%s entries must be contract:function", action))
+		}
+		contractAddr := strings.TrimSpace(entry[:sep])
+		function := strings.TrimSpace(entry[sep+1:])
+		if contractAddr == "" || function == "" || strings.ContainsAny(entry, "|; \t") {
+			return nil, fail(errcode.InvalidPayload, fmt.Sprintf("%s entries must be contract:function", action))
+		}
+		if mustExist && !contractExists(contractAddr) {
+			return nil, fail(errcode.NotFound, fmt.Sprintf("ICC contract not found: %s", contractAddr))
+		}
+		target := iccTarget(contractAddr, function)
+		if seen[target] {
+			continue
+		}
+		seen[target] = true
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		return nil, fail(errcode.InvalidPayload, fmt.Sprintf("%s requires contract:function entries", action))
+	}
+	if len(targets) > MaxICCAllowlistEntries {
+		return nil, fail(errcode.InvalidValue, fmt.Sprintf("%s cannot exceed %d entries per proposal", action, MaxICCAllowlistEntries))
+	}
+	return targets, nil
+}
+
+// iccOutsideAllowlist reports whether an outcome calls any contract:function
+// pair the project has not allowlisted.
+func iccOutsideAllowlist(projectID uint64, outcome *ProposalOutcome) bool {
+	if outcome == nil {
+		return false
+	}
+	for _, icc := range outcome.ICC {
+		if !isICCAllowlisted(projectID, iccTarget(icc.ContractAddress, icc.Function)) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalThreshold is the threshold percent a proposal's winning option must
+// reach: the project threshold, raised to the ICC threshold when the proposal
+// calls a target outside the allowlist, and to exportThreshold for an export.
+func approvalThreshold(prj *Project, prpsl *Proposal) float64 {
+	threshold := prj.Config.ThresholdPercent
+	if prj.Config.ICCThresholdPercent > threshold && iccOutsideAllowlist(prj.ID, prpsl.Outcome) {
+		threshold = prj.Config.ICCThresholdPercent
+	}
+	if _, ok := exportMetaTarget(prpsl.Outcome); ok && exportThreshold(prj) > threshold {
+		threshold = exportThreshold(prj)
+	}
+	return threshold
+}
+
+// thresholdReached checks a winning weight against percent of the proposal's
+// snapshot. Democratic projects weigh each member as one unit, so the
+// denominator is the member count at creation; stake projects use total stake.
+// Reputation-blended ballots count less than the stake behind them, so those
+// projects divide by the most the snapshot could cast instead: the blended
+// weight of the ballots plus the stake that has not voted, at full weight.
+func thresholdReached(prj *Project, prpsl *Proposal, weight float64, percent float64) bool {
+	var denom float64
+	switch proposalVotingSystem(prj, prpsl) {
+	case VotingSystemDemocratic:
+		denom = float64(prpsl.MemberCountSnapshot)
+	case VotingSystemStakeReputation:
+		unvoted := prpsl.StakeSnapshot - prpsl.VotedStake
+		if unvoted < 0 {
+			unvoted = 0
+		}
+		denom = AmountToFloat(unvoted + prpsl.VotedWeight)
+	default:
+		denom = AmountToFloat(prpsl.StakeSnapshot)
+	}
+	return denom > 0 && (weight/denom) >= (percent/100)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"okinoko_dao/errcode"
+	"strconv"
+	"strings"
+
+	"github.com/CosmWasm/tinyjson/jlexer"
+)
+
+// parseICCExpectation reads an expected-result predicate:
+//
+//	eq:<text>             the response equals text
+//	has:<text>            the response contains text
+//	min:<field>:<number>  the number at field of the JSON response is at least number
+//
+// field is a dotted path ("out.amount", "fills.0.qty"); empty reads the whole
+// response as a number. An empty predicate accepts any response.
+func parseICCExpectation(raw string) ICCExpectation {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ICCExpectation{}
+	}
+	if len(raw) > MaxICCExpectLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("ICC expectation exceeds maximum length of %d characters", MaxICCExpectLength))
+	}
+	kind, rest, ok := strings.Cut(raw, ":")
+	if !ok {
+		abort(errcode.InvalidPayload, "invalid ICC expectation (use eq:<text>, has:<text> or min:<field>:<number>)")
+	}
+	switch kind {
+	case "eq":
+		return ICCExpectation{Kind: ICCExpectExact, Value: rest}
+	case "has":
+		if rest == "" {
+			abort(errcode.InvalidValue, "ICC expectation has: needs a text")
+		}
+		return ICCExpectation{Kind: ICCExpectContains, Value: rest}
+	case "min":
+		sep := strings.LastIndex(rest, ":")
+		if sep < 0 {
+			abort(errcode.InvalidPayload, "ICC expectation min needs <field>:<number>")
+		}
+		field := strings.TrimSpace(rest[:sep])
+		value := strings.TrimSpace(rest[sep+1:])
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			abort(errcode.InvalidValue, fmt.Sprintf("invalid ICC expectation minimum %q", value))
+		}
+		return ICCExpectation{Kind: ICCExpectMin, Field: field, Value: value}
+	}
+	abort(errcode.InvalidPayload, fmt.Sprintf("unknown ICC expectation %q", kind))
+	return ICCExpectation{}
+}
+
+// String spells the predicate in the grammar parseICCExpectation reads.
+func (e ICCExpectation) String() string {
+	switch e.Kind {
+	case ICCExpectExact:
+		return "eq:" + e.Value
+	case ICCExpectContains:
+		return "has:" + e.Value
+	case ICCExpectMin:
+		return "min:" + e.Field + ":" + e.Value
+	}
+	return ""
+}
+
+// satisfiedBy reports whether a callee's response meets the predicate.
+func (e ICCExpectation) satisfiedBy(response string) bool {
+	switch e.Kind {
+	case ICCExpectExact:
+		return response == e.Value
+	case ICCExpectContains:
+		return strings.Contains(response, e.Value)
+	case ICCExpectMin:
+		text, ok := jsonPath(response, e.Field)
+		if !ok {
+			return false
+		}
+		got, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(got) {
+			return false
+		}
+		want, _ := strconv.ParseFloat(e.Value, 64)
+		return got >= want
+	}
+	return true
+}
+
+// checkICCResult aborts the execution when a call's response misses its
+// declared result. The abort reverts every outcome of the proposal.
+func checkICCResult(icc InterContractCall, response string) {
+	if icc.Expect.satisfiedBy(response) {
+		return
+	}
+	abort(errcode.ICCResult, fmt.Sprintf("ICC %s.%s returned %q, expected %s",
+		icc.ContractAddress, icc.Function, truncateICCResponse(response), icc.Expect.String()))
+}
+
+// jsonPath returns the scalar at a dotted path of a JSON document; numeric
+// segments index arrays. A response that is not valid JSON, nests too deeply
+// or repeats a field has no fields.
+func jsonPath(doc string, path string) (string, bool) {
+	r := &jlexer.Lexer{Data: []byte(doc)}
+	v, f := readJSONValue(r, 0)
+	r.Consumed()
+	if f != nil || r.Error() != nil {
+		return "", false
+	}
+	if path != "" {
+		for _, seg := range strings.Split(path, ".") {
+			switch node := v.(type) {
+			case map[string]interface{}:
+				v = node[seg]
+			case []interface{}:
+				i, err := strconv.Atoi(seg)
+				if err != nil || i < 0 || i >= len(node) {
+					return "", false
+				}
+				v = node[i]
+			default:
+				return "", false
+			}
+		}
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// truncateICCResponse caps a callee's response before it is logged.
+func truncateICCResponse(response string) string {
+	if len(response) > MaxICCResponseLog {
+		return response[:MaxICCResponseLog] + "..."
+	}
+	return response
+}
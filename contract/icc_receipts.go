@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+	"strings"
+)
+
+// parseICCReceive reads the assets an inter-contract call is declared to send
+// back, comma separated ("hbd,hive"). Only ledger assets can arrive through a
+// call, so hbd_savings is refused.
+func parseICCReceive(val string) []sdk.Asset {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil
+	}
+	var assets []sdk.Asset
+	seen := map[sdk.Asset]bool{}
+	for _, part := range strings.Split(val, ",") {
+		name := strings.ToLower(strings.TrimSpace(part))
+		if name == "" {
+			continue
+		}
+		if name != sdk.AssetHive.String() && name != sdk.AssetHbd.String() {
+			abort(errcode.InvalidValue, fmt.Sprintf("ICC can only receive hive or hbd, not %s", name))
+		}
+		asset := AssetFromString(name)
+		if seen[asset] {
+			abort(errcode.InvalidValue, fmt.Sprintf("ICC receive asset %s specified multiple times", name))
+		}
+		seen[asset] = true
+		assets = append(assets, asset)
+	}
+	return assets
+}
+
+// callWithReceipts runs an inter-contract call and credits what it sent back
+// to the project's treasury, for every asset the call declared in Receive.
+//
+// The contract's ledger balance is read before and after the call. Its
+// allowance had already been taken off the treasury and was still held by the
+// contract at the first read, so the credit is after - before + allowance:
+// what came back plus any part of the allowance the callee did not draw. The
+// contract is locked against re-entry for the duration (requireInitialized),
+// because a deposit made through a nested call into this contract would
+// otherwise be counted twice: once by its own entry point, once here.
+func callWithReceipts(prj *Project, icc InterContractCall, opts *sdk.ContractCallOptions) *string {
+	if len(icc.Receive) == 0 {
+		return callContract(icc.ContractAddress, icc.Function, icc.Payload, opts)
+	}
+	self := sdk.Address("contract:" + currentEnv().ContractId)
+	before := make([]int64, len(icc.Receive))
+	for i, asset := range icc.Receive {
+		before[i] = sdk.GetBalance(self, asset)
+	}
+	sdk.StateSetObject(ICCReceiptLockKey, "1")
+	ret := callContract(icc.ContractAddress, icc.Function, icc.Payload, opts)
+	sdk.StateDeleteObject(ICCReceiptLockKey)
+	for i, asset := range icc.Receive {
+		received := sdk.GetBalance(self, asset) - before[i] + AmountToInt64(icc.Assets[asset])
+		if received <= 0 {
+			continue
+		}
+		amount := Amount(received)
+		addTreasuryFunds(prj.ID, asset, amount)
+		emitFundsAdded(prj.ID, "contract:"+icc.ContractAddress, AmountToFloat(amount), AssetToString(asset), false)
+	}
+	return ret
+}
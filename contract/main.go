@@ -5,12 +5,7 @@
 
 package main
 
-import "okinoko_dao/sdk"
-
-// main is left empty on purpose
-func main() {
-
-}
+import "okinoko_dao/errcode"
 
 // -----------------------------------------------------------------------------
 // Contract Initialization
@@ -23,19 +18,24 @@ func main() {
 //go:wasmexport contract_init
 func ContractInit(payload *string) *string {
 	if isContractInitialized() {
-		sdk.Abort("contract already initialized")
+		abort(errcode.AlreadyInitialized, "contract already initialized")
 	}
 
-	// Parse permission parameter (unwrap from JSON)
-	permission := unwrapPayload(payload, "permission mode required (public or owner-only)")
+	// Parse permission parameter and optional event format (unwrap from JSON)
+	f := decodePayloadFields(payload, "permission mode required (public or owner-only)", "mode", "events")
+	permission := f.get(0)
 
-	// contract_init is one-shot and there is no meta action to change this later,
-	// so a typo ("Public", "pub", "owner_only") must not silently lock project
-	// creation to the deployer forever. Require an exact, known mode.
+	// A typo ("Public", "pub", "owner_only") must not silently lock project
+	// creation to the deployer until they notice and call contract_mode.
+	// Require an exact, known mode.
 	if permission != "public" && permission != "owner-only" {
-		sdk.Abort("permission mode must be exactly \"public\" or \"owner-only\"")
+		abort(errcode.InvalidValue, "permission mode must be exactly \"public\" or \"owner-only\"")
 	}
 	publicCreation := permission == "public"
+	eventFormat, ok := parseEventFormat(f.get(1))
+	if !ok {
+		abort(errcode.InvalidValue, "event format must be \"legacy\", \"json\" or \"both\"")
+	}
 
 	// Store contract config with caller as owner. The owner is encoded into a
 	// pipe-delimited config record, so guard against any delimiter bytes.
@@ -44,11 +44,12 @@ func ContractInit(payload *string) *string {
 	cfg := ContractConfig{
 		Owner:                 owner,
 		ProjectCreationPublic: publicCreation,
+		EventFormat:           eventFormat,
 	}
 	saveContractConfig(&cfg)
 
 	// Emit init event
-	emitInitEvent(cfg.Owner.String(), permission)
+	emitInitEvent(cfg.Owner.String(), permission, eventFormat)
 
 	if publicCreation {
 		return strptr("initialized with public project creation")
@@ -0,0 +1,48 @@
+//go:build native
+
+package main
+
+import (
+	"os"
+
+	"okinoko_dao/cli"
+	"okinoko_dao/emulator"
+)
+
+// Built with the native tag, the contract is the okinoko command running
+// against a local emulator (see cli.RunLocal):
+//
+//	go build -tags native -o okinoko-local ./contract
+func main() {
+	os.Exit(cli.RunLocal(os.Args[1:], daoMethods))
+}
+
+// daoMethods maps every wasm export onto its Go function. The emulator tests
+// and the local command both register it, and TestNativeMethodTable checks it
+// against the //go:wasmexport directives.
+var daoMethods = map[string]emulator.Method{
+	"contract_init":            ContractInit,
+	"contract_mode":            SetCreationMode,
+	"contract_transfer":        TransferContractOwnership,
+	"contract_accept":          AcceptContractOwnership,
+	"contract_renounce":        RenounceContractOwnership,
+	"contract_fee":             SetCreationFee,
+	"project_create":           CreateProject,
+	"project_join":             JoinProject,
+	"project_leave":            LeaveProject,
+	"project_funds":            AddFunds,
+	"project_unstake":          UnstakeProject,
+	"project_transfer":         TransferProjectOwnership,
+	"project_pause":            EmergencyPauseImmediate,
+	"project_import":           ImportProject,
+	"project_whitelist_add":    WhitelistMembers,
+	"project_whitelist_remove": RemoveWhitelistedMembers,
+	"proposal_create":          CreateProposal,
+	"proposals_vote":           VoteProposal,
+	"proposal_tally":           TallyProposal,
+	"proposal_execute":         ExecuteProposal,
+	"proposal_cancel":          CancelProposal,
+	"proposal_simulate":        SimulateProposal,
+	"reputation_claim":         ClaimReputation,
+	"dividend_claim":           ClaimDividends,
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Outcome checks
+// -----------------------------------------------------------------------------
+//
+// checkMetaValue holds the rules a meta action value must pass, shared by
+// proposal_create and ExecuteProposal so a proposal that could never execute
+// is turned away before anyone votes on it. checkMetaAction adds the rules
+// that depend on state a vote can outlive (payout locks, stakers, members,
+// treasury balances, pending unstakes) and is what execution runs right before
+// each action, on the project as the earlier actions left it. It reads that
+// state through a metaState: execution passes the stored state, which the
+// earlier actions have already changed, and proposal_simulate passes its
+// running copy, so both turn an action down for the same reasons.
+
+// checkOutcomeMeta runs checkMetaValue over every meta action of outcome in
+// sorted key order, so the first reported failure is deterministic.
+func checkOutcomeMeta(prj *Project, outcome *ProposalOutcome) *failure {
+	if outcome == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(outcome.Meta))
+	for k := range outcome.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, action := range keys {
+		if f := checkMetaValue(prj, outcome, action, outcome.Meta[action]); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// metaState is the project state beyond the project record that
+// checkMetaAction weighs an action against.
+type metaState interface {
+	balance(asset sdk.Asset) Amount
+	pendingUnstakes() int
+	isMember(addr sdk.Address) bool
+}
+
+// storedMetaState reads a project's stored state; execution checks against it
+// as the earlier actions left it.
+type storedMetaState struct {
+	projectID uint64
+}
+
+func (s storedMetaState) balance(asset sdk.Asset) Amount {
+	return getTreasuryBalance(s.projectID, asset)
+}
+
+func (s storedMetaState) pendingUnstakes() int {
+	return len(loadHbdUnstakes(s.projectID))
+}
+
+func (s storedMetaState) isMember(addr sdk.Address) bool {
+	_, exists := loadMember(s.projectID, addr)
+	return exists
+}
+
+// checkMetaAction reports why action=value could not be applied to prj in
+// state st. outcome is the proposal outcome the action belongs to.
+func checkMetaAction(prj *Project, st metaState, outcome *ProposalOutcome, action, value string) *failure {
+	if f := checkMetaValue(prj, outcome, action, value); f != nil {
+		return f
+	}
+	switch action {
+	case "kick_member":
+		addresses, _ := checkAddressList(value)
+		for _, addr := range addresses {
+			// Members who already left are skipped at execution.
+			if !st.isMember(addr) {
+				continue
+			}
+			if f := checkKick(prj, addr); f != nil {
+				return f
+			}
+		}
+	case "update_owner":
+		if !st.isMember(AddressFromString(value)) {
+			return fail(errcode.NotMember, "new owner must be a member")
+		}
+	case "distribute":
+		amount, asset, f := checkDistribute(prj, value)
+		if f != nil {
+			return f
+		}
+		return checkTreasuryCovers(st, asset, amount)
+	case "treasury_stake_hbd":
+		amount, _ := checkHbdSavingsAmount(action, value)
+		return checkTreasuryCovers(st, sdk.AssetHbd, amount)
+	case "treasury_unstake_hbd":
+		if st.pendingUnstakes() >= MaxPendingHbdUnstakes {
+			return fail(errcode.InvalidValue, fmt.Sprintf("cannot have more than %d pending hbd unstakes", MaxPendingHbdUnstakes))
+		}
+		amount, _ := checkHbdSavingsAmount(action, value)
+		return checkTreasuryCovers(st, sdk.AssetHbdSavings, amount)
+	case "dissolve_project":
+		return checkNothingInSavings(st, "dissolving")
+	case "export_project":
+		return checkNothingInSavings(st, "exporting")
+	}
+	return nil
+}
+
+// checkTreasuryCovers reports a treasury holding less than amount of asset.
+func checkTreasuryCovers(st metaState, asset sdk.Asset, amount Amount) *failure {
+	if st.balance(asset) < amount {
+		return fail(errcode.InsufficientFunds, fmt.Sprintf("insufficient %s funds in treasury", AssetToString(asset)))
+	}
+	return nil
+}
+
+// checkNothingInSavings is checkNoHbdSavings for st, which also turns away
+// HBD still on its way out of savings: a liquidation would skip it.
+func checkNothingInSavings(st metaState, doing string) *failure {
+	if st.pendingUnstakes() > 0 {
+		return fail(errcode.Locked, "hbd unstake pending")
+	}
+	if st.balance(sdk.AssetHbdSavings) > 0 {
+		return fail(errcode.Locked, fmt.Sprintf("treasury holds hbd savings, unstake them before %s", doing))
+	}
+	return nil
+}
+
+// checkMetaValue reports why action=value is not a valid meta action for prj,
+// whatever the project state at execution turns out to be.
+func checkMetaValue(prj *Project, outcome *ProposalOutcome, action, value string) *failure {
+	switch action {
+	case "update_threshold":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fail(errcode.InvalidValue, "invalid threshold update")
+		}
+		// positive-form bound also rejects NaN (all NaN comparisons are false)
+		if !(v >= MinThresholdPercent && v <= MaxThresholdPercent) {
+			return fail(errcode.InvalidValue, fmt.Sprintf("threshold must be between %.0f%% and %.0f%%", MinThresholdPercent, MaxThresholdPercent))
+		}
+	case "update_quorum":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fail(errcode.InvalidValue, "invalid quorum update")
+		}
+		if !(v >= MinQuorumPercent && v <= MaxQuorumPercent) {
+			return fail(errcode.InvalidValue, fmt.Sprintf("quorum must be between %.0f%% and %.0f%%", MinQuorumPercent, MaxQuorumPercent))
+		}
+	case "update_proposalDuration":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fail(errcode.InvalidValue, "invalid proposal duration update")
+		}
+		if v < MinProposalDurationHours {
+			return fail(errcode.InvalidValue, fmt.Sprintf("proposal duration must be at least %d hour(s)", MinProposalDurationHours))
+		}
+		if v > MaxProposalDurationHours {
+			return fail(errcode.InvalidValue, fmt.Sprintf("proposal duration must not exceed %d hours", MaxProposalDurationHours))
+		}
+	case "update_executionDelay":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fail(errcode.InvalidValue, "invalid execution delay update")
+		}
+		if v > MaxDurationHours {
+			return fail(errcode.InvalidValue, fmt.Sprintf("execution delay must not exceed %d hours", MaxDurationHours))
+		}
+	case "update_leaveCooldown":
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fail(errcode.InvalidValue, "invalid leave cooldown update")
+		}
+		if v > MaxDurationHours {
+			return fail(errcode.InvalidValue, fmt.Sprintf("leave cooldown must not exceed %d hours", MaxDurationHours))
+		}
+	case "update_proposalCost":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return fail(errcode.InvalidValue, "invalid proposal cost update")
+		}
+		if v < 0 {
+			return fail(errcode.InvalidValue, "proposal cost cannot be negative")
+		}
+		if cost, f := checkAmount(v); f != nil {
+			return f
+		} else if v > 0 && cost <= 0 {
+			return fail(errcode.InvalidValue, "proposal cost is below the minimum representable amount")
+		}
+	case "update_membershipNFT":
+		return checkTokenId(strings.TrimSpace(value))
+	case "update_membershipNFTContract":
+		if value != "" && !contractExists(value) {
+			return fail(errcode.NotFound, fmt.Sprintf("membership NFT contract not found: %s", value))
+		}
+	case "update_proposalCreatorRestriction":
+		_, f := checkCreatorRestriction(value)
+		return f
+	case "update_owner":
+		if _, ok := outcome.Meta["remove_owner"]; ok {
+			return fail(errcode.MetaConflict, "conflicting owner directives (update_owner and remove_owner)")
+		}
+		addr := AddressFromString(value)
+		if f := checkAddress(addr); f != nil {
+			return f
+		}
+		if _, exists := loadMember(prj.ID, addr); !exists {
+			return fail(errcode.NotMember, "new owner must be a member")
+		}
+	case "update_url":
+		if len(value) > MaxURLLength {
+			return fail(errcode.InvalidValue, fmt.Sprintf("url exceeds maximum length of %d characters", MaxURLLength))
+		}
+		// An empty value clears the URL.
+		if value != "" && !strings.HasPrefix(value, "https://") {
+			return fail(errcode.InvalidValue, "url must start with https://")
+		}
+	case "update_membershipNFTPayload":
+		// An empty value restores the default format.
+		if f := strings.TrimSpace(value); f != "" && (!strings.Contains(f, "{nft}") || !strings.Contains(f, "{caller}")) {
+			return fail(errcode.InvalidValue, "membership NFT payload must contain {nft} and {caller}")
+		}
+	case "update_whitelistOnly":
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "1", "true", "yes", "0", "false", "no":
+		default:
+			return fail(errcode.InvalidValue, "invalid whitelist flag")
+		}
+	case "update_iccThreshold":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fail(errcode.InvalidValue, "invalid ICC threshold update")
+		}
+		// 0 turns the elevated threshold off.
+		if v != 0 && !(v >= MinThresholdPercent && v <= MaxThresholdPercent) {
+			return fail(errcode.InvalidValue, fmt.Sprintf("ICC threshold must be 0 or between %.0f%% and %.0f%%", MinThresholdPercent, MaxThresholdPercent))
+		}
+	case "icc_allowlist_add", "icc_allowlist_remove":
+		_, f := checkICCAllowlist(action, value, action == "icc_allowlist_add")
+		return f
+	case "whitelist_add", "whitelist_remove":
+		addresses, f := checkAddressList(value)
+		if f != nil {
+			return f
+		}
+		if len(addresses) == 0 {
+			return fail(errcode.InvalidPayload, fmt.Sprintf("%s metadata requires addresses", action))
+		}
+		if len(addresses) > MaxWhitelistAddresses {
+			return fail(errcode.InvalidValue, fmt.Sprintf("%s cannot exceed %d addresses per proposal", action, MaxWhitelistAddresses))
+		}
+	case "kick_member":
+		addresses, f := checkAddressList(value)
+		if f != nil {
+			return f
+		}
+		if len(addresses) == 0 {
+			return fail(errcode.InvalidPayload, "kick_member requires addresses")
+		}
+		if len(addresses) > MaxKickAddresses {
+			return fail(errcode.InvalidValue, fmt.Sprintf("kick_member cannot exceed %d addresses per proposal", MaxKickAddresses))
+		}
+	case "update_stakeWeight":
+		parts := strings.SplitN(value, ":", 2)
+		if len(parts) != 2 {
+			return fail(errcode.InvalidPayload, "update_stakeWeight requires asset:weight")
+		}
+		asset, f := checkStakeAssetName(parts[0])
+		if f != nil {
+			return f
+		}
+		if asset == prj.FundsAsset {
+			return fail(errcode.InvalidValue, fmt.Sprintf("%s is the funds asset and always has weight 1", asset.String()))
+		}
+		if !prj.Config.VotingSystem.IsStakeWeighted() {
+			return fail(errcode.InvalidValue, "additional stake assets require a stake-weighted voting system")
+		}
+		_, f = checkStakeWeight(parts[1], true)
+		return f
+	case "distribute":
+		_, _, f := checkDistributeValue(value)
+		return f
+	case "treasury_stake_hbd", "treasury_unstake_hbd":
+		_, f := checkHbdSavingsAmount(action, value)
+		return f
+	case "dissolve_project":
+		if f := checkDissolveFlag(value); f != nil {
+			return f
+		}
+		// Dissolution empties the treasury, so an inter-contract call riding on
+		// the same proposal could never be funded.
+		if len(outcome.ICC) > 0 {
+			return fail(errcode.MetaConflict, "dissolve_project cannot be combined with inter-contract calls")
+		}
+		return checkNoHbdSavings(prj, "dissolving")
+	case "export_project":
+		target, _, f := checkExportMeta(value)
+		if f != nil {
+			return f
+		}
+		// Export empties the project into another deployment; nothing may
+		// spend from it or liquidate it on the way out.
+		if len(outcome.ICC) > 0 {
+			return fail(errcode.MetaConflict, "export_project cannot be combined with inter-contract calls")
+		}
+		if _, ok := outcome.Meta["dissolve_project"]; ok {
+			return fail(errcode.MetaConflict, "export_project cannot be combined with dissolve_project")
+		}
+		if f := checkExportTarget(prj, target); f != nil {
+			return f
+		}
+		return checkListsExportable(prj)
+	case "update_votingSystem":
+		_, f := checkVotingSystemUpdate(prj, outcome, value)
+		return f
+	case "update_stakeMin":
+		_, f := checkStakeMinUpdate(prj, value)
+		return f
+	case "update_name", "update_description", "update_metadata":
+		return checkProjectText(action, value)
+	case "update_membershipNFTContractFunction", "remove_owner", "toggle_pause":
+	default:
+		return fail(errcode.UnknownMeta, fmt.Sprintf("unknown meta action: %s", action))
+	}
+	return nil
+}
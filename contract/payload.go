@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 	"strconv"
 	"strings"
@@ -10,35 +11,29 @@ import (
 
 // decodeCreateProjectArgs unpacks the pipe-delimited payload used for project_create calls.
 func decodeCreateProjectArgs(payload *string) *CreateProjectArgs {
-	raw := unwrapPayload(payload, "project payload missing")
-	parts := strings.Split(raw, "|")
-	get := func(i int) string {
-		if i < len(parts) {
-			return parts[i]
-		}
-		return ""
-	}
+	f := decodePayloadFields(payload, "project payload missing",
+		"name", "description", "votingSystem", "threshold", "quorum",
+		"proposalDuration", "executionDelay", "leaveCooldown", "proposalCost",
+		"stakeMin", "membershipContract", "membershipFn", "membershipNftId",
+		"metadata", "proposalCreatorRestriction", "membershipPayloadFormat",
+		"url", "whitelistOnly", "stakeAssets")
+	get := f.get
 
 	name := strings.TrimSpace(get(0))
 	description := strings.TrimSpace(get(1))
-	if len(name) > MaxNameLength {
-		sdk.Abort(fmt.Sprintf("project name exceeds maximum length of %d characters", MaxNameLength))
-	}
-	if len(description) > MaxDescriptionLength {
-		sdk.Abort(fmt.Sprintf("project description exceeds maximum length of %d characters", MaxDescriptionLength))
-	}
-	if len(get(13)) > MaxDescriptionLength {
-		sdk.Abort(fmt.Sprintf("project metadata exceeds maximum length of %d characters", MaxDescriptionLength))
-	}
-	if len(get(16)) > MaxURLLength {
-		sdk.Abort(fmt.Sprintf("project URL exceeds maximum length of %d characters", MaxURLLength))
-	}
+	validateProjectText(name, description, get(13), get(16))
 	args := &CreateProjectArgs{
 		Name:        name,
 		Description: description,
 		Metadata:    normalizeOptionalField(get(13)),
 		URL:         normalizeOptionalField(get(16)),
 	}
+	// Field 18: additional stake assets, "asset=weight;asset=weight".
+	if obj, ok := f.object(18); ok {
+		args.StakeWeights = parseStakeWeightsJSON(obj)
+	} else {
+		args.StakeWeights = parseStakeWeightsField(get(18))
+	}
 	cfg := ProjectConfig{
 		VotingSystem: parseVotingSystem(get(2)),
 	}
@@ -56,7 +51,7 @@ func decodeCreateProjectArgs(payload *string) *CreateProjectArgs {
 	cfg.StakeMinAmt = parseFloatField(get(9), "min stake")
 	if v := strings.TrimSpace(get(10)); v != "" {
 		if !contractExists(v) {
-			sdk.Abort(fmt.Sprintf("membership NFT contract not found: %s", v))
+			abort(errcode.NotFound, fmt.Sprintf("membership NFT contract not found: %s", v))
 		}
 		cfg.MembershipNFTContract = strptr(v)
 	}
@@ -79,48 +74,79 @@ func decodeCreateProjectArgs(payload *string) *CreateProjectArgs {
 	return args
 }
 
+// validateProjectText enforces the length limits on a project's texts.
+func validateProjectText(name, description, metadata, url string) {
+	if len(name) > MaxNameLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("project name exceeds maximum length of %d characters", MaxNameLength))
+	}
+	if len(description) > MaxDescriptionLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("project description exceeds maximum length of %d characters", MaxDescriptionLength))
+	}
+	if len(metadata) > MaxDescriptionLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("project metadata exceeds maximum length of %d characters", MaxDescriptionLength))
+	}
+	if len(url) > MaxURLLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("project URL exceeds maximum length of %d characters", MaxURLLength))
+	}
+}
+
 // decodeCreateProposalArgs splits the string payload and normalizes optional bits like payouts.
 func decodeCreateProposalArgs(payload *string) *CreateProposalArgs {
-	raw := unwrapPayload(payload, "proposal payload missing")
-	parts := strings.Split(raw, "|")
-	get := func(i int) string {
-		if i < len(parts) {
-			return parts[i]
-		}
-		return ""
-	}
+	f := decodePayloadFields(payload, "proposal payload missing",
+		"projectId", "name", "description", "duration", "options", "forcePoll",
+		"payouts", "meta", "metadata", "url", "icc")
+	get := f.get
 	projectID := parseEntityIDField(get(0), "project id")
 	name := strings.TrimSpace(get(1))
 	description := strings.TrimSpace(get(2))
 	if len(name) > MaxNameLength {
-		sdk.Abort(fmt.Sprintf("proposal name exceeds maximum length of %d characters", MaxNameLength))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal name exceeds maximum length of %d characters", MaxNameLength))
 	}
 	if len(description) > MaxDescriptionLength {
-		sdk.Abort(fmt.Sprintf("proposal description exceeds maximum length of %d characters", MaxDescriptionLength))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal description exceeds maximum length of %d characters", MaxDescriptionLength))
 	}
 	duration := parseUintField(get(3), "proposal duration")
-	options := parseOptionsField(get(4))
+	var options []ProposalOptionInput
+	if arr, ok := f.list(4); ok {
+		options = parseOptionsJSON(arr)
+	} else {
+		options = parseOptionsField(get(4))
+	}
 	forcePoll := parseBoolField(get(5))
-	payouts := parsePayoutField(get(6))
-	metaOutcome := parseMetadataField(get(7))
+	var payouts []PayoutEntry
+	if arr, ok := f.list(6); ok {
+		payouts = parsePayoutsJSON(arr)
+	} else {
+		payouts = parsePayoutField(get(6))
+	}
+	var metaOutcome map[string]string
+	if obj, ok := f.object(7); ok {
+		metaOutcome = parseMetaJSON(obj)
+	} else {
+		metaOutcome = parseMetadataField(get(7))
+	}
+	notBefore, notAfter := takeExecutionWindow(metaOutcome)
+	condition := takeStateCondition(metaOutcome)
+	prerequisites := takePrerequisites(metaOutcome)
 	metadata := normalizeOptionalField(get(8))
 	// Bound the free-form metadata/URL like name/description: an unbounded blob
 	// bloats the proposal record that every vote/tally reloads (gas griefing).
 	if len(metadata) > MaxDescriptionLength {
-		sdk.Abort(fmt.Sprintf("proposal metadata exceeds maximum length of %d characters", MaxDescriptionLength))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal metadata exceeds maximum length of %d characters", MaxDescriptionLength))
 	}
 	if len(get(9)) > MaxURLLength {
-		sdk.Abort(fmt.Sprintf("proposal URL exceeds maximum length of %d characters", MaxURLLength))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal URL exceeds maximum length of %d characters", MaxURLLength))
 	}
 	// ICC is the trailing field and its own grammar uses '|' internally
 	// (contract|function|payload|assets), so it spans every part from index 10
 	// onward. Rejoin them; reading only parts[10] truncated the entry and made the
 	// whole ICC feature unreachable (even the documented example failed to parse).
-	iccRaw := ""
-	if len(parts) > 10 {
-		iccRaw = strings.Join(parts[10:], "|")
+	var iccCalls []InterContractCall
+	if arr, ok := f.list(10); ok {
+		iccCalls = parseICCJSON(arr)
+	} else {
+		iccCalls = parseICCField(f.rest(10))
 	}
-	iccCalls := parseICCField(iccRaw)
 
 	var outcome *ProposalOutcome
 	if len(payouts) > 0 || len(metaOutcome) > 0 || len(iccCalls) > 0 {
@@ -141,18 +167,26 @@ func decodeCreateProposalArgs(payload *string) *CreateProposalArgs {
 		Metadata:         metadata,
 		ForcePoll:        forcePoll,
 		URL:              normalizeOptionalField(get(9)),
+		NotBefore:        notBefore,
+		NotAfter:         notAfter,
+		Condition:        condition,
+		Prerequisites:    prerequisites,
 	}
 }
 
 // decodeVoteProposalArgs expects `proposalId|choices` and converts indexes into uint slice.
 func decodeVoteProposalArgs(payload *string) *VoteProposalArgs {
-	raw := unwrapPayload(payload, "vote payload missing")
-	parts := strings.Split(raw, "|")
-	if len(parts) < 2 {
-		sdk.Abort("vote payload requires proposalId|choices")
+	f := decodePayloadFields(payload, "vote payload missing", "proposalId", "choices")
+	if !f.has(1) {
+		abort(errcode.InvalidPayload, "vote payload requires proposalId|choices")
+	}
+	proposalID := parseEntityIDField(f.get(0), "proposal id")
+	var choices []uint
+	if arr, ok := f.list(1); ok {
+		choices = parseChoicesJSON(arr)
+	} else {
+		choices = parseChoiceField(f.get(1))
 	}
-	proposalID := parseEntityIDField(parts[0], "proposal id")
-	choices := parseChoiceField(parts[1])
 	return &VoteProposalArgs{
 		ProposalID: proposalID,
 		Choices:    choices,
@@ -161,13 +195,12 @@ func decodeVoteProposalArgs(payload *string) *VoteProposalArgs {
 
 // decodeAddFundsArgs extracts project id plus staking flag from the user payload.
 func decodeAddFundsArgs(payload *string) *AddFundsArgs {
-	raw := unwrapPayload(payload, "add funds payload missing")
-	parts := strings.Split(raw, "|")
-	if len(parts) < 2 {
-		sdk.Abort("add funds payload requires projectId|toStake")
+	f := decodePayloadFields(payload, "add funds payload missing", "projectId", "toStake")
+	if !f.has(1) {
+		abort(errcode.InvalidPayload, "add funds payload requires projectId|toStake")
 	}
-	projectID := parseEntityIDField(parts[0], "project id")
-	toStake := parseBoolField(parts[1])
+	projectID := parseEntityIDField(f.get(0), "project id")
+	toStake := parseBoolField(f.get(1))
 	return &AddFundsArgs{
 		ProjectID: projectID,
 		ToStake:   toStake,
@@ -177,11 +210,11 @@ func decodeAddFundsArgs(payload *string) *AddFundsArgs {
 // unwrapPayload trims quotes and whitespace, aborting if the payload is empty.
 func unwrapPayload(payload *string, errMsg string) string {
 	if payload == nil {
-		sdk.Abort(errMsg)
+		abort(errcode.InvalidPayload, errMsg)
 	}
 	raw := strings.TrimSpace(*payload)
 	if raw == "" {
-		sdk.Abort(errMsg)
+		abort(errcode.InvalidPayload, errMsg)
 	}
 	if len(raw) >= 2 {
 		first := raw[0]
@@ -192,7 +225,7 @@ func unwrapPayload(payload *string, errMsg string) string {
 			}
 			raw = strings.TrimSpace(raw[1 : len(raw)-1])
 			if raw == "" {
-				sdk.Abort(errMsg)
+				abort(errcode.InvalidPayload, errMsg)
 			}
 		}
 	}
@@ -214,11 +247,11 @@ func parseFloatField(val string, field string) float64 {
 		if (c >= '0' && c <= '9') || c == '.' || c == '-' || c == '+' || c == 'e' || c == 'E' {
 			continue
 		}
-		sdk.Abort(fmt.Sprintf("invalid %s", field))
+		abort(errcode.InvalidPayload, fmt.Sprintf("invalid %s", field))
 	}
 	f, err := strconv.ParseFloat(val, 64)
 	if err != nil {
-		sdk.Abort(fmt.Sprintf("invalid %s", field))
+		abort(errcode.InvalidPayload, fmt.Sprintf("invalid %s", field))
 	}
 	// NaN/Inf slip past every range check (all NaN comparisons are false), which
 	// would brick governance (NaN threshold never passes), bypass quorum
@@ -231,7 +264,7 @@ func parseFloatField(val string, field string) float64 {
 	// suite green. Kept deliberately — it is the last line of defence if either
 	// earlier check is ever relaxed, and NaN reaching a threshold is unrecoverable.
 	if math.IsNaN(f) || math.IsInf(f, 0) {
-		sdk.Abort(fmt.Sprintf("invalid %s", field))
+		abort(errcode.InvalidPayload, fmt.Sprintf("invalid %s", field))
 	}
 	return f
 }
@@ -244,7 +277,7 @@ func parseFloatField(val string, field string) float64 {
 // project 0. An id is never optional, so absence is an error.
 func parseEntityIDField(val string, field string) uint64 {
 	if strings.TrimSpace(val) == "" {
-		sdk.Abort(fmt.Sprintf("%s is required", field))
+		abort(errcode.InvalidPayload, fmt.Sprintf("%s is required", field))
 	}
 	return parseUintField(val, field)
 }
@@ -257,7 +290,7 @@ func parseUintField(val string, field string) uint64 {
 	}
 	n, err := strconv.ParseUint(val, 10, 64)
 	if err != nil {
-		sdk.Abort(fmt.Sprintf("invalid %s", field))
+		abort(errcode.InvalidPayload, fmt.Sprintf("invalid %s", field))
 	}
 	return n
 }
@@ -282,7 +315,7 @@ func parseOptionsField(val string) []ProposalOptionInput {
 	}
 	raw := strings.Split(val, ";")
 	if len(raw) > MaxProposalOptions {
-		sdk.Abort(fmt.Sprintf("proposal cannot have more than %d options", MaxProposalOptions))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal cannot have more than %d options", MaxProposalOptions))
 	}
 	opts := make([]ProposalOptionInput, 0, len(raw))
 	for _, opt := range raw {
@@ -301,36 +334,41 @@ func parseOptionsField(val string) []ProposalOptionInput {
 			url = strings.TrimSpace(opt[delimiterIdx+3:])
 		} else if delimiterIdx == 0 {
 			// Delimiter at the beginning with no text
-			sdk.Abort("option text cannot be empty")
+			abort(errcode.InvalidValue, "option text cannot be empty")
 		} else {
 			// No delimiter found - entire string is text
 			text = opt
 		}
 
-		// Validate lengths
-		if len(text) == 0 {
-			sdk.Abort("option text cannot be empty")
-		}
-		if len(text) > MaxOptionTextLength {
-			sdk.Abort(fmt.Sprintf("option text exceeds maximum length of %d characters", MaxOptionTextLength))
-		}
-		if len(url) > MaxURLLength {
-			sdk.Abort(fmt.Sprintf("option URL exceeds maximum length of %d characters", MaxURLLength))
-		}
+		opts = append(opts, newProposalOption(text, url))
+	}
+	return opts
+}
 
-		// Validate URL scheme if URL is provided - only HTTPS allowed
-		if url != "" {
-			if !strings.HasPrefix(url, "https://") {
-				sdk.Abort("option URL must start with https://")
-			}
+// newProposalOption validates one ballot option.
+func newProposalOption(text, url string) ProposalOptionInput {
+	// Validate lengths
+	if len(text) == 0 {
+		abort(errcode.InvalidValue, "option text cannot be empty")
+	}
+	if len(text) > MaxOptionTextLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("option text exceeds maximum length of %d characters", MaxOptionTextLength))
+	}
+	if len(url) > MaxURLLength {
+		abort(errcode.InvalidValue, fmt.Sprintf("option URL exceeds maximum length of %d characters", MaxURLLength))
+	}
+
+	// Validate URL scheme if URL is provided - only HTTPS allowed
+	if url != "" {
+		if !strings.HasPrefix(url, "https://") {
+			abort(errcode.InvalidValue, "option URL must start with https://")
 		}
+	}
 
-		opts = append(opts, ProposalOptionInput{
-			Text: text,
-			URL:  url,
-		})
+	return ProposalOptionInput{
+		Text: text,
+		URL:  url,
 	}
-	return opts
 }
 
 // parseChoiceField allows comma or semicolon separators and returns clean indexes.
@@ -347,7 +385,7 @@ func parseChoiceField(val string) []uint {
 	// (1000+ entries was accepted) is permanent state bloat for a single vote fee.
 	// No ballot can meaningfully name more distinct options than exist.
 	if len(raw) > MaxProposalOptions {
-		sdk.Abort("too many choices")
+		abort(errcode.InvalidValue, "too many choices")
 	}
 	choices := make([]uint, 0, len(raw))
 	for _, part := range raw {
@@ -355,22 +393,27 @@ func parseChoiceField(val string) []uint {
 		if part == "" {
 			continue
 		}
-		idx, err := strconv.ParseUint(part, 10, 64)
-		if err != nil {
-			sdk.Abort("invalid choice index")
-		}
-		// `uint` is 32-bit on the wasm target, so uint(idx) would silently truncate:
-		// a choice of 2^32 becomes 0 and then PASSES the idx < OptionCount bounds
-		// check, recording the ballot against option 0 instead of being rejected.
-		// (Native test builds have 64-bit uint and never see this.) Bound here.
-		if idx >= uint64(MaxProposalOptions) {
-			sdk.Abort("invalid option index")
-		}
-		choices = append(choices, uint(idx))
+		choices = append(choices, parseChoiceIndex(part))
 	}
 	return choices
 }
 
+// parseChoiceIndex validates one ballot choice.
+func parseChoiceIndex(part string) uint {
+	idx, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+	if err != nil {
+		abort(errcode.InvalidValue, "invalid choice index")
+	}
+	// `uint` is 32-bit on the wasm target, so uint(idx) would silently truncate:
+	// a choice of 2^32 becomes 0 and then PASSES the idx < OptionCount bounds
+	// check, recording the ballot against option 0 instead of being rejected.
+	// (Native test builds have 64-bit uint and never see this.) Bound here.
+	if idx >= uint64(MaxProposalOptions) {
+		abort(errcode.InvalidValue, "invalid option index")
+	}
+	return uint(idx)
+}
+
 // parseMetadataField lets payload authors include semi-colon separated key=value pairs.
 func parseMetadataField(val string) map[string]string {
 	val = strings.TrimSpace(val)
@@ -389,7 +432,7 @@ func parseMetadataField(val string) map[string]string {
 	// the proposal record that each vote and tally reloads, so an unbounded value is
 	// a gas-griefing vector against every later voter, not just the author.
 	if len(val) > MaxMetaLength {
-		sdk.Abort(fmt.Sprintf("proposal meta exceeds maximum length of %d characters", MaxMetaLength))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal meta exceeds maximum length of %d characters", MaxMetaLength))
 	}
 	meta := map[string]string{}
 	pairs := strings.Split(val, ";")
@@ -400,28 +443,26 @@ func parseMetadataField(val string) map[string]string {
 		}
 		split := strings.SplitN(pair, "=", 2)
 		if len(split) != 2 {
-			sdk.Abort("invalid metadata entry (use key=value)")
+			abort(errcode.InvalidPayload, "invalid metadata entry (use key=value)")
 		}
-		key := strings.TrimSpace(split[0])
-		value := strings.TrimSpace(split[1])
-		// Reject unknown meta keys up front. The execute-time switch has no default
-		// case, so a typo'd/unknown key would silently no-op while the proposal still
-		// "passes" — voters would believe a governance change was enacted that never
-		// happened. Fail fast at creation instead.
-		if !isKnownMetaKey(key) {
-			sdk.Abort(fmt.Sprintf("unknown meta action: %s", key))
-		}
-		// Validate contract existence for NFT contract updates
-		if key == "update_membershipNFTContract" && value != "" {
-			if !contractExists(value) {
-				sdk.Abort(fmt.Sprintf("membership NFT contract not found: %s", value))
-			}
-		}
-		meta[key] = value
+		setMetaEntry(meta, strings.TrimSpace(split[0]), strings.TrimSpace(split[1]))
 	}
 	return meta
 }
 
+// setMetaEntry checks the key of one outcome meta action and adds it to meta.
+// Values are checked against the project by checkOutcomeMeta in CreateProposal.
+func setMetaEntry(meta map[string]string, key, value string) {
+	// Reject unknown meta keys up front. The execute-time switch has no default
+	// case, so a typo'd/unknown key would silently no-op while the proposal still
+	// "passes" — voters would believe a governance change was enacted that never
+	// happened. Fail fast at creation instead.
+	if !isKnownMetaKey(key) && !isExecutionWindowKey(key) && key != MetaCondition && key != MetaRequires {
+		abort(errcode.UnknownMeta, fmt.Sprintf("unknown meta action: %s", key))
+	}
+	meta[key] = value
+}
+
 // isKnownMetaKey lists every meta action ExecuteProposal actually handles.
 func isKnownMetaKey(key string) bool {
 	switch key {
@@ -431,7 +472,11 @@ func isKnownMetaKey(key string) bool {
 		"update_membershipNFTContractFunction", "update_membershipNFTPayload",
 		"update_proposalCreatorRestriction", "update_url", "update_owner",
 		"remove_owner", "toggle_pause", "update_whitelistOnly",
-		"whitelist_add", "whitelist_remove", "kick_member":
+		"whitelist_add", "whitelist_remove", "kick_member", "dissolve_project",
+		"export_project", "distribute", "update_stakeWeight", "treasury_stake_hbd",
+		"treasury_unstake_hbd", "update_iccThreshold", "icc_allowlist_add",
+		"icc_allowlist_remove", "update_votingSystem", "update_stakeMin",
+		"update_name", "update_description", "update_metadata":
 		return true
 	}
 	return false
@@ -448,11 +493,11 @@ func normalizeProjectConfig(cfg *ProjectConfig) {
 	}
 	// Validate threshold bounds
 	if cfg.ThresholdPercent < MinThresholdPercent || cfg.ThresholdPercent > MaxThresholdPercent {
-		sdk.Abort(fmt.Sprintf("threshold must be between %.0f%% and %.0f%%", MinThresholdPercent, MaxThresholdPercent))
+		abort(errcode.InvalidValue, fmt.Sprintf("threshold must be between %.0f%% and %.0f%%", MinThresholdPercent, MaxThresholdPercent))
 	}
 	// Validate quorum bounds
 	if cfg.QuorumPercent < MinQuorumPercent || cfg.QuorumPercent > MaxQuorumPercent {
-		sdk.Abort(fmt.Sprintf("quorum must be between %.0f%% and %.0f%%", MinQuorumPercent, MaxQuorumPercent))
+		abort(errcode.InvalidValue, fmt.Sprintf("quorum must be between %.0f%% and %.0f%%", MinQuorumPercent, MaxQuorumPercent))
 	}
 	if cfg.ProposalDurationHours <= 0 {
 		cfg.ProposalDurationHours = FallbackProposalDurationHours
@@ -463,16 +508,16 @@ func normalizeProjectConfig(cfg *ProjectConfig) {
 	}
 	// Upper-bound the time fields so value*3600 cannot overflow int64.
 	if cfg.ProposalDurationHours > MaxProposalDurationHours {
-		sdk.Abort(fmt.Sprintf("proposal duration must not exceed %d hours", MaxProposalDurationHours))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal duration must not exceed %d hours", MaxProposalDurationHours))
 	}
 	if cfg.ExecutionDelayHours > MaxDurationHours {
-		sdk.Abort(fmt.Sprintf("execution delay must not exceed %d hours", MaxDurationHours))
+		abort(errcode.InvalidValue, fmt.Sprintf("execution delay must not exceed %d hours", MaxDurationHours))
 	}
 	if cfg.LeaveCooldownHours <= 0 {
 		cfg.LeaveCooldownHours = FallbackLeaveCooldownHours
 	}
 	if cfg.LeaveCooldownHours > MaxDurationHours {
-		sdk.Abort(fmt.Sprintf("leave cooldown must not exceed %d hours", MaxDurationHours))
+		abort(errcode.InvalidValue, fmt.Sprintf("leave cooldown must not exceed %d hours", MaxDurationHours))
 	}
 	if cfg.ProposalCost < 0 {
 		cfg.ProposalCost = FallbackProposalCost
@@ -480,10 +525,10 @@ func normalizeProjectConfig(cfg *ProjectConfig) {
 	// A positive cost/stake that rounds below chain precision (AmountScale) would
 	// silently become 0 — free proposals / zero stake. Reject that misconfiguration.
 	if cfg.ProposalCost > 0 && FloatToAmount(cfg.ProposalCost) <= 0 {
-		sdk.Abort("proposal cost is below the minimum representable amount")
+		abort(errcode.InvalidValue, "proposal cost is below the minimum representable amount")
 	}
 	if cfg.StakeMinAmt > 0 && FloatToAmount(cfg.StakeMinAmt) <= 0 {
-		sdk.Abort("min stake is below the minimum representable amount")
+		abort(errcode.InvalidValue, "min stake is below the minimum representable amount")
 	}
 	cfg.MembershipNftPayloadFormat = normalizeMembershipPayloadFormat(cfg.MembershipNftPayloadFormat)
 }
@@ -507,7 +552,7 @@ func parsePayoutField(val string) []PayoutEntry {
 	}
 	entries := strings.Split(val, ";")
 	if len(entries) > MaxPayoutReceivers {
-		sdk.Abort(fmt.Sprintf("proposal cannot have more than %d payout entries", MaxPayoutReceivers))
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal cannot have more than %d payout entries", MaxPayoutReceivers))
 	}
 	payouts := make([]PayoutEntry, 0, len(entries))
 	for _, entry := range entries {
@@ -518,59 +563,119 @@ func parsePayoutField(val string) []PayoutEntry {
 
 		// Split by colons to detect format
 		parts := strings.Split(entry, ":")
+		// Optional trailing destination mode: addr:amount:asset:l1
+		mode := PayoutModeLedger
+		switch strings.ToLower(strings.TrimSpace(parts[len(parts)-1])) {
+		case "l1":
+			mode = PayoutModeL1
+			parts = parts[:len(parts)-1]
+		case "ledger":
+			parts = parts[:len(parts)-1]
+		}
 		if len(parts) < 3 {
-			sdk.Abort("invalid payout entry format (need addr:amount:asset)")
+			abort(errcode.InvalidPayload, "invalid payout entry format (need addr:amount:asset)")
 		}
 
 		// Format: protocol:address:amount:asset
-		var addr sdk.Address
-		var amount Amount
-		var asset sdk.Asset
-
 		// Last part must be asset, second-to-last must be amount
 		lastPart := parts[len(parts)-1]
 		secondLastPart := parts[len(parts)-2]
 
 		// Check if last part is an asset (non-numeric)
 		if _, err := strconv.ParseFloat(lastPart, 64); err == nil {
-			sdk.Abort("payout entry missing asset (format: addr:amount:asset)")
+			abort(errcode.InvalidPayload, "payout entry missing asset (format: addr:amount:asset)")
 		}
 
-		assetStr := strings.ToLower(lastPart)
-		if !isValidAsset(assetStr) {
-			sdk.Abort(fmt.Sprintf("payout asset %s is not supported", assetStr))
+		payouts = append(payouts, newPayoutEntry(strings.Join(parts[:len(parts)-2], ":"), secondLastPart, lastPart, mode))
+	}
+	return payouts
+}
+
+// newPayoutEntry validates one payout.
+func newPayoutEntry(addrStr, amountStr, assetStr string, mode PayoutMode) PayoutEntry {
+	assetStr = strings.ToLower(strings.TrimSpace(assetStr))
+	if !isValidAsset(assetStr) {
+		abort(errcode.InvalidValue, fmt.Sprintf("payout asset %s is not supported", assetStr))
+	}
+	asset := AssetFromString(assetStr)
+	amount := FloatToAmount(mustParseFloat(amountStr, "invalid payout amount"))
+	if amount <= 0 {
+		abort(errcode.InvalidValue, "payout amount must be positive")
+	}
+	addr := AddressFromString(addrStr)
+	validateAddress(addr)
+	if mode == PayoutModeL1 {
+		// Withdrawals land on a Hive base-layer account, and only liquid
+		// assets can leave the ledger.
+		if !strings.HasPrefix(AddressToString(addr), "hive:") {
+			abort(errcode.InvalidValue, "l1 payouts require a hive: address")
 		}
-		asset = AssetFromString(assetStr)
-		amount = FloatToAmount(mustParseFloat(secondLastPart, "invalid payout amount"))
-		if amount <= 0 {
-			sdk.Abort("payout amount must be positive")
+		if asset != sdk.AssetHive && asset != sdk.AssetHbd {
+			abort(errcode.InvalidValue, "l1 payouts support hive and hbd only")
 		}
-		addr = AddressFromString(strings.Join(parts[:len(parts)-2], ":"))
-		validateAddress(addr)
+	}
+	return PayoutEntry{Address: addr, Amount: amount, Asset: asset, Mode: mode}
+}
 
-		payouts = append(payouts, PayoutEntry{Address: addr, Amount: amount, Asset: asset})
+// parsePayoutMode reads a payout destination mode; empty means a ledger transfer.
+func parsePayoutMode(val string) PayoutMode {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "", "ledger":
+		return PayoutModeLedger
+	case "l1":
+		return PayoutModeL1
 	}
-	return payouts
+	abort(errcode.InvalidValue, "payout mode must be ledger or l1")
+	return PayoutModeLedger
+}
+
+// hasL1Payout reports whether any payout entry withdraws to the base layer.
+func hasL1Payout(payouts []PayoutEntry) bool {
+	for _, entry := range payouts {
+		if entry.Mode == PayoutModeL1 {
+			return true
+		}
+	}
+	return false
 }
 
 // mustParseFloat parses a float or aborts with the given message.
 func mustParseFloat(s string, errMsg string) float64 {
 	val, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
 	if err != nil {
-		sdk.Abort(errMsg)
+		abort(errcode.InvalidPayload, errMsg)
 	}
 	return val
 }
 
 // parseAddressList accepts comma/semicolon separated addresses and normalizes them.
 func parseAddressList(val string) []sdk.Address {
+	addresses, f := checkAddressList(val)
+	must(f)
+	return addresses
+}
+
+// checkAddressList is parseAddressList without the abort.
+func checkAddressList(val string) ([]sdk.Address, *failure) {
 	val = strings.TrimSpace(val)
 	if val == "" {
-		return nil
+		return nil, nil
 	}
 	parts := strings.FieldsFunc(val, func(r rune) bool {
 		return r == ';' || r == ',' || r == '\n' || r == '\t'
 	})
+	return checkAddresses(parts)
+}
+
+// normalizeAddressList validates addresses, dropping blanks and duplicates.
+func normalizeAddressList(parts []string) []sdk.Address {
+	addresses, f := checkAddresses(parts)
+	must(f)
+	return addresses
+}
+
+// checkAddresses is normalizeAddressList without the abort.
+func checkAddresses(parts []string) ([]sdk.Address, *failure) {
 	seen := map[string]struct{}{}
 	addresses := make([]sdk.Address, 0, len(parts))
 	for _, part := range parts {
@@ -583,30 +688,37 @@ func parseAddressList(val string) []sdk.Address {
 		}
 		seen[part] = struct{}{}
 		addr := AddressFromString(part)
-		validateAddress(addr)
+		if f := checkAddress(addr); f != nil {
+			return nil, f
+		}
 		addresses = append(addresses, addr)
 	}
 	if len(addresses) == 0 {
-		return nil
+		return nil, nil
 	}
-	return addresses
+	return addresses, nil
 }
 
 // parseCreatorRestrictionField lets payloads toggle between members-only and public creators.
 func parseCreatorRestrictionField(val string) bool {
+	membersOnly, f := checkCreatorRestriction(val)
+	must(f)
+	return membersOnly
+}
+
+// checkCreatorRestriction is parseCreatorRestrictionField without the abort.
+func checkCreatorRestriction(val string) (bool, *failure) {
 	val = strings.TrimSpace(strings.ToLower(val))
 	if val == "" {
-		return FallbackProposalCreatorsMembersOnly
+		return FallbackProposalCreatorsMembersOnly, nil
 	}
 	switch val {
 	case "1", "true", "yes", "members":
-		return true
+		return true, nil
 	case "0", "false", "no", "public", "any":
-		return false
-	default:
-		sdk.Abort("invalid proposal creator restriction")
+		return false, nil
 	}
-	return true
+	return true, fail(errcode.InvalidValue, "invalid proposal creator restriction")
 }
 
 // normalizeMembershipPayloadFormat verifies the placeholders exist (nft + caller) else falls back.
@@ -641,6 +753,8 @@ func parseVotingSystem(val string) VotingSystem {
 		return VotingSystemDemocratic
 	case "1":
 		return VotingSystemStake
+	case "2":
+		return VotingSystemStakeReputation
 	default:
 		return VotingSystemStake
 	}
@@ -648,15 +762,19 @@ func parseVotingSystem(val string) VotingSystem {
 
 // decodeWhitelistPayload reads projectId|addr1;addr2 and returns both parts.
 func decodeWhitelistPayload(payload *string) (uint64, []sdk.Address) {
-	raw := unwrapPayload(payload, "whitelist payload required")
-	parts := strings.Split(raw, "|")
-	if len(parts) < 2 {
-		sdk.Abort("whitelist payload requires projectId|addresses")
+	f := decodePayloadFields(payload, "whitelist payload required", "projectId", "addresses")
+	if !f.has(1) {
+		abort(errcode.InvalidPayload, "whitelist payload requires projectId|addresses")
+	}
+	projectID := parseEntityIDField(f.get(0), "project id")
+	var addresses []sdk.Address
+	if arr, ok := f.list(1); ok {
+		addresses = parseAddressesJSON(arr)
+	} else {
+		addresses = parseAddressList(f.get(1))
 	}
-	projectID := parseEntityIDField(parts[0], "project id")
-	addresses := parseAddressList(parts[1])
 	if len(addresses) == 0 {
-		sdk.Abort("whitelist payload requires addresses")
+		abort(errcode.InvalidPayload, "whitelist payload requires addresses")
 	}
 	// No max limit for owner-initiated whitelist operations.
 	// Proposal meta operations have their own limit (MaxWhitelistAddresses).
@@ -680,7 +798,7 @@ func parseICCField(val string) []InterContractCall {
 	// ExecuteProposal, each with its own external call and treasury debit, and all
 	// of them are stored on the proposal record. 200 entries were accepted before.
 	if len(entries) > MaxICCCalls {
-		sdk.Abort(fmt.Sprintf("ICC cannot exceed %d calls per proposal", MaxICCCalls))
+		abort(errcode.InvalidValue, fmt.Sprintf("ICC cannot exceed %d calls per proposal", MaxICCCalls))
 	}
 
 	for _, entry := range entries {
@@ -689,24 +807,10 @@ func parseICCField(val string) []InterContractCall {
 			continue
 		}
 
-		// Split by pipes to get: contract_addr|function|payload[|assets]
+		// Split by pipes to get: contract_addr|function|payload[|assets[|expect[|receive]]]
 		parts := strings.Split(entry, "|")
 		if len(parts) < 3 {
-			sdk.Abort("invalid ICC entry format (need contract|function|payload[|assets])")
-		}
-
-		contractAddr := strings.TrimSpace(parts[0])
-		function := strings.TrimSpace(parts[1])
-		payload := strings.TrimSpace(parts[2])
-
-		if contractAddr == "" {
-			sdk.Abort("ICC contract address cannot be empty")
-		}
-		if !contractExists(contractAddr) {
-			sdk.Abort(fmt.Sprintf("ICC contract not found: %s", contractAddr))
-		}
-		if function == "" {
-			sdk.Abort("ICC function cannot be empty")
+			abort(errcode.InvalidPayload, "invalid ICC entry format (need contract|function|payload[|assets[|expect[|receive]]])")
 		}
 
 		// Parse assets if provided
@@ -715,17 +819,47 @@ func parseICCField(val string) []InterContractCall {
 			assets = parseICCAssets(parts[3])
 		}
 
-		calls = append(calls, InterContractCall{
-			ContractAddress: contractAddr,
-			Function:        function,
-			Payload:         payload,
-			Assets:          assets,
-		})
+		expect := ""
+		if len(parts) >= 5 {
+			expect = parts[4]
+		}
+		receive := ""
+		if len(parts) >= 6 {
+			receive = parts[5]
+		}
+
+		calls = append(calls, newICCCall(parts[0], parts[1], parts[2], assets, expect, receive))
 	}
 
 	return calls
 }
 
+// newICCCall validates one inter-contract call.
+func newICCCall(contractAddr, function, payload string, assets map[sdk.Asset]Amount, expect, receive string) InterContractCall {
+	contractAddr = strings.TrimSpace(contractAddr)
+	function = strings.TrimSpace(function)
+	payload = strings.TrimSpace(payload)
+
+	if contractAddr == "" {
+		abort(errcode.InvalidValue, "ICC contract address cannot be empty")
+	}
+	if !contractExists(contractAddr) {
+		abort(errcode.NotFound, fmt.Sprintf("ICC contract not found: %s", contractAddr))
+	}
+	if function == "" {
+		abort(errcode.InvalidValue, "ICC function cannot be empty")
+	}
+
+	return InterContractCall{
+		ContractAddress: contractAddr,
+		Function:        function,
+		Payload:         payload,
+		Assets:          assets,
+		Expect:          parseICCExpectation(expect),
+		Receive:         parseICCReceive(receive),
+	}
+}
+
 // parseICCAssets parses asset mappings for ICC.
 // Format: asset1=amount1,asset2=amount2
 // Example: "HIVE=1.5,HBD=2.0"
@@ -747,35 +881,40 @@ func parseICCAssets(val string) map[sdk.Asset]Amount {
 
 		parts := strings.SplitN(pair, "=", 2)
 		if len(parts) != 2 {
-			sdk.Abort("invalid ICC asset format (need ASSET=amount)")
+			abort(errcode.InvalidPayload, "invalid ICC asset format (need ASSET=amount)")
 		}
 
-		assetStr := strings.TrimSpace(strings.ToLower(parts[0]))
-		amountStr := strings.TrimSpace(parts[1])
+		setICCAsset(assets, parts[0], parts[1])
+	}
 
-		if assetStr == "" {
-			sdk.Abort("ICC asset name cannot be empty")
-		}
+	return assets
+}
 
-		asset := AssetFromString(assetStr)
+// setICCAsset validates one asset transfer of an inter-contract call and adds it to assets.
+func setICCAsset(assets map[sdk.Asset]Amount, assetStr, amountStr string) {
+	assetStr = strings.TrimSpace(strings.ToLower(assetStr))
+	amountStr = strings.TrimSpace(amountStr)
 
-		// Validate asset is supported
-		if !isValidAsset(assetStr) {
-			sdk.Abort(fmt.Sprintf("ICC asset %s is not supported", assetStr))
-		}
+	if assetStr == "" {
+		abort(errcode.InvalidValue, "ICC asset name cannot be empty")
+	}
 
-		// Check if asset already exists
-		if _, exists := assets[asset]; exists {
-			sdk.Abort(fmt.Sprintf("ICC asset %s specified multiple times", assetStr))
-		}
+	asset := AssetFromString(assetStr)
 
-		amount := FloatToAmount(mustParseFloat(amountStr, "invalid ICC asset amount"))
-		if amount <= 0 {
-			sdk.Abort("ICC asset amount must be positive")
-		}
+	// Validate asset is supported
+	if !isValidAsset(assetStr) {
+		abort(errcode.InvalidValue, fmt.Sprintf("ICC asset %s is not supported", assetStr))
+	}
 
-		assets[asset] = amount
+	// Check if asset already exists
+	if _, exists := assets[asset]; exists {
+		abort(errcode.InvalidValue, fmt.Sprintf("ICC asset %s specified multiple times", assetStr))
 	}
 
-	return assets
+	amount := FloatToAmount(mustParseFloat(amountStr, "invalid ICC asset amount"))
+	if amount <= 0 {
+		abort(errcode.InvalidValue, "ICC asset amount must be positive")
+	}
+
+	assets[asset] = amount
 }
@@ -0,0 +1,424 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+
+	"github.com/CosmWasm/tinyjson/jlexer"
+)
+
+// -----------------------------------------------------------------------------
+// JSON payloads
+// -----------------------------------------------------------------------------
+//
+// Every entry point also accepts a JSON object, detected by a leading '{'. Each
+// named field maps onto one position of the pipe format, so both forms run
+// through the same field parsers and validation. Scalars (strings, numbers,
+// booleans) are handed over as their text; numbers keep their literal spelling
+// so "1.500" means exactly what it does in a pipe payload. Fields with their
+// own sub-grammar (options, payouts, meta, icc, addresses, choices, stake
+// assets) may be given either as that string or as structured JSON, which is
+// decoded straight into the typed value and never re-joined with separators.
+
+// maxJSONDepth bounds nesting; no payload needs more than an object in an array
+// in an object (icc assets).
+const maxJSONDepth = 4
+
+// payloadFields is a decoded call payload in either format.
+type payloadFields struct {
+	names []string               // field name per pipe position
+	parts []string               // pipe form
+	obj   map[string]interface{} // JSON form, nil for pipe payloads
+}
+
+// isJSONPayload reports whether an unwrapped payload uses the JSON form.
+func isJSONPayload(raw string) bool {
+	return strings.HasPrefix(raw, "{")
+}
+
+// decodePayloadFields unwraps a payload and splits it into fields. names gives
+// the JSON field name of every pipe position; unknown JSON fields abort so a
+// misspelt name cannot be silently ignored.
+func decodePayloadFields(payload *string, errMsg string, names ...string) *payloadFields {
+	raw := unwrapPayload(payload, errMsg)
+	f := &payloadFields{names: names}
+	if !isJSONPayload(raw) {
+		f.parts = strings.Split(raw, "|")
+		return f
+	}
+	f.obj = parseJSONObject(raw)
+	// Sorted so the abort names the same field on every validator.
+	for _, key := range sortedJSONKeys(f.obj) {
+		if f.index(key) < 0 {
+			abort(errcode.InvalidPayload, fmt.Sprintf("unknown payload field: %s", key))
+		}
+	}
+	return f
+}
+
+// index returns the pipe position of a JSON field name, or -1.
+func (f *payloadFields) index(name string) int {
+	for i, n := range f.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// has reports whether field i was given at all.
+func (f *payloadFields) has(i int) bool {
+	if f.obj == nil {
+		return i < len(f.parts)
+	}
+	return f.value(i) != nil
+}
+
+// value returns the decoded JSON value of field i (nil for pipe payloads or
+// absent fields).
+func (f *payloadFields) value(i int) interface{} {
+	if f.obj == nil || i >= len(f.names) {
+		return nil
+	}
+	return f.obj[f.names[i]]
+}
+
+// get returns field i as text, "" when absent. Structured JSON values abort:
+// a field that only takes a scalar must not receive an array or object.
+func (f *payloadFields) get(i int) string {
+	if f.obj == nil {
+		if i < len(f.parts) {
+			return f.parts[i]
+		}
+		return ""
+	}
+	v := f.value(i)
+	if v == nil {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		abort(errcode.InvalidPayload, fmt.Sprintf("payload field %s must be a string, number or boolean", f.names[i]))
+	}
+	return s
+}
+
+// rest returns field i and, for pipe payloads, every later position re-joined.
+// Used for a trailing field whose own grammar contains '|'.
+func (f *payloadFields) rest(i int) string {
+	if f.obj == nil {
+		if i < len(f.parts) {
+			return strings.Join(f.parts[i:], "|")
+		}
+		return ""
+	}
+	return f.get(i)
+}
+
+// list returns a structured JSON array field, or nil when the field is absent
+// or given as a string.
+func (f *payloadFields) list(i int) ([]interface{}, bool) {
+	arr, ok := f.value(i).([]interface{})
+	return arr, ok
+}
+
+// object returns a structured JSON object field, or nil when the field is
+// absent or given as a string.
+func (f *payloadFields) object(i int) (map[string]interface{}, bool) {
+	m, ok := f.value(i).(map[string]interface{})
+	return m, ok
+}
+
+// unwrapSinglePayload unwraps a payload that carries a single value, either
+// bare ("12") or as a JSON object with one field ({"proposalId":12}). The bare
+// form is returned whole, exactly like unwrapPayload.
+func unwrapSinglePayload(payload *string, errMsg string, name string) string {
+	raw := unwrapPayload(payload, errMsg)
+	if !isJSONPayload(raw) {
+		return raw
+	}
+	f := decodePayloadFields(&raw, errMsg, name)
+	val := strings.TrimSpace(f.get(0))
+	if val == "" {
+		abort(errcode.InvalidPayload, errMsg)
+	}
+	return val
+}
+
+// parseJSONObject decodes a JSON object payload into maps, slices and scalar text.
+func parseJSONObject(raw string) map[string]interface{} {
+	r := &jlexer.Lexer{Data: []byte(raw)}
+	v := decodeJSONValue(r, 0)
+	r.Consumed()
+	if err := r.Error(); err != nil {
+		abort(errcode.InvalidPayload, "invalid JSON payload")
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		abort(errcode.InvalidPayload, "invalid JSON payload")
+	}
+	return obj
+}
+
+// decodeJSONValue reads one value. Objects become map[string]interface{},
+// arrays []interface{}, null nil, and every scalar its text (strings
+// unescaped, numbers and booleans verbatim).
+func decodeJSONValue(r *jlexer.Lexer, depth int) interface{} {
+	v, f := readJSONValue(r, depth)
+	must(f)
+	return v
+}
+
+// readJSONValue is decodeJSONValue without the abort: it stops at a document
+// nested too deeply or a duplicate field and reports it.
+func readJSONValue(r *jlexer.Lexer, depth int) (interface{}, *failure) {
+	if depth > maxJSONDepth {
+		return nil, fail(errcode.InvalidPayload, "JSON payload nested too deeply")
+	}
+	switch {
+	case r.IsNull():
+		r.Null()
+		return nil, nil
+	case r.IsDelim('{'):
+		r.Delim('{')
+		out := map[string]interface{}{}
+		for r.Ok() && !r.IsDelim('}') {
+			key := r.String()
+			r.WantColon()
+			if _, dup := out[key]; dup {
+				return nil, fail(errcode.InvalidPayload, fmt.Sprintf("duplicate payload field: %s", key))
+			}
+			v, f := readJSONValue(r, depth+1)
+			if f != nil {
+				return nil, f
+			}
+			out[key] = v
+			r.WantComma()
+		}
+		r.Delim('}')
+		return out, nil
+	case r.IsDelim('['):
+		r.Delim('[')
+		out := []interface{}{}
+		for r.Ok() && !r.IsDelim(']') {
+			v, f := readJSONValue(r, depth+1)
+			if f != nil {
+				return nil, f
+			}
+			out = append(out, v)
+			r.WantComma()
+		}
+		r.Delim(']')
+		return out, nil
+	}
+	lit := r.Raw()
+	if !r.Ok() || len(lit) == 0 {
+		return nil, nil
+	}
+	if lit[0] == '"' {
+		s := &jlexer.Lexer{Data: lit}
+		str := s.String()
+		if s.Error() != nil {
+			r.AddError(s.Error())
+		}
+		return str, nil
+	}
+	if c := lit[0]; c != '-' && c != 't' && c != 'f' && (c < '0' || c > '9') {
+		r.AddError(fmt.Errorf("unexpected %q", lit))
+		return nil, nil
+	}
+	return string(lit), nil
+}
+
+// jsonText returns a scalar JSON value as text, aborting on structured values.
+func jsonText(v interface{}, field string) string {
+	if v == nil {
+		return ""
+	}
+	s, ok := v.(string)
+	if !ok {
+		abort(errcode.InvalidPayload, fmt.Sprintf("%s must be a string, number or boolean", field))
+	}
+	return s
+}
+
+// jsonEntry returns a JSON array element as an object with only the allowed keys.
+func jsonEntry(v interface{}, field string, allowed ...string) map[string]interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		abort(errcode.InvalidPayload, fmt.Sprintf("%s entries must be objects", field))
+	}
+	for _, key := range sortedJSONKeys(m) {
+		known := false
+		for _, a := range allowed {
+			if a == key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			abort(errcode.InvalidPayload, fmt.Sprintf("unknown %s field: %s", field, key))
+		}
+	}
+	return m
+}
+
+// sortedJSONKeys returns the keys of a decoded JSON object in a deterministic order.
+func sortedJSONKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseOptionsJSON reads ballot options given as ["text", {"text":..,"url":..}].
+func parseOptionsJSON(arr []interface{}) []ProposalOptionInput {
+	if len(arr) > MaxProposalOptions {
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal cannot have more than %d options", MaxProposalOptions))
+	}
+	opts := make([]ProposalOptionInput, 0, len(arr))
+	for _, v := range arr {
+		if text, ok := v.(string); ok {
+			opts = append(opts, newProposalOption(strings.TrimSpace(text), ""))
+			continue
+		}
+		m := jsonEntry(v, "option", "text", "url")
+		opts = append(opts, newProposalOption(
+			strings.TrimSpace(jsonText(m["text"], "option text")),
+			strings.TrimSpace(jsonText(m["url"], "option url")),
+		))
+	}
+	return opts
+}
+
+// parsePayoutsJSON reads payouts given as [{"address","amount","asset","mode"}].
+func parsePayoutsJSON(arr []interface{}) []PayoutEntry {
+	if len(arr) > MaxPayoutReceivers {
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal cannot have more than %d payout entries", MaxPayoutReceivers))
+	}
+	payouts := make([]PayoutEntry, 0, len(arr))
+	for _, v := range arr {
+		m := jsonEntry(v, "payout", "address", "amount", "asset", "mode")
+		payouts = append(payouts, newPayoutEntry(
+			strings.TrimSpace(jsonText(m["address"], "payout address")),
+			jsonText(m["amount"], "payout amount"),
+			jsonText(m["asset"], "payout asset"),
+			parsePayoutMode(jsonText(m["mode"], "payout mode")),
+		))
+	}
+	if len(payouts) == 0 {
+		return nil
+	}
+	return payouts
+}
+
+// parseMetaJSON reads outcome meta given as {"action": value}.
+func parseMetaJSON(obj map[string]interface{}) map[string]string {
+	if len(obj) == 0 {
+		return nil
+	}
+	meta := map[string]string{}
+	size := 0
+	for _, key := range sortedJSONKeys(obj) {
+		value := strings.TrimSpace(jsonText(obj[key], "meta value"))
+		// Same bound as the key=value;... form, measured as that form would be.
+		size += len(key) + len(value) + 2
+		if size > MaxMetaLength {
+			abort(errcode.InvalidValue, fmt.Sprintf("proposal meta exceeds maximum length of %d characters", MaxMetaLength))
+		}
+		setMetaEntry(meta, strings.TrimSpace(key), value)
+	}
+	return meta
+}
+
+// parseICCJSON reads inter-contract calls given as
+// [{"contract","function","payload","assets":{"hive":"1.000"},"expect","receive":["hbd"]}].
+func parseICCJSON(arr []interface{}) []InterContractCall {
+	if len(arr) > MaxICCCalls {
+		abort(errcode.InvalidValue, fmt.Sprintf("ICC cannot exceed %d calls per proposal", MaxICCCalls))
+	}
+	calls := make([]InterContractCall, 0, len(arr))
+	for _, v := range arr {
+		m := jsonEntry(v, "icc", "contract", "function", "payload", "assets", "expect", "receive")
+		var assets map[sdk.Asset]Amount
+		switch a := m["assets"].(type) {
+		case map[string]interface{}:
+			if len(a) > 0 {
+				assets = map[sdk.Asset]Amount{}
+				for _, asset := range sortedJSONKeys(a) {
+					setICCAsset(assets, asset, jsonText(a[asset], "ICC asset amount"))
+				}
+			}
+		case string:
+			assets = parseICCAssets(a)
+		case nil:
+		default:
+			abort(errcode.InvalidPayload, "ICC assets must be an object or a string")
+		}
+		receive := ""
+		switch a := m["receive"].(type) {
+		case []interface{}:
+			names := make([]string, len(a))
+			for i, name := range a {
+				names[i] = jsonText(name, "ICC receive asset")
+			}
+			receive = strings.Join(names, ",")
+		case string:
+			receive = a
+		case nil:
+		default:
+			abort(errcode.InvalidPayload, "ICC receive must be an array or a string")
+		}
+		calls = append(calls, newICCCall(
+			jsonText(m["contract"], "ICC contract"),
+			jsonText(m["function"], "ICC function"),
+			jsonText(m["payload"], "ICC payload"),
+			assets,
+			jsonText(m["expect"], "ICC expect"),
+			receive,
+		))
+	}
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls
+}
+
+// parseStakeWeightsJSON reads additional stake assets given as {"asset": weight}.
+func parseStakeWeightsJSON(obj map[string]interface{}) map[sdk.Asset]float64 {
+	if len(obj) == 0 {
+		return nil
+	}
+	weights := map[sdk.Asset]float64{}
+	for _, asset := range sortedJSONKeys(obj) {
+		setStakeWeight(weights, asset, jsonText(obj[asset], "stake weight"))
+	}
+	return weights
+}
+
+// parseAddressesJSON reads an address list given as a JSON array.
+func parseAddressesJSON(arr []interface{}) []sdk.Address {
+	parts := make([]string, len(arr))
+	for i, v := range arr {
+		parts[i] = jsonText(v, "address")
+	}
+	return normalizeAddressList(parts)
+}
+
+// parseChoicesJSON reads ballot choices given as a JSON array of indexes.
+func parseChoicesJSON(arr []interface{}) []uint {
+	if len(arr) > MaxProposalOptions {
+		abort(errcode.InvalidValue, "too many choices")
+	}
+	choices := make([]uint, 0, len(arr))
+	for _, v := range arr {
+		choices = append(choices, parseChoiceIndex(jsonText(v, "choice")))
+	}
+	return choices
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"okinoko_dao/errcode"
+)
+
+// MetaRequires is the meta key listing a proposal's prerequisites,
+// comma separated. Like the execution window it is taken out of the meta
+// actions at creation.
+const MetaRequires = "requires"
+
+// takePrerequisites removes the requires key from meta and returns its ids.
+func takePrerequisites(meta map[string]string) []uint64 {
+	raw, ok := meta[MetaRequires]
+	if !ok {
+		return nil
+	}
+	delete(meta, MetaRequires)
+	ids := parseExportProposalIDs(raw, ",")
+	if len(ids) == 0 {
+		abort(errcode.InvalidValue, "requires needs at least one proposal id")
+	}
+	if len(ids) > MaxPrerequisites {
+		abort(errcode.InvalidValue, fmt.Sprintf("a proposal cannot require more than %d proposals", MaxPrerequisites))
+	}
+	seen := map[uint64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			abort(errcode.InvalidValue, fmt.Sprintf("prerequisite %d listed twice", id))
+		}
+		seen[id] = true
+	}
+	return ids
+}
+
+// checkPrerequisiteRefs checks a new proposal's prerequisites: earlier
+// executable proposals of the same project that can still execute. Since they
+// all exist before the proposal does, chains cannot loop.
+func checkPrerequisiteRefs(prpsl *Proposal) *failure {
+	if len(prpsl.Prerequisites) == 0 {
+		return nil
+	}
+	if prpsl.IsPoll {
+		return fail(errcode.InvalidValue, "polls do not execute and cannot have prerequisites")
+	}
+	for _, id := range prpsl.Prerequisites {
+		p := loadProposal(id)
+		if p.ProjectID != prpsl.ProjectID {
+			return fail(errcode.InvalidValue, fmt.Sprintf("prerequisite %d belongs to another project", id))
+		}
+		if p.IsPoll {
+			return fail(errcode.InvalidValue, fmt.Sprintf("prerequisite %d is a poll and never executes", id))
+		}
+		if p.State != ProposalActive && p.State != ProposalPassed && p.State != ProposalExecuted {
+			return fail(errcode.ProposalState, fmt.Sprintf("prerequisite %d is %s", id, p.State))
+		}
+	}
+	return nil
+}
+
+// failedPrerequisite returns a prerequisite that can no longer execute: one
+// that failed or was cancelled, or a passed one past its not_after deadline.
+// Otherwise it aborts while any prerequisite has not executed yet.
+func failedPrerequisite(prpsl *Proposal) (uint64, string, bool) {
+	id, state, failed, pending := checkPrerequisites(prpsl)
+	if !failed {
+		must(pending)
+	}
+	return id, state, failed
+}
+
+// checkPrerequisites is failedPrerequisite without the abort: pending is set
+// while a prerequisite has not executed yet.
+func checkPrerequisites(prpsl *Proposal) (uint64, string, bool, *failure) {
+	var pending *failure
+	for _, id := range prpsl.Prerequisites {
+		p := loadProposal(id)
+		switch {
+		case p.State == ProposalExecuted:
+			continue
+		case p.State == ProposalFailed, p.State == ProposalCancelled:
+			return id, p.State.String(), true, nil
+		case p.State == ProposalPassed && p.NotAfter > 0 && nowUnix() > p.NotAfter:
+			return id, "expired", true, nil
+		}
+		if pending == nil {
+			pending = fail(errcode.TooEarly, fmt.Sprintf("prerequisite %d is %s, not executed", p.ID, p.State))
+		}
+	}
+	return 0, "", false, pending
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Project settings set by governance
+// -----------------------------------------------------------------------------
+//
+// update_votingSystem and update_stakeMin change rules existing members joined
+// under, so they come with transition rules:
+//
+//   - Open proposals keep the voting system they were created under (see
+//     proposalVotingSystem); only proposals created afterwards use the new one.
+//   - A project cannot switch between free membership (no minimum stake) and
+//     paid membership. Free members hold no stake, so a paid project would
+//     count them as members without a stake, and stake-weighted voting in a
+//     free project would leave nobody able to create a proposal.
+//   - Members below a raised minimum stake stay members with their stake.
+//     In stake-weighted projects they cannot vote until they add stake, and
+//     can still leave at any time.
+//   - Switching to one-member-one-vote requires every additional stake asset
+//     to be retired first (weight 0), since such projects only hold stake in
+//     the funds asset. The same proposal may retire it.
+
+// proposalVotingSystem returns the voting system prpsl is counted under: the
+// project's at creation, or its current one for proposals recorded before
+// the voting system could change.
+func proposalVotingSystem(prj *Project, prpsl *Proposal) VotingSystem {
+	if prpsl.VotingSystem != nil {
+		return *prpsl.VotingSystem
+	}
+	return prj.Config.VotingSystem
+}
+
+// checkVotingSystemUpdate validates update_votingSystem=value for prj.
+func checkVotingSystemUpdate(prj *Project, outcome *ProposalOutcome, value string) (VotingSystem, *failure) {
+	var vs VotingSystem
+	switch strings.TrimSpace(value) {
+	case "0":
+		vs = VotingSystemDemocratic
+	case "1":
+		vs = VotingSystemStake
+	case "2":
+		vs = VotingSystemStakeReputation
+	default:
+		return 0, fail(errcode.InvalidValue, "invalid voting system (use 0, 1 or 2)")
+	}
+	if vs.IsStakeWeighted() && prj.Config.StakeMinAmt <= 0 {
+		return 0, fail(errcode.InvalidValue, "stake-weighted voting requires a minimum stake")
+	}
+	if vs == VotingSystemDemocratic {
+		retiring := retiredByOutcome(outcome)
+		for _, asset := range sortedAssetKeys(prj.StakeWeights) {
+			if prj.StakeWeights[asset] > 0 && asset != retiring {
+				return 0, fail(errcode.InvalidValue, fmt.Sprintf("stake asset %s must be retired before switching to one-member-one-vote", asset.String()))
+			}
+		}
+	}
+	return vs, nil
+}
+
+// retiredByOutcome returns the stake asset an update_stakeWeight of outcome
+// sets to 0, if any.
+func retiredByOutcome(outcome *ProposalOutcome) sdk.Asset {
+	value, ok := outcome.Meta["update_stakeWeight"]
+	if !ok {
+		return ""
+	}
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	asset, f := checkStakeAssetName(parts[0])
+	if f != nil {
+		return ""
+	}
+	if w, f := checkStakeWeight(parts[1], true); f != nil || w != 0 {
+		return ""
+	}
+	return asset
+}
+
+// checkStakeMinUpdate validates update_stakeMin=value for prj.
+func checkStakeMinUpdate(prj *Project, value string) (float64, *failure) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fail(errcode.InvalidValue, "invalid min stake update")
+	}
+	if v < 0 {
+		return 0, fail(errcode.InvalidValue, "min stake cannot be negative")
+	}
+	amount, f := checkAmount(v)
+	if f != nil {
+		return 0, f
+	}
+	if v > 0 && amount <= 0 {
+		return 0, fail(errcode.InvalidValue, "min stake is below the minimum representable amount")
+	}
+	if (amount > 0) != (prj.Config.StakeMinAmt > 0) {
+		return 0, fail(errcode.InvalidValue, "cannot switch between free and paid membership")
+	}
+	return v, nil
+}
+
+// checkProjectText validates the new value of a project name, description or
+// metadata update against the limits project_create applies.
+func checkProjectText(action, value string) *failure {
+	value = strings.TrimSpace(value)
+	switch action {
+	case "update_name":
+		if value == "" {
+			return fail(errcode.InvalidValue, "project name cannot be empty")
+		}
+		if len(value) > MaxNameLength {
+			return fail(errcode.InvalidValue, fmt.Sprintf("project name exceeds maximum length of %d characters", MaxNameLength))
+		}
+	case "update_description":
+		if len(value) > MaxDescriptionLength {
+			return fail(errcode.InvalidValue, fmt.Sprintf("project description exceeds maximum length of %d characters", MaxDescriptionLength))
+		}
+	case "update_metadata":
+		if len(value) > MaxDescriptionLength {
+			return fail(errcode.InvalidValue, fmt.Sprintf("project metadata exceeds maximum length of %d characters", MaxDescriptionLength))
+		}
+	}
+	return nil
+}
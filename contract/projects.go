@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 )
 
@@ -24,7 +25,7 @@ func CreateProject(payload *string) *string {
 	cfg := loadContractConfig()
 	caller := getActorAddress()
 	if !cfg.ProjectCreationPublic && !isContractOwner(caller) {
-		sdk.Abort("only contract owner can create projects")
+		abort(errcode.Unauthorized, "only contract owner can create projects")
 	}
 
 	input := decodeCreateProjectArgs(payload)
@@ -37,6 +38,7 @@ func CreateProject(payload *string) *string {
 	var stakeAmount Amount
 	var depositAmount Amount
 	var treasuryAmount Amount
+	var feeShared bool
 
 	// Check if this is a free membership DAO (no stake required)
 	freeMembership := input.ProjectConfig.StakeMinAmt <= 0
@@ -52,24 +54,48 @@ func CreateProject(payload *string) *string {
 		// Paid membership requires a transfer intent
 		ta := getFirstTransferAllow()
 		if ta == nil {
-			sdk.Abort("no valid transfer intent provided")
+			abort(errcode.NoIntent, "no valid transfer intent provided")
 		}
 		baseAsset = ta.Token
+		// Savings are only held in treasuries; see recognizeHbdInterest.
+		if baseAsset == sdk.AssetHbdSavings {
+			abort(errcode.WrongAsset, "hbd_savings cannot be the funds asset")
+		}
 
 		// determine required initial stake
 		stakeLimit := ta.Limit
 		stakeMin := input.ProjectConfig.StakeMinAmt
-		if FloatToAmount(stakeMin) > FloatToAmount(stakeLimit) {
-			sdk.Abort(fmt.Sprintf("transfer limit %f < StakeMinAmt %f", stakeLimit, stakeMin))
+		depositAmount = FloatToAmount(stakeLimit)
+		// A creation fee in the stake asset comes out of the same intent.
+		feeShared = cfg.CreationFee > 0 && cfg.CreationFeeAsset == ta.Token
+		if feeShared {
+			depositAmount -= cfg.CreationFee
+		}
+		if FloatToAmount(stakeMin) > depositAmount {
+			if feeShared {
+				abort(errcode.StakeAmount, fmt.Sprintf("transfer limit %f < StakeMinAmt %f plus creation fee %f", stakeLimit, stakeMin, AmountToFloat(cfg.CreationFee)))
+			}
+			abort(errcode.StakeAmount, fmt.Sprintf("transfer limit %f < StakeMinAmt %f", stakeLimit, stakeMin))
 		}
 
 		stakeAmount = FloatToAmount(stakeMin)
-		depositAmount = FloatToAmount(stakeLimit)
 		treasuryAmount = depositAmount - stakeAmount
 
 		// Draw funds from the intent
 		sdk.HiveDraw(AmountToInt64(depositAmount), ta.Token)
 	}
+	if cfg.CreationFee > 0 {
+		chargeCreationFee(cfg, feeShared)
+	}
+
+	if len(input.StakeWeights) > 0 {
+		if !input.ProjectConfig.VotingSystem.IsStakeWeighted() {
+			abort(errcode.InvalidValue, "additional stake assets require a stake-weighted voting system")
+		}
+		if _, ok := input.StakeWeights[baseAsset]; ok {
+			abort(errcode.InvalidValue, fmt.Sprintf("%s is the funds asset and always has weight 1", baseAsset.String()))
+		}
+	}
 
 	// --- create project ---
 	id := getCount(ProjectsCount)
@@ -92,6 +118,8 @@ func CreateProject(payload *string) *string {
 		Tx:          txID,
 		StakeTotal:  stakeAmount,
 		MemberCount: 1,
+
+		StakeWeights: input.StakeWeights,
 	}
 	prj.Config = input.ProjectConfig
 
@@ -107,17 +135,21 @@ func CreateProject(payload *string) *string {
 	}
 	saveMember(prj.ID, &creatorMember)
 	// Save initial stake history
-	saveStakeHistory(prj.ID, callerAddr, stakeAmount, now, 0)
+	saveStakeHistory(prj.ID, callerAddr, stakeAmount, nil, now, 0)
 	// Initialize treasury with the treasury amount
 	if treasuryAmount > 0 {
 		addTreasuryFunds(prj.ID, baseAsset, treasuryAmount)
 	}
 	// save project
 	saveProject(&prj)
+	markListsIndexed(prj.ID)
 	setCount(ProjectsCount, id+1)
 
 	tokenStr := baseAsset.String()
 	emitProjectCreatedEvent(&prj, callerStr)
+	if cfg.CreationFee > 0 {
+		emitCreationFeeEvent(prj.ID, callerStr, cfg.Owner.String(), AmountToFloat(cfg.CreationFee), cfg.CreationFeeAsset.String())
+	}
 	if stakeAmount > 0 {
 		emitFundsAdded(prj.ID, callerStr, AmountToFloat(stakeAmount), tokenStr, true)
 	}
@@ -134,29 +166,29 @@ func CreateProject(payload *string) *string {
 //go:wasmexport project_join
 func JoinProject(projectID *string) *string {
 	requireInitialized()
-	rawID := unwrapPayload(projectID, "project ID is required")
+	rawID := unwrapSinglePayload(projectID, "project ID is required", "projectId")
 	id, err := strconv.ParseUint(rawID, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid project ID")
+		abort(errcode.InvalidPayload, "invalid project ID")
 	}
 	prj := loadProject(id)
 	if prj.Paused {
-		sdk.Abort("project paused")
+		abort(errcode.Paused, "project paused")
 	}
 	caller := getActorAddress()
 	callerAddr := caller
 
 	if _, exists := loadMember(prj.ID, callerAddr); exists {
-		sdk.Abort("already a member")
+		abort(errcode.AlreadyMember, "already a member")
 	}
 
 	whitelisted := isWhitelisted(prj.ID, callerAddr)
 	if !checkNFTMembership(prj, callerAddr) {
-		sdk.Abort("membership nft not owned")
+		abort(errcode.NFTRequired, "membership nft not owned")
 	}
 	if prj.Config.WhitelistOnly {
 		if !whitelisted {
-			sdk.Abort("whitelist approval required")
+			abort(errcode.NotWhitelisted, "whitelist approval required")
 		}
 		deleteWhitelistEntry(prj.ID, callerAddr)
 	}
@@ -174,25 +206,25 @@ func JoinProject(projectID *string) *string {
 		// --- get first valid transfer intent ---
 		ta := getFirstTransferAllow()
 		if ta == nil {
-			sdk.Abort("no valid transfer intent provided")
+			abort(errcode.NoIntent, "no valid transfer intent provided")
 		}
 		if ta.Token != prj.FundsAsset {
-			sdk.Abort(fmt.Sprintf("invalid asset, expected %s", AssetToString(prj.FundsAsset)))
+			abort(errcode.WrongAsset, fmt.Sprintf("invalid asset, expected %s", AssetToString(prj.FundsAsset)))
 		}
 
 		if prj.Config.VotingSystem == VotingSystemDemocratic {
 			expectedStake := FloatToAmount(prj.Config.StakeMinAmt)
 			providedStake := FloatToAmount(ta.Limit)
 			if providedStake != expectedStake {
-				sdk.Abort(fmt.Sprintf("democratic projects require exactly %f %s", prj.Config.StakeMinAmt, ta.Token.String()))
+				abort(errcode.StakeAmount, fmt.Sprintf("democratic projects require exactly %f %s", prj.Config.StakeMinAmt, ta.Token.String()))
 			}
 		}
 
-		if prj.Config.VotingSystem == VotingSystemStake {
+		if prj.Config.VotingSystem.IsStakeWeighted() {
 			requiredStake := FloatToAmount(prj.Config.StakeMinAmt)
 			providedStake := FloatToAmount(ta.Limit)
 			if providedStake < requiredStake {
-				sdk.Abort(fmt.Sprintf("stake too low, minimum %f %s required", prj.Config.StakeMinAmt, ta.Token.String()))
+				abort(errcode.StakeAmount, fmt.Sprintf("stake too low, minimum %f %s required", prj.Config.StakeMinAmt, ta.Token.String()))
 			}
 		}
 
@@ -210,8 +242,10 @@ func JoinProject(projectID *string) *string {
 		JoinSeq:        allocateJoinSeq(prj),
 	}
 	saveMember(prj.ID, &newMember)
+	// Start the dividend books at today's accumulator so past distributions are not claimable.
+	settleDividends(prj.ID, callerAddr, 0, depositAmount)
 	// Save initial stake history
-	saveStakeHistory(prj.ID, callerAddr, depositAmount, now, 0)
+	saveStakeHistory(prj.ID, callerAddr, depositAmount, nil, now, 0)
 
 	// Re-read the finance record before mutating it. `prj` was loaded BEFORE
 	// checkNFTMembership above, which makes an sdk.ContractCall into a
@@ -244,10 +278,10 @@ func WhitelistMembers(payload *string) *string {
 	prj := loadProject(projectID)
 	caller := getActorAddress()
 	if !hasOwner(prj) {
-		sdk.Abort("project is autonomous - no owner privileges")
+		abort(errcode.Autonomous, "project is autonomous - no owner privileges")
 	}
 	if caller != prj.Owner {
-		sdk.Abort("only owner can update whitelist")
+		abort(errcode.Unauthorized, "only owner can update whitelist")
 	}
 	added := addWhitelistEntries(prj.ID, addresses)
 	if len(added) > 0 {
@@ -266,10 +300,10 @@ func RemoveWhitelistedMembers(payload *string) *string {
 	prj := loadProject(projectID)
 	caller := getActorAddress()
 	if !hasOwner(prj) {
-		sdk.Abort("project is autonomous - no owner privileges")
+		abort(errcode.Autonomous, "project is autonomous - no owner privileges")
 	}
 	if caller != prj.Owner {
-		sdk.Abort("only owner can update whitelist")
+		abort(errcode.Unauthorized, "only owner can update whitelist")
 	}
 	removed := removeWhitelistEntries(prj.ID, addresses)
 	if len(removed) > 0 {
@@ -279,33 +313,40 @@ func RemoveWhitelistedMembers(payload *string) *string {
 }
 
 // LeaveProject either schedules or finalizes an exit, blocking folks with active payout locks.
+// In a dissolved project it pays out the caller's stake and treasury share at once.
 // Example payload: LeaveProject(strptr("123"))
 //
 //go:wasmexport project_leave
 func LeaveProject(projectID *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(projectID, "project ID is required")
+	raw := unwrapSinglePayload(projectID, "project ID is required", "projectId")
 	id, err := strconv.ParseUint(raw, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid project ID")
+		abort(errcode.InvalidPayload, "invalid project ID")
 	}
 	caller := getActorAddress()
 	callerAddr := caller
-	prj := loadProject(id)
+	prj := loadProjectRecord(id)
+	if prj.Dissolved {
+		// Nothing is left to protect with cooldowns or locks: the member takes
+		// their stake and share of the treasury right away.
+		member := getMember(prj.ID, callerAddr)
+		return claimDissolvedShare(prj, callerAddr, &member)
+	}
 	if prj.Paused {
-		sdk.Abort("project paused")
+		abort(errcode.Paused, "project paused")
 	}
 
 	member := getMember(prj.ID, callerAddr)
 
 	// Owner must transfer ownership before leaving (unless project is autonomous)
 	if hasOwner(prj) && callerAddr == prj.Owner {
-		sdk.Abort("owner must transfer ownership before leaving")
+		abort(errcode.Locked, "owner must transfer ownership before leaving")
 	}
 
 	now := nowUnix()
 	if hasActivePayout(prj.ID, callerAddr) {
-		sdk.Abort("active proposal requesting funds")
+		abort(errcode.Locked, "active proposal requesting funds")
 	}
 	if member.ExitRequested == 0 {
 		member.ExitRequested = now
@@ -313,14 +354,14 @@ func LeaveProject(projectID *string) *string {
 		return strptr("exit requested")
 	}
 	if now-member.ExitRequested < int64(prj.Config.LeaveCooldownHours*3600) {
-		sdk.Abort("cooldown not passed")
+		abort(errcode.Cooldown, "cooldown not passed")
 	}
 	// Stake stays put until every proposal this member voted on has been decided.
 	// The cooldown is a fixed delay and cannot cover a voting period longer than
 	// itself; this covers the actual decision the member influenced. Arming the
 	// exit is still allowed — only the final withdrawal waits.
 	if now < member.VoteLockUntil {
-		sdk.Abort(fmt.Sprintf("stake locked until %s: you voted on a proposal that is still running",
+		abort(errcode.Locked, fmt.Sprintf("stake locked until %s: you voted on a proposal that is still running",
 			time.Unix(member.VoteLockUntil, 0).UTC().Format(time.RFC3339)))
 	}
 
@@ -332,6 +373,8 @@ func LeaveProject(projectID *string) *string {
 	if withdraw > 0 {
 		sdk.HiveTransfer(caller, AmountToInt64(withdraw), prj.FundsAsset)
 	}
+	exitDividends(prj.ID, callerAddr, withdraw)
+	refundAssetStakes(prj, callerAddr, &member)
 
 	// Delete all stake history for this member
 	deleteAllStakeHistory(prj.ID, callerAddr, member.StakeIncrement)
@@ -340,7 +383,7 @@ func LeaveProject(projectID *string) *string {
 		prj.MemberCount--
 	}
 	if prj.StakeTotal < withdraw {
-		sdk.Abort("accounting error: stake total mismatch")
+		abort(errcode.Internal, "accounting error: stake total mismatch")
 	}
 	prj.StakeTotal -= withdraw
 	saveProjectFinance(prj)
@@ -361,20 +404,19 @@ func LeaveProject(projectID *string) *string {
 //go:wasmexport project_unstake
 func UnstakeProject(payload *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(payload, "unstake payload required")
-	parts := strings.Split(raw, "|")
-	projectID := parseEntityIDField(parts[0], "project id")
+	f := decodePayloadFields(payload, "unstake payload required", "projectId", "amount")
+	projectID := parseEntityIDField(f.get(0), "project id")
 
 	caller := getActorAddress()
 	callerAddr := caller
 	prj := loadProject(projectID)
 	if prj.Paused {
-		sdk.Abort("project paused")
+		abort(errcode.Paused, "project paused")
 	}
 	// Democratic members hold a fixed one-vote stake (== StakeMinAmt); there is
 	// nothing to partially withdraw without dropping below the minimum.
 	if prj.Config.VotingSystem == VotingSystemDemocratic {
-		sdk.Abort("partial unstake not supported in one-member-one-vote projects")
+		abort(errcode.InvalidValue, "partial unstake not supported in one-member-one-vote projects")
 	}
 
 	member := getMember(prj.ID, callerAddr)
@@ -382,18 +424,18 @@ func UnstakeProject(payload *string) *string {
 
 	// Phase 1: no request armed -> validate and arm it with the requested amount.
 	if member.UnstakeRequested == 0 {
-		if len(parts) < 2 || strings.TrimSpace(parts[1]) == "" {
-			sdk.Abort("unstake payload requires projectId|amount")
+		if strings.TrimSpace(f.get(1)) == "" {
+			abort(errcode.InvalidPayload, "unstake payload requires projectId|amount")
 		}
-		amount := FloatToAmount(mustParseFloat(strings.TrimSpace(parts[1]), "invalid unstake amount"))
+		amount := FloatToAmount(mustParseFloat(strings.TrimSpace(f.get(1)), "invalid unstake amount"))
 		if amount <= 0 {
-			sdk.Abort("unstake amount must be positive")
+			abort(errcode.InvalidValue, "unstake amount must be positive")
 		}
 		if amount > member.Stake {
-			sdk.Abort("unstake amount exceeds your stake")
+			abort(errcode.InsufficientFunds, "unstake amount exceeds your stake")
 		}
 		if member.Stake-amount < FloatToAmount(prj.Config.StakeMinAmt) {
-			sdk.Abort("remaining stake would fall below the minimum — use project_leave to exit fully")
+			abort(errcode.StakeAmount, "remaining stake would fall below the minimum — use project_leave to exit fully")
 		}
 		member.UnstakeRequested = now
 		member.UnstakePending = amount
@@ -404,10 +446,10 @@ func UnstakeProject(payload *string) *string {
 	// Phase 2: finalize the pending request once the cooldown has passed and no
 	// voted-on proposal is still running.
 	if now-member.UnstakeRequested < int64(prj.Config.LeaveCooldownHours*3600) {
-		sdk.Abort("cooldown not passed")
+		abort(errcode.Cooldown, "cooldown not passed")
 	}
 	if now < member.VoteLockUntil {
-		sdk.Abort(fmt.Sprintf("stake locked until %s: you voted on a proposal that is still running",
+		abort(errcode.Locked, fmt.Sprintf("stake locked until %s: you voted on a proposal that is still running",
 			time.Unix(member.VoteLockUntil, 0).UTC().Format(time.RFC3339)))
 	}
 
@@ -425,6 +467,7 @@ func UnstakeProject(payload *string) *string {
 	}
 
 	sdk.HiveTransfer(caller, AmountToInt64(amount), prj.FundsAsset)
+	settleDividends(prj.ID, callerAddr, member.Stake, member.Stake-amount)
 	member.Stake -= amount
 	member.LastActionAt = now
 	member.StakeIncrement++
@@ -432,11 +475,11 @@ func UnstakeProject(payload *string) *string {
 	// a same-block proposal's denominator unaffected, matching stake top-ups; a
 	// voter's weight on already-open proposals is capped at current stake in
 	// VoteProposal, so a lower balance cannot vote with the old snapshot.
-	saveStakeHistory(prj.ID, callerAddr, member.Stake, now+1, member.StakeIncrement)
+	saveStakeHistory(prj.ID, callerAddr, member.Stake, member.AssetStakes, now+1, member.StakeIncrement)
 	saveMember(prj.ID, &member)
 
 	if prj.StakeTotal < amount {
-		sdk.Abort("accounting error: stake total mismatch")
+		abort(errcode.Internal, "accounting error: stake total mismatch")
 	}
 	prj.StakeTotal -= amount
 	saveProjectFinance(prj)
@@ -497,7 +540,7 @@ func checkNFTMembership(prj *Project, callerAddr sdk.Address) bool {
 		AddressToString(callerAddr),
 	)
 
-	editions := sdk.ContractCall(contractName, functionName, payload, nil)
+	editions := callContract(contractName, functionName, payload, nil)
 	if editions == nil {
 		return false
 	}
@@ -556,7 +599,7 @@ func AddFunds(payload *string) *string {
 	// Treasury donations are allowed while paused, but increasing stake (voting
 	// weight) is not — that mirrors the Join/Leave pause guards.
 	if prj.Paused && input.ToStake {
-		sdk.Abort("cannot add stake while project is paused")
+		abort(errcode.Paused, "cannot add stake while project is paused")
 	}
 	caller := getActorAddress()
 	callerAddr := caller
@@ -564,14 +607,14 @@ func AddFunds(payload *string) *string {
 	// Get all valid transfer intents
 	transfers := getAllTransferAllows()
 	if len(transfers) == 0 {
-		sdk.Abort("no valid transfer intent provided")
+		abort(errcode.NoIntent, "no valid transfer intent provided")
 	}
 
 	// Pre-validate staking conditions if toStake is requested
 	var stakingMember *Member
 	if input.ToStake {
 		if prj.Config.VotingSystem == VotingSystemDemocratic {
-			sdk.Abort("cannot add member stake > StakeMinAmt in democratic systems")
+			abort(errcode.StakeAmount, "cannot add member stake > StakeMinAmt in democratic systems")
 		}
 		member := getMember(prj.ID, callerAddr)
 		stakingMember = &member
@@ -587,10 +630,25 @@ func AddFunds(payload *string) *string {
 		sdk.HiveDraw(mAmount, ta.Token)
 
 		// Determine if this asset should go to stake or treasury
-		if input.ToStake && ta.Token == prj.FundsAsset {
-			// Main project asset goes to stake
+		if input.ToStake && isStakeAsset(prj, ta.Token) {
 			member := stakingMember
-			member.Stake = safeAddAmount(member.Stake, depositAmount)
+			if ta.Token == prj.FundsAsset {
+				// Main project asset goes to stake
+				newStake := safeAddAmount(member.Stake, depositAmount)
+				settleDividends(prj.ID, callerAddr, member.Stake, newStake)
+				member.Stake = newStake
+				prj.StakeTotal = safeAddAmount(prj.StakeTotal, depositAmount)
+			} else {
+				// Additional stake asset: tracked raw, weighted on read
+				if member.AssetStakes == nil {
+					member.AssetStakes = map[sdk.Asset]Amount{}
+				}
+				if prj.AssetStakeTotals == nil {
+					prj.AssetStakeTotals = map[sdk.Asset]Amount{}
+				}
+				member.AssetStakes[ta.Token] = safeAddAmount(member.AssetStakes[ta.Token], depositAmount)
+				prj.AssetStakeTotals[ta.Token] = safeAddAmount(prj.AssetStakeTotals[ta.Token], depositAmount)
+			}
 			member.LastActionAt = now
 			member.StakeIncrement++
 			// Changing your stake re-arms the leave cooldown. Without this an
@@ -609,8 +667,7 @@ func AddFunds(payload *string) *string {
 			// while the threshold denominator (StakeSnapshot) is captured before the
 			// top-up — counting it in the numerator only let a voter exceed 100% of
 			// the denominator. Joins keep their exact timestamp.
-			saveStakeHistory(prj.ID, callerAddr, member.Stake, now+1, member.StakeIncrement)
-			prj.StakeTotal = safeAddAmount(prj.StakeTotal, depositAmount)
+			saveStakeHistory(prj.ID, callerAddr, member.Stake, member.AssetStakes, now+1, member.StakeIncrement)
 			stakeAdded = true
 			emitFundsAdded(prj.ID, AddressToString(callerAddr), AmountToFloat(depositAmount), ta.Token.String(), true)
 		} else {
@@ -634,7 +691,7 @@ func AddFunds(payload *string) *string {
 func getMember(projectID uint64, user sdk.Address) Member {
 	member, ok := loadMember(projectID, user)
 	if !ok {
-		sdk.Abort(fmt.Sprintf("%s is not a member", AddressToString(user)))
+		abort(errcode.NotMember, fmt.Sprintf("%s is not a member", AddressToString(user)))
 	}
 	return *member
 }
@@ -647,15 +704,7 @@ func kickMember(prj *Project, addr sdk.Address) {
 		return // silently skip non-members
 	}
 
-	// Can't kick owner
-	if hasOwner(prj) && addr == prj.Owner {
-		sdk.Abort("cannot kick project owner")
-	}
-
-	// Check payout locks
-	if hasActivePayout(prj.ID, addr) {
-		sdk.Abort(fmt.Sprintf("cannot kick %s: active payout pending", AddressToString(addr)))
-	}
+	must(checkKick(prj, addr))
 
 	// Refund stake. Skipped for a zero balance — see LeaveProject. Here the stakes are
 	// higher still: a zero-value transfer aborts the ENTIRE ExecuteProposal, so a
@@ -665,6 +714,8 @@ func kickMember(prj *Project, addr sdk.Address) {
 	if withdraw > 0 {
 		sdk.HiveTransfer(addr, AmountToInt64(withdraw), prj.FundsAsset)
 	}
+	exitDividends(prj.ID, addr, withdraw)
+	refundAssetStakes(prj, addr, member)
 
 	// Cleanup
 	deleteAllStakeHistory(prj.ID, addr, member.StakeIncrement)
@@ -675,7 +726,7 @@ func kickMember(prj *Project, addr sdk.Address) {
 		prj.MemberCount--
 	}
 	if prj.StakeTotal < withdraw {
-		sdk.Abort("accounting error: stake total mismatch")
+		abort(errcode.Internal, "accounting error: stake total mismatch")
 	}
 	prj.StakeTotal -= withdraw
 
@@ -684,28 +735,39 @@ func kickMember(prj *Project, addr sdk.Address) {
 	emitFundsRemoved(prj.ID, AddressToString(addr), AmountToFloat(withdraw), AssetToString(prj.FundsAsset), true)
 }
 
+// checkKick reports why addr cannot be kicked: the owner cannot, and neither
+// can a member an approved payout is still owed to.
+func checkKick(prj *Project, addr sdk.Address) *failure {
+	if hasOwner(prj) && addr == prj.Owner {
+		return fail(errcode.InvalidValue, "cannot kick project owner")
+	}
+	if hasActivePayout(prj.ID, addr) {
+		return fail(errcode.Locked, fmt.Sprintf("cannot kick %s: active payout pending", AddressToString(addr)))
+	}
+	return nil
+}
+
 // TransferProjectOwnership lets the owner hand over control, but we enforce the target is still a member.
 // Example payload: TransferProjectOwnership(strptr("5|hive:alice"))
 //
 //go:wasmexport project_transfer
 func TransferProjectOwnership(payload *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(payload, "transfer payload required")
-	parts := strings.Split(raw, "|")
-	if len(parts) < 2 {
-		sdk.Abort("transfer payload requires projectId|newOwner")
+	f := decodePayloadFields(payload, "transfer payload required", "projectId", "newOwner")
+	if !f.has(1) {
+		abort(errcode.InvalidPayload, "transfer payload requires projectId|newOwner")
 	}
-	idStr := strings.TrimSpace(parts[0])
+	idStr := strings.TrimSpace(f.get(0))
 	if idStr == "" {
-		sdk.Abort("project ID is required")
+		abort(errcode.InvalidPayload, "project ID is required")
 	}
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid project ID")
+		abort(errcode.InvalidPayload, "invalid project ID")
 	}
-	newOwnerStr := strings.TrimSpace(parts[1])
+	newOwnerStr := strings.TrimSpace(f.get(1))
 	if newOwnerStr == "" {
-		sdk.Abort("new owner address required")
+		abort(errcode.InvalidPayload, "new owner address required")
 	}
 	caller := getActorAddress()
 	callerAddr := caller
@@ -713,14 +775,14 @@ func TransferProjectOwnership(payload *string) *string {
 	validateAddress(newOwnerAddr)
 	prj := loadProject(id)
 	if !hasOwner(prj) {
-		sdk.Abort("project is autonomous - no owner to transfer")
+		abort(errcode.Autonomous, "project is autonomous - no owner to transfer")
 	}
 	if callerAddr != prj.Owner {
-		sdk.Abort("only owner can transfer")
+		abort(errcode.Unauthorized, "only owner can transfer")
 	}
 
 	if _, exists := loadMember(prj.ID, newOwnerAddr); !exists {
-		sdk.Abort("new owner must be a member")
+		abort(errcode.NotMember, "new owner must be a member")
 	}
 
 	prj.Owner = newOwnerAddr
@@ -734,31 +796,27 @@ func TransferProjectOwnership(payload *string) *string {
 //go:wasmexport project_pause
 func EmergencyPauseImmediate(payload *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(payload, "pause payload required")
-	parts := strings.Split(raw, "|")
-	if len(parts) == 0 {
-		sdk.Abort("project ID is required")
-	}
-	idStr := strings.TrimSpace(parts[0])
+	f := decodePayloadFields(payload, "pause payload required", "projectId", "paused")
+	idStr := strings.TrimSpace(f.get(0))
 	if idStr == "" {
-		sdk.Abort("project ID is required")
+		abort(errcode.InvalidPayload, "project ID is required")
 	}
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid project ID")
+		abort(errcode.InvalidPayload, "invalid project ID")
 	}
 	pause := true
-	if len(parts) > 1 {
-		pause = parseBoolField(parts[1])
+	if f.has(1) {
+		pause = parseBoolField(f.get(1))
 	}
 	caller := getActorAddress()
 	callerAddr := caller
 	prj := loadProject(id)
 	if !hasOwner(prj) {
-		sdk.Abort("project is autonomous - use proposal to pause/unpause")
+		abort(errcode.Autonomous, "project is autonomous - use proposal to pause/unpause")
 	}
 	if callerAddr != prj.Owner {
-		sdk.Abort("only owner can pause/unpause")
+		abort(errcode.Unauthorized, "only owner can pause/unpause")
 	}
 	prj.Paused = pause
 	saveProjectMeta(prj)
@@ -776,8 +834,18 @@ func saveProject(prj *Project) {
 }
 
 // loadProject retrieves a project from contract storage by ID.
-// Aborts if the project does not exist.
+// Aborts if the project does not exist or has been dissolved.
 func loadProject(id uint64) *Project {
+	prj := loadProjectRecord(id)
+	if prj.Dissolved {
+		abort(errcode.Dissolved, "project is dissolved")
+	}
+	return prj
+}
+
+// loadProjectRecord is loadProject for dissolved projects too, which members
+// still leave to claim their share.
+func loadProjectRecord(id uint64) *Project {
 	meta := loadProjectMeta(id)
 	cfg := loadProjectConfig(id)
 	fin := loadProjectFinance(id)
@@ -792,8 +860,12 @@ func loadProject(id uint64) *Project {
 		FundsAsset:  fin.FundsAsset,
 		Paused:      meta.Paused,
 		Tx:          meta.Tx,
+		Dissolved:   meta.Dissolved,
 		StakeTotal:  fin.StakeTotal,
 		MemberCount: fin.MemberCount,
+
+		StakeWeights:     fin.StakeWeights,
+		AssetStakeTotals: fin.AssetStakeTotals,
 	}
 }
 
@@ -806,39 +878,40 @@ func saveProjectMeta(prj *Project) {
 		Tx:          prj.Tx,
 		Metadata:    prj.Metadata,
 		URL:         prj.URL,
+		Dissolved:   prj.Dissolved,
 	}
-	data := EncodeProjectMeta(&meta)
-	stateSetIfChanged(projectKey(prj.ID), string(data))
+	stateSetIfChanged(projectKey(prj.ID), encodeProjectMetaRecord(&meta))
 }
 
 func loadProjectMeta(id uint64) *ProjectMeta {
 	key := projectKey(id)
 	ptr := sdk.StateGetObject(key)
 	if ptr == nil || *ptr == "" {
-		sdk.Abort(fmt.Sprintf("project %d not found", id))
+		abort(errcode.NotFound, fmt.Sprintf("project %d not found", id))
 	}
-	meta, err := DecodeProjectMeta([]byte(*ptr))
+	meta, stale, err := decodeProjectMetaRecord(*ptr)
 	if err != nil {
-		sdk.Abort(fmt.Sprintf("failed to decode project meta: %v", err))
+		abort(errcode.Internal, fmt.Sprintf("failed to decode project meta: %v", err))
 	}
+	rewriteStale(key, stale, func() string { return encodeProjectMetaRecord(meta) })
 	return meta
 }
 
 func saveProjectConfig(prj *Project) {
-	data := EncodeProjectConfig(&prj.Config)
-	stateSetIfChanged(projectConfigKey(prj.ID), string(data))
+	stateSetIfChanged(projectConfigKey(prj.ID), encodeProjectConfigRecord(&prj.Config))
 }
 
 func loadProjectConfig(id uint64) *ProjectConfig {
 	key := projectConfigKey(id)
 	ptr := sdk.StateGetObject(key)
 	if ptr == nil || *ptr == "" {
-		sdk.Abort("project config not found")
+		abort(errcode.Internal, "project config not found")
 	}
-	cfg, err := DecodeProjectConfig([]byte(*ptr))
+	cfg, stale, err := decodeProjectConfigRecord(*ptr)
 	if err != nil {
-		sdk.Abort(fmt.Sprintf("failed to decode project config: %v", err))
+		abort(errcode.Internal, fmt.Sprintf("failed to decode project config: %v", err))
 	}
+	rewriteStale(key, stale, func() string { return encodeProjectConfigRecord(cfg) })
 	return cfg
 }
 
@@ -847,20 +920,23 @@ func saveProjectFinance(prj *Project) {
 		FundsAsset:  prj.FundsAsset,
 		StakeTotal:  prj.StakeTotal,
 		MemberCount: prj.MemberCount,
+
+		StakeWeights:     prj.StakeWeights,
+		AssetStakeTotals: prj.AssetStakeTotals,
 	}
-	data := EncodeProjectFinance(&fin)
-	stateSetIfChanged(projectFinanceKey(prj.ID), string(data))
+	stateSetIfChanged(projectFinanceKey(prj.ID), encodeProjectFinanceRecord(&fin))
 }
 
 func loadProjectFinance(id uint64) *ProjectFinance {
 	key := projectFinanceKey(id)
 	ptr := sdk.StateGetObject(key)
 	if ptr == nil || *ptr == "" {
-		sdk.Abort("project finance not found")
+		abort(errcode.Internal, "project finance not found")
 	}
-	fin, err := DecodeProjectFinance([]byte(*ptr))
+	fin, stale, err := decodeProjectFinanceRecord(*ptr)
 	if err != nil {
-		sdk.Abort(fmt.Sprintf("failed to decode project finance: %v", err))
+		abort(errcode.Internal, fmt.Sprintf("failed to decode project finance: %v", err))
 	}
+	rewriteStale(key, stale, func() string { return encodeProjectFinanceRecord(fin) })
 	return fin
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"math"
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 	"sort"
 	"strconv"
@@ -18,33 +19,86 @@ func CreateProposal(payload *string) *string {
 	requireInitialized()
 	input := decodeCreateProposalArgs(payload)
 
-	// Reject semantically-conflicting owner directives: with both present the final
-	// owner would depend on map-iteration order (a consensus hazard).
-	if input.ProposalOutcome != nil && input.ProposalOutcome.Meta != nil {
-		_, hasUpdate := input.ProposalOutcome.Meta["update_owner"]
-		_, hasRemove := input.ProposalOutcome.Meta["remove_owner"]
-		if hasUpdate && hasRemove {
-			sdk.Abort("conflicting owner directives (update_owner and remove_owner)")
-		}
-	}
-
 	caller := getActorAddress()
 	callerStr := caller.String()
 	callerAddr := caller
 	prj := loadProject(input.ProjectID)
+	// Meta values are checked with the same rules execution applies, so a
+	// proposal that could never execute is rejected before anyone votes on it.
+	must(checkOutcomeMeta(prj, input.ProposalOutcome))
 	if prj.Paused {
 		if input.ProposalOutcome == nil || !outcomeIsPauseSafe(input.ProposalOutcome) {
-			sdk.Abort("project is paused")
+			abort(errcode.Paused, "project is paused")
 		}
 	}
 
 	// Only members can create unless config allows public proposals
 	if prj.Config.ProposalsMembersOnly {
 		if _, exists := loadMember(prj.ID, callerAddr); !exists {
-			sdk.Abort("only members can create proposals")
+			abort(errcode.Unauthorized, "only members can create proposals")
+		}
+	}
+
+	prpsl := newProposal(prj, input, callerAddr)
+	// Prevent proposals when there are no stakes (stake-based voting would be meaningless)
+	if prj.Config.VotingSystem.IsStakeWeighted() && prpsl.StakeSnapshot == 0 {
+		abort(errcode.NotEligible, "cannot create proposal with zero total stake in stake-based project")
+	}
+	must(checkProposalSchedule(prpsl, prj))
+	id := prpsl.ID
+
+	if prj.Config.ProposalCost > 0 {
+		ta := getFirstTransferAllow()
+		if ta == nil {
+			abort(errcode.NoIntent, "no valid transfer intent provided")
+		}
+		if ta.Token != prj.FundsAsset {
+			abort(errcode.WrongAsset, fmt.Sprintf("invalid asset, expected %s", AssetToString(prj.FundsAsset)))
+		}
+		costAmount := FloatToAmount(prj.Config.ProposalCost)
+		providedAmount := FloatToAmount(ta.Limit)
+		if providedAmount < costAmount {
+			abort(errcode.InsufficientFunds, fmt.Sprintf("proposal cost requires at least %f %s", prj.Config.ProposalCost, ta.Token.String()))
+		}
+		mAmount := AmountToInt64(costAmount)
+		sdk.HiveDraw(mAmount, ta.Token)
+		addTreasuryFunds(prj.ID, ta.Token, costAmount)
+		// Record what was actually charged so a later cancel refunds exactly this,
+		// even if governance changes ProposalCost in the meantime.
+		prpsl.CostPaid = costAmount
+		emitFundsAdded(prj.ID, callerStr, AmountToFloat(costAmount), ta.Token.String(), false)
+	}
+
+	saveProposal(prpsl)
+	// Payout locks are deliberately NOT taken here; see TallyProposal.
+	//
+	// Locking at creation let ANY member freeze ANY other member's stake without
+	// their consent: naming a victim as the beneficiary of a 0.001 payout blocked
+	// their project_leave and their removal via kick_member until the proposal was
+	// tallied, which cannot happen before the creator-chosen deadline, while
+	// proposal_cancel is restricted to the creator and owner. The victim had no way
+	// out, and stacking proposals defeated a per-proposal cancel. That reproduced a
+	// stake freeze until 2035 and defeated the same escape-hatch invariant the
+	// owner-veto guard in CancelProposal exists to protect.
+	for i, optInput := range input.OptionsList {
+		opt := ProposalOption{
+			Text: optInput.Text,
+			URL:  optInput.URL,
 		}
+		saveProposalOption(prpsl.ID, uint32(i), &opt)
 	}
+	setCount(ProposalsCount, id+1)
 
+	emitProposalCreatedEvent(prpsl, prj.ID, AddressToString(callerAddr), input.OptionsList)
+	emitProposalStateChangedEvent(id, ProposalActive)
+	result := strconv.FormatUint(id, 10)
+	return &result
+}
+
+// newProposal builds the proposal input describes as proposal_create stores
+// it, with the options resolved and the electorate snapshotted now. It aborts
+// on options or a duration proposal_create would refuse.
+func newProposal(prj *Project, input *CreateProposalArgs, creator sdk.Address) *Proposal {
 	isPoll := input.ForcePoll
 	if len(input.OptionsList) == 0 {
 		input.OptionsList = []ProposalOptionInput{
@@ -56,19 +110,17 @@ func CreateProposal(payload *string) *string {
 		}
 	} else {
 		if len(input.OptionsList) < 2 {
-			sdk.Abort("proposals require at least 2 options")
+			abort(errcode.InvalidValue, "proposals require at least 2 options")
 		}
 		if !input.ForcePoll {
 			isPoll = true
 		}
 	}
 
-	id := getCount(ProposalsCount)
-
 	var duration uint64
 	if input.ProposalDuration > 0 {
 		if input.ProposalDuration < prj.Config.ProposalDurationHours {
-			sdk.Abort("Duration must be higher or equal to project defined proposal duration")
+			abort(errcode.InvalidValue, "Duration must be higher or equal to project defined proposal duration")
 		}
 		duration = input.ProposalDuration
 	} else {
@@ -77,94 +129,55 @@ func CreateProposal(payload *string) *string {
 	// Cap duration so CreatedAt + duration*3600 cannot overflow int64 and push the
 	// deadline into the past (which would make the proposal tallyable immediately).
 	if duration > MaxProposalDurationHours {
-		sdk.Abort(fmt.Sprintf("proposal duration must not exceed %d hours", MaxProposalDurationHours))
-	}
-
-	now := nowUnix()
-
-	// Count members / sum stakes from aggregates
-	memberSnap := uint(prj.MemberCount)
-	stakeSnap := prj.StakeTotal
-
-	// Prevent proposals when there are no stakes (stake-based voting would be meaningless)
-	if prj.Config.VotingSystem == VotingSystemStake && stakeSnap == 0 {
-		sdk.Abort("cannot create proposal with zero total stake in stake-based project")
+		abort(errcode.InvalidValue, fmt.Sprintf("proposal duration must not exceed %d hours", MaxProposalDurationHours))
 	}
 
 	txID := ""
 	if txPtr := sdk.GetEnvKey("tx.id"); txPtr != nil {
 		txID = *txPtr
 	}
+	votingSystem := prj.Config.VotingSystem
 
-	prpsl := &Proposal{
-		ID:                  id,
+	return &Proposal{
+		ID:                  getCount(ProposalsCount),
 		ProjectID:           input.ProjectID,
-		Creator:             callerAddr,
+		Creator:             creator,
 		Name:                input.Name,
 		Description:         input.Description,
 		Metadata:            input.Metadata,
 		URL:                 input.URL,
 		Outcome:             input.ProposalOutcome,
-		CreatedAt:           now,
+		CreatedAt:           nowUnix(),
 		DurationHours:       duration,
 		State:               ProposalActive,
 		Tx:                  txID,
-		MemberCountSnapshot: memberSnap,
-		StakeSnapshot:       stakeSnap,
+		MemberCountSnapshot: uint(prj.MemberCount),
+		StakeSnapshot:       projectVotingStake(prj),
 		// Captured together with the two denominators above so that vote eligibility
 		// and the tally denominators describe exactly the same set of members.
 		JoinSeqSnapshot: currentJoinSeq(prj),
+		StakeWeights:    copyStakeWeights(prj.StakeWeights),
 		IsPoll:          isPoll,
 		OptionCount:     uint32(len(input.OptionsList)),
 		ExecutableAt:    0,
+		NotBefore:       input.NotBefore,
+		NotAfter:        input.NotAfter,
+		Condition:       input.Condition,
+		Prerequisites:   input.Prerequisites,
+		VotingSystem:    &votingSystem,
 	}
+}
 
-	if prj.Config.ProposalCost > 0 {
-		ta := getFirstTransferAllow()
-		if ta == nil {
-			sdk.Abort("no valid transfer intent provided")
-		}
-		if ta.Token != prj.FundsAsset {
-			sdk.Abort(fmt.Sprintf("invalid asset, expected %s", AssetToString(prj.FundsAsset)))
-		}
-		costAmount := FloatToAmount(prj.Config.ProposalCost)
-		providedAmount := FloatToAmount(ta.Limit)
-		if providedAmount < costAmount {
-			sdk.Abort(fmt.Sprintf("proposal cost requires at least %f %s", prj.Config.ProposalCost, ta.Token.String()))
-		}
-		mAmount := AmountToInt64(costAmount)
-		sdk.HiveDraw(mAmount, ta.Token)
-		addTreasuryFunds(prj.ID, ta.Token, costAmount)
-		// Record what was actually charged so a later cancel refunds exactly this,
-		// even if governance changes ProposalCost in the meantime.
-		prpsl.CostPaid = costAmount
-		emitFundsAdded(prj.ID, callerStr, AmountToFloat(costAmount), ta.Token.String(), false)
+// checkProposalSchedule holds the creation rules for when a new proposal may
+// execute: its window, its condition and its prerequisites.
+func checkProposalSchedule(prpsl *Proposal, prj *Project) *failure {
+	if f := checkExecutionWindow(prpsl, prj); f != nil {
+		return f
 	}
-
-	saveProposal(prpsl)
-	// Payout locks are deliberately NOT taken here; see TallyProposal.
-	//
-	// Locking at creation let ANY member freeze ANY other member's stake without
-	// their consent: naming a victim as the beneficiary of a 0.001 payout blocked
-	// their project_leave and their removal via kick_member until the proposal was
-	// tallied, which cannot happen before the creator-chosen deadline, while
-	// proposal_cancel is restricted to the creator and owner. The victim had no way
-	// out, and stacking proposals defeated a per-proposal cancel. That reproduced a
-	// stake freeze until 2035 and defeated the same escape-hatch invariant the
-	// owner-veto guard in CancelProposal exists to protect.
-	for i, optInput := range input.OptionsList {
-		opt := ProposalOption{
-			Text: optInput.Text,
-			URL:  optInput.URL,
-		}
-		saveProposalOption(prpsl.ID, uint32(i), &opt)
+	if f := checkConditionAllowed(prpsl); f != nil {
+		return f
 	}
-	setCount(ProposalsCount, id+1)
-
-	emitProposalCreatedEvent(prpsl, prj.ID, AddressToString(callerAddr), input.OptionsList)
-	emitProposalStateChangedEvent(id, ProposalActive)
-	result := strconv.FormatUint(id, 10)
-	return &result
+	return checkPrerequisiteRefs(prpsl)
 }
 
 // -----------------------------------------------------------------------------
@@ -177,20 +190,20 @@ func CreateProposal(payload *string) *string {
 //go:wasmexport proposal_tally
 func TallyProposal(proposalId *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(proposalId, "proposal ID is required")
+	raw := unwrapSinglePayload(proposalId, "proposal ID is required", "proposalId")
 	id, err := strconv.ParseUint(raw, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid proposal ID")
+		abort(errcode.InvalidPayload, "invalid proposal ID")
 	}
 	prpsl := loadProposal(id)
 	prj := loadProject(prpsl.ProjectID)
 
 	if prpsl.State != ProposalActive {
-		sdk.Abort("proposal not active")
+		abort(errcode.ProposalState, "proposal not active")
 	}
 	deadline := prpsl.CreatedAt + int64(prpsl.DurationHours)*3600
 	if nowUnix() < deadline {
-		sdk.Abort(fmt.Sprintf("proposal still running until %s", time.Unix(deadline, 0).UTC().Format(time.RFC3339)))
+		abort(errcode.TooEarly, fmt.Sprintf("proposal still running until %s", time.Unix(deadline, 0).UTC().Format(time.RFC3339)))
 	}
 
 	// Find the winning option. On a TIE the LOWEST index wins (strict >), which on
@@ -231,15 +244,10 @@ func TallyProposal(proposalId *string) *string {
 		quorumThreshold := uint64(math.Ceil(percentageOf(float64(prpsl.MemberCountSnapshot), prj.Config.QuorumPercent)))
 		// Check quorum
 		quorumMet := voterCount >= quorumThreshold
-		// Check threshold. Democratic projects weigh each member as one unit, so the
-		// denominator is the member count at creation; stake projects use total stake.
-		var denom float64
-		if prj.Config.VotingSystem == VotingSystemDemocratic {
-			denom = float64(prpsl.MemberCountSnapshot)
-		} else {
-			denom = AmountToFloat(prpsl.StakeSnapshot)
-		}
-		thresholdMet := denom > 0 && (highestOptionValue/denom) >= (prj.Config.ThresholdPercent/100)
+		prpsl.QuorumReached = quorumMet
+		// Check threshold, raised to the ICC threshold when the proposal calls a
+		// target outside the project's ICC allowlist.
+		thresholdMet := thresholdReached(prj, prpsl, highestOptionValue, approvalThreshold(prj, prpsl))
 
 		if quorumMet && thresholdMet {
 			prpsl.ResultOptionID = int32(highestOptionId)
@@ -249,9 +257,15 @@ func TallyProposal(proposalId *string) *string {
 				// Only an APPROVE ("yes") win executes the outcome. A "no" win — or any
 				// non-approve option — is a rejection and must NOT run payouts/meta/ICC.
 				prpsl.State = ProposalPassed
-				execReady := prpsl.CreatedAt + int64(prpsl.DurationHours+prj.Config.ExecutionDelayHours)*3600
+				execReady := earliestExecution(prpsl, prj)
+				// A late tally must not shorten the time dissenters get to leave.
+				if _, ok := exportMetaTarget(prpsl.Outcome); ok {
+					if r := nowUnix() + exportCoolingOff(prj); r > execReady {
+						execReady = r
+					}
+				}
 				prpsl.ExecutableAt = execReady
-				emitProposalExecutionDelayEvent(prpsl.ProjectID, prpsl.ID, execReady)
+				emitProposalExecutionDelayEvent(prpsl.ProjectID, prpsl.ID, execReady, prpsl.NotAfter)
 			}
 		}
 	}
@@ -280,38 +294,61 @@ func TallyProposal(proposalId *string) *string {
 //go:wasmexport proposal_execute
 func ExecuteProposal(proposalID *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(proposalID, "proposal ID is required")
+	raw := unwrapSinglePayload(proposalID, "proposal ID is required", "proposalId")
 	id, err := strconv.ParseUint(raw, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid proposal ID")
+		abort(errcode.InvalidPayload, "invalid proposal ID")
 	}
 	prpsl := loadProposal(id)
 	prj := loadProject(prpsl.ProjectID)
 	if prj.Paused && !proposalAllowsExecutionWhilePaused(prpsl) {
-		sdk.Abort("project is paused")
+		abort(errcode.Paused, "project is paused")
 	}
 	if prpsl.State == ProposalExecuted {
-		sdk.Abort("proposal already executed")
+		abort(errcode.ProposalState, "proposal already executed")
 	}
 	if prpsl.State != ProposalPassed {
-		sdk.Abort(fmt.Sprintf("proposal is %s", prpsl.State))
-	}
-
-	// If proposal has ICC calls, only creator can execute
-	if prpsl.Outcome != nil && len(prpsl.Outcome.ICC) > 0 {
-		caller := getActorAddress()
-		if caller != prpsl.Creator {
-			sdk.Abort("only proposal creator can execute proposals with inter-contract calls")
+		abort(errcode.ProposalState, fmt.Sprintf("proposal is %s", prpsl.State))
+	}
+	// Past its deadline, or once a prerequisite failed, the proposal fails
+	// instead, for whoever calls first.
+	if prpsl.NotAfter > 0 && nowUnix() > prpsl.NotAfter {
+		failPassedProposal(prpsl)
+		return strptr("expired")
+	}
+	if id, state, failed := failedPrerequisite(prpsl); failed {
+		emitProposalResultEvent(prj.ID, prpsl.ID, fmt.Sprintf("prerequisite %d %s", id, state))
+		failPassedProposal(prpsl)
+		return strptr("prerequisite failed")
+	}
+
+	// Inter-contract calls to allowlisted targets run for anyone once the delay
+	// has passed. Any other target keeps the creator-only rule, and the tally is
+	// checked again against the ICC threshold: the allowlist or the threshold
+	// may have changed since the proposal passed.
+	if iccOutsideAllowlist(prj.ID, prpsl.Outcome) {
+		if getActorAddress() != prpsl.Creator {
+			abort(errcode.Unauthorized, "only proposal creator can execute inter-contract calls outside the allowlist")
+		}
+		if prj.Config.ICCThresholdPercent > 0 {
+			winning := loadProposalOption(prpsl.ID, uint32(prpsl.ResultOptionID))
+			if !thresholdReached(prj, prpsl, AmountToFloat(winning.WeightTotal), prj.Config.ICCThresholdPercent) {
+				abort(errcode.NotEligible, fmt.Sprintf("inter-contract calls outside the allowlist need %.3f%% approval", prj.Config.ICCThresholdPercent))
+			}
 		}
 	}
 
-	requiredReady := prpsl.CreatedAt + int64(prpsl.DurationHours+prj.Config.ExecutionDelayHours)*3600
+	// An export is checked again too; the thresholds may have changed.
+	must(checkExportApproval(prj, prpsl))
+
+	requiredReady := earliestExecution(prpsl, prj)
 	if prpsl.ExecutableAt > requiredReady {
 		requiredReady = prpsl.ExecutableAt
 	}
 	if nowUnix() < requiredReady {
-		sdk.Abort(fmt.Sprintf("execution delay until %s", time.Unix(requiredReady, 0).UTC().Format(time.RFC3339)))
+		abort(errcode.TooEarly, fmt.Sprintf("execution delay until %s", time.Unix(requiredReady, 0).UTC().Format(time.RFC3339)))
 	}
+	requireStateCondition(prpsl)
 
 	// CHECKS-EFFECTS-INTERACTIONS: commit the terminal state BEFORE any payout or
 	// inter-contract call. ExecuteProposal makes an attacker-controlled
@@ -323,10 +360,26 @@ func ExecuteProposal(proposalID *string) *string {
 	prpsl.State = ProposalExecuted
 	saveProposal(prpsl)
 
+	// Reward the executing member. Written here, before any payout, kick or
+	// external call, so a kick_member outcome naming the executor still removes
+	// them and no later step can overwrite the member record with a stale copy.
+	executor := getActorAddress()
+	if m, ok := loadMember(prj.ID, executor); ok {
+		addReputation(prj.ID, m, ReputationExecuteReward, nowUnix(), "execute")
+		saveMember(prj.ID, m)
+	}
+	// Matured HBD savings withdrawals and savings interest are credited before
+	// any outcome reads the treasury.
+	releaseHbdUnstakes(prj.ID, nowUnix())
+	creditHbdInterest(prj.ID)
+
 	fundsTransferred := false
 	configChanged := false
 	stateChanged := false
 	metaChanged := false
+	dissolve := false
+	exportTo := ""
+	financeChanged := false // finance record fields other than balances (stake weights)
 	if prpsl.Outcome != nil {
 		if len(prpsl.Outcome.Payout) > 0 {
 			// Transfer each payout with its specified asset
@@ -335,16 +388,20 @@ func ExecuteProposal(proposalID *string) *string {
 				// Check treasury balance for this asset
 				treasuryBalance := getTreasuryBalance(prj.ID, asset)
 				if treasuryBalance < entry.Amount {
-					sdk.Abort(fmt.Sprintf("insufficient %s funds in treasury", AssetToString(asset)))
+					abort(errcode.InsufficientFunds, fmt.Sprintf("insufficient %s funds in treasury", AssetToString(asset)))
 				}
 				// Remove from treasury
 				if !removeTreasuryFunds(prj.ID, asset, entry.Amount) {
-					sdk.Abort(fmt.Sprintf("failed to remove %s from treasury", AssetToString(asset)))
+					abort(errcode.InsufficientFunds, fmt.Sprintf("failed to remove %s from treasury", AssetToString(asset)))
 				}
 				// Transfer to recipient
 				mAmount := AmountToInt64(entry.Amount)
-				sdk.HiveTransfer(entry.Address, mAmount, asset)
-				emitFundsRemoved(prj.ID, AddressToString(entry.Address), AmountToFloat(entry.Amount), AssetToString(asset), false)
+				if entry.Mode == PayoutModeL1 {
+					sdk.HiveWithdraw(entry.Address, mAmount, asset)
+				} else {
+					sdk.HiveTransfer(entry.Address, mAmount, asset)
+				}
+				emitFundsRemovedVia(prj.ID, AddressToString(entry.Address), AmountToFloat(entry.Amount), AssetToString(asset), false, entry.Mode)
 				fundsTransferred = true
 			}
 			// Promised funds have now moved: release the exit locks taken at tally.
@@ -360,93 +417,47 @@ func ExecuteProposal(proposalID *string) *string {
 			sort.Strings(metaKeys)
 			for _, action := range metaKeys {
 				value := prpsl.Outcome.Meta[action]
+				must(checkMetaAction(prj, storedMetaState{prj.ID}, prpsl.Outcome, action, value))
 				switch action {
 				// todo: add more
 				case "update_threshold":
-					v, err := strconv.ParseFloat(value, 64)
-					if err != nil {
-						sdk.Abort("invalid threshold update")
-					}
-					// positive-form bound also rejects NaN (all NaN comparisons are false)
-					if !(v >= MinThresholdPercent && v <= MaxThresholdPercent) {
-						sdk.Abort(fmt.Sprintf("threshold must be between %.0f%% and %.0f%%", MinThresholdPercent, MaxThresholdPercent))
-					}
+					v, _ := strconv.ParseFloat(value, 64)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "threshold", fmt.Sprintf("%f", prj.Config.ThresholdPercent), fmt.Sprintf("%f", v))
 					prj.Config.ThresholdPercent = v
 					metaChanged = true
 					configChanged = true
 				case "update_quorum":
-					v, err := strconv.ParseFloat(value, 64)
-					if err != nil {
-						sdk.Abort("invalid quorum update")
-					}
-					if !(v >= MinQuorumPercent && v <= MaxQuorumPercent) {
-						sdk.Abort(fmt.Sprintf("quorum must be between %.0f%% and %.0f%%", MinQuorumPercent, MaxQuorumPercent))
-					}
+					v, _ := strconv.ParseFloat(value, 64)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "quorum", fmt.Sprintf("%f", prj.Config.QuorumPercent), fmt.Sprintf("%f", v))
 					prj.Config.QuorumPercent = v
 					metaChanged = true
 					configChanged = true
 				case "update_proposalDuration":
-					v, err := strconv.ParseUint(value, 10, 64)
-					if err != nil {
-						sdk.Abort("invalid proposal duration update")
-					}
-					if v < MinProposalDurationHours {
-						sdk.Abort(fmt.Sprintf("proposal duration must be at least %d hour(s)", MinProposalDurationHours))
-					}
-					if v > MaxProposalDurationHours {
-						sdk.Abort(fmt.Sprintf("proposal duration must not exceed %d hours", MaxProposalDurationHours))
-					}
+					v, _ := strconv.ParseUint(value, 10, 64)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "proposalDuration", fmt.Sprintf("%d", prj.Config.ProposalDurationHours), value)
 					prj.Config.ProposalDurationHours = v
 					metaChanged = true
 					configChanged = true
 				case "update_executionDelay":
-					v, err := strconv.ParseUint(value, 10, 64)
-					if err != nil {
-						sdk.Abort("invalid execution delay update")
-					}
-					if v > MaxDurationHours {
-						sdk.Abort(fmt.Sprintf("execution delay must not exceed %d hours", MaxDurationHours))
-					}
+					v, _ := strconv.ParseUint(value, 10, 64)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "executionDelay", fmt.Sprintf("%d", prj.Config.ExecutionDelayHours), value)
 					prj.Config.ExecutionDelayHours = v
 					metaChanged = true
 					configChanged = true
 				case "update_leaveCooldown":
-					v, err := strconv.ParseUint(value, 10, 64)
-					if err != nil {
-						sdk.Abort("invalid leave cooldown update")
-					}
-					if v > MaxDurationHours {
-						sdk.Abort(fmt.Sprintf("leave cooldown must not exceed %d hours", MaxDurationHours))
-					}
+					v, _ := strconv.ParseUint(value, 10, 64)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "leaveCooldown", fmt.Sprintf("%d", prj.Config.LeaveCooldownHours), value)
 					prj.Config.LeaveCooldownHours = v
 					metaChanged = true
 					configChanged = true
 				case "update_proposalCost":
-					v, err := strconv.ParseFloat(value, 64)
-					if err != nil {
-						sdk.Abort("invalid proposal cost update")
-					}
-					if math.IsNaN(v) || math.IsInf(v, 0) {
-						sdk.Abort("invalid proposal cost update")
-					}
-					if v < 0 {
-						sdk.Abort("proposal cost cannot be negative")
-					}
-					if v > 0 && FloatToAmount(v) <= 0 {
-						sdk.Abort("proposal cost is below the minimum representable amount")
-					}
+					v, _ := strconv.ParseFloat(value, 64)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "proposalCost", fmt.Sprintf("%f", prj.Config.ProposalCost), fmt.Sprintf("%f", v))
 					prj.Config.ProposalCost = v
 					metaChanged = true
 					configChanged = true
 				case "update_membershipNFT":
 					v := strings.TrimSpace(value)
-					validateTokenId(v)
 					prev := ""
 					if prj.Config.MembershipNFT != nil {
 						prev = *prj.Config.MembershipNFT
@@ -495,10 +506,6 @@ func ExecuteProposal(proposalID *string) *string {
 					stateChanged = true
 				case "update_owner":
 					newOwnerAddr := AddressFromString(value)
-					validateAddress(newOwnerAddr)
-					if _, exists := loadMember(prj.ID, newOwnerAddr); !exists {
-						sdk.Abort("new owner must be a member")
-					}
 					oldOwner := prj.Owner
 					prj.Owner = newOwnerAddr
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "owner", AddressToString(oldOwner), AddressToString(newOwnerAddr))
@@ -512,38 +519,82 @@ func ExecuteProposal(proposalID *string) *string {
 					metaChanged = true
 					stateChanged = true
 				case "update_url":
-					if len(value) > MaxURLLength {
-						sdk.Abort(fmt.Sprintf("url exceeds maximum length of %d characters", MaxURLLength))
-					}
 					prev := prj.URL
 					prj.URL = normalizeOptionalField(value)
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "url", prev, prj.URL)
 					metaChanged = true
 					stateChanged = true
+				case "update_name":
+					prev := prj.Name
+					prj.Name = strings.TrimSpace(value)
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "name", prev, prj.Name)
+					metaChanged = true
+					stateChanged = true
+				case "update_description":
+					prev := prj.Description
+					prj.Description = strings.TrimSpace(value)
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "description", prev, prj.Description)
+					metaChanged = true
+					stateChanged = true
+				case "update_metadata":
+					prev := prj.Metadata
+					prj.Metadata = normalizeOptionalField(value)
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "metadata", prev, prj.Metadata)
+					metaChanged = true
+					stateChanged = true
+				case "update_votingSystem":
+					// Open proposals keep counting under the system they were created with.
+					vs, _ := checkVotingSystemUpdate(prj, prpsl.Outcome, value)
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "votingSystem", prj.Config.VotingSystem.String(), vs.String())
+					prj.Config.VotingSystem = vs
+					metaChanged = true
+					configChanged = true
+				case "update_stakeMin":
+					// Members below a raised minimum keep their stake and membership;
+					// in stake-weighted projects they cannot vote until they top up.
+					v, _ := checkStakeMinUpdate(prj, value)
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "stakeMin", fmt.Sprintf("%f", prj.Config.StakeMinAmt), fmt.Sprintf("%f", v))
+					prj.Config.StakeMinAmt = v
+					metaChanged = true
+					configChanged = true
 				case "update_whitelistOnly":
 					val := strings.ToLower(strings.TrimSpace(value))
-					var newVal bool
-					switch val {
-					case "1", "true", "yes":
-						newVal = true
-					case "0", "false", "no":
-						newVal = false
-					default:
-						sdk.Abort("invalid whitelist flag")
-					}
+					newVal := val == "1" || val == "true" || val == "yes"
 					prev := prj.Config.WhitelistOnly
 					prj.Config.WhitelistOnly = newVal
 					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "whitelistOnly", strconv.FormatBool(prev), strconv.FormatBool(newVal))
 					metaChanged = true
 					configChanged = true
-				case "whitelist_add":
-					addresses := parseAddressList(value)
-					if len(addresses) == 0 {
-						sdk.Abort("whitelist_add metadata requires addresses")
+				case "update_iccThreshold":
+					v, _ := strconv.ParseFloat(value, 64)
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "iccThreshold", fmt.Sprintf("%f", prj.Config.ICCThresholdPercent), fmt.Sprintf("%f", v))
+					prj.Config.ICCThresholdPercent = v
+					metaChanged = true
+					configChanged = true
+				case "icc_allowlist_add":
+					added := []string{}
+					for _, target := range parseICCAllowlist(action, value, true) {
+						if setICCAllowlistEntry(prj.ID, target) {
+							added = append(added, target)
+						}
 					}
-					if len(addresses) > MaxWhitelistAddresses {
-						sdk.Abort(fmt.Sprintf("whitelist_add cannot exceed %d addresses per proposal", MaxWhitelistAddresses))
+					if len(added) > 0 {
+						emitICCAllowlistEvent(prj.ID, "add", added)
+						metaChanged = true
+					}
+				case "icc_allowlist_remove":
+					removed := []string{}
+					for _, target := range parseICCAllowlist(action, value, false) {
+						if deleteICCAllowlistEntry(prj.ID, target) {
+							removed = append(removed, target)
+						}
+					}
+					if len(removed) > 0 {
+						emitICCAllowlistEvent(prj.ID, "remove", removed)
+						metaChanged = true
 					}
+				case "whitelist_add":
+					addresses := parseAddressList(value)
 					added := addWhitelistEntries(prj.ID, addresses)
 					if len(added) > 0 {
 						emitWhitelistEvent(prj.ID, "add", added)
@@ -551,12 +602,6 @@ func ExecuteProposal(proposalID *string) *string {
 					}
 				case "whitelist_remove":
 					addresses := parseAddressList(value)
-					if len(addresses) == 0 {
-						sdk.Abort("whitelist_remove metadata requires addresses")
-					}
-					if len(addresses) > MaxWhitelistAddresses {
-						sdk.Abort(fmt.Sprintf("whitelist_remove cannot exceed %d addresses per proposal", MaxWhitelistAddresses))
-					}
 					removed := removeWhitelistEntries(prj.ID, addresses)
 					if len(removed) > 0 {
 						emitWhitelistEvent(prj.ID, "remove", removed)
@@ -564,20 +609,49 @@ func ExecuteProposal(proposalID *string) *string {
 					}
 				case "kick_member":
 					addresses := parseAddressList(value)
-					if len(addresses) == 0 {
-						sdk.Abort("kick_member requires addresses")
-					}
-					if len(addresses) > MaxKickAddresses {
-						sdk.Abort(fmt.Sprintf("kick_member cannot exceed %d addresses per proposal", MaxKickAddresses))
-					}
 					for _, addr := range addresses {
 						kickMember(prj, addr)
 					}
 					metaChanged = true
 					fundsTransferred = true
+				case "update_stakeWeight":
+					parts := strings.SplitN(value, ":", 2)
+					asset := parseStakeAssetName(parts[0])
+					w := parseStakeWeight(parts[1], true)
+					prev := prj.StakeWeights[asset]
+					if prj.StakeWeights == nil {
+						prj.StakeWeights = map[sdk.Asset]float64{}
+					}
+					prj.StakeWeights[asset] = w
+					emitProposalConfigUpdatedEvent(prj.ID, prpsl.ID, "stakeWeight."+asset.String(), fmt.Sprintf("%f", prev), fmt.Sprintf("%f", w))
+					metaChanged = true
+					financeChanged = true
+				case "distribute":
+					distributeDividend(prj, prpsl.ID, value)
+					metaChanged = true
+				case "treasury_stake_hbd":
+					stakeTreasuryHbd(prj, prpsl.ID, value)
+					fundsTransferred = true
+				case "treasury_unstake_hbd":
+					unstakeTreasuryHbd(prj, prpsl.ID, value, nowUnix())
+					fundsTransferred = true
+				case "dissolve_project":
+					// Applied after every other outcome so payouts and config
+					// changes settle first and the liquidation sees final balances.
+					dissolve = true
+				case "export_project":
+					// Run after the project record is committed below, so the
+					// bundle carries every other outcome of this proposal.
+					exportTo = value
 				}
 			}
 		}
+		if dissolve {
+			dissolveProject(prj, prpsl.ID)
+			metaChanged = true
+			fundsTransferred = true
+			stateChanged = true
+		}
 		// CHECKS-EFFECTS-INTERACTIONS, part 2 — the PROJECT record.
 		//
 		// Everything above mutates the in-memory `prj` (payout/kick stake accounting,
@@ -598,7 +672,7 @@ func ExecuteProposal(proposalID *string) *string {
 		// The ICC loop below touches only prj.ID (treasury balances live under their
 		// own state keys, not in ProjectFinance), so it has no struct changes of its
 		// own to persist — nothing is lost by committing here.
-		if fundsTransferred {
+		if fundsTransferred || financeChanged {
 			saveProjectFinance(prj)
 		}
 		if configChanged {
@@ -607,6 +681,10 @@ func ExecuteProposal(proposalID *string) *string {
 		if stateChanged {
 			saveProjectMeta(prj)
 		}
+		if exportTo != "" {
+			target, ids := parseExportMeta(exportTo)
+			exportProject(prj, target, ids, AddressToString(executor), prpsl.ID)
+		}
 
 		if len(prpsl.Outcome.ICC) > 0 {
 			// Execute inter-contract calls
@@ -628,11 +706,11 @@ func ExecuteProposal(proposalID *string) *string {
 						// Check treasury balance
 						treasuryBalance := getTreasuryBalance(prj.ID, asset)
 						if treasuryBalance < amount {
-							sdk.Abort(fmt.Sprintf("insufficient %s funds in treasury for ICC", AssetToString(asset)))
+							abort(errcode.InsufficientFunds, fmt.Sprintf("insufficient %s funds in treasury for ICC", AssetToString(asset)))
 						}
 						// Remove from treasury
 						if !removeTreasuryFunds(prj.ID, asset, amount) {
-							sdk.Abort(fmt.Sprintf("failed to remove %s from treasury for ICC", AssetToString(asset)))
+							abort(errcode.InsufficientFunds, fmt.Sprintf("failed to remove %s from treasury for ICC", AssetToString(asset)))
 						}
 
 						// Create transfer intent.
@@ -656,9 +734,15 @@ func ExecuteProposal(proposalID *string) *string {
 					}
 				}
 
-				// Execute the contract call
-				sdk.ContractCall(icc.ContractAddress, icc.Function, icc.Payload, opts)
-				emitProposalResultEvent(prj.ID, prpsl.ID, fmt.Sprintf("ICC executed: %s.%s", icc.ContractAddress, icc.Function))
+				// Execute the contract call, credit what it sent back and hold its
+				// response to the result the proposal declared; a miss reverts the
+				// whole execution.
+				response := ""
+				if ret := callWithReceipts(prj, icc, opts); ret != nil {
+					response = *ret
+				}
+				checkICCResult(icc, response)
+				emitICCResultEvent(prj.ID, prpsl.ID, icc.ContractAddress, icc.Function, truncateICCResponse(response))
 			}
 		}
 	}
@@ -683,8 +767,7 @@ func ExecuteProposal(proposalID *string) *string {
 // saveProposal persists a proposal in contract state.
 func saveProposal(prpsl *Proposal) {
 	key := proposalKey(prpsl.ID)
-	data := EncodeProposal(prpsl)
-	sdk.StateSetObject(key, string(data))
+	sdk.StateSetObject(key, encodeProposalRecord(prpsl))
 }
 
 // loadProposal retrieves a proposal from contract state by ID.
@@ -693,12 +776,13 @@ func loadProposal(id uint64) *Proposal {
 	key := proposalKey(id)
 	ptr := sdk.StateGetObject(key)
 	if ptr == nil || *ptr == "" {
-		sdk.Abort(fmt.Sprintf("proposal %d not found", id))
+		abort(errcode.NotFound, fmt.Sprintf("proposal %d not found", id))
 	}
-	prpsl, err := DecodeProposal([]byte(*ptr))
+	prpsl, stale, err := decodeProposalRecord(*ptr)
 	if err != nil {
-		sdk.Abort(fmt.Sprintf("failed to decode proposal: %v", err))
+		abort(errcode.Internal, fmt.Sprintf("failed to decode proposal: %v", err))
 	}
+	rewriteStale(key, stale, func() string { return encodeProposalRecord(prpsl) })
 	return prpsl
 }
 
@@ -708,14 +792,14 @@ func loadProposal(id uint64) *Proposal {
 //go:wasmexport proposal_cancel
 func CancelProposal(payload *string) *string {
 	requireInitialized()
-	raw := unwrapPayload(payload, "proposal ID is required")
+	raw := unwrapSinglePayload(payload, "proposal ID is required", "proposalId")
 	id, err := strconv.ParseUint(raw, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid proposal ID")
+		abort(errcode.InvalidPayload, "invalid proposal ID")
 	}
 	prpsl := loadProposal(id)
 	if prpsl.State != ProposalActive {
-		sdk.Abort("proposal not active")
+		abort(errcode.ProposalState, "proposal not active")
 	}
 	prj := loadProject(prpsl.ProjectID)
 
@@ -724,7 +808,7 @@ func CancelProposal(payload *string) *string {
 	// For autonomous projects, only creator can cancel (no owner exists)
 	isOwner := hasOwner(prj) && callerAddr == prj.Owner
 	if callerAddr != prpsl.Creator && !isOwner {
-		sdk.Abort("only creator or owner can cancel")
+		abort(errcode.Unauthorized, "only creator or owner can cancel")
 	}
 
 	// The owner must NOT be able to veto the members' escape hatch. Pause blocks
@@ -732,7 +816,7 @@ func CancelProposal(payload *string) *string {
 	// remove_owner proposal on sight, a hostile owner could freeze all member stake
 	// permanently. Those outcomes may only be withdrawn by their own creator.
 	if isOwner && callerAddr != prpsl.Creator && prpsl.Outcome != nil && outcomeIsPauseSafe(prpsl.Outcome) {
-		sdk.Abort("owner cannot cancel a pause/ownership recovery proposal")
+		abort(errcode.Unauthorized, "owner cannot cancel a pause/ownership recovery proposal")
 	}
 
 	// Refund only if owner (not creator) cancels and a cost was actually charged.
@@ -771,6 +855,19 @@ func CancelProposal(payload *string) *string {
 // Local helpers
 // -----------------------------------------------------------------------------
 
+// failPassedProposal fails a passed proposal that can no longer execute, past
+// its not_after deadline or after a prerequisite failed, and releases the
+// payout locks the tally took for it.
+func failPassedProposal(prpsl *Proposal) {
+	prpsl.State = ProposalFailed
+	prpsl.ExecutableAt = 0
+	if prpsl.Outcome != nil {
+		decrementPayoutLocks(prpsl.ProjectID, prpsl.Outcome.Payout)
+	}
+	saveProposal(prpsl)
+	emitProposalStateChangedEvent(prpsl.ID, prpsl.State)
+}
+
 // percentageOf calculates the percentage of a value.
 // Example: percentageOf(100, 50.5) returns 50.5
 func percentageOf(value, percent float64) float64 {
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"okinoko_dao/errcode"
+)
+
+// -----------------------------------------------------------------------------
+// Reputation
+// -----------------------------------------------------------------------------
+
+// ClaimReputation credits participation reputation for a ballot the caller cast on
+// a tallied proposal that reached quorum. Each vote receipt pays out once.
+//
+// Crediting happens on claim rather than at tally so the tally never has to walk
+// every voter of a large DAO; the vote receipt already proves participation.
+// Example payload: ClaimReputation(strptr("12"))
+//
+//go:wasmexport reputation_claim
+func ClaimReputation(payload *string) *string {
+	requireInitialized()
+	raw := unwrapSinglePayload(payload, "proposal ID is required", "proposalId")
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		abort(errcode.InvalidPayload, "invalid proposal ID")
+	}
+	prpsl := loadProposal(id)
+	loadProject(prpsl.ProjectID) // rejects claims against a dissolved project
+	if prpsl.State == ProposalActive || prpsl.State == ProposalCancelled {
+		abort(errcode.ProposalState, fmt.Sprintf("proposal is %s", prpsl.State))
+	}
+	if !prpsl.QuorumReached {
+		abort(errcode.NotEligible, "proposal did not reach quorum")
+	}
+	caller := getActorAddress()
+	member := getMember(prpsl.ProjectID, caller)
+	rec := loadVoteRecord(prpsl.ID, caller)
+	if rec == nil {
+		abort(errcode.NotEligible, "no vote recorded for this proposal")
+	}
+	if rec.Rewarded {
+		abort(errcode.NotEligible, "reputation already claimed")
+	}
+	addReputation(prpsl.ProjectID, &member, ReputationVoteReward, nowUnix(), "vote")
+	saveMember(prpsl.ProjectID, &member)
+	markVoteRewarded(prpsl.ID, caller, rec)
+	return strptr(strconv.FormatInt(member.Reputation, 10))
+}
+
+// decayReputation halves the member's reputation once per full
+// ReputationHalfLifeHours elapsed since ReputationAt and advances the clock by the
+// periods consumed, so a partial period carries over. Integer halving keeps every
+// validator on the same value. Returns true if the member record changed.
+func decayReputation(projectID uint64, m *Member, now int64) bool {
+	if m.Reputation <= 0 || m.ReputationAt <= 0 || now <= m.ReputationAt {
+		return false
+	}
+	period := int64(ReputationHalfLifeHours) * 3600
+	halvings := (now - m.ReputationAt) / period
+	if halvings == 0 {
+		return false
+	}
+	old := m.Reputation
+	if halvings >= 63 {
+		m.Reputation = 0
+	} else {
+		m.Reputation >>= uint(halvings)
+	}
+	m.ReputationAt += halvings * period
+	emitReputationEvent(projectID, AddressToString(m.Address), m.Reputation-old, m.Reputation, "decay")
+	return true
+}
+
+// addReputation settles pending decay, then credits delta. The caller persists
+// the member.
+func addReputation(projectID uint64, m *Member, delta int64, now int64, reason string) {
+	decayReputation(projectID, m, now)
+	if m.Reputation <= 0 || m.ReputationAt <= 0 {
+		// Nothing left to decay: start a fresh clock for the new credit.
+		m.ReputationAt = now
+	}
+	if m.Reputation > math.MaxInt64-delta {
+		m.Reputation = math.MaxInt64
+	} else {
+		m.Reputation += delta
+	}
+	emitReputationEvent(projectID, AddressToString(m.Address), delta, m.Reputation, reason)
+}
+
+// reputationWeight blends a stake weight with reputation for
+// VotingSystemStakeReputation: stake * (cap + min(rep, cap)) / (2 * cap). A member
+// with no reputation counts half their stake, one at or above the cap counts it
+// in full, and no ballot can outweigh the stake it is backed by.
+func reputationWeight(stake Amount, reputation int64) Amount {
+	rep := reputation
+	if rep < 0 {
+		rep = 0
+	}
+	if rep > ReputationBlendCap {
+		rep = ReputationBlendCap
+	}
+	scaled := math.Floor(float64(stake) * float64(ReputationBlendCap+rep) / float64(2*ReputationBlendCap))
+	return Amount(scaled)
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"okinoko_dao/sdk"
+	"strconv"
+	"strings"
+)
+
+// -----------------------------------------------------------------------------
+// Record versioning
+// -----------------------------------------------------------------------------
+//
+// Every stored record is written as recordMagic, one version byte, then the
+// body produced by the record's encoder. Records written before versioning
+// carry no header and read as version 0. recordMagic cannot start one of them:
+// as a uvarint length it would be a non-minimal encoding binary.PutUvarint never
+// emits, and it is out of range for a big-endian id, a voting system byte or a
+// decimal treasury balance.
+//
+// Changing a layout means bumping current, registering a decoder for the new
+// version and keeping the old ones. The current decoder reads every field its
+// layout has; only older decoders leave fields at their defaults. Loaders
+// rewrite a record in the current version the first time they read an older
+// one (see rewriteStale), so state migrates lazily as it is touched. Record
+// types whose layout never changed (proposal options) are still stored
+// untagged; giving them a schema is part of their first layout change.
+
+// recordMagic opens every versioned record.
+const recordMagic = "\xff\x00"
+
+// recordSchema lists the decoders for every version of one record type.
+type recordSchema struct {
+	name    string
+	current uint8
+	// decoders maps each readable version to its decoder; version 0 is the
+	// untagged layout written before records carried a version.
+	decoders map[uint8]func([]byte) (interface{}, error)
+}
+
+// encode wraps a body in the header of the current version.
+func (s *recordSchema) encode(body []byte) string {
+	return recordMagic + string([]byte{s.current}) + string(body)
+}
+
+// decode reads a record of any registered version. stale reports whether it
+// was written in an older version and should be rewritten.
+func (s *recordSchema) decode(raw string) (v interface{}, stale bool, err error) {
+	version, body := recordVersion(raw)
+	if version < 0 {
+		return nil, false, fmt.Errorf("truncated %s record header", s.name)
+	}
+	dec, ok := s.decoders[uint8(version)]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown %s record version %d", s.name, version)
+	}
+	if v, err = dec([]byte(body)); err != nil {
+		return nil, false, err
+	}
+	return v, uint8(version) != s.current, nil
+}
+
+// recordVersion splits a stored record into its version and body. It returns
+// -1 for a header without a version byte.
+func recordVersion(raw string) (int, string) {
+	if !strings.HasPrefix(raw, recordMagic) {
+		return 0, raw
+	}
+	if len(raw) == len(recordMagic) {
+		return -1, ""
+	}
+	return int(raw[len(recordMagic)]), raw[len(recordMagic)+1:]
+}
+
+var (
+	// Version 1 adds the dissolved flag.
+	projectMetaSchema = &recordSchema{name: "project meta", current: 1, decoders: map[uint8]func([]byte) (interface{}, error){
+		0: func(b []byte) (interface{}, error) { return decodeProjectMetaV0(newReader(b)) },
+		1: func(b []byte) (interface{}, error) { return DecodeProjectMeta(b) },
+	}}
+	// Version 1 adds the ICC threshold; version 0 configs read as having none.
+	projectConfigSchema = &recordSchema{name: "project config", current: 1, decoders: map[uint8]func([]byte) (interface{}, error){
+		0: func(b []byte) (interface{}, error) {
+			cfg, err := decodeProjectConfigV0(newReader(b))
+			return &cfg, err
+		},
+		1: func(b []byte) (interface{}, error) { return DecodeProjectConfig(b) },
+	}}
+	// Version 1 adds the per-asset stake weights and totals; version 0
+	// projects stake only their funds asset.
+	projectFinanceSchema = &recordSchema{name: "project finance", current: 1, decoders: map[uint8]func([]byte) (interface{}, error){
+		0: func(b []byte) (interface{}, error) { return decodeProjectFinanceV0(newReader(b)) },
+		1: func(b []byte) (interface{}, error) { return DecodeProjectFinance(b) },
+	}}
+	// Version 1 adds the reputation decay clock and per-asset stakes; a
+	// version 0 member starts its decay clock on the next reputation change.
+	memberSchema = &recordSchema{name: "member", current: 1, decoders: map[uint8]func([]byte) (interface{}, error){
+		0: func(b []byte) (interface{}, error) {
+			m, err := decodeMemberV0(newReader(b))
+			return &m, err
+		},
+		1: func(b []byte) (interface{}, error) { return DecodeMember(b) },
+	}}
+	// Version 1 adds the quorum flag, the blended ballot totals, stake weights
+	// and payout modes; version 2 adds ICC expectations and receipts, the
+	// execution window, the state condition, prerequisites and the voting
+	// system.
+	proposalSchema = &recordSchema{name: "proposal", current: 2, decoders: map[uint8]func([]byte) (interface{}, error){
+		0: func(b []byte) (interface{}, error) { return decodeProposalV0(newReader(b)) },
+		1: func(b []byte) (interface{}, error) { return decodeProposalV1(newReader(b)) },
+		2: func(b []byte) (interface{}, error) { return DecodeProposal(b) },
+	}}
+	// Treasury balances were decimal strings before versioning; version 1
+	// stores the amount as a big-endian int64 like every other record.
+	treasurySchema = &recordSchema{name: "treasury", current: 1, decoders: map[uint8]func([]byte) (interface{}, error){
+		0: func(b []byte) (interface{}, error) {
+			v, err := strconv.ParseInt(string(b), 10, 64)
+			return Amount(v), err
+		},
+		1: func(b []byte) (interface{}, error) {
+			r := newReader(b)
+			v, err := r.readAmount()
+			if err == nil && r.pos != len(r.data) {
+				err = errors.New("trailing bytes after treasury balance")
+			}
+			return v, err
+		},
+	}}
+)
+
+// simulating is set while proposal_simulate runs. A dry run reads state but
+// never writes it, so stale records are left for the next real transaction to
+// migrate.
+var simulating bool
+
+// rewriteStale stores a record that was read in an older version again in the
+// current one.
+func rewriteStale(key string, stale bool, encode func() string) {
+	if stale && !simulating {
+		sdk.StateSetObject(key, encode())
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Typed record helpers
+// -----------------------------------------------------------------------------
+
+func encodeProjectMetaRecord(meta *ProjectMeta) string {
+	return projectMetaSchema.encode(EncodeProjectMeta(meta))
+}
+
+func decodeProjectMetaRecord(raw string) (*ProjectMeta, bool, error) {
+	v, stale, err := projectMetaSchema.decode(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return v.(*ProjectMeta), stale, nil
+}
+
+func encodeProjectConfigRecord(cfg *ProjectConfig) string {
+	return projectConfigSchema.encode(EncodeProjectConfig(cfg))
+}
+
+func decodeProjectConfigRecord(raw string) (*ProjectConfig, bool, error) {
+	v, stale, err := projectConfigSchema.decode(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return v.(*ProjectConfig), stale, nil
+}
+
+func encodeProjectFinanceRecord(fin *ProjectFinance) string {
+	return projectFinanceSchema.encode(EncodeProjectFinance(fin))
+}
+
+func decodeProjectFinanceRecord(raw string) (*ProjectFinance, bool, error) {
+	v, stale, err := projectFinanceSchema.decode(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return v.(*ProjectFinance), stale, nil
+}
+
+func encodeMemberRecord(m *Member) string {
+	return memberSchema.encode(EncodeMember(m))
+}
+
+func decodeMemberRecord(raw string) (*Member, bool, error) {
+	v, stale, err := memberSchema.decode(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return v.(*Member), stale, nil
+}
+
+func encodeProposalRecord(prpsl *Proposal) string {
+	return proposalSchema.encode(EncodeProposal(prpsl))
+}
+
+func decodeProposalRecord(raw string) (*Proposal, bool, error) {
+	v, stale, err := proposalSchema.decode(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return v.(*Proposal), stale, nil
+}
+
+func encodeTreasuryRecord(amount Amount) string {
+	w := newWriter()
+	w.writeAmount(amount)
+	return treasurySchema.encode(w.bytes())
+}
+
+func decodeTreasuryRecord(raw string) (Amount, bool, error) {
+	v, stale, err := treasurySchema.decode(raw)
+	if err != nil {
+		return 0, false, err
+	}
+	return v.(Amount), stale, nil
+}
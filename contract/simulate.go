@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/CosmWasm/tinyjson/jwriter"
+)
+
+// -----------------------------------------------------------------------------
+// Simulation
+// -----------------------------------------------------------------------------
+//
+// proposal_simulate runs the checks of proposal_execute against the current
+// state and reports, instead of aborting on the first problem, every one it
+// finds. Payouts, treasury actions and the assets sent with inter-contract
+// calls are debited from a running copy of the treasury in execution order,
+// so a proposal that overspends shows which step runs dry. The calls
+// themselves are not made, so their results and expectations are unknown.
+
+// SimulateProposal dry-runs a proposal and returns a JSON report:
+//
+//	{"proposalId":7,"executable":false,
+//	 "checks":[{"step":"delay","ok":false,"code":"E_TOO_EARLY","error":"..."}],
+//	 "payouts":[{"to":"hive:alice","amount":2.5,"asset":"hbd","mode":"ledger","ok":true}],
+//	 "meta":[{"action":"update_quorum","value":"40","ok":true}],
+//	 "icc":[{"contract":"sink","function":"take","allowlisted":false,"assets":{"hive":1.5},"ok":true}]}
+//
+// The payload is a proposal id, to see what executing that proposal now
+// would do, or a proposal_create payload, to try an outcome before it is put
+// to a vote ("proposalId" is then null). Checks that depend on the executor
+// are made for the caller.
+//
+//go:wasmexport proposal_simulate
+func SimulateProposal(payload *string) *string {
+	requireInitialized()
+	simulating = true
+	defer func() { simulating = false }()
+	raw := unwrapPayload(payload, "simulation payload missing")
+	var s *simulation
+	if id, ok := simulatedProposalID(raw); ok {
+		prpsl := loadProposal(id)
+		s = newSimulation(loadProject(prpsl.ProjectID), prpsl)
+		s.existing = true
+		s.checkExecution()
+	} else {
+		input := decodeCreateProposalArgs(payload)
+		prj := loadProject(input.ProjectID)
+		s = newSimulation(prj, newProposal(prj, input, getActorAddress()))
+		s.checkCreation()
+	}
+	s.run()
+	report := s.report()
+	return &report
+}
+
+// simulatedProposalID tells an existing proposal's id from a create payload.
+func simulatedProposalID(raw string) (uint64, bool) {
+	if isJSONPayload(raw) {
+		obj := parseJSONObject(raw)
+		v, ok := obj["proposalId"].(string)
+		if !ok || len(obj) != 1 {
+			return 0, false
+		}
+		raw = v
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(raw), 10, 64)
+	return id, err == nil
+}
+
+type simulation struct {
+	prj      *Project
+	prpsl    *Proposal
+	existing bool // prpsl is stored, not built from a create payload
+	balances map[sdk.Asset]Amount
+	unstakes int // hbd unstakes still pending once matured ones are released
+	kicked   map[sdk.Address]bool
+	checks   []simStep
+	payouts  []simStep
+	meta     []simStep
+	icc      []simStep
+}
+
+// simStep is one line of the report; fail is nil when the step would pass.
+type simStep struct {
+	name  string
+	value string
+	entry PayoutEntry
+	call  InterContractCall
+	fail  *failure
+}
+
+func newSimulation(prj *Project, prpsl *Proposal) *simulation {
+	s := &simulation{prj: prj, prpsl: prpsl, balances: map[sdk.Asset]Amount{}, kicked: map[sdk.Address]bool{}}
+	// Execution releases matured hbd unstakes before anything reads the treasury.
+	now := nowUnix()
+	for _, u := range loadHbdUnstakes(prj.ID) {
+		if u.MaturesAt > now {
+			s.unstakes++
+			continue
+		}
+		s.balances[sdk.AssetHbd] = safeAddAmount(s.balance(sdk.AssetHbd), u.Amount)
+	}
+	return s
+}
+
+func (s *simulation) check(step string, f *failure) {
+	s.checks = append(s.checks, simStep{name: step, fail: f})
+}
+
+// checkExecution mirrors the checks proposal_execute makes before it applies
+// the outcome.
+func (s *simulation) checkExecution() {
+	prj, prpsl := s.prj, s.prpsl
+	if prj.Paused && !proposalAllowsExecutionWhilePaused(prpsl) {
+		s.check("paused", fail(errcode.Paused, "project is paused"))
+	}
+	if prpsl.State != ProposalPassed {
+		s.check("state", fail(errcode.ProposalState, fmt.Sprintf("proposal is %s", prpsl.State)))
+	}
+	if prpsl.NotAfter > 0 && nowUnix() > prpsl.NotAfter {
+		s.check("window", fail(errcode.ProposalState, "past not_after, execution fails the proposal"))
+	}
+	if id, state, failed, pending := checkPrerequisites(prpsl); failed {
+		s.check("prerequisites", fail(errcode.ProposalState, fmt.Sprintf("prerequisite %d %s, execution fails the proposal", id, state)))
+	} else if pending != nil {
+		s.check("prerequisites", pending)
+	}
+	if iccOutsideAllowlist(prj.ID, prpsl.Outcome) {
+		if getActorAddress() != prpsl.Creator {
+			s.check("icc", fail(errcode.Unauthorized, "only proposal creator can execute inter-contract calls outside the allowlist"))
+		}
+		if prj.Config.ICCThresholdPercent > 0 && prpsl.State == ProposalPassed {
+			winning := loadProposalOption(prpsl.ID, uint32(prpsl.ResultOptionID))
+			if !thresholdReached(prj, prpsl, AmountToFloat(winning.WeightTotal), prj.Config.ICCThresholdPercent) {
+				s.check("icc", fail(errcode.NotEligible, fmt.Sprintf("inter-contract calls outside the allowlist need %.3f%% approval", prj.Config.ICCThresholdPercent)))
+			}
+		}
+	}
+	if prpsl.State == ProposalPassed {
+		if f := checkExportApproval(prj, prpsl); f != nil {
+			s.check("export", f)
+		}
+	}
+	ready := earliestExecution(prpsl, prj)
+	if prpsl.ExecutableAt > ready {
+		ready = prpsl.ExecutableAt
+	}
+	if nowUnix() < ready {
+		s.check("delay", fail(errcode.TooEarly, fmt.Sprintf("execution delay until %s", time.Unix(ready, 0).UTC().Format(time.RFC3339))))
+	}
+	if f := checkStateCondition(prpsl); f != nil {
+		s.check("condition", f)
+	}
+}
+
+// checkCreation runs the rules proposal_create applies to a new proposal's
+// schedule, and reports whether its condition holds yet; its vote and delay
+// lie ahead.
+func (s *simulation) checkCreation() {
+	prj, prpsl := s.prj, s.prpsl
+	if prj.Paused && (prpsl.Outcome == nil || !outcomeIsPauseSafe(prpsl.Outcome)) {
+		s.check("paused", fail(errcode.Paused, "project is paused"))
+	}
+	if f := checkExecutionWindow(prpsl, prj); f != nil {
+		s.check("window", f)
+	}
+	if f := checkConditionAllowed(prpsl); f != nil {
+		s.check("condition", f)
+	} else if f := checkStateCondition(prpsl); f != nil {
+		s.check("condition", f)
+	}
+	if f := checkPrerequisiteRefs(prpsl); f != nil {
+		s.check("prerequisites", f)
+	}
+}
+
+// run walks the outcome in the order proposal_execute applies it.
+func (s *simulation) run() {
+	outcome := s.prpsl.Outcome
+	if outcome == nil {
+		return
+	}
+	for _, entry := range outcome.Payout {
+		f := s.debit(entry.Asset, entry.Amount, "")
+		s.payouts = append(s.payouts, simStep{entry: entry, fail: f})
+	}
+	actions := make([]string, 0, len(outcome.Meta))
+	for k := range outcome.Meta {
+		actions = append(actions, k)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		value := outcome.Meta[action]
+		f := checkMetaAction(s.prj, s, outcome, action, value)
+		if f == nil {
+			s.apply(action, value)
+		}
+		s.meta = append(s.meta, simStep{name: action, value: value, fail: f})
+	}
+	for _, icc := range outcome.ICC {
+		var f *failure
+		if !contractExists(icc.ContractAddress) {
+			f = fail(errcode.NotFound, fmt.Sprintf("ICC contract not found: %s", icc.ContractAddress))
+		}
+		for _, asset := range sortedAssetKeys(icc.Assets) {
+			if df := s.debit(asset, icc.Assets[asset], " for ICC"); f == nil {
+				f = df
+			}
+		}
+		s.icc = append(s.icc, simStep{call: icc, fail: f})
+	}
+}
+
+// apply books a meta action that passed checkMetaAction on the running
+// treasury and roster.
+func (s *simulation) apply(action, value string) {
+	switch action {
+	case "distribute":
+		amount, asset, _ := checkDistribute(s.prj, value)
+		s.balances[asset] = s.balance(asset) - amount
+	case "treasury_stake_hbd":
+		amount, _ := checkHbdSavingsAmount(action, value)
+		s.balances[sdk.AssetHbd] = s.balance(sdk.AssetHbd) - amount
+		s.balances[sdk.AssetHbdSavings] = safeAddAmount(s.balance(sdk.AssetHbdSavings), amount)
+	case "treasury_unstake_hbd":
+		amount, _ := checkHbdSavingsAmount(action, value)
+		s.balances[sdk.AssetHbdSavings] = s.balance(sdk.AssetHbdSavings) - amount
+		s.unstakes++
+	case "kick_member":
+		for _, addr := range parseAddressList(value) {
+			s.kicked[addr] = true
+		}
+	}
+}
+
+func (s *simulation) balance(asset sdk.Asset) Amount {
+	if b, ok := s.balances[asset]; ok {
+		return b
+	}
+	b := getTreasuryBalance(s.prj.ID, asset)
+	s.balances[asset] = b
+	return b
+}
+
+func (s *simulation) pendingUnstakes() int {
+	return s.unstakes
+}
+
+func (s *simulation) isMember(addr sdk.Address) bool {
+	if s.kicked[addr] {
+		return false
+	}
+	_, exists := loadMember(s.prj.ID, addr)
+	return exists
+}
+
+// debit takes amount off the running treasury, or reports it short.
+func (s *simulation) debit(asset sdk.Asset, amount Amount, what string) *failure {
+	b := s.balance(asset)
+	if b < amount {
+		return fail(errcode.InsufficientFunds, fmt.Sprintf("insufficient %s funds in treasury%s", AssetToString(asset), what))
+	}
+	s.balances[asset] = b - amount
+	return nil
+}
+
+// report renders the simulation as JSON.
+func (s *simulation) report() string {
+	w := &jwriter.Writer{}
+	w.RawString(`{"proposalId":`)
+	if s.existing {
+		w.Uint64(s.prpsl.ID)
+	} else {
+		w.RawString("null")
+	}
+	executable := true
+	for _, steps := range [][]simStep{s.checks, s.payouts, s.meta, s.icc} {
+		for _, st := range steps {
+			if st.fail != nil {
+				executable = false
+			}
+		}
+	}
+	w.RawString(`,"executable":`)
+	w.Bool(executable)
+	writeSimSteps(w, "checks", s.checks, func(st simStep) {
+		w.RawString(`"step":`)
+		w.String(st.name)
+	})
+	writeSimSteps(w, "payouts", s.payouts, func(st simStep) {
+		w.RawString(`"to":`)
+		w.String(AddressToString(st.entry.Address))
+		w.RawString(`,"amount":`)
+		w.RawString(strconv.FormatFloat(AmountToFloat(st.entry.Amount), 'f', -1, 64))
+		w.RawString(`,"asset":`)
+		w.String(AssetToString(st.entry.Asset))
+		w.RawString(`,"mode":`)
+		w.String(st.entry.Mode.String())
+	})
+	writeSimSteps(w, "meta", s.meta, func(st simStep) {
+		w.RawString(`"action":`)
+		w.String(st.name)
+		w.RawString(`,"value":`)
+		w.String(st.value)
+	})
+	writeSimSteps(w, "icc", s.icc, func(st simStep) {
+		w.RawString(`"contract":`)
+		w.String(st.call.ContractAddress)
+		w.RawString(`,"function":`)
+		w.String(st.call.Function)
+		w.RawString(`,"allowlisted":`)
+		w.Bool(isICCAllowlisted(s.prj.ID, iccTarget(st.call.ContractAddress, st.call.Function)))
+		w.RawString(`,"assets":{`)
+		for i, asset := range sortedAssetKeys(st.call.Assets) {
+			if i > 0 {
+				w.RawByte(',')
+			}
+			w.String(AssetToString(asset))
+			w.RawByte(':')
+			w.RawString(strconv.FormatFloat(AmountToFloat(st.call.Assets[asset]), 'f', -1, 64))
+		}
+		w.RawByte('}')
+	})
+	w.RawByte('}')
+	return string(w.Buffer.BuildBytes())
+}
+
+// writeSimSteps writes steps as an array of objects: the fields from fill,
+// then "ok" and, for a failing step, its "code" and "error".
+func writeSimSteps(w *jwriter.Writer, key string, steps []simStep, fill func(simStep)) {
+	w.RawString(`,"` + key + `":[`)
+	for i, st := range steps {
+		if i > 0 {
+			w.RawByte(',')
+		}
+		w.RawByte('{')
+		fill(st)
+		w.RawString(`,"ok":`)
+		w.Bool(st.fail == nil)
+		if st.fail != nil {
+			w.RawString(`,"code":`)
+			w.String(string(st.fail.Code))
+			w.RawString(`,"error":`)
+			w.String(st.fail.Msg)
+		}
+		w.RawByte('}')
+	}
+	w.RawByte(']')
+}
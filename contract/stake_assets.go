@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// -----------------------------------------------------------------------------
+// Multi-asset staking
+// -----------------------------------------------------------------------------
+//
+// The funds asset is staked exactly as before (Member.Stake / StakeTotal, weight
+// 1). Additional stake assets are tracked raw per asset next to it, and only
+// converted into voting weight on read, with the weights passed in. Keeping the
+// raw amounts means a governance weight change needs no walk over the members,
+// and refunds always return exactly what was deposited.
+
+// sortedAssetKeys returns the keys of an asset map in a deterministic order.
+func sortedAssetKeys[V any](m map[sdk.Asset]V) []sdk.Asset {
+	keys := make([]sdk.Asset, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
+
+// weightedAmount converts a raw amount of an additional stake asset into
+// funds-asset voting units, rounding down.
+func weightedAmount(raw Amount, weight float64) Amount {
+	if raw <= 0 || !(weight > 0) {
+		return 0
+	}
+	v := math.Floor(float64(raw) * weight)
+	if v >= float64(maxAmount) {
+		abort(errcode.Internal, "amount overflow")
+	}
+	return Amount(v)
+}
+
+// weightedStake sums a funds-asset stake and raw additional-asset stakes under
+// the given weights. Assets without a positive weight count for nothing.
+func weightedStake(stake Amount, assets map[sdk.Asset]Amount, weights map[sdk.Asset]float64) Amount {
+	total := stake
+	for _, asset := range sortedAssetKeys(assets) {
+		total = safeAddAmount(total, weightedAmount(assets[asset], weights[asset]))
+	}
+	return total
+}
+
+// memberVotingStake is the member's current stake in voting units.
+func memberVotingStake(weights map[sdk.Asset]float64, m *Member) Amount {
+	return weightedStake(m.Stake, m.AssetStakes, weights)
+}
+
+// projectVotingStake is the project's total stake in voting units.
+func projectVotingStake(prj *Project) Amount {
+	return weightedStake(prj.StakeTotal, prj.AssetStakeTotals, prj.StakeWeights)
+}
+
+// isStakeAsset reports whether deposits of asset may currently be staked.
+func isStakeAsset(prj *Project, asset sdk.Asset) bool {
+	if asset == prj.FundsAsset {
+		return true
+	}
+	return prj.StakeWeights[asset] > 0
+}
+
+// copyStakeWeights snapshots a weight map so later config changes do not alias it.
+func copyStakeWeights(weights map[sdk.Asset]float64) map[sdk.Asset]float64 {
+	if len(weights) == 0 {
+		return nil
+	}
+	out := make(map[sdk.Asset]float64, len(weights))
+	for k, v := range weights {
+		out[k] = v
+	}
+	return out
+}
+
+// parseStakeWeight validates one weight. allowZero is for governance updates,
+// where 0 retires an asset: it stops counting and stops accepting deposits, but
+// members keep their deposit until they leave.
+func parseStakeWeight(val string, allowZero bool) float64 {
+	w, f := checkStakeWeight(val, allowZero)
+	must(f)
+	return w
+}
+
+// checkStakeWeight is parseStakeWeight without the abort.
+func checkStakeWeight(val string, allowZero bool) (float64, *failure) {
+	w, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil {
+		return 0, fail(errcode.InvalidValue, "invalid stake weight")
+	}
+	if !(w >= 0 && w <= MaxStakeWeight) || (w == 0 && !allowZero) {
+		return 0, fail(errcode.InvalidValue, fmt.Sprintf("stake weight must be greater than 0 and at most %.0f", MaxStakeWeight))
+	}
+	return w, nil
+}
+
+// parseStakeAssetName validates an asset named in a stake-weight entry.
+func parseStakeAssetName(val string) sdk.Asset {
+	asset, f := checkStakeAssetName(val)
+	must(f)
+	return asset
+}
+
+// checkStakeAssetName is parseStakeAssetName without the abort.
+func checkStakeAssetName(val string) (sdk.Asset, *failure) {
+	assetStr := strings.ToLower(strings.TrimSpace(val))
+	if !isValidAsset(assetStr) {
+		return "", fail(errcode.InvalidValue, fmt.Sprintf("stake asset %s is not supported", assetStr))
+	}
+	if assetStr == sdk.AssetHbdSavings.String() {
+		return "", fail(errcode.WrongAsset, "hbd_savings cannot be staked")
+	}
+	return AssetFromString(assetStr), nil
+}
+
+// parseStakeWeightsField parses the project_create stake-asset list,
+// "asset=weight;asset=weight". Empty means funds-asset-only staking.
+func parseStakeWeightsField(val string) map[sdk.Asset]float64 {
+	val = strings.TrimSpace(val)
+	if val == "" {
+		return nil
+	}
+	weights := map[sdk.Asset]float64{}
+	for _, entry := range strings.Split(val, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		split := strings.SplitN(entry, "=", 2)
+		if len(split) != 2 {
+			abort(errcode.InvalidPayload, "invalid stake asset entry (use asset=weight)")
+		}
+		setStakeWeight(weights, split[0], split[1])
+	}
+	return weights
+}
+
+// setStakeWeight validates one project_create stake-asset entry and adds it to weights.
+func setStakeWeight(weights map[sdk.Asset]float64, assetStr, weightStr string) {
+	asset := parseStakeAssetName(assetStr)
+	if _, dup := weights[asset]; dup {
+		abort(errcode.InvalidValue, fmt.Sprintf("duplicate stake asset %s", asset.String()))
+	}
+	weights[asset] = parseStakeWeight(weightStr, false)
+}
+
+// refundAssetStakes returns a departing member's additional-asset stake and
+// removes it from the project totals. The funds-asset stake is handled by the caller.
+func refundAssetStakes(prj *Project, addr sdk.Address, m *Member) {
+	for _, asset := range sortedAssetKeys(m.AssetStakes) {
+		amount := m.AssetStakes[asset]
+		if amount <= 0 {
+			continue
+		}
+		sdk.HiveTransfer(addr, AmountToInt64(amount), asset)
+		if prj.AssetStakeTotals[asset] < amount {
+			abort(errcode.Internal, "accounting error: stake total mismatch")
+		}
+		prj.AssetStakeTotals[asset] -= amount
+		emitFundsRemoved(prj.ID, AddressToString(addr), AmountToFloat(amount), AssetToString(asset), true)
+	}
+	m.AssetStakes = nil
+}
+
+// formatAssetStakes renders per-asset stake for stake history entries:
+// "asset:amount,asset:amount" in sorted asset order.
+func formatAssetStakes(assets map[sdk.Asset]Amount) string {
+	parts := make([]string, 0, len(assets))
+	for _, asset := range sortedAssetKeys(assets) {
+		if assets[asset] <= 0 {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d", asset.String(), assets[asset]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseAssetStakes is the inverse of formatAssetStakes. Returns false on malformed input.
+func parseAssetStakes(val string) (map[sdk.Asset]Amount, bool) {
+	if val == "" {
+		return nil, true
+	}
+	out := map[sdk.Asset]Amount{}
+	for _, part := range strings.Split(val, ",") {
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, false
+		}
+		n, err := strconv.ParseInt(kv[1], 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		out[AssetFromString(kv[0])] = Amount(n)
+	}
+	return out, true
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+	"strconv"
+	"strings"
+)
+
+// MetaCondition is the meta key carrying a proposal's state condition. Like
+// the execution window it is taken out of the meta actions at creation.
+const MetaCondition = "condition"
+
+var conditionOps = []string{ConditionEq: "eq", ConditionNe: "ne", ConditionLt: "lt",
+	ConditionLe: "le", ConditionGt: "gt", ConditionGe: "ge"}
+
+// takeStateCondition removes the condition key from meta and parses it:
+//
+//	<contract>,<key>,<op>,<value>
+//
+// op is eq or ne (text) or lt, le, gt, ge (numbers). A key starting with 0x
+// is given in hex, for contracts that use binary state keys. value is the rest
+// of the entry and may itself contain commas.
+func takeStateCondition(meta map[string]string) *StateCondition {
+	raw, ok := meta[MetaCondition]
+	if !ok {
+		return nil
+	}
+	delete(meta, MetaCondition)
+	parts := strings.SplitN(raw, ",", 4)
+	if len(parts) != 4 {
+		abort(errcode.InvalidPayload, "invalid condition (use contract,key,op,value)")
+	}
+	cond := &StateCondition{
+		Contract: strings.TrimSpace(parts[0]),
+		Key:      strings.TrimSpace(parts[1]),
+		Value:    strings.TrimSpace(parts[3]),
+	}
+	if cond.Contract == "" || cond.Key == "" {
+		abort(errcode.InvalidValue, "condition contract and key cannot be empty")
+	}
+	if strings.HasPrefix(cond.Key, "0x") {
+		key, err := hex.DecodeString(cond.Key[2:])
+		if err != nil || len(key) == 0 {
+			abort(errcode.InvalidValue, fmt.Sprintf("invalid hex condition key %s", cond.Key))
+		}
+		cond.Key = string(key)
+	}
+	op := strings.TrimSpace(parts[2])
+	for i, name := range conditionOps {
+		if name != "" && name == op {
+			cond.Op = ConditionOp(i)
+		}
+	}
+	if cond.Op == 0 {
+		abort(errcode.InvalidValue, fmt.Sprintf("unknown condition comparator %q (use eq, ne, lt, le, gt or ge)", op))
+	}
+	if cond.Op.numeric() {
+		if _, ok := parseConditionNumber(cond.Value); !ok {
+			abort(errcode.InvalidValue, fmt.Sprintf("condition comparator %s needs a number, got %q", op, cond.Value))
+		}
+	}
+	if !contractExists(cond.Contract) {
+		abort(errcode.NotFound, fmt.Sprintf("condition contract not found: %s", cond.Contract))
+	}
+	return cond
+}
+
+func (op ConditionOp) numeric() bool { return op >= ConditionLt }
+
+func (op ConditionOp) String() string {
+	if int(op) < len(conditionOps) {
+		return conditionOps[op]
+	}
+	return ""
+}
+
+func parseConditionNumber(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+// holds reports whether state, the current value of the condition's key,
+// satisfies it. An unset key or a value that is not a number never satisfies
+// an ordering.
+func (c *StateCondition) holds(state *string) bool {
+	current := ""
+	if state != nil {
+		current = *state
+	}
+	switch c.Op {
+	case ConditionEq:
+		return current == c.Value
+	case ConditionNe:
+		return current != c.Value
+	}
+	have, ok := parseConditionNumber(current)
+	if !ok {
+		return false
+	}
+	want, _ := parseConditionNumber(c.Value)
+	switch c.Op {
+	case ConditionLt:
+		return have < want
+	case ConditionLe:
+		return have <= want
+	case ConditionGt:
+		return have > want
+	case ConditionGe:
+		return have >= want
+	}
+	return false
+}
+
+// checkConditionAllowed rejects a condition on a new proposal that never
+// executes.
+func checkConditionAllowed(prpsl *Proposal) *failure {
+	if prpsl.IsPoll && prpsl.Condition != nil {
+		return fail(errcode.InvalidValue, "polls do not execute and cannot have a condition")
+	}
+	return nil
+}
+
+// requireStateCondition aborts the execution until the proposal's condition
+// holds. The proposal stays passed, so it can be executed again later.
+func requireStateCondition(prpsl *Proposal) {
+	must(checkStateCondition(prpsl))
+}
+
+// checkStateCondition is requireStateCondition without the abort.
+func checkStateCondition(prpsl *Proposal) *failure {
+	c := prpsl.Condition
+	if c == nil {
+		return nil
+	}
+	state := sdk.ContractStateGet(c.Contract, c.Key)
+	if c.holds(state) {
+		return nil
+	}
+	current := "unset"
+	if state != nil && *state != "" {
+		current = strconv.Quote(*state)
+	}
+	return fail(errcode.ConditionUnmet, fmt.Sprintf("condition not met: %s %s %s %q (currently %s)",
+		c.Contract, conditionKeyString(c.Key), c.Op, c.Value, current))
+}
+
+// conditionKeyString spells a key the way takeStateCondition reads it.
+func conditionKeyString(key string) string {
+	if strings.HasPrefix(key, "0x") || strings.TrimSpace(key) != key {
+		return "0x" + hex.EncodeToString([]byte(key))
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e || key[i] == ',' || key[i] == ';' {
+			return "0x" + hex.EncodeToString([]byte(key))
+		}
+	}
+	return key
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
+	"strconv"
 	"strings"
 )
 
@@ -18,7 +20,10 @@ func isContractInitialized() bool {
 // requireInitialized aborts if the contract has not been initialized.
 func requireInitialized() {
 	if !isContractInitialized() {
-		sdk.Abort("contract not initialized")
+		abort(errcode.NotInitialized, "contract not initialized")
+	}
+	if ptr := sdk.StateGetObject(ICCReceiptLockKey); ptr != nil && *ptr != "" {
+		abort(errcode.Locked, "contract cannot be re-entered while it measures an inter-contract call's receipts")
 	}
 }
 
@@ -39,7 +44,7 @@ func saveContractConfig(cfg *ContractConfig) {
 // getContractOwner returns the contract owner address, or nil if not initialized.
 func getContractOwner() *sdk.Address {
 	cfg := loadContractConfig()
-	if cfg == nil {
+	if cfg == nil || cfg.Owner == "" {
 		return nil
 	}
 	return &cfg.Owner
@@ -56,13 +61,15 @@ func isContractOwner(addr sdk.Address) bool {
 // -----------------------------------------------------------------------------
 
 // encodeContractConfig serializes ContractConfig to a pipe-delimited string.
-// Format: owner|projectCreationPublic
+// Format: owner|projectCreationPublic|eventFormat|pendingOwner|creationFee|creationFeeAsset
 func encodeContractConfig(cfg *ContractConfig) string {
 	publicStr := "0"
 	if cfg.ProjectCreationPublic {
 		publicStr = "1"
 	}
-	return cfg.Owner.String() + "|" + publicStr
+	return cfg.Owner.String() + "|" + publicStr + "|" + strconv.Itoa(int(cfg.EventFormat)) +
+		"|" + cfg.PendingOwner.String() + "|" + strconv.FormatInt(int64(cfg.CreationFee), 10) +
+		"|" + cfg.CreationFeeAsset.String()
 }
 
 // decodeContractConfig deserializes a pipe-delimited string to ContractConfig.
@@ -71,8 +78,24 @@ func decodeContractConfig(data string) *ContractConfig {
 	if len(parts) < 2 {
 		return nil
 	}
-	return &ContractConfig{
+	cfg := &ContractConfig{
 		Owner:                 AddressFromString(parts[0]),
 		ProjectCreationPublic: parts[1] == "1",
 	}
+	// Configs written before event formats existed have two fields: legacy.
+	if len(parts) > 2 {
+		if f, err := strconv.Atoi(parts[2]); err == nil {
+			cfg.EventFormat = EventFormat(f)
+		}
+	}
+	// Configs written before contract administration have no pending owner
+	// and no creation fee.
+	if len(parts) > 5 {
+		cfg.PendingOwner = AddressFromString(parts[3])
+		if fee, err := strconv.ParseInt(parts[4], 10, 64); err == nil && fee > 0 {
+			cfg.CreationFee = Amount(fee)
+			cfg.CreationFeeAsset = sdk.Asset(parts[5])
+		}
+	}
+	return cfg
 }
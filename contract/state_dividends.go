@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
+
+// loadDividendAcc returns the accumulated dividend per stake unit for an asset,
+// scaled by DividendPrecision. Zero when nothing was ever distributed.
+func loadDividendAcc(projectID uint64, asset sdk.Asset) *big.Int {
+	acc := new(big.Int)
+	ptr := sdk.StateGetObject(projectDividendKey(projectID, asset))
+	if ptr == nil || *ptr == "" {
+		return acc
+	}
+	if _, ok := acc.SetString(*ptr, 10); !ok {
+		abort(errcode.Internal, "failed to decode dividend accumulator")
+	}
+	return acc
+}
+
+// saveDividendAcc persists the accumulator as a decimal string; it is unbounded
+// so it does not fit an int64 once many distributions pile up over a small stake.
+func saveDividendAcc(projectID uint64, asset sdk.Asset, acc *big.Int) {
+	sdk.StateSetObject(projectDividendKey(projectID, asset), acc.String())
+}
+
+// loadDividendDust returns the sub-unit dividend remainder of one asset, scaled
+// like the accumulator's product.
+func loadDividendDust(projectID uint64, asset sdk.Asset) *big.Int {
+	dust := new(big.Int)
+	ptr := sdk.StateGetObject(projectDividendDustKey(projectID, asset))
+	if ptr == nil || *ptr == "" {
+		return dust
+	}
+	if _, ok := dust.SetString(*ptr, 10); !ok {
+		abort(errcode.Internal, "failed to decode dividend remainder")
+	}
+	return dust
+}
+
+// saveDividendDust persists the remainder, dropping the key once it is zero.
+func saveDividendDust(projectID uint64, asset sdk.Asset, dust *big.Int) {
+	key := projectDividendDustKey(projectID, asset)
+	if dust.Sign() == 0 {
+		sdk.StateDeleteObject(key)
+		return
+	}
+	sdk.StateSetObject(key, dust.String())
+}
+
+// loadDividendBooks returns a member's reward debt (the accrual already accounted
+// for, scaled like the accumulator's product) and settled-but-unclaimed dividends.
+func loadDividendBooks(projectID uint64, asset sdk.Asset, addr sdk.Address) (*big.Int, Amount) {
+	debt := new(big.Int)
+	ptr := sdk.StateGetObject(memberDividendKey(projectID, asset, addr))
+	if ptr == nil || *ptr == "" {
+		return debt, 0
+	}
+	// Parse format: {debt}_{pending}
+	parts := strings.Split(*ptr, "_")
+	if len(parts) != 2 {
+		abort(errcode.Internal, "failed to decode dividend books")
+	}
+	if _, ok := debt.SetString(parts[0], 10); !ok {
+		abort(errcode.Internal, "failed to decode dividend books")
+	}
+	pending, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		abort(errcode.Internal, "failed to decode dividend books")
+	}
+	return debt, Amount(pending)
+}
+
+// saveDividendBooks writes a member's dividend books for one asset.
+func saveDividendBooks(projectID uint64, asset sdk.Asset, addr sdk.Address, debt *big.Int, pending Amount) {
+	value := fmt.Sprintf("%s_%d", debt.String(), pending)
+	stateSetIfChanged(memberDividendKey(projectID, asset, addr), value)
+}
+
+// deleteDividendBooks drops a departing member's books once they have been paid out.
+func deleteDividendBooks(projectID uint64, asset sdk.Asset, addr sdk.Address) {
+	sdk.StateDeleteObject(memberDividendKey(projectID, asset, addr))
+}
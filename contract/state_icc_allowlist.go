@@ -0,0 +1,27 @@
+package main
+
+import "okinoko_dao/sdk"
+
+// setICCAllowlistEntry allowlists a contract:function pair and reports whether it was new.
+func setICCAllowlistEntry(projectID uint64, target string) bool {
+	return addListEntry(projectID, listICCAllowlist, iccAllowlistKey(projectID, target), target)
+}
+
+// deleteICCAllowlistEntry drops a contract:function pair and reports whether it existed.
+func deleteICCAllowlistEntry(projectID uint64, target string) bool {
+	return deleteListEntry(projectID, listICCAllowlist, iccAllowlistKey(projectID, target), target)
+}
+
+// isICCAllowlisted reports whether a contract:function pair is allowlisted.
+func isICCAllowlisted(projectID uint64, target string) bool {
+	key := iccAllowlistKey(projectID, target)
+	existing := sdk.StateGetObject(key)
+	return existing != nil && *existing != ""
+}
+
+// iccAllowlistEntries lists a project's allowlisted pairs in the order they were added.
+func iccAllowlistEntries(projectID uint64) []string {
+	return listEntries(projectID, listICCAllowlist, func(target string) string {
+		return iccAllowlistKey(projectID, target)
+	})
+}
@@ -90,6 +90,27 @@ func whitelistKey(projectID uint64, addr sdk.Address) string {
 	return string(buf)
 }
 
+// iccAllowlistKey stores one allowlisted contract:function pair of a project.
+func iccAllowlistKey(projectID uint64, target string) string {
+	buf := make([]byte, 0, 1+8+len(target))
+	buf = append(buf, kProjectICCAllowlist)
+	buf = packU64LE(projectID, buf)
+	buf = append(buf, target...)
+	return string(buf)
+}
+
+// projectListSlotKey holds one entry of an indexed project list.
+// Key format: kProjectListSlot|projectID|list|seq
+// Value format: {address or contract:function}
+func projectListSlotKey(projectID uint64, list byte, seq uint64) string {
+	var buf [18]byte
+	buf[0] = kProjectListSlot
+	packU64LEInline(projectID, buf[1:])
+	buf[9] = list
+	packU64LEInline(seq, buf[10:])
+	return string(buf[:])
+}
+
 // proposalKey builds a storage key string for a proposal by ID.
 // proposalKey encodes id under 0x10 prefix keeping metadata lumps contiguous.
 func proposalKey(id uint64) string {
@@ -127,9 +148,99 @@ func memberStakeHistoryKey(projectID uint64, addr sdk.Address, increment uint64)
 	return string(buf)
 }
 
+// projectRosterKey holds one slot of a project's dense member roster.
+// Key format: kProjectRoster|projectID|position
+// Value format: {address}
+func projectRosterKey(projectID uint64, pos uint64) string {
+	var buf [17]byte
+	buf[0] = kProjectRoster
+	packU64LEInline(projectID, buf[1:])
+	packU64LEInline(pos, buf[9:])
+	return string(buf[:])
+}
+
+// memberRosterPosKey points from a member back to their roster slot.
+// Key format: kMemberRosterPos|projectID|address
+// Value format: {position}
+func memberRosterPosKey(projectID uint64, addr sdk.Address) string {
+	addrStr := AddressToString(addr)
+	buf := make([]byte, 0, 1+8+len(addrStr))
+	buf = append(buf, kMemberRosterPos)
+	buf = packU64LE(projectID, buf)
+	buf = append(buf, addrStr...)
+	return string(buf)
+}
+
+// projectRosterCountKey counts the slots of a project's roster.
+func projectRosterCountKey(projectID uint64) string {
+	return "count:rs:" + UInt64ToString(projectID)
+}
+
+// projectDividendKey holds the dividend accumulator of one asset.
+// Key format: kProjectDividend|projectID|asset
+// Value format: {accumulated dividend per stake unit * DividendPrecision}
+func projectDividendKey(projectID uint64, asset sdk.Asset) string {
+	assetStr := asset.String()
+	buf := make([]byte, 0, 1+8+len(assetStr))
+	buf = append(buf, kProjectDividend)
+	buf = packU64LE(projectID, buf)
+	buf = append(buf, assetStr...)
+	return string(buf)
+}
+
+// projectDividendDustKey holds the dividend remainder of one asset.
+// Key format: kProjectDividendDust|projectID|asset
+// Value format: {remainder * DividendPrecision}
+func projectDividendDustKey(projectID uint64, asset sdk.Asset) string {
+	assetStr := asset.String()
+	buf := make([]byte, 0, 1+8+len(assetStr))
+	buf = append(buf, kProjectDividendDust)
+	buf = packU64LE(projectID, buf)
+	buf = append(buf, assetStr...)
+	return string(buf)
+}
+
+// projectHbdInterestKey holds a treasury's HBD savings interest debt.
+// Key format: kProjectHbdInterest|projectID
+// Value format: {debt}
+func projectHbdInterestKey(projectID uint64) string {
+	var buf [9]byte
+	buf[0] = kProjectHbdInterest
+	packU64LEInline(projectID, buf[1:])
+	return string(buf[:])
+}
+
+// memberDividendKey holds one member's dividend books for one asset.
+// Key format: kMemberDividend|projectID|len(asset)|asset|address
+// Value format: {debt}_{pending}
+//
+// The asset is length-prefixed so the variable-length address can sit last
+// without two (asset, address) pairs ever encoding to the same key.
+func memberDividendKey(projectID uint64, asset sdk.Asset, addr sdk.Address) string {
+	assetStr := asset.String()
+	addrStr := AddressToString(addr)
+	buf := make([]byte, 0, 1+8+1+len(assetStr)+len(addrStr))
+	buf = append(buf, kMemberDividend)
+	buf = packU64LE(projectID, buf)
+	buf = append(buf, byte(len(assetStr)))
+	buf = append(buf, assetStr...)
+	buf = append(buf, addrStr...)
+	return string(buf)
+}
+
+// projectHbdUnstakeKey holds the treasury's pending HBD savings withdrawals.
+// Key format: kProjectHbdUnstake|projectID
+// Value format: {amount}_{maturesAt};{amount}_{maturesAt}...
+func projectHbdUnstakeKey(projectID uint64) string {
+	var buf [9]byte
+	buf[0] = kProjectHbdUnstake
+	packU64LEInline(projectID, buf[1:])
+	return string(buf[:])
+}
+
 // projectTreasuryKey stores a single asset balance in the project's multi-asset treasury.
 // Key format: kProjectTreasury|projectID|asset
-// Value format: {amount}
+// Value format: versioned record holding the amount (see schema.go)
 func projectTreasuryKey(projectID uint64, asset sdk.Asset) string {
 	assetStr := asset.String()
 	buf := make([]byte, 0, 1+8+len(assetStr))
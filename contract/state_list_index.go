@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strconv"
+
+	"okinoko_dao/sdk"
+)
+
+// The whitelist and the ICC allowlist are flags keyed by their entry, which
+// cannot be walked. Each entry also takes a slot in an insertion-ordered
+// index and its flag stores the slot number. A slot counts only while its
+// flag still points at it, so flags set before the index existed ("1") are
+// not walked and their stale slot numbers never delete someone else's slot.
+// Projects created or imported since then carry a marker saying none of
+// their flags predates the index; only they can list their entries in full.
+
+// projectListSeqKey counts the slots a project list has used.
+func projectListSeqKey(projectID uint64, list byte) string {
+	return "count:l" + string(list) + ":" + UInt64ToString(projectID)
+}
+
+// projectListsIndexedKey marks a project whose list flags are all indexed.
+// Key format: kProjectListSlot|projectID
+func projectListsIndexedKey(projectID uint64) string {
+	var buf [9]byte
+	buf[0] = kProjectListSlot
+	packU64LEInline(projectID, buf[1:])
+	return string(buf[:])
+}
+
+// markListsIndexed records that every list flag of a new project is indexed.
+func markListsIndexed(projectID uint64) {
+	sdk.StateSetObject(projectListsIndexedKey(projectID), "1")
+}
+
+// listsIndexed reports whether listEntries sees every entry of the project's
+// lists, which is not known for projects created before the index.
+func listsIndexed(projectID uint64) bool {
+	ptr := sdk.StateGetObject(projectListsIndexedKey(projectID))
+	return ptr != nil && *ptr != ""
+}
+
+// addListEntry sets flagKey and indexes entry, and reports whether it was new.
+func addListEntry(projectID uint64, list byte, flagKey string, entry string) bool {
+	if existing := sdk.StateGetObject(flagKey); existing != nil && *existing != "" {
+		return false
+	}
+	seqKey := projectListSeqKey(projectID, list)
+	seq := getCount(seqKey)
+	setCount(seqKey, seq+1)
+	sdk.StateSetObject(projectListSlotKey(projectID, list, seq), entry)
+	sdk.StateSetObject(flagKey, UInt64ToString(seq))
+	return true
+}
+
+// deleteListEntry clears flagKey and frees its slot, and reports whether the
+// flag was set.
+func deleteListEntry(projectID uint64, list byte, flagKey string, entry string) bool {
+	existing := sdk.StateGetObject(flagKey)
+	if existing == nil || *existing == "" {
+		return false
+	}
+	if seq, err := strconv.ParseUint(*existing, 10, 64); err == nil {
+		slot := projectListSlotKey(projectID, list, seq)
+		if ptr := sdk.StateGetObject(slot); ptr != nil && *ptr == entry {
+			sdk.StateDeleteObject(slot)
+		}
+	}
+	sdk.StateDeleteObject(flagKey)
+	return true
+}
+
+// listEntries returns the indexed entries of a project list in insertion order.
+func listEntries(projectID uint64, list byte, flagKey func(entry string) string) []string {
+	var entries []string
+	n := getCount(projectListSeqKey(projectID, list))
+	for seq := uint64(0); seq < n; seq++ {
+		ptr := sdk.StateGetObject(projectListSlotKey(projectID, list, seq))
+		if ptr == nil || *ptr == "" {
+			continue
+		}
+		flag := sdk.StateGetObject(flagKey(*ptr))
+		if flag == nil || *flag != UInt64ToString(seq) {
+			continue
+		}
+		entries = append(entries, *ptr)
+	}
+	return entries
+}
@@ -1,16 +1,21 @@
 package main
 
-import "okinoko_dao/sdk"
+import (
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
 
 // saveMember writes both storage and cache copy so repeated reads stay cheap.
+// It also puts the member on the roster, which backfills members who joined
+// before the roster existed the next time anything about them changes.
 func saveMember(projectID uint64, member *Member) {
 	key := memberKey(projectID, member.Address)
-	data := EncodeMember(member)
-	sdk.StateSetObject(key, string(data))
+	sdk.StateSetObject(key, encodeMemberRecord(member))
 	if cachedMembers != nil {
 		cp := *member
 		cachedMembers[key] = &cp
 	}
+	addRosterEntry(projectID, member.Address)
 }
 
 // loadMember tries cache first and decodes wasm bytes when needed.
@@ -26,10 +31,11 @@ func loadMember(projectID uint64, addr sdk.Address) (*Member, bool) {
 	if ptr == nil || *ptr == "" {
 		return nil, false
 	}
-	member, err := DecodeMember([]byte(*ptr))
+	member, stale, err := decodeMemberRecord(*ptr)
 	if err != nil {
-		sdk.Abort("failed to decode member")
+		abort(errcode.Internal, "failed to decode member")
 	}
+	rewriteStale(key, stale, func() string { return encodeMemberRecord(member) })
 	if cachedMembers != nil {
 		cp := *member
 		cachedMembers[key] = &cp
@@ -44,4 +50,67 @@ func deleteMember(projectID uint64, addr sdk.Address) {
 	if cachedMembers != nil {
 		delete(cachedMembers, key)
 	}
+	deleteRosterEntry(projectID, addr)
+}
+
+// The roster keeps a project's members in slots 0..n-1 and each member's slot
+// under memberRosterPosKey. A removal moves the last slot into the gap, so a
+// walk costs one read per member however many have come and gone.
+
+// addRosterEntry gives addr the next roster slot unless it holds one.
+func addRosterEntry(projectID uint64, addr sdk.Address) {
+	posKey := memberRosterPosKey(projectID, addr)
+	if ptr := sdk.StateGetObject(posKey); ptr != nil && *ptr != "" {
+		return
+	}
+	countKey := projectRosterCountKey(projectID)
+	n := getCount(countKey)
+	sdk.StateSetObject(projectRosterKey(projectID, n), AddressToString(addr))
+	setCount(posKey, n)
+	setCount(countKey, n+1)
+}
+
+// deleteRosterEntry frees addr's roster slot, moving the last member into it.
+func deleteRosterEntry(projectID uint64, addr sdk.Address) {
+	posKey := memberRosterPosKey(projectID, addr)
+	ptr := sdk.StateGetObject(posKey)
+	if ptr == nil || *ptr == "" {
+		return
+	}
+	pos := getCount(posKey)
+	countKey := projectRosterCountKey(projectID)
+	n := getCount(countKey)
+	if n == 0 || pos >= n {
+		abort(errcode.Internal, "member roster corrupted")
+	}
+	last := n - 1
+	if pos < last {
+		moved, _ := loadRosterEntry(projectID, last)
+		sdk.StateSetObject(projectRosterKey(projectID, pos), AddressToString(moved))
+		setCount(memberRosterPosKey(projectID, moved), pos)
+	}
+	sdk.StateDeleteObject(projectRosterKey(projectID, last))
+	sdk.StateDeleteObject(posKey)
+	setCount(countKey, last)
+}
+
+// loadRosterEntry returns the address in a roster slot.
+func loadRosterEntry(projectID uint64, pos uint64) (sdk.Address, bool) {
+	ptr := sdk.StateGetObject(projectRosterKey(projectID, pos))
+	if ptr == nil || *ptr == "" {
+		return "", false
+	}
+	return AddressFromString(*ptr), true
+}
+
+// rosterMembers lists the addresses on a project's roster.
+func rosterMembers(projectID uint64) []sdk.Address {
+	n := getCount(projectRosterCountKey(projectID))
+	addrs := make([]sdk.Address, 0, n)
+	for pos := uint64(0); pos < n; pos++ {
+		if addr, ok := loadRosterEntry(projectID, pos); ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
 }
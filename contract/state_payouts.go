@@ -1,6 +1,7 @@
 package main
 
 import (
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 	"strconv"
 )
@@ -14,7 +15,7 @@ func getPayoutLockCount(projectID uint64, addr sdk.Address) uint64 {
 	}
 	val, err := strconv.ParseUint(*ptr, 10, 64)
 	if err != nil {
-		sdk.Abort("invalid payout lock value")
+		abort(errcode.Internal, "invalid payout lock value")
 	}
 	return val
 }
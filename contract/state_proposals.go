@@ -1,6 +1,9 @@
 package main
 
-import "okinoko_dao/sdk"
+import (
+	"okinoko_dao/errcode"
+	"okinoko_dao/sdk"
+)
 
 // saveProposalOption stores each option separately to avoid rewriting the whole proposal blob.
 func saveProposalOption(proposalID uint64, idx uint32, opt *ProposalOption) {
@@ -14,11 +17,11 @@ func loadProposalOption(proposalID uint64, idx uint32) *ProposalOption {
 	key := proposalOptionKey(proposalID, idx)
 	ptr := sdk.StateGetObject(key)
 	if ptr == nil || *ptr == "" {
-		sdk.Abort("proposal option not found")
+		abort(errcode.NotFound, "proposal option not found")
 	}
 	opt, err := DecodeProposalOption([]byte(*ptr))
 	if err != nil {
-		sdk.Abort("failed to decode proposal option")
+		abort(errcode.Internal, "failed to decode proposal option")
 	}
 	return opt
 }
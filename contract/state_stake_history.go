@@ -12,14 +12,23 @@ import (
 type StakeHistoryEntry struct {
 	Stake     Amount
 	Timestamp int64
+	Assets    map[sdk.Asset]Amount // additional stake assets, raw
 }
 
 // saveStakeHistory appends a new stake history entry for a member.
 // Increments the member's StakeIncrement counter.
-func saveStakeHistory(projectID uint64, addr sdk.Address, stake Amount, timestamp int64, increment uint64) {
+func saveStakeHistory(projectID uint64, addr sdk.Address, stake Amount, assets map[sdk.Asset]Amount, timestamp int64, increment uint64) {
 	key := memberStakeHistoryKey(projectID, addr, increment)
-	value := fmt.Sprintf("%d_%d", stake, timestamp)
-	sdk.StateSetObject(key, value)
+	sdk.StateSetObject(key, formatStakeHistoryEntry(&StakeHistoryEntry{Stake: stake, Timestamp: timestamp, Assets: assets}))
+}
+
+// formatStakeHistoryEntry encodes an entry as {stake}_{timestamp}[_{asset}:{amount},...].
+func formatStakeHistoryEntry(entry *StakeHistoryEntry) string {
+	value := fmt.Sprintf("%d_%d", entry.Stake, entry.Timestamp)
+	if extra := formatAssetStakes(entry.Assets); extra != "" {
+		value += "_" + extra
+	}
+	return value
 }
 
 // loadStakeHistory retrieves a specific stake history entry by increment.
@@ -29,12 +38,23 @@ func loadStakeHistory(projectID uint64, addr sdk.Address, increment uint64) *Sta
 	if dataPtr == nil {
 		return nil
 	}
+	return parseStakeHistoryEntry(*dataPtr)
+}
 
-	// Parse format: {stake}_{timestamp}
-	parts := strings.Split(*dataPtr, "_")
-	if len(parts) != 2 {
+// parseStakeHistoryEntry is the inverse of formatStakeHistoryEntry; it
+// returns nil for a malformed entry.
+func parseStakeHistoryEntry(data string) *StakeHistoryEntry {
+	parts := strings.Split(data, "_")
+	if len(parts) != 2 && len(parts) != 3 {
 		return nil
 	}
+	var assets map[sdk.Asset]Amount
+	if len(parts) == 3 {
+		var ok bool
+		if assets, ok = parseAssetStakes(parts[2]); !ok {
+			return nil
+		}
+	}
 
 	stake, err1 := strconv.ParseInt(parts[0], 10, 64)
 	timestamp, err2 := strconv.ParseInt(parts[1], 10, 64)
@@ -45,12 +65,14 @@ func loadStakeHistory(projectID uint64, addr sdk.Address, increment uint64) *Sta
 	return &StakeHistoryEntry{
 		Stake:     Amount(stake),
 		Timestamp: timestamp,
+		Assets:    assets,
 	}
 }
 
 // getStakeAtTime finds the member's stake at a specific timestamp by searching backwards
-// through their stake history from their current increment.
-func getStakeAtTime(projectID uint64, addr sdk.Address, targetTime int64, currentIncrement uint64) Amount {
+// through their stake history from their current increment. Additional stake
+// assets are converted into voting units with weights.
+func getStakeAtTime(projectID uint64, addr sdk.Address, targetTime int64, currentIncrement uint64, weights map[sdk.Asset]float64) Amount {
 	// Search backwards from current increment to 0
 	for i := int64(currentIncrement); i >= 0; i-- {
 		entry := loadStakeHistory(projectID, addr, uint64(i))
@@ -60,7 +82,7 @@ func getStakeAtTime(projectID uint64, addr sdk.Address, targetTime int64, curren
 
 		// Found an entry at or before the target time
 		if entry.Timestamp <= targetTime {
-			return entry.Stake
+			return weightedStake(entry.Stake, entry.Assets, weights)
 		}
 	}
 
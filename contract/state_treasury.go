@@ -2,8 +2,11 @@ package main
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
+	"strings"
 
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 )
 
@@ -11,22 +14,26 @@ import (
 func getTreasuryBalance(projectID uint64, asset sdk.Asset) Amount {
 	key := projectTreasuryKey(projectID, asset)
 	dataPtr := sdk.StateGetObject(key)
-	if dataPtr == nil {
+	if dataPtr == nil || *dataPtr == "" {
 		return 0
 	}
-
-	balance, err := strconv.ParseInt(*dataPtr, 10, 64)
+	balance, stale, err := decodeTreasuryRecord(*dataPtr)
 	if err != nil {
-		return 0
+		abort(errcode.Internal, "failed to decode treasury balance")
 	}
-	return Amount(balance)
+	rewriteStale(key, stale, func() string { return encodeTreasuryRecord(balance) })
+	return balance
 }
 
 // setTreasuryBalance sets the balance of a specific asset in the project treasury.
+// An hbd_savings balance is booked against the savings pool first, which adds
+// the interest it earned so far.
 func setTreasuryBalance(projectID uint64, asset sdk.Asset, amount Amount) {
+	if asset == sdk.AssetHbdSavings {
+		amount = bookHbdSavings(projectID, amount)
+	}
 	key := projectTreasuryKey(projectID, asset)
-	value := fmt.Sprintf("%d", amount)
-	sdk.StateSetObject(key, value)
+	sdk.StateSetObject(key, encodeTreasuryRecord(amount))
 }
 
 // addTreasuryFunds adds funds to a specific asset in the project treasury.
@@ -58,3 +65,115 @@ func loadTreasuryMap(projectID uint64, knownAssets []sdk.Asset) map[sdk.Asset]Am
 	}
 	return treasury
 }
+
+// HbdUnstake is a treasury HBD savings withdrawal that the ledger has not paid
+// out yet. Its amount is in neither the hbd nor the hbd_savings balance.
+type HbdUnstake struct {
+	Amount    Amount
+	MaturesAt int64
+}
+
+// loadHbdUnstakes returns the pending withdrawals, oldest first.
+func loadHbdUnstakes(projectID uint64) []HbdUnstake {
+	ptr := sdk.StateGetObject(projectHbdUnstakeKey(projectID))
+	if ptr == nil || *ptr == "" {
+		return nil
+	}
+	// Parse format: {amount}_{maturesAt};...
+	entries := strings.Split(*ptr, ";")
+	out := make([]HbdUnstake, 0, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(entry, "_")
+		if len(parts) != 2 {
+			abort(errcode.Internal, "failed to decode pending hbd unstakes")
+		}
+		amount, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			abort(errcode.Internal, "failed to decode pending hbd unstakes")
+		}
+		maturesAt, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			abort(errcode.Internal, "failed to decode pending hbd unstakes")
+		}
+		out = append(out, HbdUnstake{Amount: Amount(amount), MaturesAt: maturesAt})
+	}
+	return out
+}
+
+// saveHbdUnstakes writes the pending withdrawals, deleting the key when none remain.
+func saveHbdUnstakes(projectID uint64, pending []HbdUnstake) {
+	if len(pending) == 0 {
+		sdk.StateDeleteObject(projectHbdUnstakeKey(projectID))
+		return
+	}
+	parts := make([]string, len(pending))
+	for i, u := range pending {
+		parts[i] = fmt.Sprintf("%d_%d", u.Amount, u.MaturesAt)
+	}
+	sdk.StateSetObject(projectHbdUnstakeKey(projectID), strings.Join(parts, ";"))
+}
+
+// HbdSavingsPool books the contract's HBD savings. Booked is the sum of every
+// treasury's hbd_savings balance, Unpaid the interest recognized but not yet
+// credited to a treasury, and Acc the interest per booked unit, scaled by
+// DividendPrecision.
+type HbdSavingsPool struct {
+	Booked Amount
+	Unpaid Amount
+	Acc    *big.Int
+}
+
+// loadHbdSavingsPool returns the contract's savings pool, empty before any HBD
+// was staked.
+func loadHbdSavingsPool() *HbdSavingsPool {
+	pool := &HbdSavingsPool{Acc: new(big.Int)}
+	ptr := sdk.StateGetObject(HbdSavingsPoolKey)
+	if ptr == nil || *ptr == "" {
+		return pool
+	}
+	// Parse format: {booked}_{unpaid}_{acc}
+	parts := strings.Split(*ptr, "_")
+	if len(parts) != 3 {
+		abort(errcode.Internal, "failed to decode hbd savings pool")
+	}
+	booked, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		abort(errcode.Internal, "failed to decode hbd savings pool")
+	}
+	unpaid, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		abort(errcode.Internal, "failed to decode hbd savings pool")
+	}
+	if _, ok := pool.Acc.SetString(parts[2], 10); !ok {
+		abort(errcode.Internal, "failed to decode hbd savings pool")
+	}
+	pool.Booked, pool.Unpaid = Amount(booked), Amount(unpaid)
+	return pool
+}
+
+func saveHbdSavingsPool(pool *HbdSavingsPool) {
+	stateSetIfChanged(HbdSavingsPoolKey, fmt.Sprintf("%d_%d_%s", pool.Booked, pool.Unpaid, pool.Acc.String()))
+}
+
+// loadHbdInterestDebt returns a treasury's interest debt against the pool.
+func loadHbdInterestDebt(projectID uint64) *big.Int {
+	debt := new(big.Int)
+	ptr := sdk.StateGetObject(projectHbdInterestKey(projectID))
+	if ptr == nil || *ptr == "" {
+		return debt
+	}
+	if _, ok := debt.SetString(*ptr, 10); !ok {
+		abort(errcode.Internal, "failed to decode hbd interest debt")
+	}
+	return debt
+}
+
+// saveHbdInterestDebt persists the debt, dropping the key once it is zero.
+func saveHbdInterestDebt(projectID uint64, debt *big.Int) {
+	key := projectHbdInterestKey(projectID)
+	if debt.Sign() == 0 {
+		sdk.StateDeleteObject(key)
+		return
+	}
+	stateSetIfChanged(key, debt.String())
+}
@@ -4,23 +4,12 @@ import "okinoko_dao/sdk"
 
 // setWhitelistEntry stores a short-lived approval entry.
 func setWhitelistEntry(projectID uint64, addr sdk.Address) bool {
-	key := whitelistKey(projectID, addr)
-	if existing := sdk.StateGetObject(key); existing != nil && *existing != "" {
-		return false
-	}
-	sdk.StateSetObject(key, "1")
-	return true
+	return addListEntry(projectID, listWhitelist, whitelistKey(projectID, addr), AddressToString(addr))
 }
 
 // deleteWhitelistEntry removes a pending approval and reports whether it existed.
 func deleteWhitelistEntry(projectID uint64, addr sdk.Address) bool {
-	key := whitelistKey(projectID, addr)
-	existing := sdk.StateGetObject(key)
-	if existing == nil || *existing == "" {
-		return false
-	}
-	sdk.StateDeleteObject(key)
-	return true
+	return deleteListEntry(projectID, listWhitelist, whitelistKey(projectID, addr), AddressToString(addr))
 }
 
 // isWhitelisted reports whether an address holds a pending approval.
@@ -29,3 +18,15 @@ func isWhitelisted(projectID uint64, addr sdk.Address) bool {
 	existing := sdk.StateGetObject(key)
 	return existing != nil && *existing != ""
 }
+
+// whitelistEntries lists a project's pending approvals in the order they were made.
+func whitelistEntries(projectID uint64) []sdk.Address {
+	entries := listEntries(projectID, listWhitelist, func(entry string) string {
+		return whitelistKey(projectID, AddressFromString(entry))
+	})
+	addrs := make([]sdk.Address, 0, len(entries))
+	for _, entry := range entries {
+		addrs = append(addrs, AddressFromString(entry))
+	}
+	return addrs
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"math"
 
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 )
 
@@ -25,10 +26,15 @@ type VotingSystem uint8
 // ProposalState captures a proposal's lifecycle.
 type ProposalState uint8
 
-// ContractConfig stores contract-level settings set during initialization.
+// ContractConfig stores contract-level settings set during initialization and
+// changed by the contract owner afterwards (see contract_admin.go).
 type ContractConfig struct {
-	Owner                 sdk.Address // Contract owner who initialized the contract
+	Owner                 sdk.Address // Contract owner; empty once ownership was renounced
 	ProjectCreationPublic bool        // If false, only owner can create projects
+	EventFormat           EventFormat // Event encoding(s) logged, see events_json.go
+	PendingOwner          sdk.Address // Offered ownership until they accept; empty when none
+	CreationFee           Amount      // Charged by project_create and paid to the owner; 0 when none
+	CreationFeeAsset      sdk.Asset
 }
 
 // FloatToAmount scales human floats by AmountScale and rounds to int64 so storage stays precise.
@@ -36,17 +42,24 @@ type ContractConfig struct {
 // a bogus (possibly negative) balance.
 // Example payload: FloatToAmount(1.234)
 func FloatToAmount(v float64) Amount {
+	a, f := checkAmount(v)
+	must(f)
+	return a
+}
+
+// checkAmount is FloatToAmount without the abort.
+func checkAmount(v float64) (Amount, *failure) {
 	scaled := math.Round(v * AmountScale)
 	if math.IsNaN(scaled) || math.IsInf(scaled, 0) {
-		sdk.Abort("invalid amount")
+		return 0, fail(errcode.InvalidValue, "invalid amount")
 	}
 	// float64(math.MaxInt64) rounds UP to 2^63, which is not a valid int64. Use >=
 	// so a scaled value of exactly 2^63 is rejected instead of wrapping negative
 	// (native) or trapping (wasm i64.trunc). MinInt64 == -2^63 is exact, so keep <.
 	if scaled >= float64(math.MaxInt64) || scaled < float64(math.MinInt64) {
-		sdk.Abort("amount out of range")
+		return 0, fail(errcode.InvalidValue, "amount out of range")
 	}
-	return Amount(scaled)
+	return Amount(scaled), nil
 }
 
 // AmountToFloat converts back to float64 for reporting or events.
@@ -69,11 +82,20 @@ func (vs VotingSystem) String() string {
 		return "0"
 	case VotingSystemStake:
 		return "1"
+	case VotingSystemStakeReputation:
+		return "2"
 	default:
 		return "0"
 	}
 }
 
+// IsStakeWeighted reports whether ballots are weighted by stake (plain or
+// reputation-blended) rather than one unit per member.
+// Example payload: VotingSystemStakeReputation.IsStakeWeighted()
+func (vs VotingSystem) IsStakeWeighted() bool {
+	return vs == VotingSystemStake || vs == VotingSystemStakeReputation
+}
+
 // String prints the proposal state as lower-case text for events and logs.
 // Example payload: ProposalPassed.String()
 func (ps ProposalState) String() string {
@@ -110,6 +132,11 @@ type ProjectConfig struct {
 	MembershipNftPayloadFormat    string
 	ProposalsMembersOnly          bool
 	WhitelistOnly                 bool
+	// ICCThresholdPercent is the threshold a proposal must reach when one of
+	// its inter-contract calls targets a contract:function pair outside the
+	// project's ICC allowlist. 0, or anything below ThresholdPercent, leaves
+	// the normal threshold in force.
+	ICCThresholdPercent float64
 }
 
 type Member struct {
@@ -134,6 +161,13 @@ type Member struct {
 	// amount to withdraw once the cooldown passes. See UnstakeProject.
 	UnstakeRequested int64
 	UnstakePending   Amount
+	// ReputationAt is the point in time Reputation has been decayed up to. Decay is
+	// applied lazily whenever the member's reputation is touched. See reputation.go.
+	ReputationAt int64
+	// AssetStakes holds stake deposited in the project's additional stake assets
+	// (see Project.StakeWeights), in raw units of each asset. Stake above stays
+	// the funds-asset stake.
+	AssetStakes map[sdk.Asset]Amount
 }
 
 type Project struct {
@@ -149,6 +183,15 @@ type Project struct {
 	Metadata    string
 	StakeTotal  Amount
 	MemberCount uint64
+	// StakeWeights lists additional stake assets and the voting weight of one
+	// unit of each relative to one unit of FundsAsset. AssetStakeTotals holds the
+	// raw stake per additional asset, the counterpart of StakeTotal.
+	StakeWeights     map[sdk.Asset]float64
+	AssetStakeTotals map[sdk.Asset]Amount
+	// Dissolved is set once a dissolve_project proposal has liquidated the
+	// project, or an export has moved it to another deployment. It is terminal:
+	// loadProject rejects every later call.
+	Dissolved bool
 }
 
 // ProjectMeta stores immutable/general metadata for a project.
@@ -160,6 +203,7 @@ type ProjectMeta struct {
 	Tx          string
 	Metadata    string
 	URL         string
+	Dissolved   bool
 }
 
 // ProjectFinance keeps track of treasury and aggregate staking data.
@@ -168,6 +212,9 @@ type ProjectFinance struct {
 	StakeTotal  Amount
 	MemberCount uint64
 	Treasury    map[sdk.Asset]Amount // Multi-asset treasury balances
+	// StakeWeights / AssetStakeTotals: see Project.
+	StakeWeights     map[sdk.Asset]float64
+	AssetStakeTotals map[sdk.Asset]Amount
 }
 
 type ProposalOption struct {
@@ -177,11 +224,30 @@ type ProposalOption struct {
 	VoterCount  uint64
 }
 
+// PayoutMode selects how a payout reaches its recipient.
+type PayoutMode uint8
+
+const (
+	// PayoutModeLedger transfers within the VSC ledger (default).
+	PayoutModeLedger PayoutMode = 0
+	// PayoutModeL1 withdraws to the recipient's Hive account on the base layer.
+	PayoutModeL1 PayoutMode = 1
+)
+
+// String returns the payload/event spelling of the mode.
+func (m PayoutMode) String() string {
+	if m == PayoutModeL1 {
+		return "l1"
+	}
+	return "ledger"
+}
+
 // PayoutEntry represents a single payout with address and asset specification
 type PayoutEntry struct {
 	Address sdk.Address
 	Amount  Amount
 	Asset   sdk.Asset
+	Mode    PayoutMode
 }
 
 // InterContractCall represents a single inter-contract call with asset transfers
@@ -190,6 +256,31 @@ type InterContractCall struct {
 	Function        string               // Function/method to call
 	Payload         string               // JSON payload string for the function
 	Assets          map[sdk.Asset]Amount // Asset transfers to include (e.g., map[HIVE]1000, map[HBD]500)
+	Expect          ICCExpectation       // Optional check on the callee's response
+	Receive         []sdk.Asset          // Assets the callee sends back, credited to the treasury
+}
+
+// ICCExpectKind selects how an inter-contract call's response is checked.
+type ICCExpectKind uint8
+
+const (
+	// ICCExpectNone accepts any response.
+	ICCExpectNone ICCExpectKind = 0
+	// ICCExpectExact requires the response to equal Value.
+	ICCExpectExact ICCExpectKind = 1
+	// ICCExpectContains requires the response to contain Value.
+	ICCExpectContains ICCExpectKind = 2
+	// ICCExpectMin requires the number at Field of the JSON response to be at
+	// least Value. An empty Field reads the whole response as the number.
+	ICCExpectMin ICCExpectKind = 3
+)
+
+// ICCExpectation is the predicate a callee's response must satisfy for the
+// proposal execution to go through.
+type ICCExpectation struct {
+	Kind  ICCExpectKind
+	Field string // dotted path into the JSON response, for ICCExpectMin
+	Value string
 }
 
 type ProposalOutcome struct {
@@ -223,6 +314,52 @@ type Proposal struct {
 	// vote only if their JoinSeq is strictly below it, which matches exactly the
 	// membership captured by MemberCountSnapshot/StakeSnapshot.
 	JoinSeqSnapshot uint64
+	// QuorumReached is set by the tally. Voters on a quorate proposal may claim
+	// participation reputation whatever the outcome was.
+	QuorumReached bool
+	// VotedStake and VotedWeight sum the ballots of a reputation-blended
+	// proposal: the stake behind them and the blended weight they carry. The
+	// tally measures approval against VotedWeight plus the stake that has not
+	// voted, the most the snapshot could still cast.
+	VotedStake  Amount
+	VotedWeight Amount
+	// StakeWeights is the project's stake-asset weighting at creation. Ballots
+	// are weighed with it so a later weight change cannot skew StakeSnapshot.
+	StakeWeights map[sdk.Asset]float64
+	// NotBefore and NotAfter bound when a passed proposal may execute, in unix
+	// seconds; 0 leaves that side open. Past NotAfter the proposal fails.
+	NotBefore int64
+	NotAfter  int64
+	// Condition, when set, must hold in another contract's state for the
+	// proposal to execute.
+	Condition *StateCondition
+	// Prerequisites are earlier proposals of the same project that must have
+	// executed before this one may; if one fails, this one fails too.
+	Prerequisites []uint64
+	// VotingSystem is the project's voting system at creation. Ballots and the
+	// tally use it, so update_votingSystem cannot change how an open proposal
+	// is counted. Nil on proposals created before it was recorded.
+	VotingSystem *VotingSystem
+}
+
+// ConditionOp compares a contract state value with a proposal condition's value.
+type ConditionOp uint8
+
+const (
+	ConditionEq ConditionOp = iota + 1 // equal text
+	ConditionNe                        // different text
+	ConditionLt                        // numerically less
+	ConditionLe                        // numerically less or equal
+	ConditionGt                        // numerically greater
+	ConditionGe                        // numerically greater or equal
+)
+
+// StateCondition is a check on one key of another contract's state.
+type StateCondition struct {
+	Contract string
+	Key      string
+	Op       ConditionOp
+	Value    string
 }
 
 type CreateProjectArgs struct {
@@ -231,6 +368,7 @@ type CreateProjectArgs struct {
 	Description   string
 	Metadata      string
 	URL           string
+	StakeWeights  map[sdk.Asset]float64
 }
 
 type ProposalOptionInput struct {
@@ -248,6 +386,10 @@ type CreateProposalArgs struct {
 	Metadata         string
 	ForcePoll        bool
 	URL              string
+	NotBefore        int64
+	NotAfter         int64
+	Condition        *StateCondition
+	Prerequisites    []uint64
 }
 
 type VoteProposalArgs struct {
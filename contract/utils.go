@@ -1,10 +1,12 @@
 package main
 
 import (
+	"math/bits"
 	"strconv"
 	"strings"
 	"time"
 
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 )
 
@@ -81,7 +83,7 @@ func nowUnix() int64 {
 	// per-node time.Now() would stamp divergent values into state on every
 	// validator — an immediate chain fork. A missing/unparseable block timestamp
 	// is an unrecoverable environment error, so fail deterministically instead.
-	sdk.Abort("block timestamp unavailable")
+	abort(errcode.Internal, "block timestamp unavailable")
 	return 0
 }
 
@@ -122,34 +124,45 @@ func AddressToString(a sdk.Address) string { return a.String() }
 // call, so reject over-length ids and any character that would corrupt those:
 // '|' (create field separator), '"' and '\' (JSON), and control/whitespace bytes.
 func validateTokenId(id string) {
+	must(checkTokenId(id))
+}
+
+// checkTokenId is validateTokenId without the abort.
+func checkTokenId(id string) *failure {
 	if id == "" {
-		sdk.Abort("membership nft id required")
+		return fail(errcode.InvalidPayload, "membership nft id required")
 	}
 	if len(id) > MaxTokenIdLength {
-		sdk.Abort("membership nft id exceeds maximum length")
+		return fail(errcode.InvalidValue, "membership nft id exceeds maximum length")
 	}
 	for i := 0; i < len(id); i++ {
 		c := id[i]
 		if c == '|' || c == '"' || c == '\\' || c <= ' ' {
-			sdk.Abort("invalid character in membership nft id")
+			return fail(errcode.InvalidValue, "invalid character in membership nft id")
 		}
 	}
+	return nil
 }
 
 func validateAddress(addr sdk.Address) {
+	must(checkAddress(addr))
+}
+
+// checkAddress is validateAddress without the abort.
+func checkAddress(addr sdk.Address) *failure {
 	s := addr.String()
 	if s == "" {
-		sdk.Abort("address required")
+		return fail(errcode.InvalidPayload, "address required")
 	}
 	if len(s) > MaxAddressLength {
-		sdk.Abort("address exceeds maximum length")
+		return fail(errcode.InvalidValue, "address exceeds maximum length")
 	}
 	for i := 0; i < len(s); i++ {
 		c := s[i]
 		// Reject the structural delimiters ('|' state keys/config, ';' and ','
 		// record separators) and any control/whitespace byte (<= 0x20).
 		if c == '|' || c == ';' || c == ',' || c <= ' ' {
-			sdk.Abort("invalid character in address")
+			return fail(errcode.InvalidValue, "invalid character in address")
 		}
 	}
 	// Require a known address namespace with a non-empty body. Without this, bare
@@ -160,10 +173,10 @@ func validateAddress(addr sdk.Address) {
 	// transfer the treasury to an unrecoverable destination.
 	for _, prefix := range validAddressPrefixes {
 		if len(s) > len(prefix) && strings.HasPrefix(s, prefix) {
-			return
+			return nil
 		}
 	}
-	sdk.Abort("address must be hive:<name> or did:<id>")
+	return fail(errcode.InvalidValue, "address must be hive:<name> or did:<id>")
 }
 
 // -----------------------------------------------------------------------------
@@ -175,14 +188,29 @@ func validateAddress(addr sdk.Address) {
 // insufficient-funds guards downstream).
 func safeAddAmount(a, b Amount) Amount {
 	if b > 0 && a > maxAmount-b {
-		sdk.Abort("amount overflow")
+		abort(errcode.Internal, "amount overflow")
 	}
 	if b < 0 && a < minAmount-b {
-		sdk.Abort("amount underflow")
+		abort(errcode.Internal, "amount underflow")
 	}
 	return a + b
 }
 
+// mulDivAmount returns floor(a*b/c) for non-negative a, b and positive c with
+// b <= c, computing the product in 128 bits so large balances cannot overflow.
+// The result never exceeds a, which is what pro-rata shares rely on.
+func mulDivAmount(a, b, c Amount) Amount {
+	if a <= 0 || b <= 0 || c <= 0 {
+		return 0
+	}
+	if b > c {
+		abort(errcode.Internal, "amount ratio out of range")
+	}
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	q, _ := bits.Div64(hi, lo, uint64(c))
+	return Amount(q)
+}
+
 // AssetFromString wraps a ticker string so type checking keeps us honest.
 func AssetFromString(s string) sdk.Asset { return sdk.Asset(s) }
 
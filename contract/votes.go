@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"math"
+	"okinoko_dao/errcode"
 	"okinoko_dao/sdk"
 	"sort"
 )
@@ -25,29 +26,53 @@ func proposalVoteKey(id uint64, voter sdk.Address) string {
 
 // saveVote persists a voter's choices and voting weight
 // for a specific proposal.
-func saveVote(id uint64, voter sdk.Address, choices []uint, weight float64) {
-	data := encodeVoteRecord(choices, weight)
-	sdk.StateSetObject(proposalVoteKey(id, voter), data)
+func saveVote(id uint64, voter sdk.Address, rec *voteRecord) {
+	sdk.StateSetObject(proposalVoteKey(id, voter), encodeVoteRecord(rec))
 }
 
-func encodeVoteRecord(choices []uint, weight float64) string {
+// encodeVoteRecord packs choices and weight. A trailing flag byte marks a
+// receipt whose participation reputation has been claimed, and the stake
+// behind a reputation-blended ballot follows it; ballots with neither omit
+// both.
+func encodeVoteRecord(rec *voteRecord) string {
 	var buf bytes.Buffer
 	var tmp [binary.MaxVarintLen64]byte
-	count := binary.PutUvarint(tmp[:], uint64(len(choices)))
+	count := binary.PutUvarint(tmp[:], uint64(len(rec.Choices)))
 	buf.Write(tmp[:count])
-	for _, choice := range choices {
+	for _, choice := range rec.Choices {
 		n := binary.PutUvarint(tmp[:], uint64(choice))
 		buf.Write(tmp[:n])
 	}
 	var floatBuf [8]byte
-	binary.BigEndian.PutUint64(floatBuf[:], math.Float64bits(weight))
+	binary.BigEndian.PutUint64(floatBuf[:], math.Float64bits(rec.Weight))
 	buf.Write(floatBuf[:])
+	if rec.Rewarded || rec.Stake != 0 {
+		if rec.Rewarded {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	}
+	if rec.Stake != 0 {
+		binary.BigEndian.PutUint64(floatBuf[:], math.Float64bits(rec.Stake))
+		buf.Write(floatBuf[:])
+	}
 	return buf.String()
 }
 
 type voteRecord struct {
 	Choices []uint
 	Weight  float64
+	// Rewarded is set once reputation_claim has credited this ballot.
+	Rewarded bool
+	// Stake is the stake behind a reputation-blended ballot, before blending.
+	Stake float64
+}
+
+// markVoteRewarded rewrites the receipt with the claimed flag set.
+func markVoteRewarded(id uint64, voter sdk.Address, rec *voteRecord) {
+	rec.Rewarded = true
+	saveVote(id, voter, rec)
 }
 
 func loadVoteRecord(id uint64, voter sdk.Address) *voteRecord {
@@ -64,22 +89,29 @@ func decodeVoteRecord(data string) *voteRecord {
 	reader := bytes.NewReader([]byte(data))
 	count, err := binary.ReadUvarint(reader)
 	if err != nil {
-		sdk.Abort("failed to decode vote record")
+		abort(errcode.Internal, "failed to decode vote record")
 	}
 	choices := make([]uint, 0, count)
 	for i := uint64(0); i < count; i++ {
 		val, err := binary.ReadUvarint(reader)
 		if err != nil {
-			sdk.Abort("failed to decode vote choice")
+			abort(errcode.Internal, "failed to decode vote choice")
 		}
 		choices = append(choices, uint(val))
 	}
 	var floatBuf [8]byte
 	if _, err := reader.Read(floatBuf[:]); err != nil {
-		sdk.Abort("failed to decode vote weight")
+		abort(errcode.Internal, "failed to decode vote weight")
 	}
 	weight := math.Float64frombits(binary.BigEndian.Uint64(floatBuf[:]))
-	return &voteRecord{Choices: choices, Weight: weight}
+	rec := &voteRecord{Choices: choices, Weight: weight}
+	if flag, err := reader.ReadByte(); err == nil {
+		rec.Rewarded = flag == 1
+	}
+	if _, err := reader.Read(floatBuf[:]); err == nil {
+		rec.Stake = math.Float64frombits(binary.BigEndian.Uint64(floatBuf[:]))
+	}
+	return rec
 }
 
 // VoteProposal validates membership + weight, then updates options and stores the vote receipt.
@@ -92,12 +124,12 @@ func VoteProposal(payload *string) *string {
 	// An empty ballot is not participation — reject it so it cannot silently count
 	// toward quorum (the distinct-voter counter below only bumps on a real ballot).
 	if len(input.Choices) == 0 {
-		sdk.Abort("vote must select at least one option")
+		abort(errcode.InvalidValue, "vote must select at least one option")
 	}
 	prpsl := loadProposal(input.ProposalID)
 
 	if prpsl.State != ProposalActive {
-		sdk.Abort("proposal not active")
+		abort(errcode.ProposalState, "proposal not active")
 	}
 	prj := loadProject(prpsl.ProjectID)
 	voter := getActorAddress()
@@ -118,7 +150,7 @@ func VoteProposal(payload *string) *string {
 	// never needed. A `>=` comparison would instead disenfranchise the former.
 	// Timestamps simply cannot order events within a block; the join sequence can.
 	if member.JoinSeq >= prpsl.JoinSeqSnapshot {
-		sdk.Abort("proposal was created before joining the project")
+		abort(errcode.NotEligible, "proposal was created before joining the project")
 	}
 
 	// Determine voting weight.
@@ -126,27 +158,41 @@ func VoteProposal(payload *string) *string {
 	//    so free-membership DAOs (stake 0) remain governable.
 	//  - Stake projects use the member's historical stake at proposal-creation time,
 	//    which prevents topping up stake after creation to buy more voting power.
+	//  - Both follow the voting system the proposal was created under.
+	votingSystem := proposalVotingSystem(prj, prpsl)
 	var weight Amount
-	if prj.Config.VotingSystem == VotingSystemDemocratic {
+	if votingSystem == VotingSystemDemocratic {
 		weight = Amount(AmountScale) // one vote unit
 	} else {
-		weight = getStakeAtTime(prj.ID, voterAddr, prpsl.CreatedAt, member.StakeIncrement)
+		weight = getStakeAtTime(prj.ID, voterAddr, prpsl.CreatedAt, member.StakeIncrement, prpsl.StakeWeights)
 		if weight == 0 {
-			sdk.Abort("no stake history found at proposal creation time")
+			abort(errcode.NotEligible, "no stake history found at proposal creation time")
 		}
 		// A member who withdrew stake after the proposal was created must not vote
 		// with the higher historical snapshot — cap the weight at what they still
 		// hold. (A top-up after creation already cannot raise it, since
 		// getStakeAtTime only sees history entries at or before CreatedAt.)
-		if member.Stake < weight {
-			weight = member.Stake
+		if current := memberVotingStake(prpsl.StakeWeights, &member); current < weight {
+			weight = current
 		}
 		// check if stakemin is still valid (it can get modified by proposals)
 		if FloatToAmount(prj.Config.StakeMinAmt) > weight {
-			sdk.Abort("minimum stake requirement not met at proposal creation time")
+			abort(errcode.NotEligible, "minimum stake requirement not met at proposal creation time")
 		}
 	}
 
+	// Reputation-blended projects scale the stake weight by the voter's current
+	// (decayed) reputation. The result never exceeds the stake itself, so the
+	// StakeSnapshot denominator in the tally stays an upper bound.
+	memberChanged := false
+	stake := weight
+	if votingSystem == VotingSystemStakeReputation {
+		if decayReputation(prj.ID, &member, nowUnix()) {
+			memberChanged = true
+		}
+		weight = reputationWeight(weight, member.Reputation)
+	}
+
 	// Load all options once to avoid repeated storage reads
 	optionCache := make(map[uint32]*ProposalOption)
 
@@ -181,7 +227,7 @@ func VoteProposal(payload *string) *string {
 	seen := map[uint]bool{}
 	for _, idx := range input.Choices {
 		if idx >= uint(prpsl.OptionCount) {
-			sdk.Abort("invalid option index")
+			abort(errcode.InvalidValue, "invalid option index")
 		}
 		// avoid double-counting same option in one vote
 		if seen[idx] {
@@ -228,7 +274,6 @@ func VoteProposal(payload *string) *string {
 	// until the deadline means voters keep skin in the game for the decision they
 	// influenced.
 	deadline := prpsl.CreatedAt + int64(prpsl.DurationHours)*3600
-	memberChanged := false
 	if deadline > member.VoteLockUntil {
 		member.VoteLockUntil = deadline
 		memberChanged = true
@@ -243,12 +288,29 @@ func VoteProposal(payload *string) *string {
 
 	// Track DISTINCT voters for quorum (a voter selecting multiple options must
 	// count once, not once per option). Only a brand-new ballot bumps the count.
+	proposalChanged := false
 	if prevVote == nil {
 		prpsl.VoterCount++
+		proposalChanged = true
+	}
+	// Reputation-blended proposals also sum the ballots' stake and blended
+	// weight for the tally's denominator (see thresholdReached).
+	rec := &voteRecord{Choices: input.Choices, Weight: AmountToFloat(weight)}
+	if votingSystem == VotingSystemStakeReputation {
+		rec.Stake = AmountToFloat(stake)
+		if prevVote != nil {
+			prpsl.VotedStake -= FloatToAmount(prevVote.Stake)
+			prpsl.VotedWeight -= FloatToAmount(prevVote.Weight)
+		}
+		prpsl.VotedStake += stake
+		prpsl.VotedWeight += weight
+		proposalChanged = true
+	}
+	if proposalChanged {
 		saveProposal(prpsl)
 	}
 
-	saveVote(input.ProposalID, voter, input.Choices, AmountToFloat(weight))
+	saveVote(input.ProposalID, voter, rec)
 	emitVoteCasted(input.ProposalID, AddressToString(voterAddr), input.Choices, AmountToFloat(weight))
 	return strptr("voted")
 }
@@ -0,0 +1,8 @@
+//go:build !native
+
+package main
+
+// main is left empty on purpose
+func main() {
+
+}
@@ -0,0 +1,525 @@
+//go:build native
+
+// Package emulator is an in-memory VSC node for contracts built with the native
+// tag. It implements sdk.Host: contract state, ledger balances with
+// transfer.allow intents, HBD savings, block time and calls between registered
+// contracts, so a contract package can be exercised with plain `go test -tags
+// native` instead of tinygo artifacts and vsc-node's test harness.
+//
+// Contracts are registered as Go functions (the same ones the contract exports
+// to wasm). The sdk talks to a single process-wide host, so tests driving an
+// Emulator must not run in parallel.
+package emulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"okinoko_dao/sdk"
+)
+
+// MaxCallDepth mirrors the node's CONTRACT_CALL_MAX_RECURSION_DEPTH.
+const MaxCallDepth = 20
+
+// HbdUnstakePeriod is how long unstaked HBD savings take to become HBD.
+const HbdUnstakePeriod = 72 * time.Hour
+
+// TimestampLayout is the block.timestamp format the node reports.
+const TimestampLayout = "2006-01-02T15:04:05"
+
+// Method is one exported contract function.
+type Method func(payload *string) *string
+
+// Call is a transaction calling a contract.
+type Call struct {
+	Caller     string // signing account; msg.sender and msg.caller of the first frame
+	ContractID string
+	Action     string
+	Payload    string // raw payload as the node passes it, e.g. `"1|true"`
+	Intents    []sdk.Intent
+	TxID       string // defaults to a fresh, process-wide unique "tx-<n>"
+}
+
+// Result is the outcome of a Call. A failed call leaves state and balances as
+// they were before it.
+type Result struct {
+	Success bool
+	Ret     string
+	Err     string // abort/revert message or runtime panic
+	Symbol  string // revert symbol, "" for aborts
+	Logs    []string
+}
+
+// Withdrawal is an asset unmapped from the ledger to a Hive account.
+type Withdrawal struct {
+	From   string
+	To     string
+	Amount int64
+	Asset  string
+}
+
+type contract struct {
+	owner   string
+	methods map[string]Method
+}
+
+type pendingUnstake struct {
+	account   string
+	amount    int64
+	maturesAt time.Time
+}
+
+type frame struct {
+	contractID string
+	caller     string
+	sender     string
+	intents    []sdk.Intent
+	drawn      map[string]int64
+}
+
+// txSeq numbers transactions process-wide: contracts cache per-tx data keyed on
+// tx.id in package globals, which outlive a single Emulator.
+var txSeq uint64
+
+var _ sdk.Host = (*Emulator)(nil)
+
+// failure is the panic value Abort and Revert unwind a call with.
+type failure struct {
+	msg    string
+	symbol string
+}
+
+// Emulator is an in-memory node. The zero value is not usable; call New.
+type Emulator struct {
+	contracts   map[string]*contract
+	state       map[string]map[string]string
+	balances    map[string]map[string]int64
+	unstakes    []pendingUnstake
+	withdrawals []Withdrawal
+	now         time.Time
+	height      uint64
+	txID        string
+	frames      []*frame
+	logs        []string
+}
+
+// New returns an empty emulator whose clock starts at start.
+func New(start time.Time) *Emulator {
+	return &Emulator{
+		contracts: map[string]*contract{},
+		state:     map[string]map[string]string{},
+		balances:  map[string]map[string]int64{},
+		now:       start.UTC(),
+	}
+}
+
+// ContractAddress returns the ledger account of a contract.
+func ContractAddress(contractID string) string {
+	return "contract:" + contractID
+}
+
+// TransferAllow builds a transfer.allow intent for limit (decimal, e.g. "1.000").
+func TransferAllow(limit string, asset string) sdk.Intent {
+	return sdk.Intent{Type: "transfer.allow", Args: map[string]string{"limit": limit, "token": asset}}
+}
+
+// Register adds a contract with its exported methods.
+func (e *Emulator) Register(contractID string, owner string, methods map[string]Method) {
+	e.contracts[contractID] = &contract{owner: owner, methods: methods}
+	if e.state[contractID] == nil {
+		e.state[contractID] = map[string]string{}
+	}
+}
+
+// Deposit credits amount (base units) of asset to an account.
+func (e *Emulator) Deposit(address string, asset string, amount int64) {
+	e.credit(address, asset, amount)
+}
+
+// Balance returns an account's balance of asset in base units.
+func (e *Emulator) Balance(address string, asset string) int64 {
+	return e.balances[address][asset]
+}
+
+// State returns a raw state value of a contract.
+func (e *Emulator) State(contractID string, key string) (string, bool) {
+	v, ok := e.state[contractID][key]
+	return v, ok
+}
+
+// StateKeys returns the sorted state keys of a contract.
+func (e *Emulator) StateKeys(contractID string) []string {
+	keys := make([]string, 0, len(e.state[contractID]))
+	for k := range e.state[contractID] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Withdrawals lists every withdrawal to Hive so far.
+func (e *Emulator) Withdrawals() []Withdrawal {
+	return append([]Withdrawal(nil), e.withdrawals...)
+}
+
+// Now returns the current block time.
+func (e *Emulator) Now() time.Time {
+	return e.now
+}
+
+// SetTime moves the block clock. Matured HBD unstakes are credited.
+func (e *Emulator) SetTime(t time.Time) {
+	e.now = t.UTC()
+	e.settleUnstakes()
+}
+
+// Advance moves the block clock forward by d.
+func (e *Emulator) Advance(d time.Duration) {
+	e.SetTime(e.now.Add(d))
+}
+
+// Call runs a transaction. Aborts, reverts and runtime panics anywhere in the
+// call tree fail the whole transaction and roll back its effects.
+func (e *Emulator) Call(c Call) (res Result) {
+	if len(e.frames) > 0 {
+		panic("emulator: Call while a call is running, use sdk.ContractCall")
+	}
+	txSeq++
+	e.height++
+	e.txID = c.TxID
+	if e.txID == "" {
+		e.txID = fmt.Sprintf("tx-%d", txSeq)
+	}
+	e.logs = nil
+	e.settleUnstakes()
+	sdk.SetHost(e)
+
+	snap := e.snapshot()
+	defer func() {
+		if r := recover(); r != nil {
+			e.frames = nil
+			e.restore(snap)
+			res = Result{}
+			if f, ok := r.(failure); ok {
+				res.Err, res.Symbol = f.msg, f.symbol
+			} else {
+				res.Err = fmt.Sprint(r)
+			}
+		}
+		res.Logs = e.logs
+	}()
+	ret := e.invoke(c.ContractID, c.Action, c.Payload, c.Caller, c.Caller, c.Intents)
+	res.Success = true
+	if ret != nil {
+		res.Ret = *ret
+	}
+	return res
+}
+
+// invoke runs one contract frame.
+func (e *Emulator) invoke(contractID, method, payload, caller, sender string, intents []sdk.Intent) *string {
+	if len(e.frames) >= MaxCallDepth {
+		e.Abort("contract call depth exceeded")
+	}
+	ct, ok := e.contracts[contractID]
+	if !ok {
+		e.Abort("contract not found: " + contractID)
+	}
+	fn, ok := ct.methods[method]
+	if !ok {
+		e.Abort("method not found: " + method)
+	}
+	e.frames = append(e.frames, &frame{
+		contractID: contractID,
+		caller:     caller,
+		sender:     sender,
+		intents:    intents,
+		drawn:      map[string]int64{},
+	})
+	defer func() { e.frames = e.frames[:len(e.frames)-1] }()
+	return fn(&payload)
+}
+
+func (e *Emulator) frame() *frame {
+	if len(e.frames) == 0 {
+		panic("emulator: host function called outside a contract call")
+	}
+	return e.frames[len(e.frames)-1]
+}
+
+// -----------------------------------------------------------------------------
+// Snapshots
+// -----------------------------------------------------------------------------
+
+type snapshot struct {
+	state       map[string]map[string]string
+	balances    map[string]map[string]int64
+	unstakes    []pendingUnstake
+	withdrawals []Withdrawal
+}
+
+func (e *Emulator) snapshot() snapshot {
+	s := snapshot{
+		state:       make(map[string]map[string]string, len(e.state)),
+		balances:    make(map[string]map[string]int64, len(e.balances)),
+		unstakes:    append([]pendingUnstake(nil), e.unstakes...),
+		withdrawals: append([]Withdrawal(nil), e.withdrawals...),
+	}
+	for id, kv := range e.state {
+		m := make(map[string]string, len(kv))
+		for k, v := range kv {
+			m[k] = v
+		}
+		s.state[id] = m
+	}
+	for addr, bals := range e.balances {
+		m := make(map[string]int64, len(bals))
+		for a, v := range bals {
+			m[a] = v
+		}
+		s.balances[addr] = m
+	}
+	return s
+}
+
+func (e *Emulator) restore(s snapshot) {
+	e.state = s.state
+	e.balances = s.balances
+	e.unstakes = s.unstakes
+	e.withdrawals = s.withdrawals
+}
+
+// -----------------------------------------------------------------------------
+// Ledger
+// -----------------------------------------------------------------------------
+
+func (e *Emulator) credit(address, asset string, amount int64) {
+	if e.balances[address] == nil {
+		e.balances[address] = map[string]int64{}
+	}
+	e.balances[address][asset] += amount
+}
+
+func (e *Emulator) debit(address, asset string, amount int64) {
+	if e.balances[address][asset] < amount {
+		e.Abort(fmt.Sprintf("insufficient %s balance of %s", asset, address))
+	}
+	e.balances[address][asset] -= amount
+}
+
+func (e *Emulator) settleUnstakes() {
+	kept := e.unstakes[:0]
+	for _, u := range e.unstakes {
+		if !e.now.Before(u.maturesAt) {
+			e.credit(u.account, string(sdk.AssetHbd), u.amount)
+			continue
+		}
+		kept = append(kept, u)
+	}
+	e.unstakes = kept
+}
+
+// parseAmount reads a base-unit amount argument.
+func (e *Emulator) parseAmount(s string) int64 {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v <= 0 {
+		e.Abort("invalid amount: " + s)
+	}
+	return v
+}
+
+// parseLimit reads a transfer.allow limit ("1.5", "1.000") as base units.
+func parseLimit(s string) (int64, bool) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(frac) > 3 {
+		return 0, false
+	}
+	frac += strings.Repeat("0", 3-len(frac))
+	v, err := strconv.ParseInt(whole+frac, 10, 64)
+	return v, err == nil && v > 0
+}
+
+// allowance returns the transfer.allow limit of the current frame for asset.
+func (e *Emulator) allowance(f *frame, asset string) int64 {
+	for _, in := range f.intents {
+		if in.Type != "transfer.allow" || in.Args["token"] != asset {
+			continue
+		}
+		if v, ok := parseLimit(in.Args["limit"]); ok {
+			return v
+		}
+	}
+	return 0
+}
+
+// -----------------------------------------------------------------------------
+// sdk.Host
+// -----------------------------------------------------------------------------
+
+func (e *Emulator) Log(msg string) {
+	e.logs = append(e.logs, msg)
+}
+
+func (e *Emulator) StateSet(key, value string) {
+	e.state[e.frame().contractID][key] = value
+}
+
+func (e *Emulator) StateGet(key string) *string {
+	if v, ok := e.state[e.frame().contractID][key]; ok {
+		return &v
+	}
+	return nil
+}
+
+func (e *Emulator) StateDelete(key string) {
+	delete(e.state[e.frame().contractID], key)
+}
+
+func (e *Emulator) env() map[string]interface{} {
+	f := e.frame()
+	intents := f.intents
+	if intents == nil {
+		intents = []sdk.Intent{}
+	}
+	return map[string]interface{}{
+		"contract.id":                f.contractID,
+		"contract.owner":             e.contracts[f.contractID].owner,
+		"tx.id":                      e.txID,
+		"tx.index":                   0,
+		"tx.op_index":                0,
+		"block.id":                   fmt.Sprintf("block-%d", e.height),
+		"block.height":               e.height,
+		"block.timestamp":            e.now.Format(TimestampLayout),
+		"msg.sender":                 f.sender,
+		"msg.caller":                 f.caller,
+		"msg.payer":                  f.sender,
+		"msg.required_auths":         []string{f.sender},
+		"msg.required_posting_auths": []string{},
+		"intents":                    intents,
+	}
+}
+
+func (e *Emulator) Env() string {
+	b, err := json.Marshal(e.env())
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func (e *Emulator) EnvKey(key string) *string {
+	v, ok := e.env()[key]
+	if !ok {
+		return nil
+	}
+	var s string
+	switch t := v.(type) {
+	case string:
+		s = t
+	case int, uint64:
+		s = fmt.Sprint(t)
+	default:
+		b, _ := json.Marshal(t)
+		s = string(b)
+	}
+	return &s
+}
+
+func (e *Emulator) GetBalance(address, asset string) string {
+	return strconv.FormatInt(e.Balance(address, asset), 10)
+}
+
+// Draw pulls funds from the frame's caller into the contract, up to the
+// caller's transfer.allow limit for the asset over the whole frame.
+func (e *Emulator) Draw(amount, asset string) {
+	f := e.frame()
+	amt := e.parseAmount(amount)
+	if f.drawn[asset]+amt > e.allowance(f, asset) {
+		e.Abort(fmt.Sprintf("draw of %s %s exceeds transfer.allow intent", amount, asset))
+	}
+	e.debit(f.caller, asset, amt)
+	e.credit(ContractAddress(f.contractID), asset, amt)
+	f.drawn[asset] += amt
+}
+
+func (e *Emulator) Transfer(to, amount, asset string) {
+	amt := e.parseAmount(amount)
+	e.debit(ContractAddress(e.frame().contractID), asset, amt)
+	e.credit(to, asset, amt)
+}
+
+func (e *Emulator) Withdraw(to, amount, asset string) {
+	if !strings.HasPrefix(to, "hive:") {
+		e.Abort("withdrawals go to hive accounts only")
+	}
+	if asset != string(sdk.AssetHive) && asset != string(sdk.AssetHbd) {
+		e.Abort("only hive and hbd can be withdrawn")
+	}
+	amt := e.parseAmount(amount)
+	from := ContractAddress(e.frame().contractID)
+	e.debit(from, asset, amt)
+	e.withdrawals = append(e.withdrawals, Withdrawal{From: from, To: to, Amount: amt, Asset: asset})
+}
+
+func (e *Emulator) StakeHbd(amount string) {
+	amt := e.parseAmount(amount)
+	acct := ContractAddress(e.frame().contractID)
+	e.debit(acct, string(sdk.AssetHbd), amt)
+	e.credit(acct, string(sdk.AssetHbdSavings), amt)
+}
+
+func (e *Emulator) UnstakeHbd(amount string) {
+	amt := e.parseAmount(amount)
+	acct := ContractAddress(e.frame().contractID)
+	e.debit(acct, string(sdk.AssetHbdSavings), amt)
+	e.unstakes = append(e.unstakes, pendingUnstake{account: acct, amount: amt, maturesAt: e.now.Add(HbdUnstakePeriod)})
+}
+
+// ContractRead returns nil for unknown contracts and "" for unset keys, as the
+// node does.
+func (e *Emulator) ContractRead(contractID, key string) *string {
+	if _, ok := e.contracts[contractID]; !ok {
+		return nil
+	}
+	v := e.state[contractID][key]
+	return &v
+}
+
+// ContractCall runs a nested frame. The callee sees this contract as msg.caller
+// and the original signer as msg.sender; option intents let it draw from us.
+func (e *Emulator) ContractCall(contractID, method, payload, options string) *string {
+	f := e.frame()
+	var opts sdk.ContractCallOptions
+	if options != "" {
+		if err := json.Unmarshal([]byte(options), &opts); err != nil {
+			e.Abort("invalid contract call options")
+		}
+	}
+	return e.invoke(contractID, method, payload, ContractAddress(f.contractID), f.sender, opts.Intents)
+}
+
+func (e *Emulator) TssCreateKey(keyID, algo string) string {
+	e.Abort("tss is not supported by the emulator")
+	return ""
+}
+
+func (e *Emulator) TssSignKey(keyID, msg string) {
+	e.Abort("tss is not supported by the emulator")
+}
+
+func (e *Emulator) TssGetKey(keyID string) string {
+	e.Abort("tss is not supported by the emulator")
+	return ""
+}
+
+func (e *Emulator) Abort(msg string) {
+	panic(failure{msg: msg})
+}
+
+func (e *Emulator) Revert(msg, symbol string) {
+	panic(failure{msg: msg, symbol: symbol})
+}
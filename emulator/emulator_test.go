@@ -0,0 +1,145 @@
+//go:build native
+
+package emulator
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	"okinoko_dao/sdk"
+)
+
+// vault keeps what it is allowed to draw and records the depositor; "fail"
+// does the same and then aborts.
+func vault() map[string]Method {
+	deposit := func(payload *string) *string {
+		env := sdk.GetEnv()
+		sdk.HiveDraw(1000, sdk.AssetHive)
+		sdk.StateSetObject("last", env.Sender.Address.String())
+		return payload
+	}
+	return map[string]Method{
+		"deposit": deposit,
+		"fail": func(payload *string) *string {
+			deposit(payload)
+			sdk.Abort("boom")
+			return nil
+		},
+		"forward": func(payload *string) *string {
+			opts := &sdk.ContractCallOptions{Intents: []sdk.Intent{TransferAllow("1.000", "hive")}}
+			return sdk.ContractCall("inner", "deposit", *payload, opts)
+		},
+	}
+}
+
+func newVaults(t *testing.T) *Emulator {
+	t.Helper()
+	e := New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Register("outer", "hive:owner", vault())
+	e.Register("inner", "hive:owner", vault())
+	e.Deposit("hive:alice", "hive", 5000)
+	return e
+}
+
+func TestDrawNeedsIntent(t *testing.T) {
+	e := newVaults(t)
+	res := e.Call(Call{Caller: "hive:alice", ContractID: "outer", Action: "deposit", Payload: `"x"`})
+	if res.Success {
+		t.Fatal("draw without intent succeeded")
+	}
+	res = e.Call(Call{Caller: "hive:alice", ContractID: "outer", Action: "deposit", Payload: `"x"`,
+		Intents: []sdk.Intent{TransferAllow("1", "hive")}})
+	if !res.Success || res.Ret != `"x"` {
+		t.Fatalf("deposit failed: %+v", res)
+	}
+	if got := e.Balance(ContractAddress("outer"), "hive"); got != 1000 {
+		t.Fatalf("outer holds %d, want 1000", got)
+	}
+	if v, _ := e.State("outer", "last"); v != "hive:alice" {
+		t.Fatalf("sender %q, want hive:alice", v)
+	}
+}
+
+func TestAbortRollsBackTransaction(t *testing.T) {
+	e := newVaults(t)
+	res := e.Call(Call{Caller: "hive:alice", ContractID: "outer", Action: "fail",
+		Intents: []sdk.Intent{TransferAllow("1.000", "hive")}})
+	if res.Success || res.Err != "boom" {
+		t.Fatalf("want abort boom, got %+v", res)
+	}
+	if got := e.Balance("hive:alice", "hive"); got != 5000 {
+		t.Fatalf("alice has %d after rollback, want 5000", got)
+	}
+	if _, ok := e.State("outer", "last"); ok {
+		t.Fatal("state write survived the abort")
+	}
+}
+
+func TestNestedCallDrawsFromCallingContract(t *testing.T) {
+	e := newVaults(t)
+	e.Deposit(ContractAddress("outer"), "hive", 1000)
+	res := e.Call(Call{Caller: "hive:alice", ContractID: "outer", Action: "forward", Payload: `"y"`})
+	if !res.Success {
+		t.Fatalf("forward failed: %s", res.Err)
+	}
+	if got := e.Balance(ContractAddress("inner"), "hive"); got != 1000 {
+		t.Fatalf("inner holds %d, want 1000", got)
+	}
+	// The signer stays msg.sender across frames.
+	if v, _ := e.State("inner", "last"); v != "hive:alice" {
+		t.Fatalf("inner saw sender %q", v)
+	}
+}
+
+func TestHbdUnstakeMatures(t *testing.T) {
+	e := New(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Register("c", "hive:owner", map[string]Method{
+		"save": func(p *string) *string { sdk.HiveStakeHbd(500); return nil },
+		"free": func(p *string) *string { sdk.HiveUnstakeHbd(500); return nil },
+	})
+	acct := ContractAddress("c")
+	e.Deposit(acct, "hbd", 500)
+	for _, action := range []string{"save", "free"} {
+		if res := e.Call(Call{ContractID: "c", Action: action}); !res.Success {
+			t.Fatalf("%s failed: %s", action, res.Err)
+		}
+	}
+	e.Advance(HbdUnstakePeriod - time.Second)
+	if got := e.Balance(acct, "hbd"); got != 0 {
+		t.Fatalf("unstake matured early: %s", strconv.FormatInt(got, 10))
+	}
+	e.Advance(time.Second)
+	if got := e.Balance(acct, "hbd"); got != 500 {
+		t.Fatalf("hbd %d after maturity, want 500", got)
+	}
+}
+
+func TestSaveLoadKeepsChain(t *testing.T) {
+	e := newVaults(t)
+	e.Register("bin", "hive:owner", map[string]Method{
+		"set": func(p *string) *string { sdk.StateSetObject("\x01k\xff", "\x00\x80"); return nil },
+	})
+	e.Call(Call{Caller: "hive:alice", ContractID: "outer", Action: "deposit", Payload: `"x"`,
+		Intents: []sdk.Intent{TransferAllow("1", "hive")}})
+	e.Call(Call{ContractID: "bin", Action: "set"})
+	var buf bytes.Buffer
+	if err := e.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded := New(time.Time{})
+	loaded.Register("outer", "hive:owner", vault())
+	if err := loaded.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Balance("hive:alice", "hive"); got != 4000 {
+		t.Fatalf("alice holds %d after load, want 4000", got)
+	}
+	if v, _ := loaded.State("bin", "\x01k\xff"); v != "\x00\x80" {
+		t.Fatalf("binary state %q after load", v)
+	}
+	if !loaded.Now().Equal(e.Now()) {
+		t.Fatalf("clock %v after load, want %v", loaded.Now(), e.Now())
+	}
+}
@@ -0,0 +1,77 @@
+//go:build native
+
+package emulator
+
+import (
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// saved is the persisted form of an Emulator. Contract state holds raw binary
+// keys and values, so it is written with gob rather than JSON.
+type saved struct {
+	State       map[string]map[string]string
+	Balances    map[string]map[string]int64
+	Unstakes    []savedUnstake
+	Withdrawals []Withdrawal
+	Now         time.Time
+	Height      uint64
+	TxSeq       uint64
+}
+
+type savedUnstake struct {
+	Account   string
+	Amount    int64
+	MaturesAt time.Time
+}
+
+// Save writes the chain (state, balances, pending unstakes, withdrawals and
+// clock) to w. Registered contracts are code and are not saved.
+func (e *Emulator) Save(w io.Writer) error {
+	s := saved{
+		State:       e.state,
+		Balances:    e.balances,
+		Withdrawals: e.withdrawals,
+		Now:         e.now,
+		Height:      e.height,
+		TxSeq:       txSeq,
+	}
+	for _, u := range e.unstakes {
+		s.Unstakes = append(s.Unstakes, savedUnstake{u.account, u.amount, u.maturesAt})
+	}
+	return gob.NewEncoder(w).Encode(&s)
+}
+
+// Load replaces the chain with one written by Save. Contracts registered on e
+// keep their methods and pick up their saved state.
+func (e *Emulator) Load(r io.Reader) error {
+	var s saved
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return err
+	}
+	e.state = s.State
+	if e.state == nil {
+		e.state = map[string]map[string]string{}
+	}
+	for id := range e.contracts {
+		if e.state[id] == nil {
+			e.state[id] = map[string]string{}
+		}
+	}
+	e.balances = s.Balances
+	if e.balances == nil {
+		e.balances = map[string]map[string]int64{}
+	}
+	e.unstakes = nil
+	for _, u := range s.Unstakes {
+		e.unstakes = append(e.unstakes, pendingUnstake{u.Account, u.Amount, u.MaturesAt})
+	}
+	e.withdrawals = s.Withdrawals
+	e.now, e.height = s.Now.UTC(), s.Height
+	// Transaction ids must stay unique across sessions.
+	if s.TxSeq > txSeq {
+		txSeq = s.TxSeq
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package errcode is the catalogue of error codes the DAO contract reverts
+// with. A failed call carries one of these as its revert symbol and the human
+// readable reason as its message. Codes are stable across releases; messages
+// are not, so callers should branch on the code and only show the message.
+package errcode
+
+// Code identifies a class of failure.
+type Code string
+
+const (
+	// InvalidPayload: the payload is malformed, a field is missing or unknown,
+	// or an entry does not follow its grammar.
+	InvalidPayload Code = "E_INVALID_PAYLOAD"
+	// InvalidValue: a well-formed value is out of range or exceeds a limit.
+	InvalidValue Code = "E_INVALID_VALUE"
+	// UnknownMeta: a proposal names a meta action the contract does not know.
+	UnknownMeta Code = "E_UNKNOWN_META"
+	// MetaConflict: a proposal combines outcomes that cannot run together.
+	MetaConflict Code = "E_META_CONFLICT"
+
+	// NotInitialized: contract_init has not been called yet.
+	NotInitialized Code = "E_NOT_INITIALIZED"
+	// AlreadyInitialized: contract_init was called twice.
+	AlreadyInitialized Code = "E_ALREADY_INITIALIZED"
+
+	// Unauthorized: the caller is not the owner, creator or contract owner the
+	// action requires.
+	Unauthorized Code = "E_UNAUTHORIZED"
+	// Autonomous: the action needs a project owner and the project has none.
+	Autonomous Code = "E_AUTONOMOUS"
+	// NotMember: the address is not a member of the project.
+	NotMember Code = "E_NOT_MEMBER"
+	// AlreadyMember: the caller is already a member of the project.
+	AlreadyMember Code = "E_ALREADY_MEMBER"
+	// NotWhitelisted: the project only admits whitelisted addresses.
+	NotWhitelisted Code = "E_NOT_WHITELISTED"
+	// NFTRequired: the caller does not hold the membership NFT.
+	NFTRequired Code = "E_NFT_REQUIRED"
+
+	// NotFound: a project, proposal or contract does not exist.
+	NotFound Code = "E_NOT_FOUND"
+	// Paused: the project is paused.
+	Paused Code = "E_PAUSED"
+	// Dissolved: the project has been dissolved or exported.
+	Dissolved Code = "E_DISSOLVED"
+
+	// Cooldown: the leave cooldown has not passed yet.
+	Cooldown Code = "E_COOLDOWN"
+	// Locked: funds cannot move yet because a running vote, a pending payout or
+	// a savings withdrawal still holds them.
+	Locked Code = "E_LOCKED"
+	// TooEarly: voting is still running, the execution delay has not passed or
+	// a prerequisite proposal has not executed yet.
+	TooEarly Code = "E_TOO_EARLY"
+	// ProposalState: the proposal is not in a state that allows the action.
+	ProposalState Code = "E_PROPOSAL_STATE"
+	// NotEligible: the caller may not create, vote on or claim reputation from
+	// this proposal.
+	NotEligible Code = "E_NOT_ELIGIBLE"
+
+	// NoIntent: the call needs a transfer.allow intent and has none.
+	NoIntent Code = "E_NO_INTENT"
+	// InvalidIntent: a transfer.allow intent names an unknown asset or a bad
+	// limit.
+	InvalidIntent Code = "E_INVALID_INTENT"
+	// WrongAsset: the intent's asset is not the one the project takes.
+	WrongAsset Code = "E_WRONG_ASSET"
+	// StakeAmount: the stake does not meet the project's stake rules.
+	StakeAmount Code = "E_STAKE_AMOUNT"
+	// InsufficientFunds: the treasury, stake or intent does not cover the
+	// amount.
+	InsufficientFunds Code = "E_INSUFFICIENT_FUNDS"
+
+	// ICCResult: an inter-contract call's response did not satisfy the result
+	// the proposal declared for it.
+	ICCResult Code = "E_ICC_RESULT"
+	// ConditionUnmet: another contract's state does not satisfy the condition
+	// the proposal's execution waits for.
+	ConditionUnmet Code = "E_CONDITION_UNMET"
+
+	// Internal: stored state could not be decoded or an invariant broke.
+	Internal Code = "E_INTERNAL"
+)
+
+// All lists every code, in catalogue order.
+var All = []Code{
+	InvalidPayload, InvalidValue, UnknownMeta, MetaConflict,
+	NotInitialized, AlreadyInitialized,
+	Unauthorized, Autonomous, NotMember, AlreadyMember, NotWhitelisted, NFTRequired,
+	NotFound, Paused, Dissolved,
+	Cooldown, Locked, TooEarly, ProposalState, NotEligible,
+	NoIntent, InvalidIntent, WrongAsset, StakeAmount, InsufficientFunds,
+	ICCResult, ConditionUnmet, Internal,
+}
+
+// Known reports whether s is a code of this catalogue.
+func Known(s string) bool {
+	for _, c := range All {
+		if string(c) == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package indexer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/client"
+)
+
+// Mismatch is one fact on which the indexed view and the state dump disagree.
+// An empty side means the fact is missing there.
+type Mismatch struct {
+	Path    string
+	Indexed string
+	State   string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: indexed %q, state %q", m.Path, m.Indexed, m.State)
+}
+
+// Check compares an indexed State against one decoded with FromState and
+// returns every disagreement, sorted by path.
+//
+// Compared are the facts both sides can know: owner, flags and numeric config
+// of each project, stake weights and totals, treasury balances, pending HBD
+// withdrawals, whitelist, ICC allowlist, members with their stakes and
+// reputation, and each proposal's creator, state, payouts, voter count, option
+// tallies and ballots.
+// Free text is left out because legacy lines sanitize it, and config floats are
+// compared to four decimals because config events print them with %f.
+//
+// A few state changes are not logged by the contract, so a stream that
+// includes them will legitimately disagree with the dump: ownership transfers
+// through project_transfer, emergency pauses through project_pause, and assets
+// a passed proposal sends along with its inter-contract calls.
+func Check(indexed, state *State) []Mismatch {
+	a, b := indexed.facts(), state.facts()
+	var out []Mismatch
+	for path, v := range a {
+		if w := b[path]; w != v {
+			out = append(out, Mismatch{path, v, w})
+		}
+	}
+	for path, w := range b {
+		if _, ok := a[path]; !ok {
+			out = append(out, Mismatch{path, "", w})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// facts flattens the comparable part of a State into path/value pairs. Zero
+// balances are dropped, since the contract may keep or delete a drained key.
+func (s *State) facts() map[string]string {
+	f := map[string]string{}
+	for id, p := range s.Projects {
+		base := fmt.Sprintf("project/%d/", id)
+		f[base+"owner"] = p.Owner
+		f[base+"paused"] = strconv.FormatBool(p.Paused)
+		f[base+"dissolved"] = strconv.FormatBool(p.Dissolved)
+		f[base+"fundsAsset"] = p.FundsAsset
+		f[base+"votingSystem"] = strconv.Itoa(int(p.VotingSystem))
+		f[base+"threshold"] = fixed(p.Threshold)
+		f[base+"quorum"] = fixed(p.Quorum)
+		f[base+"proposalDuration"] = strconv.FormatUint(p.ProposalDuration, 10)
+		f[base+"executionDelay"] = strconv.FormatUint(p.ExecutionDelay, 10)
+		f[base+"leaveCooldown"] = strconv.FormatUint(p.LeaveCooldown, 10)
+		f[base+"proposalCost"] = p.ProposalCost.String()
+		f[base+"stakeMin"] = p.StakeMin.String()
+		f[base+"membersOnly"] = strconv.FormatBool(p.MembersOnly)
+		f[base+"whitelistOnly"] = strconv.FormatBool(p.WhitelistOnly)
+		f[base+"iccThreshold"] = fixed(p.ICCThreshold)
+		for asset, w := range p.StakeWeights {
+			f[base+"stakeWeight/"+asset] = fixed(w)
+		}
+		amounts(f, base+"stakeTotal/", p.StakeTotals)
+		amounts(f, base+"treasury/", p.Treasury)
+		if p.HbdUnstaking != 0 {
+			f[base+"hbdUnstaking"] = p.HbdUnstaking.String()
+		}
+		for addr, ok := range p.Whitelist {
+			if ok {
+				f[base+"whitelist/"+addr] = "true"
+			}
+		}
+		for target, ok := range p.ICCAllowlist {
+			if ok {
+				f[base+"iccAllowlist/"+target] = "true"
+			}
+		}
+		f[base+"memberCount"] = strconv.Itoa(len(p.Members))
+		for addr, m := range p.Members {
+			mb := base + "member/" + addr + "/"
+			f[mb+"reputation"] = strconv.FormatInt(m.Reputation, 10)
+			amounts(f, mb+"stake/", m.Stakes)
+		}
+	}
+	for id, p := range s.Proposals {
+		base := fmt.Sprintf("proposal/%d/", id)
+		f[base+"project"] = strconv.FormatUint(p.ProjectID, 10)
+		f[base+"creator"] = p.Creator
+		f[base+"state"] = p.State
+		f[base+"isPoll"] = strconv.FormatBool(p.IsPoll)
+		f[base+"duration"] = strconv.FormatUint(p.Duration, 10)
+		f[base+"voterCount"] = strconv.FormatUint(p.VoterCount, 10)
+		f[base+"optionCount"] = strconv.Itoa(len(p.Options))
+		for i, o := range p.Options {
+			ob := fmt.Sprintf("%soption/%d/", base, i)
+			f[ob+"weight"] = o.Weight.String()
+			f[ob+"voters"] = strconv.FormatUint(o.Voters, 10)
+		}
+		for i, po := range p.Payouts {
+			f[fmt.Sprintf("%spayout/%d", base, i)] = fmt.Sprintf("%s %s %s", po.To, po.Amount, po.Asset)
+		}
+		for addr, b := range p.Ballots {
+			f[base+"ballot/"+addr] = joinChoices(b.Choices) + " " + b.Weight.String()
+		}
+	}
+	return f
+}
+
+func amounts(f map[string]string, prefix string, m map[string]client.Amount) {
+	for asset, v := range m {
+		if v != 0 {
+			f[prefix+asset] = v.String()
+		}
+	}
+}
+
+func fixed(v float64) string {
+	return strconv.FormatFloat(v, 'f', 4, 64)
+}
+
+func joinChoices(choices []uint) string {
+	parts := make([]string, len(choices))
+	for i, c := range choices {
+		parts[i] = strconv.FormatUint(uint64(c), 10)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,454 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/client"
+)
+
+// Key prefixes of the contract's state records (contract/constants.go).
+const (
+	kProjectMeta      byte = 0x01
+	kProjectConfig    byte = 0x02
+	kProjectFinance   byte = 0x03
+	kProjectMember    byte = 0x04
+	kProjectWhitelist byte = 0x06
+	kProjectTreasury  byte = 0x07
+	kHbdUnstake       byte = 0x0b
+	kICCAllowlist     byte = 0x0c
+	kProposalMeta     byte = 0x10
+	kProposalOption   byte = 0x11
+	kVoteReceipt      byte = 0x20
+)
+
+// recordMagic opens a versioned state record: magic, one version byte, body.
+// Records without it predate versioning and are version 0 (contract/schema.go).
+const recordMagic = "\xff\x00"
+
+// recordVersions is the latest version of each versioned record type. Newer
+// versions only append fields, which the decoders below read when present.
+var recordVersions = map[byte]int{
+	kProjectMeta: 1, kProjectConfig: 1, kProjectFinance: 1, kProjectMember: 1,
+	kProjectTreasury: 1, kProposalMeta: 2,
+}
+
+// proposalStates names the contract's ProposalState values as events spell them.
+var proposalStates = map[byte]string{
+	1: "active", 2: "closed", 3: "passed", 4: "executed", 5: "failed", 6: "cancelled",
+}
+
+// FromState decodes a dump of the contract's state (raw key to raw value) into
+// a State, for comparison with an indexed one. Keys the indexer does not model
+// (counters, rosters, stake history, dividends, payout locks) are skipped.
+func FromState(dump map[string]string) (*State, error) {
+	s := NewState()
+	prj := func(id uint64) *Project {
+		p := s.Projects[id]
+		if p == nil {
+			p = &Project{
+				ID:           id,
+				StakeTotals:  map[string]client.Amount{},
+				Treasury:     map[string]client.Amount{},
+				Members:      map[string]*Member{},
+				Whitelist:    map[string]bool{},
+				ICCAllowlist: map[string]bool{},
+			}
+			s.Projects[id] = p
+		}
+		return p
+	}
+	prop := func(id uint64) *Proposal {
+		p := s.Proposals[id]
+		if p == nil {
+			p = &Proposal{ID: id, Ballots: map[string]Ballot{}}
+			s.Proposals[id] = p
+		}
+		return p
+	}
+	type optionRecord struct {
+		proposal uint64
+		idx      uint32
+		opt      Option
+	}
+	var options []optionRecord
+
+	for key, val := range dump {
+		if len(key) < 9 {
+			continue
+		}
+		id := binary.LittleEndian.Uint64([]byte(key[1:9]))
+		rest := key[9:]
+		var version int
+		var err error
+		if latest, ok := recordVersions[key[0]]; ok {
+			if version, val, err = recordBody(val, latest); err != nil {
+				return nil, fmt.Errorf("state key %q: %w", key, err)
+			}
+		}
+		switch key[0] {
+		case kProjectMeta:
+			err = decodeMeta(prj(id), val)
+		case kProjectConfig:
+			err = decodeConfig(prj(id), val)
+		case kProjectFinance:
+			err = decodeFinance(prj(id), val)
+		case kProjectMember:
+			var m *Member
+			if m, err = decodeMember(val); err == nil {
+				prj(id).Members[rest] = m
+			}
+		case kProjectWhitelist:
+			if val != "" {
+				prj(id).Whitelist[rest] = true
+			}
+		case kProjectTreasury:
+			var v int64
+			if v, err = decodeTreasury(version, val); err == nil {
+				prj(id).Treasury[rest] = client.Amount(v)
+			}
+		case kICCAllowlist:
+			if val != "" {
+				prj(id).ICCAllowlist[rest] = true
+			}
+		case kHbdUnstake:
+			err = decodeHbdUnstakes(prj(id), val)
+		case kProposalMeta:
+			err = decodeProposal(prop(id), val)
+		case kProposalOption:
+			if len(rest) != 4 {
+				continue
+			}
+			var opt Option
+			if opt, err = decodeOption(val); err == nil {
+				options = append(options, optionRecord{id, binary.LittleEndian.Uint32([]byte(rest)), opt})
+			}
+		case kVoteReceipt:
+			var b Ballot
+			if b, err = decodeBallot(val); err == nil {
+				prop(id).Ballots[rest] = b
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("state key %q: %w", key, err)
+		}
+	}
+	for _, o := range options {
+		p := prop(o.proposal)
+		for uint32(len(p.Options)) <= o.idx {
+			p.Options = append(p.Options, Option{})
+		}
+		p.Options[o.idx] = o.opt
+	}
+	// A member record keeps its funds-asset stake apart from other assets.
+	for _, p := range s.Projects {
+		for _, m := range p.Members {
+			if stake, ok := m.Stakes[""]; ok {
+				delete(m.Stakes, "")
+				m.Stakes[p.FundsAsset] = stake
+			}
+		}
+	}
+	return s, nil
+}
+
+// recordBody strips the version header from a versioned record.
+func recordBody(val string, latest int) (int, string, error) {
+	if !strings.HasPrefix(val, recordMagic) {
+		return 0, val, nil
+	}
+	if len(val) == len(recordMagic) {
+		return 0, "", errors.New("truncated record header")
+	}
+	version := int(val[len(recordMagic)])
+	if version < 1 || version > latest {
+		return 0, "", fmt.Errorf("unsupported record version %d", version)
+	}
+	return version, val[len(recordMagic)+1:], nil
+}
+
+// decodeTreasury reads a balance: a decimal string before versioning, a
+// big-endian int64 since.
+func decodeTreasury(version int, val string) (int64, error) {
+	if version == 0 {
+		return strconv.ParseInt(val, 10, 64)
+	}
+	r := &reader{data: []byte(val)}
+	v := r.i64()
+	if r.err == nil && r.more() {
+		return 0, errors.New("trailing bytes after treasury balance")
+	}
+	return v, r.err
+}
+
+func decodeMeta(prj *Project, val string) error {
+	r := &reader{data: []byte(val)}
+	prj.Owner = r.str()
+	prj.Name = r.str()
+	prj.Description = r.str()
+	prj.Paused = r.bool()
+	r.str() // tx
+	prj.Metadata = r.str()
+	if r.more() {
+		prj.URL = r.str()
+	}
+	if r.more() {
+		prj.Dissolved = r.bool()
+	}
+	return r.err
+}
+
+func decodeConfig(prj *Project, val string) error {
+	r := &reader{data: []byte(val)}
+	prj.VotingSystem = client.VotingSystem(r.byte())
+	prj.Threshold = r.f64()
+	prj.Quorum = r.f64()
+	prj.ProposalDuration = r.u64()
+	prj.ExecutionDelay = r.u64()
+	prj.LeaveCooldown = r.u64()
+	prj.ProposalCost = floatAmount(r.f64())
+	prj.StakeMin = floatAmount(r.f64())
+	r.optStr() // membership NFT contract
+	r.optStr() // membership NFT function
+	r.optStr() // membership NFT
+	r.str()    // membership payload format
+	prj.MembersOnly = r.bool()
+	if r.more() {
+		prj.WhitelistOnly = r.bool()
+	}
+	if r.more() {
+		prj.ICCThreshold = r.f64()
+	}
+	return r.err
+}
+
+func decodeFinance(prj *Project, val string) error {
+	r := &reader{data: []byte(val)}
+	prj.FundsAsset = r.str()
+	if total := client.Amount(r.i64()); total != 0 {
+		prj.StakeTotals[prj.FundsAsset] = total
+	}
+	r.u64() // member count, implied by the member records
+	if r.more() {
+		for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+			asset := r.str()
+			if prj.StakeWeights == nil {
+				prj.StakeWeights = map[string]float64{}
+			}
+			prj.StakeWeights[asset] = r.f64()
+		}
+	}
+	if r.more() {
+		for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+			asset := r.str()
+			prj.StakeTotals[asset] = client.Amount(r.i64())
+		}
+	}
+	return r.err
+}
+
+func decodeMember(val string) (*Member, error) {
+	r := &reader{data: []byte(val)}
+	m := newMember(r.str())
+	if stake := client.Amount(r.i64()); stake != 0 {
+		// Keyed by the funds asset once the project is known; see normalize.
+		m.Stakes[""] = stake
+	}
+	r.i64() // joinedAt
+	r.i64() // lastAction
+	r.i64() // exitRequested
+	m.Reputation = r.i64()
+	if r.more() {
+		r.u64() // stake increment
+	}
+	r.u64() // join seq
+	r.i64() // vote lock
+	for i := 0; i < 3 && r.more(); i++ {
+		r.i64() // unstakeRequested, unstakePending, reputationAt
+	}
+	if r.more() {
+		for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+			asset := r.str()
+			m.Stakes[asset] = client.Amount(r.i64())
+		}
+	}
+	return m, r.err
+}
+
+func decodeHbdUnstakes(prj *Project, val string) error {
+	for _, entry := range strings.Split(val, ";") {
+		amt, _, _ := strings.Cut(entry, "_")
+		v, err := strconv.ParseInt(amt, 10, 64)
+		if err != nil {
+			return err
+		}
+		prj.HbdUnstaking += client.Amount(v)
+	}
+	return nil
+}
+
+func decodeProposal(p *Proposal, val string) error {
+	r := &reader{data: []byte(val)}
+	r.u64() // id
+	p.ProjectID = r.u64()
+	p.Creator = r.str()
+	p.Name = r.str()
+	p.Description = r.str()
+	r.u64() // option count, implied by the option records
+	p.Duration = r.u64()
+	r.i64() // createdAt
+	p.State = proposalStates[r.byte()]
+	if r.bool() {
+		p.Meta = map[string]string{}
+		for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+			k := r.str()
+			p.Meta[k] = r.str()
+		}
+		for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+			to := r.str()
+			amt := client.Amount(r.i64())
+			p.Payouts = append(p.Payouts, client.EventPayout{To: to, Amount: amt, Asset: r.str()})
+		}
+		if r.more() {
+			for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+				r.str() // contract
+				r.str() // function
+				r.str() // payload
+				for a := r.uvarint(); a > 0 && r.err == nil; a-- {
+					r.str()
+					r.i64()
+				}
+			}
+		}
+	}
+	r.str() // tx
+	r.i64() // stake snapshot
+	r.uvarint()
+	p.Metadata = r.str()
+	p.IsPoll = r.bool()
+	r.varint() // result option
+	p.ReadyAt = r.i64()
+	if r.more() {
+		p.URL = r.str()
+	}
+	if r.more() {
+		p.VoterCount = r.uvarint()
+	}
+	return r.err
+}
+
+func decodeOption(val string) (Option, error) {
+	r := &reader{data: []byte(val)}
+	opt := Option{Text: r.str(), URL: r.str(), Weight: client.Amount(r.i64()), Voters: r.u64()}
+	return opt, r.err
+}
+
+func decodeBallot(val string) (Ballot, error) {
+	r := &reader{data: []byte(val)}
+	var b Ballot
+	for n := r.uvarint(); n > 0 && r.err == nil; n-- {
+		b.Choices = append(b.Choices, uint(r.uvarint()))
+	}
+	b.Weight = floatAmount(r.f64())
+	return b, r.err
+}
+
+func floatAmount(v float64) client.Amount {
+	return client.Amount(math.Round(v * 1000))
+}
+
+// reader mirrors the contract's binReader: big-endian fixed-width numbers and
+// varuint-prefixed strings. The first error sticks and zero values follow.
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+var errShort = errors.New("unexpected end of record")
+
+func (r *reader) more() bool { return r.err == nil && r.pos < len(r.data) }
+
+func (r *reader) take(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = errShort
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) byte() byte {
+	if b := r.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) bool() bool { return r.byte() == 1 }
+
+func (r *reader) u64() uint64 {
+	if b := r.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) i64() int64 { return int64(r.u64()) }
+
+func (r *reader) f64() float64 { return math.Float64frombits(r.u64()) }
+
+func (r *reader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = errShort
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *reader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data[r.pos:])
+	if n <= 0 {
+		r.err = errShort
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *reader) str() string {
+	n := r.uvarint()
+	if n > uint64(len(r.data)-r.pos) {
+		if r.err == nil {
+			r.err = errShort
+		}
+		return ""
+	}
+	return string(bytes.Clone(r.take(int(n))))
+}
+
+func (r *reader) optStr() string {
+	if r.bool() {
+		return r.str()
+	}
+	return ""
+}
@@ -0,0 +1,531 @@
+// Package indexer is a reference event-sourced indexer for the DAO contract.
+// It replays the contract's log stream (legacy lines, JSON events or both) and
+// rebuilds projects, members, treasuries, proposals and tallies in memory.
+// SaveSQL persists a snapshot through database/sql, and Check compares the
+// indexer's view against a dump of the contract's state.
+package indexer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"okinoko_dao/client"
+)
+
+// State is the DAO as reconstructed from events (or decoded from a state dump).
+type State struct {
+	Projects  map[uint64]*Project
+	Proposals map[uint64]*Proposal
+}
+
+// Project is one DAO with its configuration, treasury and members.
+type Project struct {
+	ID          uint64
+	Owner       string
+	Name        string
+	Description string
+	Metadata    string
+	URL         string
+	FundsAsset  string
+
+	VotingSystem     client.VotingSystem
+	Threshold        float64
+	Quorum           float64
+	ProposalDuration uint64
+	ExecutionDelay   uint64
+	LeaveCooldown    uint64
+	ProposalCost     client.Amount
+	StakeMin         client.Amount
+	MembersOnly      bool
+	WhitelistOnly    bool
+	ICCThreshold     float64
+	StakeWeights     map[string]float64
+
+	Paused    bool
+	Dissolved bool
+
+	// StakeTotals is the staked amount per asset, funds asset included.
+	StakeTotals map[string]client.Amount
+	// Treasury is the spendable balance per asset (hbd_savings included).
+	Treasury map[string]client.Amount
+	// HbdUnstaking is HBD on its way out of savings, not yet spendable.
+	HbdUnstaking client.Amount
+	Members      map[string]*Member
+	Whitelist    map[string]bool
+	// ICCAllowlist holds the contract:function pairs proposals may call
+	// without the elevated ICC threshold.
+	ICCAllowlist map[string]bool
+}
+
+// Member is one project member.
+type Member struct {
+	Address    string
+	Stakes     map[string]client.Amount
+	Reputation int64
+}
+
+// Option is one ballot option with its running tally.
+type Option struct {
+	Text   string
+	URL    string
+	Weight client.Amount
+	Voters uint64
+}
+
+// Ballot is a member's current vote on a proposal.
+type Ballot struct {
+	Choices []uint
+	Weight  client.Amount
+}
+
+// Proposal is one proposal with its tally.
+type Proposal struct {
+	ID          uint64
+	ProjectID   uint64
+	Creator     string
+	Name        string
+	Description string
+	Metadata    string
+	URL         string
+	Duration    uint64
+	IsPoll      bool
+	Payouts     []client.EventPayout
+	Meta        map[string]string
+
+	State   string
+	ReadyAt int64
+	// Deadline is the proposal's not_after, 0 when it has none. Known from
+	// the proposal.ready event only.
+	Deadline   int64
+	Result     string
+	Options    []Option
+	VoterCount uint64
+	// Ballots holds each voter's latest ballot. Only the event stream knows
+	// ballots; a decoded state dump leaves it empty.
+	Ballots map[string]Ballot
+}
+
+// NewState returns an empty State.
+func NewState() *State {
+	return &State{Projects: map[uint64]*Project{}, Proposals: map[uint64]*Proposal{}}
+}
+
+// Indexer applies events to a State.
+type Indexer struct {
+	State *State
+	// Owner and Mode are taken from the contract.init event and follow
+	// contract.config changes, as do PendingOwner and the creation fee.
+	Owner            string
+	Mode             string
+	PendingOwner     string
+	CreationFee      client.Amount
+	CreationFeeAsset string
+	// jsonOnly is set once the contract announced JSON events, so the legacy
+	// twin of each event is skipped in "both" mode.
+	jsonOnly bool
+}
+
+// New returns an Indexer with an empty State. Feed it the contract's log
+// lines from contract_init on, in order.
+func New() *Indexer {
+	return &Indexer{State: NewState()}
+}
+
+// ApplyLine parses and applies one log line. Lines that are not DAO events are
+// ignored. With the "both" event format each event is logged twice; once the
+// JSON contract.init event has been seen, legacy lines are skipped.
+func (ix *Indexer) ApplyLine(line string) error {
+	line = strings.TrimSpace(line)
+	isJSON := strings.HasPrefix(line, "{")
+	if ix.jsonOnly && !isJSON {
+		return nil
+	}
+	ev, err := client.ParseEvent(line)
+	if errors.Is(err, client.ErrUnknownEvent) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if init, ok := ev.(*client.ContractInitEvent); ok && isJSON && init.Events != "legacy" {
+		ix.jsonOnly = true
+	}
+	return ix.Apply(ev)
+}
+
+// ApplyLines applies a transaction's log lines in order.
+func (ix *Indexer) ApplyLines(lines []string) error {
+	for _, line := range lines {
+		if err := ix.ApplyLine(line); err != nil {
+			return fmt.Errorf("%q: %w", line, err)
+		}
+	}
+	return nil
+}
+
+// Apply applies one parsed event.
+func (ix *Indexer) Apply(ev client.Event) error {
+	s := ix.State
+	switch e := ev.(type) {
+	case *client.ContractInitEvent:
+		ix.Owner, ix.Mode = e.Owner, e.Mode
+	case *client.ContractConfigEvent:
+		return ix.applyContractConfig(e.Field, e.New)
+	case *client.ProjectCreatedEvent:
+		prj := &Project{
+			ID: e.ProjectID, Owner: e.By, Name: e.Name, Description: e.Description,
+			Metadata: e.Metadata, URL: e.URL, FundsAsset: e.Asset,
+			VotingSystem: e.VotingSystem, Threshold: e.Threshold, Quorum: e.Quorum,
+			ProposalDuration: e.ProposalDuration, ExecutionDelay: e.ExecutionDelay,
+			LeaveCooldown: e.LeaveCooldown, ProposalCost: e.ProposalCost, StakeMin: e.StakeMin,
+			MembersOnly: e.MembersOnly, WhitelistOnly: e.WhitelistOnly,
+			StakeWeights: e.StakeAssets,
+			StakeTotals:  map[string]client.Amount{},
+			Treasury:     map[string]client.Amount{},
+			Members:      map[string]*Member{},
+			Whitelist:    map[string]bool{},
+			ICCAllowlist: map[string]bool{},
+		}
+		// The creator is the founding member; their stake follows as funds.added.
+		prj.Members[e.By] = newMember(e.By)
+		s.Projects[prj.ID] = prj
+	case *client.MemberJoinedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.Members[e.By] = newMember(e.By)
+		if prj.WhitelistOnly {
+			delete(prj.Whitelist, e.By)
+		}
+	case *client.MemberLeftEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		if m := prj.Members[e.By]; m != nil {
+			for asset, amt := range m.Stakes {
+				prj.StakeTotals[asset] -= amt
+			}
+			delete(prj.Members, e.By)
+		}
+	case *client.FundsAddedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		if !e.ToStake {
+			prj.Treasury[e.Asset] += e.Amount
+			break
+		}
+		m := prj.Members[e.By]
+		if m == nil {
+			return fmt.Errorf("project %d: stake from non-member %s", e.ProjectID, e.By)
+		}
+		m.Stakes[e.Asset] += e.Amount
+		prj.StakeTotals[e.Asset] += e.Amount
+	case *client.FundsRemovedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		if !e.FromStake {
+			prj.Treasury[e.Asset] -= e.Amount
+			break
+		}
+		// A leave logs member.left before the refund, and already took the
+		// member's stake off the totals.
+		if m := prj.Members[e.To]; m != nil {
+			m.Stakes[e.Asset] -= e.Amount
+			prj.StakeTotals[e.Asset] -= e.Amount
+		}
+	case *client.ReputationChangedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		if m := prj.Members[e.By]; m != nil {
+			m.Reputation = e.Total
+		}
+	case *client.WhitelistChangedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, addr := range e.Addresses {
+			if e.Action == "add" {
+				prj.Whitelist[addr] = true
+			} else {
+				delete(prj.Whitelist, addr)
+			}
+		}
+	case *client.ICCAllowlistEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		for _, target := range e.Targets {
+			if e.Action == "add" {
+				prj.ICCAllowlist[target] = true
+			} else {
+				delete(prj.ICCAllowlist, target)
+			}
+		}
+	case *client.ProjectDissolvedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		// Members leave one by one afterwards, each logging their refund and
+		// treasury share.
+		prj.Paused, prj.Dissolved = true, true
+	case *client.ProjectExportedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		// Everything moved to the target deployment with the project.
+		prj.Treasury = map[string]client.Amount{}
+		prj.StakeTotals = map[string]client.Amount{}
+		prj.Members = map[string]*Member{}
+		prj.Paused, prj.Dissolved = true, true
+	case *client.ProjectImportedEvent:
+		// The imported members, balances and proposals travel in the call
+		// payload, not in the log, so the project cannot be rebuilt from events.
+		return fmt.Errorf("project %d was imported from %s and cannot be replayed; index from a state dump", e.ProjectID, e.Source)
+	case *client.DividendDistributedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.Treasury[e.Asset] -= e.Amount
+	case *client.DividendClaimedEvent:
+		// Paid from the dividend pool, not the treasury.
+	case *client.DividendReturnedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.Treasury[e.Asset] += e.Amount
+	case *client.HbdStakedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.Treasury["hbd"] -= e.Amount
+		prj.Treasury["hbd_savings"] += e.Amount
+	case *client.HbdUnstakedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.Treasury["hbd_savings"] -= e.Amount
+		prj.HbdUnstaking += e.Amount
+	case *client.HbdReleasedEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.HbdUnstaking -= e.Amount
+		prj.Treasury["hbd"] += e.Amount
+	case *client.HbdInterestEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		prj.Treasury["hbd_savings"] += e.Amount
+	case *client.ProposalCreatedEvent:
+		p := &Proposal{
+			ID: e.ProposalID, ProjectID: e.ProjectID, Creator: e.By, Name: e.Name,
+			Description: e.Description, Metadata: e.Metadata, URL: e.URL,
+			Duration: e.Duration, IsPoll: e.IsPoll, Payouts: e.Payouts, Meta: e.OutcomeMeta,
+			Ballots: map[string]Ballot{},
+		}
+		for _, opt := range e.Options {
+			p.Options = append(p.Options, Option{Text: opt.Text, URL: opt.URL})
+		}
+		s.Proposals[p.ID] = p
+	case *client.ProposalStateEvent:
+		p, err := s.proposal(e.ProposalID)
+		if err != nil {
+			return err
+		}
+		p.State = e.State
+	case *client.ProposalReadyEvent:
+		p, err := s.proposal(e.ProposalID)
+		if err != nil {
+			return err
+		}
+		p.ReadyAt = e.ReadyAt
+		p.Deadline = e.Deadline
+	case *client.ProposalResultEvent:
+		p, err := s.proposal(e.ProposalID)
+		if err != nil {
+			return err
+		}
+		p.Result = e.Result
+	case *client.VoteCastEvent:
+		p, err := s.proposal(e.ProposalID)
+		if err != nil {
+			return err
+		}
+		return p.vote(e)
+	case *client.ProposalConfigEvent:
+		prj, err := s.project(e.ProjectID)
+		if err != nil {
+			return err
+		}
+		return prj.applyConfig(e.Field, e.New)
+	}
+	return nil
+}
+
+func newMember(addr string) *Member {
+	return &Member{Address: addr, Stakes: map[string]client.Amount{}}
+}
+
+func (s *State) project(id uint64) (*Project, error) {
+	prj := s.Projects[id]
+	if prj == nil {
+		return nil, fmt.Errorf("event for unknown project %d", id)
+	}
+	return prj, nil
+}
+
+func (s *State) proposal(id uint64) (*Proposal, error) {
+	p := s.Proposals[id]
+	if p == nil {
+		return nil, fmt.Errorf("event for unknown proposal %d", id)
+	}
+	return p, nil
+}
+
+// vote replaces the voter's previous ballot, mirroring VoteProposal.
+func (p *Proposal) vote(e *client.VoteCastEvent) error {
+	weight := client.Amount(math.Round(e.Weight * 1000))
+	if prev, ok := p.Ballots[e.By]; ok {
+		for _, idx := range dedupe(prev.Choices) {
+			if int(idx) >= len(p.Options) {
+				continue
+			}
+			opt := &p.Options[idx]
+			opt.Weight -= prev.Weight
+			if opt.Weight < 0 {
+				opt.Weight = 0
+			}
+			if opt.Voters > 0 {
+				opt.Voters--
+			}
+		}
+	} else {
+		p.VoterCount++
+	}
+	for _, idx := range dedupe(e.Choices) {
+		if int(idx) >= len(p.Options) {
+			return fmt.Errorf("proposal %d: vote for unknown option %d", p.ID, idx)
+		}
+		p.Options[idx].Weight += weight
+		p.Options[idx].Voters++
+	}
+	p.Ballots[e.By] = Ballot{Choices: e.Choices, Weight: weight}
+	return nil
+}
+
+func dedupe(choices []uint) []uint {
+	seen := map[uint]bool{}
+	out := make([]uint, 0, len(choices))
+	for _, c := range choices {
+		if !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// applyContractConfig applies one contract.config change by its event field name.
+func (ix *Indexer) applyContractConfig(field, value string) error {
+	switch field {
+	case "mode":
+		ix.Mode = value
+	case "pendingOwner":
+		ix.PendingOwner = value
+	case "owner":
+		// Accepting a transfer also clears the offer.
+		ix.Owner, ix.PendingOwner = value, ""
+	case "fee":
+		if value == "" {
+			ix.CreationFee, ix.CreationFeeAsset = 0, ""
+			return nil
+		}
+		amount, asset, ok := strings.Cut(value, " ")
+		fee, err := client.ParseAmount(amount)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid creation fee %q", value)
+		}
+		ix.CreationFee, ix.CreationFeeAsset = fee, asset
+	}
+	return nil
+}
+
+// applyConfig applies one proposal.config change by its event field name.
+func (prj *Project) applyConfig(field, value string) error {
+	var err error
+	switch field {
+	case "threshold":
+		prj.Threshold, err = strconv.ParseFloat(value, 64)
+	case "quorum":
+		prj.Quorum, err = strconv.ParseFloat(value, 64)
+	case "proposalDuration":
+		prj.ProposalDuration, err = strconv.ParseUint(value, 10, 64)
+	case "executionDelay":
+		prj.ExecutionDelay, err = strconv.ParseUint(value, 10, 64)
+	case "leaveCooldown":
+		prj.LeaveCooldown, err = strconv.ParseUint(value, 10, 64)
+	case "proposalCost":
+		prj.ProposalCost, err = client.ParseAmount(value)
+	case "proposalCreatorRestriction":
+		prj.MembersOnly, err = strconv.ParseBool(value)
+	case "whitelistOnly":
+		prj.WhitelistOnly, err = strconv.ParseBool(value)
+	case "iccThreshold":
+		prj.ICCThreshold, err = strconv.ParseFloat(value, 64)
+	case "paused":
+		prj.Paused, err = strconv.ParseBool(value)
+	case "owner":
+		prj.Owner = value
+	case "url":
+		prj.URL = value
+	case "name":
+		prj.Name = value
+	case "description":
+		prj.Description = value
+	case "metadata":
+		prj.Metadata = value
+	case "votingSystem":
+		var vs uint64
+		if vs, err = strconv.ParseUint(value, 10, 8); err == nil {
+			prj.VotingSystem = client.VotingSystem(vs)
+		}
+	case "stakeMin":
+		prj.StakeMin, err = client.ParseAmount(value)
+	default:
+		if asset, ok := strings.CutPrefix(field, "stakeWeight."); ok {
+			var w float64
+			if w, err = strconv.ParseFloat(value, 64); err == nil {
+				if prj.StakeWeights == nil {
+					prj.StakeWeights = map[string]float64{}
+				}
+				prj.StakeWeights[asset] = w
+			}
+		}
+		// Membership NFT settings are not part of the indexed model.
+	}
+	if err != nil {
+		return fmt.Errorf("project %d: invalid %s update %q", prj.ID, field, value)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// A "both" stream logs every event twice; only the JSON copy may count.
+func TestApplyBothFormats(t *testing.T) {
+	stream := []string{
+		`{"v":1,"type":"contract.init","owner":"hive:o","mode":"public","events":"both"}`,
+		"shindao_init|owner:hive:o|mode:public",
+		`{"v":1,"type":"project.created","projectId":0,"by":"hive:a","name":"x","asset":"hive","votingSystem":1}`,
+		"dc|id:0|by:hive:a",
+		`{"v":1,"type":"funds.added","projectId":0,"by":"hive:a","amount":2,"asset":"hive","toStake":true}`,
+		"af|id:0|by:hive:a|am:2.000000|as:hive|s:true",
+		`{"v":1,"type":"member.joined","projectId":0,"by":"hive:b"}`,
+		`{"v":1,"type":"funds.added","projectId":0,"by":"hive:b","amount":1,"asset":"hive","toStake":true}`,
+		`{"v":1,"type":"funds.added","projectId":0,"by":"hive:b","amount":0.5,"asset":"hive","toStake":false}`,
+		`{"v":1,"type":"proposal.created","proposalId":3,"projectId":0,"by":"hive:a","options":[{"text":"no"},{"text":"yes"}]}`,
+		`{"v":1,"type":"vote.cast","proposalId":3,"by":"hive:a","choices":[0],"weight":2}`,
+		`{"v":1,"type":"vote.cast","proposalId":3,"by":"hive:a","choices":[1,1],"weight":2}`,
+		`{"v":1,"type":"vote.cast","proposalId":3,"by":"hive:b","choices":[1],"weight":1}`,
+		`{"v":1,"type":"member.left","projectId":0,"by":"hive:b"}`,
+		`{"v":1,"type":"funds.removed","projectId":0,"to":"hive:b","amount":1,"asset":"hive","fromStake":true}`,
+		"not an event",
+	}
+	ix := New()
+	if err := ix.ApplyLines(stream); err != nil {
+		t.Fatal(err)
+	}
+	prj := ix.State.Projects[0]
+	if prj.StakeTotals["hive"] != 2000 || prj.Treasury["hive"] != 500 || len(prj.Members) != 1 {
+		t.Fatalf("project %+v", prj)
+	}
+	p := ix.State.Proposals[3]
+	if p.VoterCount != 2 || p.Options[0].Weight != 0 || p.Options[1].Weight != 3000 || p.Options[1].Voters != 2 {
+		t.Fatalf("tally %+v", p)
+	}
+	if ix.Owner != "hive:o" {
+		t.Fatalf("owner %q", ix.Owner)
+	}
+}
+
+func TestApplyUnknownProject(t *testing.T) {
+	if err := New().ApplyLine("mj|id:7|by:hive:a"); err == nil {
+		t.Fatal("expected error for unknown project")
+	}
+}
+
+func TestSaveSQL(t *testing.T) {
+	ix := New()
+	ix.ApplyLines([]string{
+		`{"v":1,"type":"project.created","projectId":1,"by":"hive:a","asset":"hive","stakeAssets":{"hbd":0.5}}`,
+		`{"v":1,"type":"funds.added","projectId":1,"by":"hive:a","amount":1,"asset":"hive","toStake":true}`,
+		`{"v":1,"type":"whitelist.changed","projectId":1,"action":"add","addresses":["hive:b"]}`,
+		`{"v":1,"type":"proposal.created","proposalId":0,"projectId":1,"by":"hive:a","options":[{"text":"no"},{"text":"yes"}],` +
+			`"payouts":[{"to":"hive:a","amount":1,"asset":"hive","mode":"ledger"}],"outcomeMeta":{"toggle_pause":"1"}}`,
+		`{"v":1,"type":"vote.cast","proposalId":0,"by":"hive:a","choices":[1],"weight":1}`,
+	})
+	rec := &recorder{}
+	sql.Register("indexer-recorder", rec)
+	db, err := sql.Open("indexer-recorder", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := ix.State.SaveSQL(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{
+		"projects": 1, "project_assets": 2, "members": 1, "member_stakes": 1, "whitelist": 1,
+		"proposals": 1, "proposal_options": 2, "proposal_payouts": 1, "proposal_meta": 1, "ballots": 1,
+	}
+	for table, n := range want {
+		if rec.inserts[table] != n {
+			t.Errorf("%s: %d rows, want %d", table, rec.inserts[table], n)
+		}
+	}
+	if !rec.committed {
+		t.Fatal("transaction not committed")
+	}
+}
+
+// recorder is a database/sql driver that counts inserted rows per table. Its
+// statements report their placeholder count, so database/sql rejects any
+// insert whose arguments do not line up with the query.
+type recorder struct {
+	inserts   map[string]int
+	committed bool
+}
+
+func (r *recorder) Open(string) (driver.Conn, error) { return r, nil }
+func (r *recorder) Prepare(q string) (driver.Stmt, error) {
+	return &recordedStmt{r, q}, nil
+}
+func (r *recorder) Close() error { return nil }
+func (r *recorder) Begin() (driver.Tx, error) {
+	r.inserts = map[string]int{}
+	return r, nil
+}
+func (r *recorder) Commit() error   { r.committed = true; return nil }
+func (r *recorder) Rollback() error { return nil }
+
+type recordedStmt struct {
+	r *recorder
+	q string
+}
+
+func (s *recordedStmt) Close() error  { return nil }
+func (s *recordedStmt) NumInput() int { return strings.Count(s.q, "?") }
+func (s *recordedStmt) Exec([]driver.Value) (driver.Result, error) {
+	if table, ok := strings.CutPrefix(s.q, "INSERT INTO "); ok {
+		s.r.inserts[strings.Fields(table)[0]]++
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s *recordedStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
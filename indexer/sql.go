@@ -0,0 +1,220 @@
+package indexer
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+)
+
+// Schema creates the tables SaveSQL writes. It is plain SQLite-compatible DDL;
+// amounts are stored in base units.
+const Schema = `
+CREATE TABLE IF NOT EXISTS projects (
+	id INTEGER PRIMARY KEY,
+	owner TEXT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	metadata TEXT NOT NULL,
+	url TEXT NOT NULL,
+	funds_asset TEXT NOT NULL,
+	voting_system INTEGER NOT NULL,
+	threshold REAL NOT NULL,
+	quorum REAL NOT NULL,
+	proposal_duration INTEGER NOT NULL,
+	execution_delay INTEGER NOT NULL,
+	leave_cooldown INTEGER NOT NULL,
+	proposal_cost INTEGER NOT NULL,
+	stake_min INTEGER NOT NULL,
+	members_only INTEGER NOT NULL,
+	whitelist_only INTEGER NOT NULL,
+	icc_threshold REAL NOT NULL,
+	paused INTEGER NOT NULL,
+	dissolved INTEGER NOT NULL,
+	hbd_unstaking INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS project_assets (
+	project_id INTEGER NOT NULL,
+	asset TEXT NOT NULL,
+	treasury INTEGER NOT NULL,
+	stake_total INTEGER NOT NULL,
+	stake_weight REAL,
+	PRIMARY KEY (project_id, asset)
+);
+CREATE TABLE IF NOT EXISTS members (
+	project_id INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	reputation INTEGER NOT NULL,
+	PRIMARY KEY (project_id, address)
+);
+CREATE TABLE IF NOT EXISTS member_stakes (
+	project_id INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	asset TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	PRIMARY KEY (project_id, address, asset)
+);
+CREATE TABLE IF NOT EXISTS whitelist (
+	project_id INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	PRIMARY KEY (project_id, address)
+);
+CREATE TABLE IF NOT EXISTS icc_allowlist (
+	project_id INTEGER NOT NULL,
+	target TEXT NOT NULL,
+	PRIMARY KEY (project_id, target)
+);
+CREATE TABLE IF NOT EXISTS proposals (
+	id INTEGER PRIMARY KEY,
+	project_id INTEGER NOT NULL,
+	creator TEXT NOT NULL,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL,
+	metadata TEXT NOT NULL,
+	url TEXT NOT NULL,
+	duration INTEGER NOT NULL,
+	is_poll INTEGER NOT NULL,
+	state TEXT NOT NULL,
+	ready_at INTEGER NOT NULL,
+	deadline INTEGER NOT NULL,
+	result TEXT NOT NULL,
+	voter_count INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS proposal_options (
+	proposal_id INTEGER NOT NULL,
+	idx INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	url TEXT NOT NULL,
+	weight INTEGER NOT NULL,
+	voters INTEGER NOT NULL,
+	PRIMARY KEY (proposal_id, idx)
+);
+CREATE TABLE IF NOT EXISTS proposal_payouts (
+	proposal_id INTEGER NOT NULL,
+	idx INTEGER NOT NULL,
+	address TEXT NOT NULL,
+	amount INTEGER NOT NULL,
+	asset TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	PRIMARY KEY (proposal_id, idx)
+);
+CREATE TABLE IF NOT EXISTS proposal_meta (
+	proposal_id INTEGER NOT NULL,
+	key TEXT NOT NULL,
+	value TEXT NOT NULL,
+	PRIMARY KEY (proposal_id, key)
+);
+CREATE TABLE IF NOT EXISTS ballots (
+	proposal_id INTEGER NOT NULL,
+	voter TEXT NOT NULL,
+	choices TEXT NOT NULL,
+	weight INTEGER NOT NULL,
+	PRIMARY KEY (proposal_id, voter)
+);
+`
+
+var sqlTables = []string{
+	"projects", "project_assets", "members", "member_stakes", "whitelist", "icc_allowlist",
+	"proposals", "proposal_options", "proposal_payouts", "proposal_meta", "ballots",
+}
+
+// SaveSQL replaces the database's snapshot with s in one transaction, creating
+// the tables first if needed. It uses "?" placeholders and needs no specific
+// driver; register one (for example an SQLite driver) and pass the *sql.DB.
+func (s *State) SaveSQL(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, stmt := range strings.Split(Schema, ";") {
+		if strings.TrimSpace(stmt) == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	for _, table := range sqlTables {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			return err
+		}
+	}
+	exec := func(query string, args ...interface{}) {
+		if err == nil {
+			_, err = tx.ExecContext(ctx, query, args...)
+		}
+	}
+	for _, p := range s.Projects {
+		exec(`INSERT INTO projects VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			int64(p.ID), p.Owner, p.Name, p.Description, p.Metadata, p.URL, p.FundsAsset,
+			int64(p.VotingSystem), p.Threshold, p.Quorum, int64(p.ProposalDuration),
+			int64(p.ExecutionDelay), int64(p.LeaveCooldown), int64(p.ProposalCost), int64(p.StakeMin),
+			p.MembersOnly, p.WhitelistOnly, p.ICCThreshold, p.Paused, p.Dissolved, int64(p.HbdUnstaking))
+		assets := map[string]bool{}
+		for a := range p.Treasury {
+			assets[a] = true
+		}
+		for a := range p.StakeTotals {
+			assets[a] = true
+		}
+		for a := range p.StakeWeights {
+			assets[a] = true
+		}
+		for _, a := range sortedKeys(assets) {
+			var weight interface{}
+			if w, ok := p.StakeWeights[a]; ok {
+				weight = w
+			}
+			exec(`INSERT INTO project_assets VALUES (?, ?, ?, ?, ?)`,
+				int64(p.ID), a, int64(p.Treasury[a]), int64(p.StakeTotals[a]), weight)
+		}
+		for _, addr := range sortedKeys(p.Members) {
+			m := p.Members[addr]
+			exec(`INSERT INTO members VALUES (?, ?, ?)`, int64(p.ID), addr, m.Reputation)
+			for _, a := range sortedKeys(m.Stakes) {
+				exec(`INSERT INTO member_stakes VALUES (?, ?, ?, ?)`, int64(p.ID), addr, a, int64(m.Stakes[a]))
+			}
+		}
+		for _, addr := range sortedKeys(p.Whitelist) {
+			exec(`INSERT INTO whitelist VALUES (?, ?)`, int64(p.ID), addr)
+		}
+		for _, target := range sortedKeys(p.ICCAllowlist) {
+			exec(`INSERT INTO icc_allowlist VALUES (?, ?)`, int64(p.ID), target)
+		}
+	}
+	for _, p := range s.Proposals {
+		exec(`INSERT INTO proposals VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			int64(p.ID), int64(p.ProjectID), p.Creator, p.Name, p.Description, p.Metadata, p.URL,
+			int64(p.Duration), p.IsPoll, p.State, p.ReadyAt, p.Deadline, p.Result, int64(p.VoterCount))
+		for i, o := range p.Options {
+			exec(`INSERT INTO proposal_options VALUES (?, ?, ?, ?, ?, ?)`,
+				int64(p.ID), i, o.Text, o.URL, int64(o.Weight), int64(o.Voters))
+		}
+		for i, po := range p.Payouts {
+			exec(`INSERT INTO proposal_payouts VALUES (?, ?, ?, ?, ?, ?)`,
+				int64(p.ID), i, po.To, int64(po.Amount), po.Asset, po.Mode)
+		}
+		for _, k := range sortedKeys(p.Meta) {
+			exec(`INSERT INTO proposal_meta VALUES (?, ?, ?)`, int64(p.ID), k, p.Meta[k])
+		}
+		for _, voter := range sortedKeys(p.Ballots) {
+			b := p.Ballots[voter]
+			exec(`INSERT INTO ballots VALUES (?, ?, ?, ?)`,
+				int64(p.ID), voter, joinChoices(b.Choices), int64(b.Weight))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
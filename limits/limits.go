@@ -0,0 +1,114 @@
+// Package limits holds the amount scale and validation limits of the DAO
+// contract. The contract and the client package both use these values, so a
+// payload built by a client is checked against exactly the bounds the contract
+// enforces.
+package limits
+
+// -----------------------------------------------------------------------------
+// Assets and Addresses
+// -----------------------------------------------------------------------------
+
+// Assets lists the asset names accepted for treasury funds and transfers.
+var Assets = []string{"hbd", "hive", "hbd_savings"}
+
+// AddressPrefixes lists the accepted address namespaces. VSC account addresses
+// are "hive:<username>"; contracts and keys use a "did:" URI.
+var AddressPrefixes = []string{"hive:", "did:"}
+
+// -----------------------------------------------------------------------------
+// Amount Scaling
+// -----------------------------------------------------------------------------
+
+// AmountScale defines the precision multiplier for converting floats to int64.
+const AmountScale = 1000
+
+// -----------------------------------------------------------------------------
+// Validation Limits
+// -----------------------------------------------------------------------------
+
+const (
+	// MaxNameLength limits the size of project and proposal names.
+	MaxNameLength = 128
+	// MaxDescriptionLength limits the size of project and proposal descriptions.
+	MaxDescriptionLength = 512
+	// MaxOptionTextLength limits the size of proposal option text.
+	MaxOptionTextLength = 500
+	// MaxURLLength limits the size of URLs (for projects, proposals, and options).
+	MaxURLLength = 500
+	// MaxProposalOptions limits the number of options per proposal. Each option is
+	// a separate state write plus an entry in the creation event; the wasm heap
+	// exhausts (nil-deref trap) around ~46 options, so this is capped well below
+	// that so the advertised maximum is always reachable, not just parseable.
+	MaxProposalOptions = 40
+	// MaxPayoutReceivers limits the number of payout entries per proposal.
+	MaxPayoutReceivers = 50
+	// MaxWhitelistAddresses limits the number of addresses per whitelist operation.
+	MaxWhitelistAddresses = 50
+	// MaxAddressLength bounds any user-supplied address string. Hive addresses
+	// ("hive:<username>") are short; this cap keeps forged keys/records from
+	// bloating state.
+	MaxAddressLength = 128
+	// MaxTokenIdLength bounds a membership NFT token id. magi_nft token ids are
+	// arbitrary strings (e.g. "alicante-991"), so the id must stay short and safe
+	// to embed in the pipe-delimited create payload and the JSON membership call.
+	MaxTokenIdLength = 128
+	// MaxKickAddresses limits the number of addresses per kick_member operation.
+	MaxKickAddresses = 50
+	// MaxMetaLength bounds the outcome-meta blob. It must accommodate the largest
+	// LEGITIMATE directive, which is whitelist_add/kick_member carrying
+	// MaxWhitelistAddresses (50) x MaxAddressLength (128) plus separators, so it is
+	// necessarily much larger than MaxDescriptionLength.
+	MaxMetaLength = 8192
+	// MaxICCCalls limits inter-contract calls per proposal. Each one is an external
+	// call plus a treasury debit executed inside a single ExecuteProposal.
+	MaxICCCalls = 20
+	// MaxICCExpectLength bounds the expected-result predicate of one
+	// inter-contract call.
+	MaxICCExpectLength = 256
+	// MaxICCResponseLog bounds how much of a callee's response is logged in the
+	// proposal result event.
+	MaxICCResponseLog = 512
+	// MaxExportProposals limits the open proposals carried by a project export.
+	// Each one is copied with its options and ballots inside a single call.
+	MaxExportProposals = 20
+	// MaxICCAllowlistEntries limits the contract:function pairs per allowlist
+	// operation.
+	MaxICCAllowlistEntries = 20
+	// MaxPrerequisites limits the proposals one proposal can wait for. Each is
+	// loaded on every execution attempt.
+	MaxPrerequisites = 10
+	// MinProposalDurationHours enforces a minimum voting period.
+	MinProposalDurationHours = 1
+	// MaxDurationHours caps execution delay and leave cooldown.
+	// Any value * 3600 must stay well within int64, so this also prevents the
+	// deadline/execution-time integer overflow. 10 years is far beyond any real use.
+	MaxDurationHours = 87600
+	// MaxProposalDurationHours caps a single proposal's VOTING period at 90 days.
+	//
+	// This is tighter than MaxDurationHours for a security reason, not a UX one.
+	// Creating a proposal takes a payout lock on every named beneficiary
+	// (CreateProposal -> incrementPayoutLocks), which blocks their project_leave and
+	// their removal via kick_member until the proposal is tallied — and tallying is
+	// impossible before the deadline the CREATOR chose. Since anyone may name anyone
+	// as a beneficiary without consent, the voting period is the exact length of time
+	// one member can freeze another member's stake. At MaxDurationHours that was ten
+	// years; this bounds it to 90 days.
+	//
+	// NOTE: this bounds the griefing window, it does not close it. A hostile member
+	// can still freeze a victim for up to 90 days, and stacking proposals defeats a
+	// per-proposal cancel. Full mitigation requires either taking the lock only once
+	// a proposal PASSES, or letting a named beneficiary decline their own payout —
+	// both change deliberate, test-encoded behaviour and need a product decision.
+	MaxProposalDurationHours = 2160
+	// MinThresholdPercent is the minimum allowed threshold percentage.
+	MinThresholdPercent = 1.0
+	// MaxThresholdPercent is the maximum allowed threshold percentage.
+	MaxThresholdPercent = 100.0
+	// MinQuorumPercent is the minimum allowed quorum percentage.
+	MinQuorumPercent = 1.0
+	// MaxQuorumPercent is the maximum allowed quorum percentage.
+	MaxQuorumPercent = 100.0
+	// MaxStakeWeight caps the voting weight of one unit of an additional stake
+	// asset relative to one unit of the funds asset.
+	MaxStakeWeight = 1000.0
+)
@@ -0,0 +1,15 @@
+//go:build !native && hbdsavings
+
+package sdk
+
+// HBD savings host functions. Nodes that do not provide them refuse to load a
+// module importing them, so they are only linked into builds made with
+// -tags hbdsavings; see HbdSavingsSupported.
+
+const hbdSavingsHost = true
+
+//go:wasmimport sdk hive.stake_hbd
+func hiveStakeHbd(arg1 *string) *string
+
+//go:wasmimport sdk hive.unstake_hbd
+func hiveUnstakeHbd(arg1 *string) *string
@@ -0,0 +1,16 @@
+//go:build !native && !hbdsavings
+
+package sdk
+
+// Builds without the hbdsavings tag do not import the HBD savings host
+// functions. Contracts check HbdSavingsSupported before calling them.
+
+const hbdSavingsHost = false
+
+func hiveStakeHbd(arg1 *string) *string {
+	panic("hive.stake_hbd is not linked into this build")
+}
+
+func hiveUnstakeHbd(arg1 *string) *string {
+	panic("hive.unstake_hbd is not linked into this build")
+}
@@ -0,0 +1,138 @@
+//go:build native
+
+package sdk
+
+// Native builds (go build -tags native) replace the wasm host imports with
+// calls into a Host installed by SetHost, so contracts run as ordinary Go code.
+// The emulator package provides an in-memory Host for tests.
+
+// Host is the set of functions a VSC node exposes to contracts, one method per
+// wasm import. Arguments and results keep the import's string encoding.
+// Abort and Revert must not return: they end the call by panicking.
+type Host interface {
+	Log(msg string)
+	StateSet(key, value string)
+	StateGet(key string) *string
+	StateDelete(key string)
+	Env() string
+	EnvKey(key string) *string
+	GetBalance(address, asset string) string
+	Draw(amount, asset string)
+	Transfer(to, amount, asset string)
+	Withdraw(to, amount, asset string)
+	StakeHbd(amount string)
+	UnstakeHbd(amount string)
+	ContractRead(contractId, key string) *string
+	ContractCall(contractId, method, payload, options string) *string
+	TssCreateKey(keyId, algo string) string
+	TssSignKey(keyId, msg string)
+	TssGetKey(keyId string) string
+	Abort(msg string)
+	Revert(msg, symbol string)
+}
+
+var host Host
+
+// The emulator provides every host function.
+const hbdSavingsHost = true
+
+// SetHost installs the Host every sdk function talks to in native builds.
+func SetHost(h Host) {
+	host = h
+}
+
+func currentHost() Host {
+	if host == nil {
+		panic("sdk: no host installed, call sdk.SetHost first")
+	}
+	return host
+}
+
+func log(s *string) *string {
+	currentHost().Log(*s)
+	return nil
+}
+
+func stateSetObject(key *string, value *string) *string {
+	currentHost().StateSet(*key, *value)
+	return nil
+}
+
+func stateGetObject(key *string) *string {
+	return currentHost().StateGet(*key)
+}
+
+func stateDeleteObject(key *string) *string {
+	currentHost().StateDelete(*key)
+	return nil
+}
+
+func getEnv(arg *string) *string {
+	env := currentHost().Env()
+	return &env
+}
+
+func getEnvKey(arg *string) *string {
+	return currentHost().EnvKey(*arg)
+}
+
+func getBalance(arg1 *string, arg2 *string) *string {
+	bal := currentHost().GetBalance(*arg1, *arg2)
+	return &bal
+}
+
+func hiveDraw(arg1 *string, arg2 *string) *string {
+	currentHost().Draw(*arg1, *arg2)
+	return nil
+}
+
+func hiveTransfer(arg1 *string, arg2 *string, arg3 *string) *string {
+	currentHost().Transfer(*arg1, *arg2, *arg3)
+	return nil
+}
+
+func hiveWithdraw(arg1 *string, arg2 *string, arg3 *string) *string {
+	currentHost().Withdraw(*arg1, *arg2, *arg3)
+	return nil
+}
+
+func hiveStakeHbd(arg1 *string) *string {
+	currentHost().StakeHbd(*arg1)
+	return nil
+}
+
+func hiveUnstakeHbd(arg1 *string) *string {
+	currentHost().UnstakeHbd(*arg1)
+	return nil
+}
+
+func contractRead(contractId *string, key *string) *string {
+	return currentHost().ContractRead(*contractId, *key)
+}
+
+func contractCall(contractId *string, method *string, payload *string, options *string) *string {
+	return currentHost().ContractCall(*contractId, *method, *payload, *options)
+}
+
+func tssCreateKey(keyId *string, algo *string) *string {
+	res := currentHost().TssCreateKey(*keyId, *algo)
+	return &res
+}
+
+func tssSignKey(keyId *string, msgId *string) *string {
+	currentHost().TssSignKey(*keyId, *msgId)
+	return nil
+}
+
+func tssGetKey(keyId *string) *string {
+	res := currentHost().TssGetKey(*keyId)
+	return &res
+}
+
+func abort(msg, file *string, line, column *int32) {
+	currentHost().Abort(*msg)
+}
+
+func revert(msg, symbol *string) {
+	currentHost().Revert(*msg, *symbol)
+}
@@ -2,67 +2,15 @@ package sdk
 
 import (
 	"encoding/hex"
-	_ "okinoko_dao/runtime"
 	"strconv"
 
 	"github.com/CosmWasm/tinyjson"
 )
 
-//go:wasmimport sdk console.log
-func log(s *string) *string
-
 func Log(s string) {
 	log(&s)
 }
 
-//go:wasmimport sdk db.set_object
-func stateSetObject(key *string, value *string) *string
-
-//go:wasmimport sdk db.get_object
-func stateGetObject(key *string) *string
-
-//go:wasmimport sdk db.rm_object
-func stateDeleteObject(key *string) *string
-
-//go:wasmimport sdk system.get_env
-func getEnv(arg *string) *string
-
-//go:wasmimport sdk system.get_env_key
-func getEnvKey(arg *string) *string
-
-//go:wasmimport sdk hive.get_balance
-func getBalance(arg1 *string, arg2 *string) *string
-
-//go:wasmimport sdk hive.draw
-func hiveDraw(arg1 *string, arg2 *string) *string
-
-//go:wasmimport sdk hive.transfer
-func hiveTransfer(arg1 *string, arg2 *string, arg3 *string) *string
-
-//go:wasmimport sdk hive.withdraw
-func hiveWithdraw(arg1 *string, arg2 *string, arg3 *string) *string
-
-//go:wasmimport sdk contracts.read
-func contractRead(contractId *string, key *string) *string
-
-//go:wasmimport sdk contracts.call
-func contractCall(contractId *string, method *string, payload *string, options *string) *string
-
-//go:wasmimport sdk tss.create_key
-func tssCreateKey(keyId *string, algo *string) *string
-
-//go:wasmimport sdk tss.sign_key
-func tssSignKey(keyId *string, msgId *string) *string
-
-//go:wasmimport sdk tss.get_key
-func tssGetKey(keyId *string) *string
-
-//go:wasmimport env abort
-func abort(msg, file *string, line, column *int32)
-
-//go:wasmimport env revert
-func revert(msg, symbol *string)
-
 // Aborts the contract execution
 func Abort(msg string) {
 	ln := int32(0)
@@ -161,6 +109,25 @@ func HiveWithdraw(to Address, amount int64, asset Asset) {
 	hiveWithdraw(&toaddr, &amt, &as)
 }
 
+// HbdSavingsSupported reports whether this build links the HBD savings host
+// functions (hive.stake_hbd, hive.unstake_hbd). Wasm builds link them only
+// with -tags hbdsavings, for nodes that provide them.
+func HbdSavingsSupported() bool {
+	return hbdSavingsHost
+}
+
+// Move HBD from the contract account into its HBD savings.
+func HiveStakeHbd(amount int64) {
+	amt := strconv.FormatInt(amount, 10)
+	hiveStakeHbd(&amt)
+}
+
+// Start moving HBD savings of the contract account back to HBD. The funds arrive once the ledger's savings withdrawal period has passed.
+func HiveUnstakeHbd(amount int64) {
+	amt := strconv.FormatInt(amount, 10)
+	hiveUnstakeHbd(&amt)
+}
+
 // Get a value by key from the contract state of another contract
 func ContractStateGet(contractId string, key string) *string {
 	return contractRead(&contractId, &key)
@@ -59,19 +59,13 @@ func TestBreak_ProjectMetadataLengthBounded(t *testing.T) {
 	assertAborts(t, res, "project metadata exceeds maximum length of 512 characters", "an unbounded project metadata blob was accepted")
 }
 
-// R10-6: over-long URL via update_url governance is rejected at execution.
+// R10-6: over-long URL via update_url governance is rejected at creation.
 func TestBreak_UpdateURLLengthBounded(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
 	big := "https://x/" + strings.Repeat("a", 600)
-	pf := []string{strconv.FormatUint(pid, 10), "u", "d", "1", "", "0", "", "update_url=" + big, ""}
-	propID, ok := createProposalRaw(ct, pf, "hive:someone", "c") // non-gas-asserting create
-	assert.True(t, ok, "proposal creation failed")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	res := tryMetaProposal(ct, pid, "update_url="+big)
 	assertAborts(t, res, "url exceeds maximum length of 500 characters", "an unbounded url was set via governance")
 }
 
@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"okinoko_dao/errcode"
+
 	"vsc-node/lib/test_utils"
 )
 
@@ -131,23 +133,12 @@ func TestBreak_DurationUpperBoundsEnforced(t *testing.T) {
 		transferIntent("1.000"), "hive:someone", defaultTimestamp, "c2")
 	assertAborts(t, res2, "leave cooldown must not exceed", "leave cooldown above the cap")
 
-	// governance outcome: update_executionDelay above the cap.
-	//
-	// NOTE this bound is enforced at EXECUTE time, not at creation — the proposal is
-	// accepted and can pass a full vote before being rejected. That fails closed (the
-	// out-of-range value is never applied) but wastes a governance cycle; validating
-	// meta outcomes at creation would be the friendlier behaviour.
+	// governance outcome: update_executionDelay above the cap. Meta values are
+	// checked when the proposal is created, so it never reaches a vote.
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	fields := []string{strconv.FormatUint(pid, 10), "raise", "d", "1", "", "0", "",
-		"update_executionDelay=" + over, "", "", ""}
-	propID, ok := createProposalRaw(ct, fields, "hive:someone", "p")
-	assert.True(t, ok, "proposal creation itself is not where this is caught")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	exec := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
-	assertAborts(t, exec, "execution delay must not exceed", "executing an out-of-range execution delay")
+	res3 := tryMetaProposal(ct, pid, "update_executionDelay="+over)
+	assertAborts(t, res3, "execution delay must not exceed", "proposing an out-of-range execution delay")
 }
 
 // R14-5: a numeric overflow literal must be rejected in every float config field.
@@ -184,8 +175,9 @@ func TestBreak_OverflowLiteralRejected(t *testing.T) {
 // assertAbortsAny is for negative sites whose exact message legitimately varies
 // (malformed-payload fuzz loops, or a branch that is currently unreachable). It
 // still enforces the property that matters most: the call was rejected BY THE
-// CONTRACT, with a real abort message — not by a host-level error such as
-// "contract not found", which would mean the test never reached its subject.
+// CONTRACT — it reverted with a code from the errcode catalogue — not by a
+// host-level error such as "contract not found", which would mean the test never
+// reached its subject.
 func assertAbortsAny(t *testing.T, res test_utils.ContractTestCallResult, what string, args ...interface{}) {
 	t.Helper()
 	label := fmt.Sprintf(what, args...)
@@ -193,7 +185,7 @@ func assertAbortsAny(t *testing.T, res test_utils.ContractTestCallResult, what s
 	if res.Success {
 		return
 	}
-	assert.Contains(t, res.Ret, "msg:",
-		"%s: rejected by the HOST, not by the contract (got %q) — the test never reached its subject",
-		label, res.Ret)
+	assert.True(t, errcode.Known(fmt.Sprint(res.Err)),
+		"%s: rejected by the HOST, not by the contract (got %v: %q) — the test never reached its subject",
+		label, res.Err, res.Ret)
 }
@@ -38,16 +38,12 @@ func TestBreak_ICCMalformedRejected(t *testing.T) {
 	assertAborts(t, res, "invalid ICC entry format (need contract|function|payload[|assets])", "a 1-component ICC entry was accepted")
 }
 
-// R3-3: a proposal that sets ownership to a non-member must fail at execution.
+// R3-3: a proposal that sets ownership to a non-member must be rejected at creation.
 func TestBreak_UpdateOwnerToNonMemberRejected(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	propID := createPollProposal(t, ct, pid, "1", "", "update_owner=hive:ghost")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	res := tryMetaProposal(ct, pid, "update_owner=hive:ghost")
 	assertAborts(t, res, "new owner must be a member", "ownership was transferred to a non-member via proposal")
 }
 
@@ -209,16 +205,13 @@ func TestBreak_NFTIdUpdateViaProposalAcceptsStringId(t *testing.T) {
 }
 
 // R3-7i: update_membershipNFT via proposal rejects an unsafe (JSON-breaking) id
-// at execution time.
+// at creation time.
 func TestBreak_NFTIdUpdateViaProposalRejectsUnsafeId(t *testing.T) {
 	ct := SetupContractTest()
 	pid := makeProject(t, ct, "1", "50.000", "1")
 	joinWithStake(t, ct, pid, "hive:member2", "100.000")
-	propID := createPollProposal(t, ct, pid, "1", "", `update_membershipNFT=a"b`)
-	assert.True(t, voteRaw(ct, propID, "hive:member2", "1", "v").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	exec := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
-	assertAborts(t, exec, "invalid character in membership nft id", "unsafe id accepted via proposal meta")
+	res := tryMetaProposal(ct, pid, `update_membershipNFT=a"b`)
+	assertAborts(t, res, "invalid character in membership nft id", "unsafe id accepted via proposal meta")
 }
 
 // R3-8: an active proposal cannot be cancelled twice.
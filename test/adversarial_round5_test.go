@@ -31,11 +31,7 @@ func TestBreak_SubMilliunitCostViaMetaRejected(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	propID := createPollProposal(t, ct, pid, "1", "", "update_proposalCost=0.0004")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	res := tryMetaProposal(ct, pid, "update_proposalCost=0.0004")
 	assertAborts(t, res, "proposal cost is below the minimum representable amount", "sub-milliunit proposal cost accepted via meta update")
 }
 
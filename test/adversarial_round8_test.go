@@ -80,15 +80,9 @@ func TestBreak_WhitelistMetaAddressCap(t *testing.T) {
 		}
 		return strings.Join(a, ",")
 	}
-	// 51 addresses via a proposal meta whitelist_add must be rejected at execution.
-	fields := []string{strconv.FormatUint(pid, 10), "wl", "d", "1", "", "0", "", "whitelist_add=" + mk(51), ""}
-	propID, ok := createProposalRaw(ct, fields, "hive:someone", "c")
-	assert.True(t, ok, "proposal creation failed")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
-	assertAborts(t, res, "whitelist_add cannot exceed 50 addresses per proposal", "51-address whitelist_add via proposal meta executed (over cap)")
+	// 51 addresses via a proposal meta whitelist_add must be rejected at creation.
+	res := tryMetaProposal(ct, pid, "whitelist_add="+mk(51))
+	assertAborts(t, res, "whitelist_add cannot exceed 50 addresses per proposal", "51-address whitelist_add via proposal meta accepted (over cap)")
 }
 
 // R8-6: a proposal duration below the project's configured minimum is rejected.
@@ -49,42 +49,30 @@ func TestBreak_InfCostRejected(t *testing.T) {
 	assertAborts(t, res, "invalid proposal cost", "Inf proposal cost")
 }
 
-// R9-6: NaN threshold via a passing governance proposal is rejected at execution.
+// R9-6: NaN threshold via a governance proposal is rejected at creation.
 func TestBreak_NaNThresholdViaMetaRejected(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	propID := createPollProposal(t, ct, pid, "1", "", "update_threshold=NaN")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	res := tryMetaProposal(ct, pid, "update_threshold=NaN")
 	assertAborts(t, res, "threshold must be between 1% and 100%", "NaN threshold set via governance (would brick the DAO)")
 }
 
-// R9-7: NaN quorum via governance is rejected at execution.
+// R9-7: NaN quorum via governance is rejected at creation.
 func TestBreak_NaNQuorumViaMetaRejected(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	propID := createPollProposal(t, ct, pid, "1", "", "update_quorum=NaN")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	res := tryMetaProposal(ct, pid, "update_quorum=NaN")
 	assertAborts(t, res, "quorum must be between 1% and 100%", "NaN quorum set via governance (quorum bypass)")
 }
 
-// R9-8: NaN cost via governance is rejected at execution.
+// R9-8: NaN cost via governance is rejected at creation.
 func TestBreak_NaNCostViaMetaRejected(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	propID := createPollProposal(t, ct, pid, "1", "", "update_proposalCost=NaN")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	res := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	res := tryMetaProposal(ct, pid, "update_proposalCost=NaN")
 	assertAborts(t, res, "invalid proposal cost update", "NaN proposal cost set via governance (free proposals)")
 }
 
@@ -15,6 +15,8 @@ import (
 	"strings"
 	"testing"
 
+	"okinoko_dao/errcode"
+
 	"vsc-node/lib/test_utils"
 	"vsc-node/modules/db/vsc/contracts"
 	ledgerDb "vsc-node/modules/db/vsc/ledger"
@@ -27,8 +29,9 @@ const lateTS = "2025-09-05T00:00:00" // well past a 1h proposal deadline
 
 // rawCallAt invokes the contract WITHOUT the success/failure assertion baked
 // into CallContract, so a test can inspect the raw outcome itself. The result's
-// Ret is normalized to carry the abort message on failure (the host now returns
-// it in ErrMsg), so existing `res.Ret` substring assertions keep working.
+// Ret is normalized to carry the revert message on failure (the host returns it
+// in ErrMsg, with the errcode symbol in Err), so `res.Ret` substring assertions
+// keep working.
 func rawCallAt(ct *test_utils.ContractTest, action string, payload []byte, intents []contracts.Intent, authUser, timestamp, nonce string) test_utils.ContractTestCallResult {
 	if timestamp == "" {
 		timestamp = defaultTimestamp
@@ -270,25 +273,16 @@ func TestBreak_ProposalDurationOverflow(t *testing.T) {
 }
 
 // BREAK 11: Setting leave-cooldown to an absurd value via governance must not be
-// able to permanently trap members' stake. Either the update is bounded, or the
-// member can still eventually exit.
+// able to permanently trap members' stake. The update is bounded at proposal
+// creation, so it never reaches a vote.
 func TestBreak_LeaveCooldownOverflowTrapsStake(t *testing.T) {
 	ct := SetupContractTest()
 	pid := createDefaultProject(t, ct)
 	joinProjectMember(t, ct, pid, "hive:someoneelse")
-	// Pass a meta proposal raising leave cooldown to max uint64.
-	propID := createPollProposal(t, ct, pid, "1", "", "update_leaveCooldown=18446744073709551615")
-	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
-	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
-	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
-	execRes := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
-	if !execRes.Success {
-		return // rejecting the update is acceptable
-	}
-	// Cooldown*3600 overflows int64; check a member can still exit some finite time later.
-	rawCallAt(ct, "project_leave", PayloadUint64(pid), nil, "hive:someoneelse", lateTS, "l1")
-	res := rawCallAt(ct, "project_leave", PayloadUint64(pid), nil, "hive:someoneelse", "2099-01-01T00:00:00", "l2")
-	assert.True(t, res.Success, "leave-cooldown overflow permanently trapped a member's stake")
+	// Cooldown*3600 would overflow int64.
+	createRes := tryMetaProposal(ct, pid, "update_leaveCooldown=18446744073709551615")
+	assertAborts(t, createRes, "leave cooldown must not exceed", "unbounded leave cooldown was accepted")
+	assert.Equal(t, string(errcode.InvalidValue), fmt.Sprint(createRes.Err))
 }
 
 // ============================================================================
@@ -584,6 +578,9 @@ func joinPipe(fields []string) string {
 	return out
 }
 
+// trimMsg strips the whitespace around a result message. The contract reverts
+// with the bare message (its code is the revert symbol in Err); the "msg:"
+// prefix only appears on results from hosts that predate revert.
 func trimMsg(s string) string {
 	for len(s) > 0 && (s[0] == ' ' || s[0] == '\n' || s[0] == '\t') {
 		s = s[1:]
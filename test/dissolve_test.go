@@ -0,0 +1,82 @@
+package contract_test
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Dissolving freezes the project; each member then leaves with their stake
+// and a pro-rata share of the treasury, and every other call aborts.
+func TestDissolveProjectLiquidatesProRata(t *testing.T) {
+	ct := SetupContractTest()
+	pid := makeProject(t, ct, "1", "50.000", "1") // founder stakes 1.000
+	joinWithStake(t, ct, pid, "hive:someoneelse", "3.000")
+	addTreasuryFunds(t, ct, pid, "4.000")
+	propID := createPollProposal(t, ct, pid, "1", "", "dissolve_project=1") // +1.000 proposal cost → treasury 5.000
+
+	founderBefore := hiveBal(ct, "hive:someone")
+	otherBefore := hiveBal(ct, "hive:someoneelse")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someoneelse")
+	assert.True(t, exec.Success, "dissolution failed: %s", exec.Ret)
+	assert.Equal(t, founderBefore, hiveBal(ct, "hive:someone"), "nothing is paid out before the member leaves")
+
+	join := rawCallAt(ct, "project_join", PayloadUint64(pid), transferIntent("1.000"), "hive:member2", lateTS, "j")
+	assertAborts(t, join, "project is dissolved", "join after dissolution")
+	funds := rawCallAt(ct, "project_funds", PayloadString(fmt.Sprintf("%d|false", pid)), transferIntent("1.000"), "hive:someone", lateTS, "f")
+	assertAborts(t, funds, "project is dissolved", "treasury deposit after dissolution")
+
+	// Stake back plus 1/4 and 3/4 of the 5.000 treasury; the last member out takes the dust.
+	leave := rawCallAt(ct, "project_leave", PayloadUint64(pid), nil, "hive:someone", lateTS, "l1")
+	assert.True(t, leave.Success, "founder claim failed: %s", leave.Ret)
+	assert.Equal(t, founderBefore+1000+1250, hiveBal(ct, "hive:someone"), "founder refund/share")
+	leave = rawCallAt(ct, "project_leave", PayloadUint64(pid), nil, "hive:someoneelse", lateTS, "l2")
+	assert.True(t, leave.Success, "member claim failed: %s", leave.Ret)
+	assert.Equal(t, otherBefore+3000+3750, hiveBal(ct, "hive:someoneelse"), "member refund/share")
+
+	again := rawCallAt(ct, "project_leave", PayloadUint64(pid), nil, "hive:someoneelse", lateTS, "l3")
+	assertAborts(t, again, "is not a member", "second claim after dissolution")
+}
+
+// A free-membership project has no stake to weigh by, so the treasury is split evenly.
+func TestDissolveFreeProjectSplitsEvenly(t *testing.T) {
+	ct := SetupContractTest()
+	f := defaultProjectFields()
+	f[9] = "0"
+	res, _, _ := CallContract(t, ct, "project_create", PayloadString(joinPipe(f)), nil, "hive:someone", true, uint(1_000_000_000))
+	pid := parseCreatedID(t, res.Ret, "project")
+	joinProjectMember(t, ct, pid, "hive:someoneelse")
+	joinProjectMember(t, ct, pid, "hive:member2")
+	addTreasuryFunds(t, ct, pid, "2.000")
+	propID := createPollProposal(t, ct, pid, "1", "", "dissolve_project=1") // treasury 3.000
+
+	before := hiveBal(ct, "hive:member2")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someone", "hive:someoneelse")
+	assert.True(t, exec.Success, "dissolution failed: %s", exec.Ret)
+	leave := rawCallAt(ct, "project_leave", PayloadUint64(pid), nil, "hive:member2", lateTS, "l")
+	assert.True(t, leave.Success, "claim failed: %s", leave.Ret)
+	assert.Equal(t, before+1000, hiveBal(ct, "hive:member2"), "even split of 3.000 across 3 members")
+}
+
+// Only an explicit truthy flag is accepted, and a failed vote leaves the project intact.
+func TestDissolveProjectRequiresFlagAndPassingVote(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createDefaultProject(t, ct)
+	joinProjectMember(t, ct, pid, "hive:someoneelse")
+
+	fields := []string{strconv.FormatUint(pid, 10), "wind down", "", "1", "", "0", "", "dissolve_project=0", ""}
+	_, ok := createProposalRaw(ct, fields, "hive:someone", "bad")
+	assert.False(t, ok, "dissolve_project=0 was accepted")
+
+	propID := createPollProposal(t, ct, pid, "1", "", "dissolve_project=1")
+	assert.True(t, voteRaw(ct, propID, "hive:someone", "0", "n1").Success)
+	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "0", "n2").Success)
+	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
+	exec := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", propID)), nil, "hive:someone", lateTS, "e")
+	assertAborts(t, exec, "proposal is failed", "rejected dissolution executed")
+
+	join := rawCallAt(ct, "project_join", PayloadUint64(pid), transferIntent("1.000"), "hive:member2", lateTS, "j")
+	assert.True(t, join.Success, "project unusable after a rejected dissolution: %s", join.Ret)
+}
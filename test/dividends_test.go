@@ -0,0 +1,46 @@
+package contract_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A distribute outcome credits stakers pro-rata; each member claims their share once.
+func TestDistributeDividendProRataClaim(t *testing.T) {
+	ct := SetupContractTest()
+	pid := makeProject(t, ct, "1", "50.000", "1") // founder stakes 1.000
+	joinWithStake(t, ct, pid, "hive:someoneelse", "3.000")
+	addTreasuryFunds(t, ct, pid, "8.000")
+	propID := createPollProposal(t, ct, pid, "1", "", "distribute=4.000:hive")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someoneelse")
+	assert.True(t, exec.Success, "distribute failed: %s", exec.Ret)
+
+	// A member joining after the distribution earns nothing from it.
+	joinWithStake(t, ct, pid, "hive:member2", "4.000")
+	late := rawCallAt(ct, "dividend_claim", PayloadUint64(pid), nil, "hive:member2", lateTS, "c0")
+	assertAborts(t, late, "no dividends to claim", "claim by a member who joined after the distribution")
+
+	before := hiveBal(ct, "hive:someone")
+	assert.True(t, rawCallAt(ct, "dividend_claim", PayloadUint64(pid), nil, "hive:someone", lateTS, "c1").Success)
+	assert.Equal(t, before+1000, hiveBal(ct, "hive:someone"), "1/4 of the stake earns 1/4 of 4.000")
+
+	before = hiveBal(ct, "hive:someoneelse")
+	claim := rawCallAt(ct, "dividend_claim", PayloadString(fmt.Sprintf("%d|hive", pid)), nil, "hive:someoneelse", lateTS, "c2")
+	assert.True(t, claim.Success, "claim failed: %s", claim.Ret)
+	assert.Equal(t, before+3000, hiveBal(ct, "hive:someoneelse"), "3/4 of the stake earns 3/4 of 4.000")
+
+	again := rawCallAt(ct, "dividend_claim", PayloadUint64(pid), nil, "hive:someoneelse", lateTS, "c3")
+	assertAborts(t, again, "no dividends to claim", "second claim")
+}
+
+// Distribution cannot exceed what the treasury actually holds.
+func TestDistributeDividendInsufficientTreasury(t *testing.T) {
+	ct := SetupContractTest()
+	pid := makeProject(t, ct, "1", "50.000", "1")
+	joinWithStake(t, ct, pid, "hive:someoneelse", "3.000")
+	propID := createPollProposal(t, ct, pid, "1", "", "distribute=50.000:hive")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someoneelse")
+	assertAborts(t, exec, "insufficient hive funds in treasury", "distribution larger than the treasury")
+}
@@ -0,0 +1,30 @@
+package contract_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// With events "both" every legacy line is followed by a typed JSON event that
+// carries user text unsanitized.
+func TestJSONEventsLoggedAlongsideLegacy(t *testing.T) {
+	ct := setupContractTestWithMode("public|both")
+	f := defaultProjectFields()
+	f[1] = "a: b; c"
+	_, _, logs := CallContract(t, ct, "project_create", PayloadString(strings.Join(f, "|")),
+		transferIntent("1.000"), "hive:someone", true, uint(1_000_000_000))
+	out := fmt.Sprintf("%v", logs)
+	assert.Contains(t, out, "description:a- b, c", "legacy line missing")
+	assert.Contains(t, out, `{"v":1,"type":"project.created","projectId":`, "json event missing")
+	assert.Contains(t, out, `"description":"a: b; c"`, "json text was sanitized")
+}
+
+// The event format is validated at contract_init.
+func TestEventFormatValidatedAtInit(t *testing.T) {
+	ct := SetupContractTestUninitialized()
+	res := rawCallAt(ct, "contract_init", PayloadString(`{"mode":"public","events":"xml"}`), nil, ownerAddress, defaultTimestamp, "i")
+	assertAborts(t, res, "event format must be", "unknown event format")
+}
@@ -0,0 +1,18 @@
+package contract_test
+
+import "testing"
+
+// The harness builds the contract without -tags hbdsavings, so it links no HBD savings host
+// functions and both savings outcomes are rejected when the proposal is created. The balance
+// checks and interest crediting are covered natively (TestNativeHbdSavingsInterest).
+func TestTreasuryHbdSavingsUnsupported(t *testing.T) {
+	ct := SetupContractTest()
+	pid := makeProject(t, ct, "1", "50.000", "1")
+	joinWithStake(t, ct, pid, "hive:someoneelse", "3.000")
+
+	stake := tryMetaProposal(ct, pid, "treasury_stake_hbd=5.000")
+	assertAborts(t, stake, "treasury_stake_hbd is not supported by this build of the contract", "staking hbd")
+
+	unstake := tryMetaProposal(ct, pid, "treasury_unstake_hbd=1.000")
+	assertAborts(t, unstake, "treasury_unstake_hbd is not supported by this build of the contract", "unstaking hbd")
+}
@@ -145,8 +145,8 @@ func callContractWithTimestamp(t *testing.T, ct *test_utils.ContractTest, action
 		RcLimit:    100000,
 		Intents:    intents,
 	})
-	// The host now returns the abort message in ErrMsg; mirror it into Ret so
-	// existing `res.Ret` substring assertions keep working.
+	// The host returns the revert message in ErrMsg (and the errcode symbol in
+	// Err); mirror it into Ret so `res.Ret` substring assertions keep working.
 	if !result.Success && result.Ret == "" {
 		result.Ret = result.ErrMsg
 	}
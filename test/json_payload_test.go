@@ -0,0 +1,58 @@
+package contract_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Every step of a proposal lifecycle accepts named JSON fields instead of pipe positions.
+func TestJSONPayloadLifecycle(t *testing.T) {
+	ct := SetupContractTest()
+	res, _, _ := CallContract(t, ct, "project_create", PayloadString(
+		`{"name":"json dao","description":"named fields","votingSystem":1,"threshold":50.001,"quorum":50.001,`+
+			`"proposalDuration":1,"executionDelay":0,"leaveCooldown":1,"proposalCost":1,"stakeMin":1,"proposalCreatorRestriction":true}`),
+		transferIntent("1.000"), "hive:someone", true, uint(1_000_000_000))
+	pid := parseCreatedID(t, res.Ret, "project")
+
+	join := rawCallAt(ct, "project_join", PayloadString(fmt.Sprintf(`{"projectId":%d}`, pid)), transferIntent("3.000"), "hive:someoneelse", defaultTimestamp, "j")
+	assert.True(t, join.Success, "json join failed: %s", join.Ret)
+	funds := rawCallAt(ct, "project_funds", PayloadString(fmt.Sprintf(`{"projectId":%d,"toStake":false}`, pid)), transferIntent("5.000"), "hive:someone", defaultTimestamp, "f")
+	assert.True(t, funds.Success, "json funds failed: %s", funds.Ret)
+
+	// The description carries both separators the pipe format reserves.
+	prop := rawCallAt(ct, "proposal_create", PayloadString(fmt.Sprintf(
+		`{"projectId":%d,"name":"grant","description":"a|b;c","duration":1,`+
+			`"payouts":[{"address":"hive:member2","amount":"2.000","asset":"hive"}],"meta":{"update_quorum":"60"}}`, pid)),
+		transferIntent("1.000"), "hive:someone", defaultTimestamp, "p")
+	assert.True(t, prop.Success, "json proposal failed: %s", prop.Ret)
+	propID := parseCreatedID(t, prop.Ret, "proposal")
+
+	// A 50.001% quorum of two members needs both ballots.
+	for i, voter := range []string{"hive:someone", "hive:someoneelse"} {
+		vote := rawCallAt(ct, "proposals_vote", PayloadString(fmt.Sprintf(`{"proposalId":%d,"choices":[1]}`, propID)), nil, voter, defaultTimestamp, fmt.Sprintf("v%d", i))
+		assert.True(t, vote.Success, "json vote by %s failed: %s", voter, vote.Ret)
+	}
+	rawCallAt(ct, "proposal_tally", PayloadString(fmt.Sprintf(`{"proposalId":%d}`, propID)), nil, "hive:someone", lateTS, "t")
+
+	before := hiveBal(ct, "hive:member2")
+	exec := rawCallAt(ct, "proposal_execute", PayloadString(fmt.Sprintf(`{"proposalId":%d}`, propID)), nil, "hive:someone", lateTS, "x")
+	assert.True(t, exec.Success, "json execute failed: %s", exec.Ret)
+	assert.Equal(t, before+2000, hiveBal(ct, "hive:member2"))
+}
+
+// Malformed JSON and misspelt field names are rejected instead of being ignored.
+func TestJSONPayloadRejectsUnknownAndMalformed(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createDefaultProject(t, ct)
+
+	typo := rawCallAt(ct, "project_funds", PayloadString(fmt.Sprintf(`{"projectId":%d,"tostake":true}`, pid)), transferIntent("1.000"), "hive:someone", defaultTimestamp, "a")
+	assertAborts(t, typo, "unknown payload field: tostake", "misspelt field")
+
+	broken := rawCallAt(ct, "project_funds", PayloadString(fmt.Sprintf(`{"projectId":%d,"toStake":}`, pid)), transferIntent("1.000"), "hive:someone", defaultTimestamp, "b")
+	assertAborts(t, broken, "invalid JSON payload", "malformed JSON")
+
+	nested := rawCallAt(ct, "project_funds", PayloadString(`{"projectId":[1],"toStake":false}`), transferIntent("1.000"), "hive:someone", defaultTimestamp, "c")
+	assertAborts(t, nested, "payload field projectId must be a string, number or boolean", "array for a scalar field")
+}
@@ -0,0 +1,45 @@
+package contract_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// An l1 payout leaves the ledger: the treasury is debited but the recipient's ledger balance stays put.
+func TestPayoutL1WithdrawsOffLedger(t *testing.T) {
+	ct := SetupContractTest()
+	pid := makeProject(t, ct, "1", "50.000", "1")
+	joinWithStake(t, ct, pid, "hive:someoneelse", "3.000")
+	addTreasuryFunds(t, ct, pid, "2.000")
+
+	before := hiveBal(ct, "hive:member2")
+	propID := createPollProposal(t, ct, pid, "1", "hive:member2:1.500:hive:l1", "")
+	held := hiveBal(ct, "contract:"+ContractID)
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someoneelse")
+	assert.True(t, exec.Success, "l1 payout failed: %s", exec.Ret)
+	assert.Equal(t, before, hiveBal(ct, "hive:member2"), "l1 payout must not credit the ledger balance")
+	assert.Equal(t, held-1_500, hiveBal(ct, "contract:"+ContractID), "l1 payout must leave the contract's ledger balance")
+
+	// Each proposal's 1.000 cost goes into the treasury: 2.000 + 1.000 - 1.500 + 1.000
+	// leaves 2.500, so a 3.000 payout no longer fits.
+	next := createPollProposal(t, ct, pid, "1", "hive:member2:3.000:hive", "")
+	exec = passAndExecuteAt(t, ct, next, lateTS, "hive:someoneelse")
+	assertAborts(t, exec, "insufficient hive funds in treasury", "payout after l1 withdrawal")
+}
+
+// l1 payouts are limited to Hive accounts and liquid assets.
+func TestPayoutL1Validation(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createDefaultProject(t, ct)
+	cases := []struct{ payout, msg string }{
+		{"did:key:z6Mk:1.000:hive:l1", "l1 payouts require a hive: address"},
+		{"hive:member2:1.000:hbd_savings:l1", "l1 payouts support hive and hbd only"},
+	}
+	for i, c := range cases {
+		fields := []string{fmt.Sprint(pid), "p", "d", "1", "", "0", c.payout, "", ""}
+		res := rawCallAt(ct, "proposal_create", PayloadString(joinPipe(fields)), transferIntent("1.000"), "hive:someone", defaultTimestamp, fmt.Sprintf("c%d", i))
+		assertAborts(t, res, c.msg, "payout %q", c.payout)
+	}
+}
@@ -0,0 +1,90 @@
+package contract_test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// A ballot on a quorate proposal earns ReputationVoteReward exactly once.
+func TestReputationClaimAfterQuorum(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createDefaultProject(t, ct)
+	joinProjectMember(t, ct, pid, "hive:someoneelse")
+	propID := createSimpleProposal(t, ct, pid, "1")
+	assert.True(t, voteRaw(ct, propID, "hive:someone", "1", "v1").Success)
+	assert.True(t, voteRaw(ct, propID, "hive:someoneelse", "1", "v2").Success)
+
+	// Still running: nothing to claim yet.
+	early := rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(propID, 10)), nil, "hive:someoneelse", defaultTimestamp, "c0")
+	assertAborts(t, early, "proposal is active", "claim before tally")
+
+	rawCallAt(ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", lateTS, "t")
+
+	claim := rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(propID, 10)), nil, "hive:someoneelse", lateTS, "c1")
+	assert.True(t, claim.Success, "claim failed: %s", claim.Ret)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(claim.Ret), "10"), "unexpected reputation total %q", claim.Ret)
+
+	again := rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(propID, 10)), nil, "hive:someoneelse", lateTS, "c2")
+	assertAborts(t, again, "reputation already claimed", "second claim for the same ballot")
+}
+
+// Members who did not vote, and ballots on proposals that missed quorum, earn nothing.
+func TestReputationClaimRequiresQuorateBallot(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createDefaultProject(t, ct)
+	joinProjectMember(t, ct, pid, "hive:someoneelse")
+	joinProjectMember(t, ct, pid, "hive:member2")
+
+	// Quorum 50.001% of 3 members needs 2 voters; only one votes.
+	lonely := createSimpleProposal(t, ct, pid, "1")
+	assert.True(t, voteRaw(ct, lonely, "hive:someone", "1", "v1").Success)
+	rawCallAt(ct, "proposal_tally", PayloadUint64(lonely), nil, "hive:someone", lateTS, "t1")
+	res := rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(lonely, 10)), nil, "hive:someone", lateTS, "c1")
+	assertAborts(t, res, "proposal did not reach quorum", "claim on a proposal below quorum")
+
+	quorate := createSimpleProposal(t, ct, pid, "1")
+	assert.True(t, voteRaw(ct, quorate, "hive:someone", "1", "v2").Success)
+	assert.True(t, voteRaw(ct, quorate, "hive:someoneelse", "0", "v3").Success)
+	rawCallAt(ct, "proposal_tally", PayloadUint64(quorate), nil, "hive:someone", lateTS, "t2")
+	res = rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(quorate, 10)), nil, "hive:member2", lateTS, "c2")
+	assertAborts(t, res, "no vote recorded", "claim by a member who did not vote")
+
+	// A losing ballot still counts as participation.
+	res = rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(quorate, 10)), nil, "hive:someoneelse", lateTS, "c3")
+	assert.True(t, res.Success, "losing voter could not claim: %s", res.Ret)
+}
+
+// Executing a passed proposal credits the executor; their later vote claim adds on top.
+func TestReputationExecutorReward(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createDefaultProject(t, ct)
+	joinProjectMember(t, ct, pid, "hive:someoneelse")
+	propID := createSimpleProposal(t, ct, pid, "1")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someone", "hive:someoneelse")
+	assert.True(t, exec.Success, "execute failed: %s", exec.Ret)
+
+	claim := rawCallAt(ct, "reputation_claim", PayloadString(strconv.FormatUint(propID, 10)), nil, "hive:someone", lateTS, "c")
+	assert.True(t, claim.Success, "claim failed: %s", claim.Ret)
+	assert.True(t, strings.HasSuffix(strings.TrimSpace(claim.Ret), "15"), "executor should hold 5 + 10 reputation, got %q", claim.Ret)
+}
+
+// Voting system 2 blends stake with reputation: members without reputation vote
+// with half their stake, and the tally measures them against what the
+// electorate could cast, so a unanimous fresh electorate still clears 60%.
+func TestReputationBlendedVotingHalvesFreshStake(t *testing.T) {
+	ct := SetupContractTest()
+	f := defaultProjectFields()
+	f[2] = "2"
+	f[3] = "60.0"
+	res, _, _ := CallContract(t, ct, "project_create", PayloadString(strings.Join(f, "|")),
+		transferIntent("1.000"), "hive:someone", true, uint(1_000_000_000))
+	pid := parseCreatedID(t, res.Ret, "project")
+	joinWithStake(t, ct, pid, "hive:someoneelse", "1.000")
+
+	propID := createSimpleProposal(t, ct, pid, "1")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someone", "hive:someoneelse")
+	assert.True(t, exec.Success, "unanimous zero-reputation vote did not pass: %s", exec.Ret)
+}
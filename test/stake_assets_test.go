@@ -0,0 +1,61 @@
+package contract_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Additional stake assets count toward voting weight at their configured factor.
+func TestMultiAssetStakeCountsWithWeight(t *testing.T) {
+	ct := SetupContractTest()
+	f := append(defaultProjectFields(), "hbd=0.5")
+	f[2] = "1"
+	f[3] = "60.0"
+	res, _, _ := CallContract(t, ct, "project_create", PayloadString(strings.Join(f, "|")),
+		transferIntent("1.000"), "hive:someone", true, uint(1_000_000_000))
+	pid := parseCreatedID(t, res.Ret, "project")
+	joinWithStake(t, ct, pid, "hive:someoneelse", "1.000")
+
+	before := hbdBal(ct, "hive:someoneelse")
+	stake := rawCallAt(ct, "project_funds", PayloadString(fmt.Sprintf("%d|true", pid)),
+		transferIntentWithToken("4.000", "hbd"), "hive:someoneelse", defaultTimestamp, "s")
+	assert.True(t, stake.Success, "hbd stake failed: %s", stake.Ret)
+	assert.Equal(t, before-4000, hbdBal(ct, "hive:someoneelse"), "hbd was not drawn")
+
+	// 1 hive + 4 hbd * 0.5 = 3 of 4 voting units: 75% clears the 60% threshold alone.
+	propID := createSimpleProposal(t, ct, pid, "1")
+	exec := passAndExecuteAt(t, ct, propID, lateTS, "hive:someoneelse")
+	assert.True(t, exec.Success, "weighted hbd stake did not carry the vote: %s", exec.Ret)
+}
+
+// Without a weight an asset is not a stake asset: staking it lands in the treasury.
+func TestUnweightedAssetStakeGoesToTreasury(t *testing.T) {
+	ct := SetupContractTest()
+	pid := createStakeProject(t, ct)
+	res := rawCallAt(ct, "project_funds", PayloadString(fmt.Sprintf("%d|true", pid)),
+		transferIntentWithToken("1.000", "hbd"), "hive:someone", defaultTimestamp, "s")
+	assert.True(t, res.Success, "deposit failed: %s", res.Ret)
+	assert.Contains(t, res.Ret, "no stake asset provided")
+}
+
+// Stake weights are validated at creation.
+func TestStakeWeightsValidatedAtCreation(t *testing.T) {
+	ct := SetupContractTest()
+	cases := []struct {
+		voting, assets, msg string
+	}{
+		{"0", "hbd=0.5", "additional stake assets require a stake-weighted voting system"},
+		{"1", "hive=2", "hive is the funds asset"},
+		{"1", "hbd=0", "stake weight must be greater than 0"},
+		{"1", "btc=1", "stake asset btc is not supported"},
+	}
+	for i, c := range cases {
+		f := append(defaultProjectFields(), c.assets)
+		f[2] = c.voting
+		res := rawCallAt(ct, "project_create", PayloadString(strings.Join(f, "|")), transferIntent("1.000"), "hive:someone", defaultTimestamp, fmt.Sprintf("c%d", i))
+		assertAborts(t, res, c.msg, "stake assets %q on voting %s", c.assets, c.voting)
+	}
+}
@@ -68,18 +68,9 @@ func TestThresholdBoundsValidViaMetaUpdate(t *testing.T) {
 	projectID := createProjectForMetaTest(t, ct)
 	joinProjectMember(t, ct, projectID, "hive:someoneelse")
 
-	// Create proposal to update threshold to invalid value
-	proposalID := createMetaProposal(t, ct, projectID, "1", "update_threshold=150")
-
-	// Vote and tally
-	voteForProposal(t, ct, proposalID, "hive:someone", "hive:someoneelse")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(proposalID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-
-	// Execute should fail due to invalid threshold
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", proposalID)), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T00:00:00")
-	if !strings.Contains(res.Ret, "threshold must be between") {
-		t.Fatalf("expected threshold bounds rejection on execute, got %q", res.Ret)
-	}
+	// The value could never execute, so the proposal is rejected before any vote
+	res := tryMetaProposal(ct, projectID, "update_threshold=150")
+	assertAborts(t, res, "threshold must be between", "threshold update to 150")
 }
 
 // TestQuorumBoundsValidViaMetaUpdate checks that quorum updates via proposal also validate bounds.
@@ -88,18 +79,9 @@ func TestQuorumBoundsValidViaMetaUpdate(t *testing.T) {
 	projectID := createProjectForMetaTest(t, ct)
 	joinProjectMember(t, ct, projectID, "hive:someoneelse")
 
-	// Create proposal to update quorum to invalid value
-	proposalID := createMetaProposal(t, ct, projectID, "1", "update_quorum=0")
-
-	// Vote and tally
-	voteForProposal(t, ct, proposalID, "hive:someone", "hive:someoneelse")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(proposalID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-
-	// Execute should fail due to invalid quorum
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", proposalID)), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T00:00:00")
-	if !strings.Contains(res.Ret, "quorum must be between") {
-		t.Fatalf("expected quorum bounds rejection on execute, got %q", res.Ret)
-	}
+	// The value could never execute, so the proposal is rejected before any vote
+	res := tryMetaProposal(ct, projectID, "update_quorum=0")
+	assertAborts(t, res, "quorum must be between", "quorum update to 0")
 }
 
 // =============================================================================
@@ -313,18 +295,9 @@ func TestProposalMetaWhitelistAddLimitEnforced(t *testing.T) {
 	}
 	addressesStr := strings.Join(addresses, ",")
 
-	// Create proposal with whitelist_add meta that exceeds limit (uses high gas limit for large payload)
-	proposalID := createMetaProposalWithGas(t, ct, projectID, "1", fmt.Sprintf("whitelist_add=%s", addressesStr), uint(3_000_000_000))
-
-	// Vote and tally
-	voteForProposal(t, ct, proposalID, "hive:someone", "hive:someoneelse")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(proposalID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-
-	// Execute should fail due to 50 address limit for proposal meta
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", proposalID)), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T00:00:00")
-	if !strings.Contains(res.Ret, "cannot exceed 50 addresses") {
-		t.Fatalf("expected max whitelist rejection for proposal meta, got %q", res.Ret)
-	}
+	// Creation fails due to 50 address limit for proposal meta
+	res := tryMetaProposal(ct, projectID, fmt.Sprintf("whitelist_add=%s", addressesStr))
+	assertAborts(t, res, "cannot exceed 50 addresses", "whitelist_add with 51 addresses")
 }
 
 // TestProposalMetaWhitelistRemoveLimitEnforced checks that proposal meta whitelist_remove still has 50 limit.
@@ -340,18 +313,9 @@ func TestProposalMetaWhitelistRemoveLimitEnforced(t *testing.T) {
 	}
 	addressesStr := strings.Join(addresses, ",")
 
-	// Create proposal with whitelist_remove meta that exceeds limit (uses high gas limit for large payload)
-	proposalID := createMetaProposalWithGas(t, ct, projectID, "1", fmt.Sprintf("whitelist_remove=%s", addressesStr), uint(3_000_000_000))
-
-	// Vote and tally
-	voteForProposal(t, ct, proposalID, "hive:someone", "hive:someoneelse")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(proposalID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-
-	// Execute should fail due to 50 address limit for proposal meta
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", proposalID)), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T00:00:00")
-	if !strings.Contains(res.Ret, "cannot exceed 50 addresses") {
-		t.Fatalf("expected max whitelist rejection for proposal meta, got %q", res.Ret)
-	}
+	// Creation fails due to 50 address limit for proposal meta
+	res := tryMetaProposal(ct, projectID, fmt.Sprintf("whitelist_remove=%s", addressesStr))
+	assertAborts(t, res, "cannot exceed 50 addresses", "whitelist_remove with 51 addresses")
 }
 
 // =============================================================================
@@ -480,18 +444,9 @@ func TestMinProposalDurationUpdateViaMetaEnforced(t *testing.T) {
 	projectID := createProjectForMetaTest(t, ct)
 	joinProjectMember(t, ct, projectID, "hive:someoneelse")
 
-	// Create proposal to update proposal duration to 0 (should fail on execute)
-	proposalID := createMetaProposal(t, ct, projectID, "1", "update_proposalDuration=0")
-
-	// Vote and tally
-	voteForProposal(t, ct, proposalID, "hive:someone", "hive:someoneelse")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(proposalID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-
-	// Execute should fail due to invalid duration
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadString(fmt.Sprintf("%d", proposalID)), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T00:00:00")
-	if !strings.Contains(res.Ret, "proposal duration must be at least") {
-		t.Fatalf("expected min duration rejection on execute, got %q", res.Ret)
-	}
+	// A proposal to update proposal duration to 0 is rejected at creation
+	res := tryMetaProposal(ct, projectID, "update_proposalDuration=0")
+	assertAborts(t, res, "proposal duration must be at least", "proposal duration update to 0")
 }
 
 // =============================================================================
@@ -534,6 +489,13 @@ func createMetaProposalWithGas(t *testing.T, ct *test_utils.ContractTest, projec
 	return parseCreatedID(t, res.Ret, "proposal")
 }
 
+// tryMetaProposal submits a meta proposal as hive:someone and returns the raw
+// result, for tests that expect creation to be rejected.
+func tryMetaProposal(ct *test_utils.ContractTest, projectID uint64, meta string) test_utils.ContractTestCallResult {
+	fields := []string{strconv.FormatUint(projectID, 10), "config update", "updating project config", "1", "", "0", "", meta, ""}
+	return rawCallAt(ct, "proposal_create", PayloadString(joinPipe(fields)), transferIntent("1.000"), "hive:someone", defaultTimestamp, "meta")
+}
+
 // createPayoutProposal creates a proposal with payout outcome.
 func createPayoutProposal(t *testing.T, ct *test_utils.ContractTest, projectID uint64, duration string, payouts string) uint64 {
 	fields := []string{
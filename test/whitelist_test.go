@@ -196,30 +196,20 @@ func TestWhitelistOwnerOnlyAccess(t *testing.T) {
 	}
 }
 
-// TestWhitelistProposalInvalidMetadata ensures malformed metadata is rejected during execution.
+// TestWhitelistProposalInvalidMetadata ensures malformed metadata is rejected at proposal creation.
 func TestWhitelistProposalInvalidMetadata(t *testing.T) {
 	ct := SetupContractTest()
 	projectID := createDefaultProject(t, ct)
-	propID := createPollProposal(t, ct, projectID, "1", "", "whitelist_add=")
-	voteForProposal(t, ct, propID, "hive:someone")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadUint64(propID), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T01:00:00")
-	if !strings.Contains(res.Ret, "whitelist_add metadata requires addresses") {
-		t.Fatalf("expected empty whitelist rejection, got %q", res.Ret)
-	}
+	res := tryMetaProposal(ct, projectID, "whitelist_add=")
+	assertAborts(t, res, "whitelist_add metadata requires addresses", "whitelist_add=")
 }
 
 // TestWhitelistToggleInvalidFlag ensures invalid flag values are rejected.
 func TestWhitelistToggleInvalidFlag(t *testing.T) {
 	ct := SetupContractTest()
 	projectID := createDefaultProject(t, ct)
-	propID := createPollProposal(t, ct, projectID, "1", "", "update_whitelistOnly=maybe")
-	voteForProposal(t, ct, propID, "hive:someone")
-	CallContractAt(t, ct, "proposal_tally", PayloadUint64(propID), nil, "hive:someone", true, uint(1_000_000_000), "2025-09-05T00:00:00")
-	res, _, _ := CallContractAt(t, ct, "proposal_execute", PayloadUint64(propID), nil, "hive:someone", false, uint(1_000_000_000), "2025-09-05T01:00:00")
-	if !strings.Contains(res.Ret, "invalid whitelist flag") {
-		t.Fatalf("expected invalid flag rejection, got %q", res.Ret)
-	}
+	res := tryMetaProposal(ct, projectID, "update_whitelistOnly=maybe")
+	assertAborts(t, res, "invalid whitelist flag", "update_whitelistOnly=maybe")
 }
 
 // TestWhitelistAndNFTEnforced ensures whitelist + NFT enforcement combination works.